@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/cache"
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/i18n"
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// loadConfig loads the configuration and verifies the Jira credentials are set,
+// exiting with a helpful message otherwise
+func loadConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		if err == config.ErrConfigNotFound {
+			fatal(exitConfig, "Configuration file not found.\nPlease run 'jql-to-plan config' to create one.")
+		}
+		fatal(exitConfig, "Error loading config: %v", err)
+	}
+
+	if cfg.JiraURL == "" || !cfg.HasCredentials() {
+		fatal(exitConfig, "Error: JIRA_URL and JIRA_PAT (or a Jira session) must be set via environment variables or config file\nRun 'jql-to-plan config' to edit your configuration.")
+	}
+
+	applyLanguage(cfg)
+	return cfg
+}
+
+// applyLanguage translates generated text into the configured language,
+// unless --lang chose one
+func applyLanguage(cfg *config.Config) {
+	if language != "" {
+		return
+	}
+	if err := i18n.SetLanguage(cfg.Language); err != nil {
+		fatal(exitConfig, "Error: invalid language in configuration: %v", err)
+	}
+}
+
+// requireEffortField exits unless the effort custom field is configured
+func requireEffortField(cfg *config.Config) {
+	if cfg.EffortCustomFieldID == "" && cfg.EffortFieldName == "" {
+		fatal(exitConfig, "Error: effort_custom_field_id is not set in configuration.\nThis field is required for this command.\nPlease run 'jql-to-plan config' and uncomment/set the effort_custom_field_id (or effort_field_name).")
+	}
+}
+
+// clientOptions maps the configuration onto Jira client options
+func clientOptions(cfg *config.Config) jira.ClientOptions {
+	return jira.ClientOptions{
+		APIVersion:            cfg.APIVersion,
+		EffortFieldName:       cfg.EffortFieldName,
+		EpicLinkFieldName:     cfg.EpicLinkFieldName,
+		FlaggedCustomFieldID:  cfg.FlaggedCustomFieldID,
+		InProgressStatuses:    cfg.InProgressStatuses,
+		DoneStatuses:          cfg.DoneStatuses,
+		ExcludeIssueTypes:     cfg.ExcludeIssueTypes,
+		CoAssigneeField:       cfg.CoAssigneeField,
+		ReviewerField:         cfg.ReviewerField,
+		IssueTypeEfforts:      cfg.IssueTypeEfforts,
+		ProxyURL:              cfg.ProxyURL,
+		TLSCAFile:             cfg.TLSCAFile,
+		TLSInsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+		RequestTimeout:        cfg.RequestTimeout,
+		EpicBatchSize:         cfg.EpicBatchSize,
+		MaxJQLLength:          cfg.MaxJQLLength,
+		Headers:               cfg.Headers,
+		SessionCookie:         cfg.JiraSessionCookie,
+		Username:              cfg.JiraUsername,
+		Password:              cfg.JiraPassword,
+		NegotiateCommand:      cfg.KerberosTokenCommand,
+		HoursPerDay:           cfg.HoursPerDay,
+		DaysPerWeek:           cfg.DaysPerWeek,
+		EffortUnit:            cfg.EffortFieldUnit,
+		DaysPerPoint:          cfg.DaysPerPoint,
+		Location:              cfg.Location,
+	}
+}
+
+// resolveQuery turns a saved query name or JQL into the JQL to run, expanding
+// placeholders from the configured variables overlaid with --var
+func resolveQuery(cfg *config.Config, query string) string {
+	if saved, ok := cfg.Queries[strings.ToLower(strings.TrimSpace(query))]; ok {
+		query = saved
+	}
+
+	vars := make(map[string]string, len(cfg.Variables)+len(queryVars))
+	for name, value := range cfg.Variables {
+		vars[strings.ToLower(name)] = value
+	}
+	for _, v := range queryVars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			fatal(exitError, "Error: --var %q must have the form name=value", v)
+		}
+		vars[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+
+	jql, err := jira.ExpandJQL(query, vars, time.Now().In(cfg.Location))
+	if err != nil {
+		fatal(exitError, "Error expanding query: %v", err)
+	}
+	return jql
+}
+
+// fullFetchInterval bounds how long --incremental relies on deltas before
+// fetching everything again, which also picks up issues deleted in Jira
+const fullFetchInterval = 24 * time.Hour
+
+// fetchTickets runs the query. With --incremental, only issues updated since
+// the cached previous run are fetched and merged into it.
+func fetchTickets(ctx context.Context, cfg *config.Config, client *jira.Client, opts jira.ClientOptions, jql string) ([]jira.Ticket, map[string]jira.Ticket, error) {
+	if !incremental {
+		return client.GetTickets(ctx, jql)
+	}
+
+	key := cache.Key(cfg.JiraURL, jql, cfg.EffortCustomFieldID, cfg.EpicLinkCustomFieldID, fmt.Sprintf("%+v", opts))
+	started := time.Now()
+
+	entry, err := cache.Load(cfg.CacheDir, key)
+	if err != nil && !os.IsNotExist(err) {
+		term.Warnf("Ignoring unreadable fetch cache: %v", err)
+	}
+
+	var tickets []jira.Ticket
+	var epics map[string]jira.Ticket
+	if entry != nil && started.Sub(entry.FullFetchAt) < fullFetchInterval {
+		tickets, epics, err = client.GetTicketsSince(ctx, jql, entry.FetchedAt, entry.Tickets)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Printf("Fetched changes since %s\n", entry.FetchedAt.Local().Format("2006-01-02 15:04"))
+	} else {
+		tickets, epics, err = client.GetTickets(ctx, jql)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry = &cache.Entry{JQL: jql, FullFetchAt: started}
+	}
+
+	entry.FetchedAt = started
+	entry.Tickets = tickets
+	if err := cache.Save(cfg.CacheDir, key, entry); err != nil {
+		term.Warnf("Could not update fetch cache: %v", err)
+	}
+	return tickets, epics, nil
+}
+
+// newJiraClient creates a Jira client or exits on failure
+func newJiraClient(cfg *config.Config, opts jira.ClientOptions) *jira.Client {
+	if cfg.TLSInsecureSkipVerify {
+		term.Warnf("TLS certificate verification is disabled (tls_insecure_skip_verify)")
+	}
+
+	client, err := jira.NewClient(cfg.JiraURL, cfg.JiraPAT, cfg.EffortCustomFieldID, cfg.EpicLinkCustomFieldID, withTracing(opts))
+	if err != nil {
+		fatal(exitConfig, "Error creating Jira client: %v", err)
+	}
+	jiraClients = append(jiraClients, client)
+	return client
+}
+
+// traceFile is the --debug-http-file dump, opened by the first client
+var traceFile *os.File
+
+// withTracing adds --debug-http and --debug-http-file tracing to opts. It is
+// applied when creating clients, not in clientOptions, so the fetch cache key
+// does not change with tracing.
+func withTracing(opts jira.ClientOptions) jira.ClientOptions {
+	if debugHTTP || debugHTTPFile != "" {
+		opts.Trace = os.Stderr
+	}
+	if debugHTTPFile != "" {
+		if traceFile == nil {
+			f, err := os.Create(debugHTTPFile)
+			if err != nil {
+				fatal(exitWrite, "Error creating --debug-http-file: %v", err)
+			}
+			traceFile = f
+		}
+		opts.TraceBodies = traceFile
+	}
+	return opts
+}
+
+// fatalJira exits with an error from a Jira request, adding a hint on how to
+// fix the common causes. The exit code tells rejected credentials and queries
+// apart from Jira being unavailable.
+func fatalJira(what string, err error) {
+	var authErr *jira.AuthError
+	var jqlErr *jira.JQLSyntaxError
+	code := exitJira
+	switch {
+	case errors.As(err, &authErr):
+		code = exitAuth
+	case errors.As(err, &jqlErr):
+		code = exitJQL
+	}
+	fatal(code, "Error %s: %v%s", what, err, jiraHint(err))
+}
+
+// jiraHint suggests what to do about a typed Jira error, or returns ""
+func jiraHint(err error) string {
+	var authErr *jira.AuthError
+	var jqlErr *jira.JQLSyntaxError
+	var rateErr *jira.RateLimitError
+	var notFound *jira.NotFoundError
+	switch {
+	case errors.As(err, &authErr):
+		return "\nCheck jira_pat (or jira_username, jira_password and jira_session_cookie) in your configuration, or run 'jql-to-plan doctor'."
+	case errors.As(err, &jqlErr):
+		return "\nTry the query in Jira's issue search to see what is wrong with it."
+	case errors.As(err, &rateErr) && rateErr.RetryAfter > 0:
+		return fmt.Sprintf("\nJira is throttling requests; try again in %s.", rateErr.RetryAfter)
+	case errors.As(err, &rateErr):
+		return "\nJira is throttling requests; try again later."
+	case errors.As(err, &notFound):
+		return "\nCheck jira_url and api_version in your configuration."
+	}
+	return ""
+}
+
+// runContext returns the context for a whole run, bounded by run_timeout if
+// set and cancelled by SIGINT or SIGTERM. After the first signal, a second one
+// terminates the process as usual.
+func runContext(cfg *config.Config) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if cfg.RunTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), cfg.RunTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"strings"
 
 	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +18,21 @@ import (
 const configTemplate = `# Jira Configuration
 jira_url: "https://your-domain.atlassian.net"
 jira_pat: "your-personal-access-token"
+# Run 'jql-to-plan config encrypt-pat' to store the PAT encrypted instead.
+# Or refer to a secret fetched at startup: "vault://secret/data/jira#pat"
+# (HashiCorp Vault) or "aws-sm://jira-pat" (AWS Secrets Manager).
+
+# Optional: For Data Center behind an SSO proxy that blocks PATs, log in to a
+# Jira session instead, or use a session cookie copied from the browser
+# (all cookies of the Jira site, as sent in the Cookie header).
+# jira_username: "planner"
+# jira_password: "vault://secret/data/jira#password"
+# jira_session_cookie: "JSESSIONID=...; SSO_TOKEN=..."
+
+# Optional: Kerberos (SPNEGO) authentication. The command prints a base64
+# token for the Jira host ({host}) using the ticket from kinit; see the README
+# for a helper based on python-gssapi.
+# kerberos_token_command: ["jira-spnego", "{host}"]
 
 # Optional: Custom Field ID for Effort (e.g. customfield_10105)
 # This is required for accurate effort estimation in the Gantt chart.
@@ -22,6 +41,268 @@ jira_pat: "your-personal-access-token"
 # Optional: Custom Field ID for Epic Link (e.g. customfield_11000)
 # This is required for grouping tasks by Epic.
 # epic_link_custom_field_id: "11000"
+
+# Optional: Custom Field ID for Flagged (e.g. customfield_10021)
+# Flagged (impeded) issues are highlighted in the generated plan.
+# flagged_custom_field_id: "10021"
+
+# Optional: Field holding a ticket's team, by ID or name (e.g. "Team").
+# Unassigned tickets with a team are assigned to a resource for the team.
+# team_field: "Team"
+
+# Optional: User or multi-user field whose users share each task with its
+# assignee, by ID or name. The assignee gets primary_assignee_units (below 1)
+# of the work and the others split the rest; by default everyone gets an
+# equal share.
+# co_assignee_field: "Co-assignees"
+# primary_assignee_units: 0.6
+
+# Optional: User field holding a ticket's reviewer, by ID or name. With
+# --review-tasks, a "Review: <summary>" task of review_effort (default 0.25d)
+# is assigned to the reviewer after each reviewed ticket.
+# reviewer_field: "Code Reviewer"
+# review_effort: "2h"
+
+# Optional: Field holding a ticket's risk or confidence level, by ID or name.
+# Effort is multiplied per level, and padded tasks are marked in their note.
+# risk_field: "Risk"
+# risk_multipliers:
+#   High: 1.5
+#   Medium: 1.2
+
+# Optional: Select the custom fields by name instead. The names are resolved
+# against Jira at runtime and win over a mismatching ID above.
+# effort_field_name: "Story Points"
+# epic_link_field_name: "Epic Link"
+
+# Optional: Statuses that mark work as started and finished, used by
+# --actual-start and the reports.
+# in_progress_statuses: ["In Progress"]
+# done_statuses: ["Done", "Closed", "Resolved"]
+
+# Optional: Per issue type behavior. Excluded types are left out of plans and
+# reports, default efforts (days or durations) apply to tickets of that type
+# without an estimate instead of 1 day, and prefixes are prepended to task
+# titles. issue_type_efforts is the older form, in days only, which
+# 'jql-to-plan config migrate' converts; a type set in both takes its
+# default_effort.
+# exclude_issue_types: ["Sub-task"]
+# default_effort:
+#   Bug: "0.5d"
+#   Story: "2d"
+#   Spike: "4h"
+# issue_type_prefixes:
+#   Bug: "🐞 "
+#   Spike: "🔬 "
+
+# Optional: Go template for each task's note, executed with the ticket. The
+# note_fields are fetched and available by name with index .Fields; join
+# joins a list and date formats a date as YYYY-MM-DD.
+# note_fields: ["description", "Acceptance Criteria", "Sprint"]
+# note_template: |
+#   {{index .Fields "description"}}
+#   {{with index .Fields "Acceptance Criteria"}}Acceptance criteria: {{.}}{{end}}
+#   Sprint: {{index .Fields "Sprint"}}{{with date .DueDate}}, due {{.}}{{end}}
+#   Labels: {{join .Labels}}
+
+# Optional: Longest task and epic title, in characters. Longer Jira summaries
+# are cut with "…" and kept whole in the task note and "Jira Summary" data.
+# max_title_length: 80
+
+# Optional: Font and colors of generated plans, instead of OmniPlan's default
+# gray. Colors are hex sRGB values; completed tasks are also struck through.
+# styles:
+#   font: "Helvetica Neue"
+#   font_size: 12
+#   group_color: "#1f3a5f"
+#   milestone_color: "#e3a008"
+#   completed_color: "#8a8a8a"
+
+# Optional: Saved queries, passed by name in place of the JQL argument, and
+# values for {{name}} placeholders in queries. Built-in placeholders are
+# {{me}}, {{sprint.active}}, {{sprint.future}}, {{sprint.closed}} and
+# {{today}}, optionally offset like {{today-14d}} or {{today+2w}}.
+# Variables can also be set per run with --var name=value.
+# queries:
+#   open-work: "project = {{project}} AND sprint in {{sprint.active}} AND statusCategory != Done"
+#   my-recent: "assignee = {{me}} AND updated >= {{today-14d}}"
+# variables:
+#   project: "PROJ"
+
+# Optional: Jira REST API version used for searches. "2" (default) works for
+# Server, Data Center and Cloud; "3" uses the new Cloud /search/jql endpoint.
+# api_version: "2"
+
+# Optional: Proxy used for all Jira requests. When unset, the standard
+# HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment variables are honored.
+# SOCKS5 proxies such as an SSH dynamic tunnel (ssh -D 1080 bastion) work too,
+# resolving the Jira host on the far side: "socks5://127.0.0.1:1080".
+# proxy_url: "http://proxy.example.com:3128"
+
+# Optional: Trust an internal CA (PEM bundle) for self-hosted Jira.
+# tls_ca_file: "/etc/ssl/certs/corp-ca.pem"
+# Disables certificate verification entirely. Use only for testing.
+# tls_insecure_skip_verify: false
+
+# Optional: Timeout for each Jira request (default 60s) and an overall
+# deadline for the whole run (default none). Go duration syntax, e.g. "90s", "5m".
+# request_timeout: "60s"
+# run_timeout: "10m"
+
+# Optional: Epics are fetched in batches of epic_batch_size keys (default 50),
+# with the bulk fetch endpoint when api_version is "3". For servers or
+# proxies with small URL or JQL limits, max_jql_length caps the length of the
+# "key in (...)" queries. Rejected queries are retried in smaller batches.
+# epic_batch_size: 50
+# max_jql_length: 2000
+
+# Optional: Effort values may be numbers of days or Jira-style durations such
+# as "2w 3d 4h" or "1.5d". These convert weeks and hours into days
+# (default 8 hours per day, 5 days per week).
+# hours_per_day: 8
+# days_per_week: 5
+
+# Optional: Unit of plain numbers in the effort field: days (default), hours
+# or points. Story points are converted with days_per_point (default 1).
+# effort_field_unit: "days"
+# days_per_point: 0.5
+
+# Optional: IANA time zone for due dates, changelog timestamps, schedule dates
+# and {{today}} (default: the system time zone). Set this when plans are
+# generated in a different region than the team works in.
+# timezone: "Europe/Oslo"
+
+# Optional: Date format for forecasts, burndown charts and Confluence pages
+# (default "YYYY-MM-DD"). Tokens: YYYY, YY, MMMM (January), MMM (Jan), MM, M,
+# DD, D, dddd (Monday), ddd (Mon). Month and day names are English.
+# CSV output and TaskPaper tags always use YYYY-MM-DD.
+# date_format: "DD.MM.YYYY"
+
+# Optional: Language of generated text: milestone, buffer and part titles,
+# notes and warnings. Built in are "de" (German) and "nb" (Norwegian Bokmål);
+# other languages take the path of a YAML catalog mapping English messages to
+# translations. --lang overrides this. Reports and Jira data are unchanged.
+# language: "de"
+
+# Optional: Extra headers sent with every Jira request, e.g. for API gateways.
+# headers:
+#   X-ApiGateway-Key: "gateway-key"
+#   User-Agent: "jql-to-plan"
+
+# Optional: Post a summary of plan changes to a chat webhook whenever
+# an existing plan is regenerated. Type is "slack" (default) or "teams".
+# notify_webhook_url: "https://hooks.slack.com/services/..."
+# notify_webhook_type: "slack"
+
+# Optional: Upload the generated package after every run.
+# S3 uses AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY, GCS uses an OAuth access token.
+# upload_destinations:
+#   - "s3://my-bucket/plans"
+#   - "gs://my-bucket/plans"
+#   - "https://dav.example.com/remote.php/webdav/plans"
+# s3_region: "eu-west-1"
+# s3_endpoint: "https://minio.example.com"
+# gcs_access_token: "ya29...."
+# webdav_username: "planner"
+# webdav_password: "secret"
+
+# Optional: Author of the entries added to the plan's __changelog.xml on each
+# run (default: the current user)
+# changelog_author: "Jane Planner"
+
+# Optional: Directory for 'snapshot' history (default ~/.jql-to-plan/snapshots)
+# snapshot_dir: "/path/to/snapshots"
+
+# Optional: Directory for the --incremental fetch cache (default ~/.jql-to-plan/cache)
+# cache_dir: "/path/to/cache"
+
+# Optional: Plans 'schedule' regenerates whenever their cron expression fires,
+# in the configured timezone: minute, hour, day of month, month and day of
+# week, or @daily, @weekly and the like. query is JQL or a saved query name,
+# output the directory the plan is written to, upload destinations added to
+# upload_destinations, and flags the command-line flags without dashes.
+# jobs:
+#   - name: "Payments weekly"
+#     cron: "0 7 * * mon"
+#     project: "Payments"
+#     query: "payments"
+#     output: "/srv/plans"
+#     upload: ["s3://my-bucket/plans"]
+#     flags:
+#       epic-group: "true"
+
+# Optional: SMTP server used by --email-to to mail the zipped package.
+# Port 465 uses implicit TLS, other ports use STARTTLS when available.
+# smtp:
+#   host: "smtp.example.com"
+#   port: 587
+#   username: "planner@example.com"
+#   password: "secret"
+#   from: "planner@example.com"
+
+# Optional: Confluence instance used by 'publish confluence'. The page is
+# created under parent_id (a page ID) if set, and updated on later runs.
+# confluence:
+#   url: "https://confluence.example.com"
+#   pat: "your-confluence-personal-access-token"
+#   space: "PROJ"
+#   parent_id: "123456"
+
+# Optional: monday.com board source used by 'monday'. The token (or the
+# MONDAY_API_TOKEN environment variable) is a personal API token; columns map
+# board columns, by column ID, onto the plan. dates is a date column (due
+# dates) or a timeline column (start and end constraints).
+# monday:
+#   token: "your-monday-api-token"
+#   columns:
+#     effort: "numbers"
+#     owner: "person"
+#     dependencies: "dependency"
+#     status: "status"
+#     dates: "timeline"
+
+# Optional: Notion database source used by 'notion'. The token (or the
+# NOTION_TOKEN environment variable) is an internal integration token, and the
+# database must be shared with the integration. properties map database
+# properties, by name, onto the plan.
+# notion:
+#   token: "secret_your-notion-integration-token"
+#   properties:
+#     status: "Status"
+#     estimate: "Estimate"
+#     owner: "Assignee"
+#     dependencies: "Blocked by"
+#     dates: "Dates"
+
+# Optional: Fields for --wsjf (Weighted Shortest Job First), by ID or name.
+# The cost of delay is the sum of the fields, divided by the job size
+# (default: the effort in days).
+# wsjf:
+#   cost_of_delay_fields: ["Business Value", "Time Criticality", "Risk Reduction"]
+#   job_size_field: "Job Size"
+
+# Optional: Rules adding standard downstream work after matching tickets,
+# e.g. QA and deployment of every Payments story. A rule matches tickets of
+# issue_types (all if unset) whose field, by ID or name, has one of values.
+# Its tasks follow the ticket one after the other.
+# follow_up_rules:
+#   - issue_types: ["Story"]
+#     field: "components"
+#     values: ["Payments"]
+#     tasks:
+#       - title: "QA"
+#         effort: "0.5d"
+#       - title: "Deploy"
+#         effort: "0.25d"
+#         assignee: "Release Manager"
+
+# Optional: External commitments added to every plan as milestones fixed to
+# their date. depends_on lists ticket or epic keys that must be done first;
+# OmniPlan shows a violation when that work runs past the date.
+# milestones:
+#   - name: "Marketing launch"
+#     date: "2025-10-01"
+#     depends_on: ["PROJ-100"]
 `
 
 var configCmd = &cobra.Command{
@@ -31,16 +312,14 @@ var configCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		configPath, err := config.GetConfigPath()
 		if err != nil {
-			fmt.Printf("Error getting config path: %v\n", err)
-			os.Exit(1)
+			fatal(exitConfig, "Error getting config path: %v", err)
 		}
 
 		// Check if config file exists
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
 			// Create the file with template content
 			if err := os.WriteFile(configPath, []byte(configTemplate), 0600); err != nil {
-				fmt.Printf("Error creating config file at %s: %v\n", configPath, err)
-				os.Exit(1)
+				fatal(exitWrite, "Error creating config file at %s: %v", configPath, err)
 			}
 			fmt.Printf("Created new configuration file at: %s\n", configPath)
 		} else {
@@ -52,8 +331,117 @@ var configCmd = &cobra.Command{
 	},
 }
 
+var configEncryptPATCmd = &cobra.Command{
+	Use:   "encrypt-pat",
+	Short: "Encrypt the Jira PAT stored in the configuration file",
+	Long: `Reads a Personal Access Token from stdin, encrypts it and stores it as jira_pat in the configuration file.
+
+The key is derived from the passphrase in $` + secrets.PassphraseEnv + ` if set, otherwise from a
+machine key file ($HOME/.jql-to-plan.key) that is created on first use. The PAT is decrypted
+transparently whenever the configuration is loaded.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The file Load reads, which may be in the current directory or
+		// named by JQL_TO_PLAN_CONFIG rather than in the home directory
+		configPath, err := config.FindFile()
+		if err != nil {
+			fatal(exitConfig, "Error finding config file: %v\nPlease run 'jql-to-plan config' first.", err)
+		}
+
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			fatal(exitConfig, "Error reading config file: %v", err)
+		}
+
+		fmt.Print("Jira PAT: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fatal(exitError, "Error reading PAT: %v", err)
+		}
+		pat := strings.TrimSpace(line)
+		if pat == "" {
+			fatal(exitError, "Error: PAT must not be empty")
+		}
+
+		encrypted, err := secrets.Encrypt(pat)
+		if err != nil {
+			fatal(exitError, "Error encrypting PAT: %v", err)
+		}
+
+		entry := fmt.Sprintf("jira_pat: %q", encrypted)
+		patLine := regexp.MustCompile(`(?m)^jira_pat:.*$`)
+		var updated string
+		if patLine.Match(content) {
+			updated = patLine.ReplaceAllLiteralString(string(content), entry)
+		} else {
+			updated = strings.TrimRight(string(content), "\n") + "\n" + entry + "\n"
+		}
+
+		if err := os.WriteFile(configPath, []byte(updated), 0600); err != nil {
+			fatal(exitWrite, "Error writing config file: %v", err)
+		}
+		fmt.Printf("Stored encrypted PAT in %s\n", configPath)
+	},
+}
+
+var migrateDryRun bool
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [file]",
+	Short: "Upgrade the configuration file to the current settings",
+	Long: `Rewrites outdated settings in the configuration file (the one commands read unless a file
+is given) in their current form and reports each change, keeping the original as <file>.bak.
+For example, issue_type_efforts is moved into default_effort. Comments are kept, though the file
+is reformatted. Problems that need a decision, such as unknown keys, are listed afterwards.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var configPath string
+		if len(args) > 0 {
+			configPath = args[0]
+		} else {
+			var err error
+			if configPath, err = config.FindFile(); err != nil {
+				fatal(exitConfig, "Error finding config file: %v", err)
+			}
+		}
+
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			fatal(exitConfig, "Error reading config file: %v", err)
+		}
+		migrated, changes, err := config.Migrate(content)
+		if err != nil {
+			fatal(exitConfig, "Error parsing %s: %v", configPath, err)
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("%s is up to date\n", configPath)
+		} else {
+			for _, change := range changes {
+				fmt.Printf("  %s\n", change)
+			}
+			if migrateDryRun {
+				fmt.Printf("Would migrate %s (%d change(s))\n", configPath, len(changes))
+				return
+			}
+			if err := os.WriteFile(configPath+".bak", content, 0600); err != nil {
+				fatal(exitWrite, "Error backing up config file: %v", err)
+			}
+			if err := os.WriteFile(configPath, migrated, 0600); err != nil {
+				fatal(exitWrite, "Error writing config file: %v", err)
+			}
+			fmt.Printf("Migrated %s (%d change(s)); the original is in %s.bak\n", configPath, len(changes), configPath)
+		}
+
+		if err := config.ValidateFile(configPath); err != nil {
+			fatal(exitConfig, "Remaining problems to fix by hand:\n%v", err)
+		}
+	},
+}
+
 func init() {
-	// No flags needed for now
+	configCmd.AddCommand(configEncryptPATCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Report the changes without writing the file")
 }
 
 func openInEditor(path string) {
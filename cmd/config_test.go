@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/secrets"
+)
+
+func TestConfigEncryptPAT_WritesConfigFileUsed(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string) // Makes the file in dir the one Load reads
+	}{
+		{
+			name: "named by the environment",
+			setup: func(t *testing.T, dir string) {
+				t.Setenv(config.ConfigFileEnv, filepath.Join(dir, ".jql-to-plan.yaml"))
+			},
+		},
+		{
+			name: "in the current directory",
+			setup: func(t *testing.T, dir string) {
+				t.Setenv(config.ConfigFileEnv, "")
+				t.Chdir(dir)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv(secrets.PassphraseEnv, "correct horse battery staple")
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".jql-to-plan.yaml")
+			original := "jira_url: https://jira.example.com\njira_pat: old-pat\nteam_field: Team\n"
+			if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(t, dir)
+
+			stdin := filepath.Join(t.TempDir(), "stdin")
+			if err := os.WriteFile(stdin, []byte("new-pat\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(stdin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			saved := os.Stdin
+			os.Stdin = f
+			defer func() { os.Stdin = saved }()
+
+			configEncryptPATCmd.Run(configEncryptPATCmd, nil)
+
+			if _, err := os.Stat(filepath.Join(home, ".jql-to-plan.yaml")); !os.IsNotExist(err) {
+				t.Errorf("Expected no config file in the home directory, got %v", err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(content), "old-pat") || !strings.Contains(string(content), "team_field: Team\n") {
+				t.Errorf("Expected only jira_pat to be replaced:\n%s", content)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if cfg.JiraPAT != "new-pat" {
+				t.Errorf("Decrypted PAT = %q, want new-pat", cfg.JiraPAT)
+			}
+		})
+	}
+}
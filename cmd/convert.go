@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/csvplan"
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/mspdi"
+	"github.com/gunnarrb/jql-to-plan/internal/omnijs"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/gunnarrb/jql-to-plan/internal/smartsheet"
+	"github.com/gunnarrb/jql-to-plan/internal/snapshot"
+	"github.com/gunnarrb/jql-to-plan/internal/taskpaper"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/gunnarrb/jql-to-plan/internal/xer"
+	"github.com/spf13/cobra"
+)
+
+var convertOutput string
+var convertFrom string
+var convertTo string
+
+// convertExtensions are the file extensions of the convert formats
+var convertExtensions = map[string]string{
+	"omniplan":   ".oplx",
+	"mspdi":      ".xml",
+	"json":       ".json",
+	"csv":        ".csv",
+	"taskpaper":  ".taskpaper",
+	"smartsheet": ".xlsx",
+	"xer":        ".xer",
+	"omnijs":     ".js",
+}
+
+// writeOnlyFormats are the convert formats that cannot be read
+var writeOnlyFormats = []string{"taskpaper", "smartsheet", "xer", "omnijs"}
+
+// convertedPlan is a plan read by convert, ready for any exporter
+type convertedPlan struct {
+	name       string
+	tickets    []jira.Ticket
+	epics      map[string]jira.Ticket
+	milestones []omniplan.Milestone
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [input]",
+	Short: "Convert a plan between formats without Jira",
+	Long: `Converts a plan from one format to another, without connecting to Jira.
+
+Input formats (--from, default by extension):
+  omniplan   .oplx package, generated or hand-made
+  mspdi      Microsoft Project XML (.xml)
+  json       Plan snapshot (.json), as written by 'snapshot' or convert
+  csv        One ticket per row (.csv)
+
+Output formats (--to, default by the --output extension, else omniplan):
+  omniplan, json, csv, taskpaper (OmniFocus outline), smartsheet (.xlsx for
+  Smartsheet import), xer (Primavera P6) and omnijs (OmniPlan automation script)
+
+Groups and summary tasks nest their tasks, MS Project milestones become fixed-date
+milestones, and all links are finish-to-start. Only OmniPlan keeps milestones.
+Dates use the configured timezone, if any.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		input := filepath.Clean(args[0])
+		from := convertFrom
+		if from == "" {
+			from = formatOf(input)
+		}
+		if from == "" || slices.Contains(writeOnlyFormats, from) {
+			fatal(exitError, "Error: cannot tell the format of %s; set --from to omniplan, mspdi, json or csv", input)
+		}
+		to := convertTo
+		if to == "" && convertOutput != "" {
+			to = formatOf(convertOutput)
+		}
+		if to == "" {
+			to = "omniplan"
+		}
+		if to == "mspdi" {
+			fatal(exitError, "Error: --to mspdi is not supported (expected omniplan, json, csv, taskpaper, smartsheet, xer or omnijs)")
+		}
+		if _, ok := convertExtensions[to]; !ok {
+			fatal(exitError, "Error: unsupported --to format %q (expected omniplan, json, csv, taskpaper, smartsheet, xer or omnijs)", to)
+		}
+		output := convertOutput
+		if output == "" {
+			output = strings.TrimSuffix(input, filepath.Ext(input)) + convertExtensions[to]
+		}
+		output = filepath.Clean(output)
+		if output == input {
+			fatal(exitError, "Error: --output %s is the input", output)
+		}
+
+		// Converting needs no Jira access, so a missing configuration is fine
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.Config{Location: time.Local}
+		}
+		applyLanguage(cfg)
+
+		plan, err := readPlan(input, from, cfg.Location)
+		if err != nil {
+			fatal(exitError, "Error reading %s: %v", input, err)
+		}
+		if len(plan.milestones) > 0 && to != "omniplan" {
+			term.Warnf("%d milestones are dropped; only omniplan output keeps milestones", len(plan.milestones))
+		}
+
+		if to == "omniplan" {
+			writeConvertedPackage(cfg, plan, input, output)
+			return
+		}
+		err = writeReport(output, func(w io.Writer) error {
+			switch to {
+			case "json":
+				return writePlanJSON(w, plan)
+			case "csv":
+				return csvplan.Write(w, plan.tickets)
+			case "smartsheet":
+				return smartsheet.Write(w, plan.name, withoutGroups(plan.tickets), plan.epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+			case "xer":
+				return xer.Write(w, plan.name, withoutGroups(plan.tickets), plan.epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+			case "omnijs":
+				return omnijs.Write(w, plan.name, withoutGroups(plan.tickets), plan.epics, nil)
+			default:
+				return taskpaper.Write(w, plan.name, withoutGroups(plan.tickets), plan.epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+			}
+		})
+		if err != nil {
+			fatal(exitWrite, "Error writing %s: %v", output, err)
+		}
+	},
+}
+
+// formatOf returns the convert format of a path by its extension, or ""
+func formatOf(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for format, e := range convertExtensions {
+		if e == ext {
+			return format
+		}
+	}
+	return ""
+}
+
+// readPlan reads a plan of the given format
+func readPlan(path, format string, loc *time.Location) (*convertedPlan, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch format {
+	case "omniplan":
+		scenario, err := omniplan.ReadScenarioFile(filepath.Join(path, omniplan.ActualScenarioFile))
+		if err != nil {
+			return nil, err
+		}
+		tickets, milestones := omniplan.ScenarioTickets(scenario, loc)
+		return &convertedPlan{name: planTitle(path), tickets: tickets, milestones: milestones}, nil
+	case "mspdi":
+		p, err := mspdi.ReadFile(path, loc)
+		if err != nil {
+			return nil, err
+		}
+		if p.Name != "" {
+			name = p.Name
+		}
+		return &convertedPlan{name: name, tickets: p.Tickets, milestones: p.Milestones}, nil
+	case "json":
+		snap, err := snapshot.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if snap.Project != "" {
+			name = snap.Project
+		}
+		return &convertedPlan{name: name, tickets: snap.Tickets, epics: snap.Epics}, nil
+	case "csv":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		tickets, err := csvplan.Read(f, loc)
+		if err != nil {
+			return nil, err
+		}
+		return &convertedPlan{name: name, tickets: tickets}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q (expected omniplan, mspdi, json or csv)", format)
+}
+
+// withoutGroups drops the tickets that only group their sub-tasks, for
+// outlines that do not nest. Dependencies on a group move to its sub-tasks.
+func withoutGroups(tickets []jira.Ticket) []jira.Ticket {
+	children := make(map[string][]string)
+	for _, t := range tickets {
+		if t.Parent != "" {
+			children[t.Parent] = append(children[t.Parent], t.Key)
+		}
+	}
+	isGroup := func(key string) bool { return len(children[key]) > 0 }
+
+	// Sub-tasks of a group may be groups too
+	var expand func(key string) []string
+	expand = func(key string) []string {
+		if !isGroup(key) {
+			return []string{key}
+		}
+		var keys []string
+		for _, child := range children[key] {
+			keys = append(keys, expand(child)...)
+		}
+		return keys
+	}
+
+	var kept []jira.Ticket
+	for _, t := range tickets {
+		if isGroup(t.Key) && t.EffortDays == 0 {
+			continue
+		}
+		var deps []string
+		for _, dep := range t.DependencyKeys {
+			deps = append(deps, expand(dep)...)
+		}
+		t.DependencyKeys = deps
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// writePlanJSON writes a plan in the snapshot layout, so it can be read back
+func writePlanJSON(w io.Writer, plan *convertedPlan) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snapshot.Snapshot{
+		Project: plan.name,
+		TakenAt: time.Now().UTC(),
+		Tickets: plan.tickets,
+		Epics:   plan.epics,
+	})
+}
+
+// writeConvertedPackage writes a plan as an OmniPlan package
+func writeConvertedPackage(cfg *config.Config, plan *convertedPlan, input, dirName string) {
+	serializer := omniplan.NewSerializer(plan.name)
+	serializer.NestSubtasks = true
+	serializer.GroupByEpic = len(plan.epics) > 0
+	serializer.Milestones = plan.milestones
+	serializer.MaxTitleLength = cfg.MaxTitleLength
+	serializer.Palette = stylePalette(cfg)
+
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		fatal(exitWrite, "Error creating directory %s: %v", dirName, err)
+	}
+	actualPath := filepath.Join(dirName, omniplan.ActualScenarioFile)
+	actualFile, err := os.Create(actualPath)
+	if err != nil {
+		fatal(exitWrite, "Error creating file %s: %v", actualPath, err)
+	}
+	defer actualFile.Close()
+
+	if err := serializer.Serialize(actualFile, plan.tickets, plan.epics); err != nil {
+		fatal(exitWrite, "Error serializing to OmniPlan: %v", err)
+	}
+	if err := omniplan.WritePackageTOC(dirName); err != nil {
+		fatal(exitWrite, "Error writing __TOC.xml: %v", err)
+	}
+	comment := fmt.Sprintf("Converted %s: %d tasks, %d milestones", filepath.Base(input), len(plan.tickets), len(plan.milestones))
+	if err := omniplan.AppendChangelog(dirName, time.Now(), changelogAuthor(cfg), comment); err != nil {
+		fatal(exitWrite, "Error writing %s: %v", omniplan.ChangelogFile, err)
+	}
+
+	fmt.Printf("Created OmniPlan package: %s\n", dirName)
+}
+
+func init() {
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "File or package to create (default: the input name with the output format's extension)")
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "Input format: omniplan, mspdi, json or csv (default: by extension)")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Output format: omniplan, json, csv, taskpaper, smartsheet, xer or omnijs (default: by --output extension, else omniplan)")
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment, configuration and Jira connection",
+	Long: `Runs a series of diagnostics and prints a pass/fail checklist: the runtime environment,
+the configuration file and its permissions, Jira reachability, authentication, custom field
+resolution and the embedded OmniPlan templates. Exits non-zero if any check fails.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var c checklist
+		runDiagnostics(&c)
+
+		fmt.Printf("\n%d passed, %d warnings, %d failed\n", c.passed, c.warned, c.failed)
+		if c.failed > 0 {
+			fatal(exitError, "Error: %d check(s) failed", c.failed)
+		}
+	},
+}
+
+// checklist prints diagnostic results and counts them
+type checklist struct {
+	passed, warned, failed int
+}
+
+func (c *checklist) pass(check, format string, args ...interface{}) {
+	c.passed++
+	fmt.Printf("%s %s: %s\n", term.Green.Paint("[PASS]"), check, fmt.Sprintf(format, args...))
+}
+
+func (c *checklist) warn(check, format string, args ...interface{}) {
+	c.warned++
+	fmt.Printf("%s %s: %s\n", term.Yellow.Paint("[WARN]"), check, fmt.Sprintf(format, args...))
+}
+
+func (c *checklist) fail(check, format string, args ...interface{}) {
+	c.failed++
+	fmt.Printf("%s %s: %s\n", term.Red.Paint("[FAIL]"), check, fmt.Sprintf(format, args...))
+}
+
+// runDiagnostics runs the checks in order, skipping those whose prerequisites failed
+func runDiagnostics(c *checklist) {
+	c.pass("Environment", "%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	checkTemplates(c)
+
+	cfg, err := config.Load()
+	if err != nil {
+		if err == config.ErrConfigNotFound {
+			path, _ := config.GetConfigPath()
+			c.fail("Config file", "not found; run 'jql-to-plan config' to create %s", path)
+		} else {
+			c.fail("Config file", "%v", err)
+		}
+		return
+	}
+	checkConfigFile(c, cfg)
+
+	if cfg.JiraURL == "" || !cfg.HasCredentials() {
+		c.fail("Credentials", "jira_url and jira_pat must be set in the config file or JIRA_URL/JIRA_PAT")
+		return
+	}
+	switch {
+	case len(cfg.KerberosTokenCommand) > 0:
+		c.pass("Credentials", "jira_url is %s, logging in with Kerberos", cfg.JiraURL)
+	case cfg.JiraUsername != "":
+		c.pass("Credentials", "jira_url is %s, logging in as %s", cfg.JiraURL, cfg.JiraUsername)
+	case cfg.JiraSessionCookie != "":
+		c.pass("Credentials", "jira_url is %s, using the captured session cookie", cfg.JiraURL)
+	default:
+		c.pass("Credentials", "jira_url is %s", cfg.JiraURL)
+	}
+
+	if cfg.EffortCustomFieldID == "" && cfg.EffortFieldName == "" {
+		c.warn("Effort field", "effort_custom_field_id is not set; plans and reports need it")
+	}
+
+	client, err := jira.NewClient(cfg.JiraURL, cfg.JiraPAT, cfg.EffortCustomFieldID, cfg.EpicLinkCustomFieldID, withTracing(clientOptions(cfg)))
+	if err != nil {
+		c.fail("Jira client", "%v", err)
+		return
+	}
+	if cfg.TLSInsecureSkipVerify {
+		c.warn("TLS", "tls_insecure_skip_verify is enabled; certificates are not verified")
+	}
+
+	ctx, cancel := runContext(cfg)
+	defer cancel()
+
+	// Any HTTP answer proves the server is reachable, even an authentication error
+	info, err := client.ServerInfo(ctx)
+	var statusErr *jira.StatusError
+	switch {
+	case err == nil:
+		c.pass("Jira reachable", "%s %s (%s)", info.ServerTitle, info.Version, info.DeploymentType)
+	case errors.As(err, &statusErr):
+		c.pass("Jira reachable", "server answered with HTTP %d", statusErr.StatusCode)
+	default:
+		c.fail("Jira reachable", "%v", err)
+		return
+	}
+
+	user, err := client.CurrentUser(ctx)
+	var authErr *jira.AuthError
+	if errors.As(err, &authErr) {
+		switch {
+		case len(cfg.KerberosTokenCommand) > 0:
+			c.fail("Authentication", "Jira rejected the Kerberos token (HTTP %d); check the ticket with klist, or run kinit", authErr.StatusCode)
+		case cfg.JiraUsername != "":
+			c.fail("Authentication", "Jira rejected the login of %s (HTTP %d); check jira_password", cfg.JiraUsername, authErr.StatusCode)
+		case cfg.JiraSessionCookie != "":
+			c.fail("Authentication", "Jira rejected jira_session_cookie (HTTP %d); capture a new session from the browser", authErr.StatusCode)
+		default:
+			c.fail("Authentication", "Jira rejected jira_pat (HTTP %d); create a new personal access token", authErr.StatusCode)
+		}
+		return
+	}
+	if err != nil {
+		c.fail("Authentication", "%v", err)
+		return
+	}
+	c.pass("Authentication", "authenticated as %s", user)
+
+	checks, err := client.CheckFields(ctx)
+	if err != nil {
+		c.fail("Custom fields", "%v", err)
+		return
+	}
+	for _, check := range checks {
+		if check.Err != nil {
+			c.fail("Custom fields", "%v", check.Err)
+		} else {
+			c.pass("Custom fields", "%s resolves to %s ('%s')", check.Setting, check.ID, check.Name)
+		}
+	}
+}
+
+// checkConfigFile reports which file was loaded and whether others can read it
+func checkConfigFile(c *checklist, cfg *config.Config) {
+	if cfg.File == "" {
+		c.pass("Config file", "none, using environment variables")
+		return
+	}
+
+	info, err := os.Stat(cfg.File)
+	if err != nil {
+		c.fail("Config file", "%v", err)
+		return
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		c.warn("Config file", "%s is readable by other users (mode %s); run 'chmod 600 %s'", cfg.File, info.Mode().Perm(), cfg.File)
+		return
+	}
+	c.pass("Config file", "%s", cfg.File)
+}
+
+// checkTemplates verifies the OmniPlan templates embedded in the binary
+func checkTemplates(c *checklist) {
+	paths, err := fs.Glob(omniplan.TemplateFS, "templates/*")
+	if err != nil || len(paths) == 0 {
+		c.fail("Templates", "no embedded OmniPlan templates found")
+		return
+	}
+	for _, path := range paths {
+		if _, err := omniplan.TemplateFS.ReadFile(path); err != nil {
+			c.fail("Templates", "%s: %v", path, err)
+			return
+		}
+	}
+	tmpl, _ := omniplan.TemplateFS.ReadFile("templates/__TOC.xml")
+	sample := []omniplan.TOCScenario{{ID: "gen1", Name: "Actual", Filename: omniplan.ActualScenarioFile}}
+	if err := omniplan.WriteTOC(io.Discard, string(tmpl), sample); err != nil {
+		c.fail("Templates", "__TOC.xml: %v", err)
+		return
+	}
+	c.pass("Templates", "%d embedded OmniPlan templates", len(paths))
+}
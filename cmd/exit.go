@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// Exit codes, so scripts and nightly pipelines can tell "Jira is down" from
+// "the plan has problems"
+const (
+	exitError    = 1 // Any other failure, such as invalid flags
+	exitConfig   = 2 // Missing or invalid configuration or --extra-tasks file
+	exitAuth     = 3 // Jira rejected the credentials
+	exitJQL      = 4 // Jira rejected the query
+	exitJira     = 5 // Jira could not be reached or failed the request
+	exitProblems = 6 // Lint problems, tickets that cannot be scheduled, or warnings with --fail-on=warning
+	exitWrite    = 7 // The plan, a report or an upload could not be written
+)
+
+// fatal logs the message, also as an error annotation with --annotations, and
+// exits with code
+func fatal(code int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	term.AnnotateError("", msg)
+	log.Print(msg)
+	os.Exit(code)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+)
+
+// exitArgsEnv passes the command line to run to the test binary started by
+// runExit
+const exitArgsEnv = "JQL_TO_PLAN_TEST_ARGS"
+
+func init() {
+	// Run as the command under test, for runExit
+	if args := os.Getenv(exitArgsEnv); args != "" {
+		rootCmd.SetArgs(strings.Fields(args))
+		Execute()
+		os.Exit(0)
+	}
+}
+
+// runExit runs the command line args in a new process, since failures exit,
+// and returns its exit code and output
+func runExit(t *testing.T, args string, env ...string) (int, string) {
+	t.Helper()
+	c := exec.Command(os.Args[0])
+	c.Env = append(os.Environ(), append(env, exitArgsEnv+"="+args)...)
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String()
+	}
+	if err != nil {
+		t.Fatalf("running %q: %v", args, err)
+	}
+	return 0, out.String()
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("request_timeout: 60\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args string
+		env  []string
+		code int
+		msg  string
+	}{
+		{
+			name: "invalid configuration",
+			args: "snapshot compare PROJ",
+			env:  []string{config.ConfigFileEnv + "=" + invalid},
+			code: exitConfig,
+			msg:  "Error loading config",
+		},
+		{
+			name: "config file cannot be written",
+			args: "config",
+			env:  []string{"HOME=" + filepath.Join(dir, "missing")},
+			code: exitWrite,
+			msg:  "Error creating config file",
+		},
+		{
+			name: "snapshot cannot be read",
+			args: "snapshot compare " + filepath.Join(dir, "old.json") + " " + filepath.Join(dir, "new.json"),
+			code: exitError,
+			msg:  "Error loading snapshot",
+		},
+		{
+			name: "invalid flag value",
+			args: "report deps project=PROJ --format xml",
+			code: exitError,
+			msg:  `unsupported format "xml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := runExit(t, tt.args, tt.env...)
+			if code != tt.code || !strings.Contains(out, tt.msg) {
+				t.Errorf("exit %d with output:\n%s\nwant exit %d with %q", code, out, tt.code, tt.msg)
+			}
+		})
+	}
+}
+
+func TestExitAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		args string
+		env  []string
+		want string
+	}{
+		{
+			name: "write failure",
+			args: "config --annotations github",
+			env:  []string{"HOME=" + filepath.Join(dir, "missing")},
+			want: "::error::Error creating config file at " + filepath.Join(dir, "missing", ".jql-to-plan.yaml"),
+		},
+		{
+			name: "invalid flag value",
+			args: "report deps project=PROJ --format xml --annotations github",
+			want: `::error::Error: unsupported format "xml" (expected text, csv or edges)`,
+		},
+		{
+			name: "missing configuration",
+			args: "report deps project=PROJ --annotations github",
+			env:  []string{"HOME=" + dir, config.ConfigFileEnv + "=", "JIRA_URL="},
+			want: "::error::Configuration file not found.%0APlease run 'jql-to-plan config' to create one.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out := runExit(t, tt.args, tt.env...)
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("expected the annotation %q in:\n%s", tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// jiraClients are the clients created by newJiraClient, whose partial
+// failures are summarized at the end of the run
+var jiraClients []*jira.Client
+
+// reportPartialFailures prints a single warning listing what the Jira
+// clients could not fetch or read, instead of a warning per failure in the
+// middle of the run. Nothing is printed when the output is complete.
+func reportPartialFailures() {
+	var lines []string
+	for _, client := range jiraClients {
+		for _, f := range client.FetchFailures() {
+			lines = append(lines, f.String())
+		}
+		for _, p := range client.IssueProblems() {
+			lines = append(lines, "ticket "+p.String())
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	term.Warnf("%d part(s) of the Jira data could not be fetched or read, so the output is degraded:\n  %s",
+		len(lines), strings.Join(lines, "\n  "))
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// sprintField is the Jira Software sprint field, resolved by name
+const sprintField = "Sprint"
+
+// groupFieldAliases map --group-by levels to the Jira field they stand for
+var groupFieldAliases = map[string]string{
+	"component": "components",
+}
+
+// groupLevels splits a --group-by value such as "epic,component" into its
+// levels, outermost first
+func groupLevels(spec string) ([]string, error) {
+	var levels []string
+	for _, level := range strings.Split(spec, ",") {
+		level = strings.TrimSpace(level)
+		if strings.EqualFold(level, "epic") || strings.EqualFold(level, "quarter") {
+			level = strings.ToLower(level)
+		}
+		kind, arg, found := strings.Cut(level, ":")
+		switch {
+		case level == "":
+			return nil, fmt.Errorf("--group-by %q has an empty level", spec)
+		case kind == "label" && found:
+			if arg == "" {
+				return nil, fmt.Errorf("--group-by=label needs a label prefix, e.g. label:stream-")
+			}
+		case found:
+			return nil, fmt.Errorf("unsupported --group-by level %q (expected epic, label:<prefix> or a field ID or name)", level)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// groupFields returns the Jira fields the levels need fetched
+func groupFields(levels []string) []string {
+	var fields []string
+	for _, level := range levels {
+		if level == "epic" || level == "quarter" || strings.HasPrefix(level, "label:") {
+			continue
+		}
+		fields = append(fields, groupField(level))
+	}
+	return fields
+}
+
+// groupField returns the Jira field a level groups by
+func groupField(level string) string {
+	if field, ok := groupFieldAliases[strings.ToLower(level)]; ok {
+		return field
+	}
+	return level
+}
+
+// groupByFuncs returns a function per level returning the group of a ticket
+// ("" for none). Epic groups are titled with the epic's summary.
+func groupByFuncs(levels []string, epics map[string]jira.Ticket) []func(jira.Ticket) string {
+	funcs := make([]func(jira.Ticket) string, 0, len(levels))
+	for _, level := range levels {
+		switch prefix, isLabel := strings.CutPrefix(level, "label:"); {
+		case level == "epic":
+			funcs = append(funcs, func(t jira.Ticket) string {
+				if epic, ok := epics[t.EpicLink]; ok && epic.Summary != "" {
+					return epic.Summary
+				}
+				return t.EpicLink
+			})
+		case level == "quarter":
+			funcs = append(funcs, quarter)
+		case isLabel:
+			// A ticket with several matching labels goes to the first in sort order
+			funcs = append(funcs, func(t jira.Ticket) string {
+				var group string
+				for _, label := range t.Labels {
+					if strings.HasPrefix(label, prefix) && (group == "" || label < group) {
+						group = label
+					}
+				}
+				return group
+			})
+		default:
+			// Any other level is a Jira field, grouped by its display value
+			field := groupField(level)
+			funcs = append(funcs, func(t jira.Ticket) string {
+				return t.Fields[field]
+			})
+		}
+	}
+	return funcs
+}
+
+// quarter returns the calendar quarter, e.g. "2025 Q3", of a ticket's due
+// date or else the end of its last sprint, and "" when it has neither
+func quarter(t jira.Ticket) string {
+	date := t.DueDate
+	if date.IsZero() {
+		date = t.SprintEnd
+	}
+	if date.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d Q%d", date.Year(), (int(date.Month())+2)/3)
+}
+
+// sortByQuarter orders tickets by quarter, so quarter groups are created in
+// calendar order. Tickets without a quarter go last; the order is kept
+// otherwise.
+func sortByQuarter(tickets []jira.Ticket) {
+	sort.SliceStable(tickets, func(i, j int) bool {
+		a, b := quarter(tickets[i]), quarter(tickets[j])
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+}
+
+// lastSprint returns the latest sprint of a ticket. Jira lists the sprints a
+// ticket was carried through in order, so the last one is where it lands.
+func lastSprint(t jira.Ticket) string {
+	sprints := strings.Split(t.Fields[sprintField], ", ")
+	return sprints[len(sprints)-1]
+}
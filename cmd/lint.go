@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [plan.oplx...]",
+	Short: "Check OmniPlan packages for structural problems",
+	Long: `Parses .oplx packages, generated or not, and reports missing files, scenarios that disagree
+with __TOC.xml, duplicate IDs, references to unknown tasks or resources and tasks left out of the
+outline. Useful after editing a package by hand or merging two copies of it.
+Exits with status 6 when any problem is found.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		problems := 0
+		for _, arg := range args {
+			dirName := filepath.Clean(arg)
+			issues, err := omniplan.LintPackage(dirName)
+			if err != nil {
+				fatal(exitError, "Error reading %s: %v", dirName, err)
+			}
+			for _, issue := range issues {
+				if term.Annotating() {
+					term.AnnotateError(filepath.Join(dirName, issue.File), issue.Message)
+					continue
+				}
+				fmt.Printf("%s: %s\n", dirName, issue)
+			}
+			problems += len(issues)
+		}
+
+		if problems > 0 {
+			fmt.Println(term.Red.Paint(fmt.Sprintf("%d problem(s) found", problems)))
+			os.Exit(exitProblems)
+		}
+		fmt.Println(term.Green.Paint("No problems found"))
+	},
+}
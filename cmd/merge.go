@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/spf13/cobra"
+)
+
+var mergeOutput string
+var mergeName string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [plan.oplx...]",
+	Short: "Combine OmniPlan packages into one programme plan",
+	Long: `Combines the Actual scenarios of two or more .oplx packages, generated or hand-made,
+into a new package. Each plan becomes a top-level group titled with its project name.
+Resources with the same name and type are merged, so people planned by several teams are
+levelled across all their work. IDs are reassigned, so plans never clash.
+Only the elements jql-to-plan models are carried over.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if mergeOutput == "" {
+			fatal(exitError, "Error: merge requires --output, e.g. -o Programme.oplx")
+		}
+		dirName := filepath.Clean(mergeOutput)
+		name := mergeName
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(dirName), ".oplx")
+		}
+
+		var scenarios []*omniplan.Scenario
+		var titles []string
+		for _, arg := range args {
+			input := filepath.Clean(arg)
+			if input == dirName {
+				fatal(exitError, "Error: --output %s is also an input", dirName)
+			}
+			scenario, err := omniplan.ReadScenarioFile(filepath.Join(input, omniplan.ActualScenarioFile))
+			if err != nil {
+				fatal(exitError, "Error reading %s: %v", input, err)
+			}
+			scenarios = append(scenarios, scenario)
+			titles = append(titles, planTitle(input))
+		}
+
+		if err := os.MkdirAll(dirName, 0755); err != nil {
+			fatal(exitWrite, "Error creating directory %s: %v", dirName, err)
+		}
+		actualPath := filepath.Join(dirName, omniplan.ActualScenarioFile)
+		actualFile, err := os.Create(actualPath)
+		if err != nil {
+			fatal(exitWrite, "Error creating file %s: %v", actualPath, err)
+		}
+		defer actualFile.Close()
+
+		if err := omniplan.WriteScenario(actualFile, omniplan.MergeScenarios(name, scenarios, titles)); err != nil {
+			fatal(exitWrite, "Error writing merged plan: %v", err)
+		}
+		if err := omniplan.WritePackageTOC(dirName); err != nil {
+			fatal(exitWrite, "Error writing __TOC.xml: %v", err)
+		}
+		comment := fmt.Sprintf("Merged %d plans: %s", len(titles), strings.Join(titles, ", "))
+		// Merging needs no Jira access, so a missing configuration is fine
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.Config{}
+		}
+		if err := omniplan.AppendChangelog(dirName, time.Now(), changelogAuthor(cfg), comment); err != nil {
+			fatal(exitWrite, "Error writing %s: %v", omniplan.ChangelogFile, err)
+		}
+
+		fmt.Printf("Created OmniPlan package: %s\n", dirName)
+	},
+}
+
+// planTitle names a plan by the project it was generated for, or else by
+// its package name
+func planTitle(dir string) string {
+	if m, err := omniplan.ReadManifest(dir); err == nil && m.Project != "" {
+		return m.Project
+	}
+	return strings.TrimSuffix(filepath.Base(dir), ".oplx")
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Package to create, e.g. Programme.oplx")
+	mergeCmd.Flags().StringVar(&mergeName, "name", "", "Programme name (default: the output package name)")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/monday"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var mondayOutput string
+
+var mondayCmd = &cobra.Command{
+	Use:   "monday [board ID]",
+	Short: "Convert a monday.com board to OmniPlan",
+	Long: `Reads a monday.com board through its GraphQL API and writes its items as an OmniPlan
+package, without Jira. The board ID is the number in the board's URL (.../boards/1234567890).
+
+Each item becomes a task grouped by its board group. The monday.columns block of the
+configuration maps board columns, by column ID, onto effort, owner, dependencies, status
+and dates; unmapped columns are ignored. The token comes from monday.token or the
+MONDAY_API_TOKEN environment variable.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Reading monday.com needs no Jira access, so a missing configuration is fine
+		cfg, err := config.Load()
+		if err == config.ErrConfigNotFound {
+			cfg = &config.Config{Location: time.Local}
+			cfg.Monday.Token = os.Getenv("MONDAY_API_TOKEN")
+		} else if err != nil {
+			fatal(exitConfig, "Error loading config: %v", err)
+		}
+		applyLanguage(cfg)
+		if cfg.Monday.Token == "" {
+			fatal(exitConfig, "Error: 'monday' requires a monday.com API token.\nSet MONDAY_API_TOKEN, or run 'jql-to-plan config' and set monday.token.")
+		}
+		columns := cfg.Monday.Columns
+		if columns.Effort == "" {
+			term.Warnf("No monday.columns.effort column is mapped; tasks are planned without estimates")
+		}
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		board, err := monday.New(cfg.Monday.Token).ReadBoard(ctx, args[0], monday.Mapping{
+			Effort:       columns.Effort,
+			Owner:        columns.Owner,
+			Dependencies: columns.Dependencies,
+			Status:       columns.Status,
+			Dates:        columns.Dates,
+			HoursPerDay:  cfg.HoursPerDay,
+			DaysPerWeek:  cfg.DaysPerWeek,
+		}, cfg.Location)
+		if err != nil {
+			fatal(exitError, "Error reading monday.com board %s: %v", args[0], err)
+		}
+
+		output := mondayOutput
+		if output == "" {
+			output = board.Name + ".oplx"
+		}
+		plan := &convertedPlan{name: board.Name, tickets: board.Tickets, epics: board.Epics}
+		writeConvertedPackage(cfg, plan, fmt.Sprintf("monday.com board %s", args[0]), filepath.Clean(output))
+	},
+}
+
+func init() {
+	mondayCmd.Flags().StringVarP(&mondayOutput, "output", "o", "", "Package to create (default: the board name with .oplx)")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/notion"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var notionOutput string
+
+var notionCmd = &cobra.Command{
+	Use:   "notion [database ID]",
+	Short: "Convert a Notion database to OmniPlan",
+	Long: `Reads a Notion database through the Notion API and writes its pages as an OmniPlan
+package, without Jira. The database ID is the 32-character ID in the database's URL
+(notion.so/<workspace>/<database ID>?v=...).
+
+Each page becomes a task. The notion.properties block of the configuration maps database
+properties, by name, onto status, estimate, owner, dependencies and dates; unmapped
+properties are ignored. The token comes from notion.token or the NOTION_TOKEN environment
+variable, and the database must be shared with the integration.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Reading Notion needs no Jira access, so a missing configuration is fine
+		cfg, err := config.Load()
+		if err == config.ErrConfigNotFound {
+			cfg = &config.Config{Location: time.Local}
+			cfg.Notion.Token = os.Getenv("NOTION_TOKEN")
+		} else if err != nil {
+			fatal(exitConfig, "Error loading config: %v", err)
+		}
+		applyLanguage(cfg)
+		if cfg.Notion.Token == "" {
+			fatal(exitConfig, "Error: 'notion' requires a Notion integration token.\nSet NOTION_TOKEN, or run 'jql-to-plan config' and set notion.token.")
+		}
+		properties := cfg.Notion.Properties
+		if properties.Estimate == "" {
+			term.Warnf("No notion.properties.estimate property is mapped; tasks are planned without estimates")
+		}
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		db, err := notion.New(cfg.Notion.Token).ReadDatabase(ctx, args[0], notion.Mapping{
+			Status:       properties.Status,
+			Estimate:     properties.Estimate,
+			Owner:        properties.Owner,
+			Dependencies: properties.Dependencies,
+			Dates:        properties.Dates,
+			HoursPerDay:  cfg.HoursPerDay,
+			DaysPerWeek:  cfg.DaysPerWeek,
+		}, cfg.Location)
+		if err != nil {
+			fatal(exitError, "Error reading Notion database %s: %v", args[0], err)
+		}
+
+		output := notionOutput
+		if output == "" {
+			output = db.Name + ".oplx"
+		}
+		plan := &convertedPlan{name: db.Name, tickets: db.Tickets}
+		writeConvertedPackage(cfg, plan, fmt.Sprintf("Notion database %s", args[0]), filepath.Clean(output))
+	},
+}
+
+func init() {
+	notionCmd.Flags().StringVarP(&notionOutput, "output", "o", "", "Package to create (default: the database name with .oplx)")
+}
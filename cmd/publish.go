@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/confluence"
+	"github.com/gunnarrb/jql-to-plan/internal/report"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish plan summaries to other tools",
+}
+
+var publishSpace string
+var publishParent string
+
+var publishConfluenceCmd = &cobra.Command{
+	Use:   "confluence [title] [JQL]",
+	Short: "Create or update a Confluence page with the plan summary",
+	Long: `Forecasts the tickets matching the JQL query (see 'report forecast') and publishes the
+summary and per-epic table to the Confluence page with the given title. The page is created
+in the configured space the first time and updated on later runs.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		title := args[0]
+
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		jql := resolveQuery(cfg, args[1])
+
+		space := cfg.Confluence.Space
+		if publishSpace != "" {
+			space = publishSpace
+		}
+		parent := cfg.Confluence.ParentID
+		if publishParent != "" {
+			parent = publishParent
+		}
+		if cfg.Confluence.URL == "" || cfg.Confluence.PAT == "" || space == "" {
+			fatal(exitConfig, "Error: publishing requires a confluence block (url, pat and space) in configuration.\nPlease run 'jql-to-plan config' and set confluence.url, confluence.pat and confluence.space.")
+		}
+
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, epics, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		forecast, err := report.NewForecast(tickets, epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+		if err != nil {
+			fatal(exitProblems, "Error projecting forecast: %v", err)
+		}
+		forecast.DateLayout = cfg.DateLayout
+		var page bytes.Buffer
+		if err := forecast.WriteHTML(&page, jql); err != nil {
+			fatal(exitWrite, "Error rendering summary: %v", err)
+		}
+
+		published, err := confluence.New(cfg.Confluence.URL, cfg.Confluence.PAT).Publish(ctx, space, title, parent, page.String())
+		if err != nil {
+			fatal(exitWrite, "Error publishing to Confluence: %v", err)
+		}
+		fmt.Printf("Published %q (version %d): %s\n", title, published.Version, published.URL)
+	},
+}
+
+func init() {
+	publishCmd.AddCommand(publishConfluenceCmd)
+
+	publishConfluenceCmd.Flags().StringVar(&publishSpace, "space", "", "Confluence space key (overrides confluence.space)")
+	publishConfluenceCmd.Flags().StringVar(&publishParent, "parent", "", "Parent page ID for new pages (overrides confluence.parent_id)")
+}
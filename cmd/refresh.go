@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh [plan.oplx]",
+	Short: "Regenerate a plan with the query and flags it was created with",
+	Long: `Reads the manifest stored in a generated .oplx package and re-runs the same generation:
+the same project name, JQL query and command-line flags, writing to the same package.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dirName := filepath.Clean(args[0])
+
+		manifest, err := omniplan.ReadManifest(dirName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				fatal(exitError, "Error: %s has no %s; it was not generated by this version of jql-to-plan", dirName, omniplan.ManifestFile)
+			}
+			fatal(exitError, "Error reading manifest: %v", err)
+		}
+
+		// Replay the original flags on the root command, in a stable order
+		names := make([]string, 0, len(manifest.Flags))
+		for name := range manifest.Flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := rootCmd.Flags().Set(name, manifest.Flags[name]); err != nil {
+				term.Warnf("Ignoring recorded flag --%s=%s: %v", name, manifest.Flags[name], err)
+			}
+		}
+
+		if cfg, err := config.Load(); err == nil && cfg.File != manifest.ConfigFile {
+			term.Warnf("%s was generated with configuration %q, now using %q", dirName, manifest.ConfigFile, cfg.File)
+		}
+
+		fmt.Printf("Refreshing %s (generated %s)\n", dirName, manifest.GeneratedAt.Local().Format("2006-01-02 15:04"))
+		generatePlan(manifest.Project, manifest.JQL, dirName, manifest.Flags)
+	},
+}
+
+func init() {
+	refreshCmd.Flags().BoolVar(&openPlan, "open", false, "Open the package in OmniPlan once written (macOS only; ignored elsewhere)")
+}
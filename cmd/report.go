@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/report"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate analytical reports from Jira data",
+}
+
+var reportCycleTimeCmd = &cobra.Command{
+	Use:   "cycletime [JQL]",
+	Short: "Report lead and cycle time distributions per issue type and assignee",
+	Long: `Fetches the changelogs of the issues matching the JQL query and reports lead time
+(created to resolved) and cycle time (first in-progress to resolved) distributions,
+overall, per issue type and per assignee. Statuses are taken from in_progress_statuses
+and done_statuses in the configuration.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		jql := resolveQuery(cfg, args[0])
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		records, err := client.GetFlowRecords(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		if err := report.NewCycleTimeReport(records).WriteText(os.Stdout); err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+var burndownFormat string
+var burndownOutput string
+var burndownStart string
+
+var reportBurndownCmd = &cobra.Command{
+	Use:   "burndown [JQL]",
+	Short: "Project a burndown/burnup of the queried scope as CSV or HTML",
+	Long: `Schedules the open tickets matching the JQL query (one task at a time per assignee,
+respecting dependencies, 8-hour Monday-Friday workdays) and projects remaining and
+completed effort per workday until the projected completion date.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if burndownFormat != "csv" && burndownFormat != "html" {
+			fatal(exitError, "Error: unsupported format %q (expected csv or html)", burndownFormat)
+		}
+
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		origin := parseStart(burndownStart, cfg.Location)
+		jql := resolveQuery(cfg, args[0])
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, _, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		burndown, err := report.NewBurndown(tickets, origin, schedule.DefaultCalendar())
+		if err != nil {
+			fatal(exitProblems, "Error projecting burndown: %v", err)
+		}
+		burndown.DateLayout = cfg.DateLayout
+
+		err = writeReport(burndownOutput, func(w io.Writer) error {
+			if burndownFormat == "html" {
+				return burndown.WriteHTML(w, "Burndown: "+args[0])
+			}
+			return burndown.WriteCSV(w)
+		})
+		if err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+var forecastByEpic bool
+var forecastOutput string
+var forecastStart string
+
+var reportForecastCmd = &cobra.Command{
+	Use:   "forecast [JQL]",
+	Short: "Project when the queried work finishes, optionally per epic",
+	Long: `Schedules the open tickets matching the JQL query (one task at a time per assignee,
+respecting dependencies, 8-hour Monday-Friday workdays) and reports the remaining effort,
+the number of assignees and the projected finish date. With --by-epic, each epic is listed
+with its remaining and unassigned effort, assignees, projected finish and due date.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		origin := parseStart(forecastStart, cfg.Location)
+		jql := resolveQuery(cfg, args[0])
+		if forecastByEpic && cfg.EpicLinkCustomFieldID == "" && cfg.EpicLinkFieldName == "" {
+			fatal(exitConfig, "Error: --by-epic requires epic_link_custom_field_id to be set in configuration.\nPlease run 'jql-to-plan config' and set the epic_link_custom_field_id.")
+		}
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, epics, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		forecast, err := report.NewForecast(tickets, epics, origin, schedule.DefaultCalendar())
+		if err != nil {
+			fatal(exitProblems, "Error projecting forecast: %v", err)
+		}
+		forecast.DateLayout = cfg.DateLayout
+
+		err = writeReport(forecastOutput, func(w io.Writer) error {
+			return forecast.WriteText(w, forecastByEpic)
+		})
+		if err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+var criticalPathOutput string
+var criticalPathStart string
+
+var reportCriticalPathCmd = &cobra.Command{
+	Use:   "critical-path [JQL]",
+	Short: "Print the chain of tickets that determines the projected finish",
+	Long: `Schedules the open tickets matching the JQL query like the forecast (one task at a
+time per assignee, respecting dependencies, 8-hour Monday-Friday workdays) and prints the
+chain of tickets that determines the projected finish date, first to last, with each
+ticket's dates, effort and slack and what it waits for: a dependency or its assignee's
+previous ticket.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		origin := parseStart(criticalPathStart, cfg.Location)
+		jql := resolveQuery(cfg, args[0])
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, _, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		path, err := report.NewCriticalPath(tickets, origin, schedule.DefaultCalendar())
+		if err != nil {
+			fatal(exitProblems, "Error projecting schedule: %v", err)
+		}
+		path.DateLayout = cfg.DateLayout
+
+		if err := writeReport(criticalPathOutput, path.WriteText); err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+var floatOutput string
+var floatStart string
+var floatThreshold float64
+
+var reportFloatCmd = &cobra.Command{
+	Use:   "float [JQL]",
+	Short: "List the total and free float of each open ticket",
+	Long: `Schedules the open tickets matching the JQL query like the forecast and lists each
+open ticket's total float (how far it can slip before the projected finish moves) and free
+float (how far it can slip before another ticket moves), least first. Critical tickets,
+without float, are shown in red; near-critical ones, with less total float than
+--threshold days, in yellow.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if floatThreshold < 0 {
+			fatal(exitError, "Error: invalid --threshold %v (expected days)", floatThreshold)
+		}
+
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		origin := parseStart(floatStart, cfg.Location)
+		jql := resolveQuery(cfg, args[0])
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, _, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		floats, err := report.NewFloatReport(tickets, origin, schedule.DefaultCalendar(), floatThreshold)
+		if err != nil {
+			fatal(exitProblems, "Error projecting schedule: %v", err)
+		}
+		floats.DateLayout = cfg.DateLayout
+
+		if err := writeReport(floatOutput, floats.WriteText); err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+var depsFormat string
+var depsOutput string
+
+var reportDepsCmd = &cobra.Command{
+	Use:   "deps [JQL]",
+	Short: "Report the dependencies between the queried tickets",
+	Long: `Lists the dependencies between the issues matching the JQL query, for dependency
+review meetings. The text format lists all dependencies, then those crossing epics and,
+with team_field set in the configuration, those crossing teams. The csv format is a
+matrix with one row and column per ticket and "X" where the row depends on the column;
+the edges format is a CSV list of dependencies with cross_epic and cross_team columns.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if depsFormat != "text" && depsFormat != "csv" && depsFormat != "edges" {
+			fatal(exitError, "Error: unsupported format %q (expected text, csv or edges)", depsFormat)
+		}
+
+		cfg := loadConfig()
+		jql := resolveQuery(cfg, args[0])
+		opts := clientOptions(cfg)
+		if cfg.TeamField != "" {
+			opts.ExtraFields = append(opts.ExtraFields, cfg.TeamField)
+		}
+		client := newJiraClient(cfg, opts)
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, _, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		var team func(jira.Ticket) string
+		if cfg.TeamField != "" {
+			team = func(t jira.Ticket) string { return t.Fields[cfg.TeamField] }
+		}
+		deps := report.NewDependencyReport(tickets, team)
+
+		err = writeReport(depsOutput, func(w io.Writer) error {
+			switch depsFormat {
+			case "csv":
+				return deps.WriteMatrixCSV(w)
+			case "edges":
+				return deps.WriteEdgesCSV(w)
+			}
+			return deps.WriteText(w)
+		})
+		if err != nil {
+			fatal(exitWrite, "Error writing report: %v", err)
+		}
+	},
+}
+
+// parseStart parses a --start date in loc, defaulting to now
+func parseStart(value string, loc *time.Location) time.Time {
+	if value == "" {
+		return time.Now().In(loc)
+	}
+	start, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		fatal(exitError, "Error: invalid --start date %q (expected YYYY-MM-DD)", value)
+	}
+	return start
+}
+
+// writeReport writes to the given file, or stdout if path is empty. A file
+// left incomplete by an error is removed.
+func writeReport(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
+
+func init() {
+	reportCmd.AddCommand(reportCycleTimeCmd)
+	reportCmd.AddCommand(reportBurndownCmd)
+	reportCmd.AddCommand(reportForecastCmd)
+	reportCmd.AddCommand(reportDepsCmd)
+	reportCmd.AddCommand(reportCriticalPathCmd)
+	reportCmd.AddCommand(reportFloatCmd)
+
+	reportBurndownCmd.Flags().StringVarP(&burndownFormat, "format", "f", "csv", "Output format: csv or html")
+	reportBurndownCmd.Flags().StringVarP(&burndownOutput, "output", "o", "", "Write to this file instead of stdout")
+	reportBurndownCmd.Flags().StringVar(&burndownStart, "start", "", "Projection start date (YYYY-MM-DD, default today)")
+
+	reportForecastCmd.Flags().BoolVar(&forecastByEpic, "by-epic", false, "List the forecast per epic")
+	reportForecastCmd.Flags().StringVarP(&forecastOutput, "output", "o", "", "Write to this file instead of stdout")
+	reportForecastCmd.Flags().StringVar(&forecastStart, "start", "", "Projection start date (YYYY-MM-DD, default today)")
+
+	reportDepsCmd.Flags().StringVarP(&depsFormat, "format", "f", "text", "Output format: text, csv (matrix) or edges (CSV list)")
+	reportCriticalPathCmd.Flags().StringVarP(&criticalPathOutput, "output", "o", "", "Write to this file instead of stdout")
+	reportCriticalPathCmd.Flags().StringVar(&criticalPathStart, "start", "", "Projection start date (YYYY-MM-DD, default today)")
+
+	reportFloatCmd.Flags().Float64Var(&floatThreshold, "threshold", 2, "Days of total float below which a ticket is near-critical")
+	reportFloatCmd.Flags().StringVarP(&floatOutput, "output", "o", "", "Write to this file instead of stdout")
+	reportFloatCmd.Flags().StringVar(&floatStart, "start", "", "Projection start date (YYYY-MM-DD, default today)")
+
+	reportDepsCmd.Flags().StringVarP(&depsOutput, "output", "o", "", "Write to this file instead of stdout")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var roadmapOutput string
+var roadmapLinkType string
+
+var roadmapCmd = &cobra.Command{
+	Use:   "roadmap [plan ID]",
+	Short: "Convert a Jira Advanced Roadmaps plan to OmniPlan",
+	Long: `Reads an Advanced Roadmaps plan (Jira Plans) and writes its issues as an OmniPlan package,
+for teams that maintain their roadmap in Jira but present it in OmniPlan. The plan ID is
+the number in the plan's URL (.../plans/42/...).
+
+The plan's issue sources and exclusions decide the tickets. Its start and end date fields
+(Target start and Target end, or a custom date field) become start-no-earlier-than and
+end-no-later-than constraints, and its dependencies ("Blocks" links by default) become
+task dependencies. Plans can only be read from Jira Cloud (api_version: 3).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		requireEffortField(cfg)
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		roadmap, err := newJiraClient(cfg, clientOptions(cfg)).GetRoadmap(ctx, args[0])
+		if err != nil {
+			fatalJira("reading the plan", err)
+		}
+
+		// The plan decides which fields hold its dates and dependencies
+		opts := clientOptions(cfg)
+		opts.TargetStartField = roadmap.TargetStartField
+		opts.TargetEndField = roadmap.TargetEndField
+		opts.BlockingLinkType = roadmapLinkType
+		tickets, epics, err := newJiraClient(cfg, opts).GetTickets(ctx, roadmap.JQL)
+		if err != nil {
+			fatalJira("fetching the plan's tickets", err)
+		}
+
+		output := roadmapOutput
+		if output == "" {
+			output = roadmap.Name + ".oplx"
+		}
+		plan := &convertedPlan{name: roadmap.Name, tickets: tickets, epics: epics}
+		writeConvertedPackage(cfg, plan, fmt.Sprintf("Advanced Roadmaps plan %d", roadmap.ID), filepath.Clean(output))
+	},
+}
+
+func init() {
+	roadmapCmd.Flags().StringVarP(&roadmapOutput, "output", "o", "", "Package to create (default: the plan name with .oplx)")
+	roadmapCmd.Flags().StringVar(&roadmapLinkType, "link-type", "Blocks", "Issue link type the plan uses for dependencies, as set in its Advanced Roadmaps settings")
+}
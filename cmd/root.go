@@ -1,130 +1,717 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
-	"embed"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"os/exec"
+	"os/user"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/extratasks"
+	"github.com/gunnarrb/jql-to-plan/internal/i18n"
 	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/mail"
+	"github.com/gunnarrb/jql-to-plan/internal/notify"
+	"github.com/gunnarrb/jql-to-plan/internal/omnijs"
 	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+	"github.com/gunnarrb/jql-to-plan/internal/report"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/gunnarrb/jql-to-plan/internal/smartsheet"
+	"github.com/gunnarrb/jql-to-plan/internal/taskpaper"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/gunnarrb/jql-to-plan/internal/upload"
+	"github.com/gunnarrb/jql-to-plan/internal/xer"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-//go:embed templates/__TOC.xml templates/__changelog.xml
-var templateFS embed.FS
 var epicGroup bool
 var milestoneDone bool
+var emailTo []string
+var actualStart bool
+var outputFormat string
+var attachTo string
+var incremental bool
+var queryVars []string
+var extraTasksFile string
+var subtasksMode string
+var groupBy string
+var sprintPhases bool
+var resolvedSince string
+var oplxVersion int
+var noColor bool
+var resourceProfiles bool
+var wsjf bool
+var bufferPercent int
+var reviewTasks bool
+var maxTaskDays float64
+var rollingWave string
+var partialOutput bool
+var failOn string
+var annotationFormat string
+var debugHTTP bool
+var debugHTTPFile string
+var openPlan bool
+var uploadTo []string
+var language string
 
 var rootCmd = &cobra.Command{
 	Use:   "jql-to-plan [project] [JQL]",
 	Short: "A CLI to fetch Jira tickets via JQL and output OmniPlan XML",
 	Args:  cobra.ExactArgs(2),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		term.Configure(noColor)
+		if failOn != "error" && failOn != "warning" {
+			fatal(exitError, "Error: unsupported --fail-on %q (expected error or warning)", failOn)
+		}
+		if err := term.ConfigureAnnotations(annotationFormat); err != nil {
+			fatal(exitError, "Error: --annotations: %v", err)
+		}
+		if err := i18n.SetLanguage(language); err != nil {
+			fatal(exitError, "Error: --lang: %v", err)
+		}
+	},
+	// With --fail-on=warning, a run that warned fails once its work is done
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		reportPartialFailures()
+		if n := term.Warnings(); failOn == "warning" && n > 0 {
+			fatal(exitProblems, "Error: %d warning(s) with --fail-on=warning", n)
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		projectName := args[0]
-		jql := args[1]
+		generatePlan(args[0], args[1], args[0]+".oplx", changedFlags(cmd.Flags()))
+	},
+}
 
-		cfg, err := config.Load()
-		if err != nil {
-			if err == config.ErrConfigNotFound {
-				fmt.Printf("Configuration file not found.\nPlease run 'jql-to-plan config' to create one.\n")
-				os.Exit(1)
-			}
-			log.Fatalf("Error loading config: %v", err)
+// generatePlan fetches the tickets and writes the OmniPlan package to dirName,
+// then notifies, uploads and emails as configured. flags are the command-line
+// flags that were set, recorded in the manifest for 'refresh'.
+func generatePlan(projectName, jql, dirName string, flags map[string]string) {
+	if !slices.Contains([]string{"omniplan", "taskpaper", "smartsheet", "xer", "omnijs"}, outputFormat) {
+		fatal(exitError, "Error: unsupported format %q (expected omniplan, taskpaper, smartsheet, xer or omnijs)", outputFormat)
+	}
+	if !omniplan.ValidFormatVersion(oplxVersion) {
+		fatal(exitError, "Error: unsupported --oplx-version %d (expected 2 or 4)", oplxVersion)
+	}
+	if bufferPercent < 0 {
+		fatal(exitError, "Error: invalid --buffers %d (expected a percentage, 0 for none)", bufferPercent)
+	}
+	if rollingWave != "" {
+		if _, err := schedule.HorizonDate(rollingWave, time.Now()); err != nil {
+			fatal(exitError, "Error: invalid --rolling-wave: %v", err)
 		}
+	}
+	if maxTaskDays < 0 {
+		fatal(exitError, "Error: invalid --max-task-days %v (expected days, 0 for no limit)", maxTaskDays)
+	}
+	if !slices.Contains([]string{"flat", "exclude", "collapse", "nest"}, subtasksMode) {
+		fatal(exitError, "Error: unsupported --subtasks mode %q (expected flat, exclude, collapse or nest)", subtasksMode)
+	}
+
+	switch outputFormat {
+	case "taskpaper":
+		term.AnnotateFile(projectName + ".taskpaper")
+	case "smartsheet":
+		term.AnnotateFile(projectName + ".xlsx")
+	case "xer":
+		term.AnnotateFile(projectName + ".xer")
+	case "omnijs":
+		term.AnnotateFile(projectName + ".js")
+	default:
+		term.AnnotateFile(filepath.Join(dirName, omniplan.ActualScenarioFile))
+	}
+
+	cfg := loadConfig()
+
+	// Enforce optional field for this command
+	requireEffortField(cfg)
 
-		if cfg.JiraURL == "" || cfg.JiraPAT == "" {
-			log.Fatal("Error: JIRA_URL and JIRA_PAT must be set via environment variables or config file\nRun 'jql-to-plan config' to edit your configuration.")
+	// The manifest keeps the query as given so 'refresh' expands it again
+	query := resolveQuery(cfg, jql)
+
+	if len(emailTo) > 0 && cfg.SMTP.Host == "" {
+		fatal(exitConfig, "Error: --email-to flag requires an smtp block in configuration.\nPlease run 'jql-to-plan config' and set smtp.host and smtp.from.")
+	}
+
+	var levels []string
+	if groupBy != "" {
+		if epicGroup {
+			fatal(exitError, "Error: --group-by and --epic-group cannot be combined")
 		}
+		var err error
+		if levels, err = groupLevels(groupBy); err != nil {
+			fatal(exitError, "Error: %v", err)
+		}
+	}
 
-		// Enforce optional field for this command
-		if cfg.EffortCustomFieldID == "" {
-			log.Fatal("Error: effort_custom_field_id is not set in configuration.\nThis field is required for this command.\nPlease run 'jql-to-plan config' and uncomment/set the effort_custom_field_id.")
+	if slices.Contains(levels, "epic") && cfg.EpicLinkCustomFieldID == "" && cfg.EpicLinkFieldName == "" {
+		fatal(exitConfig, "Error: --group-by=epic requires epic_link_custom_field_id to be set in configuration.\nPlease run 'jql-to-plan config' and set the epic_link_custom_field_id.")
+	}
+	if resolvedSince != "" && !jira.ValidResolvedWindow(resolvedSince) {
+		fatal(exitError, "Error: invalid --resolved-since %q (expected days or weeks, e.g. 30d or 4w)", resolvedSince)
+	}
+	if sprintPhases && !epicGroup {
+		fatal(exitError, "Error: --sprint-phases requires --epic-group")
+	}
+	if wsjf && len(cfg.WSJF.CostOfDelayFields) == 0 {
+		fatal(exitConfig, "Error: --wsjf requires wsjf.cost_of_delay_fields to be set in configuration.\nPlease run 'jql-to-plan config' and set the wsjf fields.")
+	}
+	if reviewTasks && cfg.ReviewerField == "" {
+		fatal(exitConfig, "Error: --review-tasks requires reviewer_field to be set in configuration.\nPlease run 'jql-to-plan config' and set the reviewer_field.")
+	}
+	if epicGroup && cfg.EpicLinkCustomFieldID == "" && cfg.EpicLinkFieldName == "" {
+		fatal(exitConfig, "Error: --epic-group flag requires epic_link_custom_field_id to be set in configuration.\nPlease run 'jql-to-plan config' and set the epic_link_custom_field_id.")
+	}
+	var noteTemplate *omniplan.NoteTemplate
+	if cfg.NoteTemplate != "" {
+		var err error
+		if noteTemplate, err = omniplan.ParseNoteTemplate(cfg.NoteTemplate); err != nil {
+			fatal(exitConfig, "Error: invalid note_template: %v", err)
 		}
+	}
 
-		if epicGroup && cfg.EpicLinkCustomFieldID == "" {
-			log.Fatal("Error: --epic-group flag requires epic_link_custom_field_id to be set in configuration.\nPlease run 'jql-to-plan config' and set the epic_link_custom_field_id.")
+	var extras []jira.Ticket
+	if extraTasksFile != "" {
+		var err error
+		if extras, err = extratasks.Load(extraTasksFile, cfg.HoursPerDay, cfg.DaysPerWeek); err != nil {
+			fatal(exitConfig, "Error loading extra tasks: %v", err)
 		}
+	}
 
-		client, err := jira.NewClient(cfg.JiraURL, cfg.JiraPAT, cfg.EffortCustomFieldID, cfg.EpicLinkCustomFieldID)
-		if err != nil {
-			log.Fatalf("Error creating Jira client: %v", err)
+	opts := clientOptions(cfg)
+	opts.FetchChangelog = actualStart
+	opts.ExtraFields = append(opts.ExtraFields, groupFields(levels)...)
+	if slices.Contains(levels, "quarter") {
+		opts.SprintField = sprintField
+	}
+	if sprintPhases {
+		opts.ExtraFields = append(opts.ExtraFields, sprintField)
+	}
+	if cfg.TeamField != "" {
+		opts.ExtraFields = append(opts.ExtraFields, cfg.TeamField)
+	}
+	if cfg.RiskField != "" {
+		opts.ExtraFields = append(opts.ExtraFields, cfg.RiskField)
+	}
+	opts.ExtraFields = append(opts.ExtraFields, cfg.NoteFields...)
+	followUps := followUpRules(cfg)
+	for _, rule := range followUps {
+		if rule.Field != "" {
+			opts.ExtraFields = append(opts.ExtraFields, rule.Field)
+		}
+	}
+	scoring := jira.WSJF{CostOfDelayFields: cfg.WSJF.CostOfDelayFields, JobSizeField: cfg.WSJF.JobSizeField}
+	if wsjf {
+		opts.ExtraFields = append(opts.ExtraFields, scoring.CostOfDelayFields...)
+		if scoring.JobSizeField != "" {
+			opts.ExtraFields = append(opts.ExtraFields, scoring.JobSizeField)
 		}
+	}
+	client := newJiraClient(cfg, opts)
+
+	ctx, cancel := runContext(cfg)
+	defer cancel()
 
-		tickets, epics, err := client.GetTickets(context.Background(), jql)
+	tickets, epics, err := fetchTickets(ctx, cfg, client, opts, query)
+	interrupted := false
+	if err != nil {
+		if !partialOutput || ctx.Err() == nil || len(tickets) == 0 {
+			fatalJira("fetching tickets", err)
+		}
+		term.Warnf("Fetching stopped after %d tickets (%v); writing a partial plan", len(tickets), ctx.Err())
+		interrupted = true
+	}
+	if resolvedSince != "" && !interrupted {
+		resolved, resolvedEpics, err := client.GetResolvedSince(ctx, resolvedSince, tickets)
 		if err != nil {
-			log.Fatalf("Error fetching tickets: %v", err)
+			fatalJira("fetching resolved tickets", err)
+		}
+		fmt.Printf("Added %d tickets resolved in the last %s\n", len(resolved), resolvedSince)
+		tickets = append(tickets, resolved...)
+		for key, epic := range resolvedEpics {
+			if _, ok := epics[key]; !ok {
+				epics[key] = epic
+			}
+		}
+	}
+	switch subtasksMode {
+	case "exclude":
+		tickets = jira.ExcludeSubtasks(tickets)
+	case "collapse":
+		tickets = jira.CollapseSubtasks(tickets)
+	}
+	risk := jira.RiskPadding{Field: cfg.RiskField, Multipliers: cfg.RiskMultipliers}
+	if cfg.RiskField != "" {
+		risk.Apply(tickets)
+	}
+	imported := len(tickets)
+	tickets = mergeExtraTasks(tickets, extras)
+	if rollingWave != "" {
+		now := time.Now().In(cfg.Location)
+		horizon, _ := schedule.HorizonDate(rollingWave, now)
+		var collapsed []string
+		if tickets, collapsed, err = schedule.RollingWave(tickets, epics, now, horizon, schedule.DefaultCalendar()); err != nil {
+			fatal(exitProblems, "Error projecting epic start dates for --rolling-wave: %v", err)
+		}
+		if len(collapsed) > 0 {
+			fmt.Printf("Collapsed %d epics starting after %s: %s\n", len(collapsed), horizon.Format(cfg.DateLayout), strings.Join(collapsed, ", "))
 		}
+	}
+	if wsjf {
+		scoring.Sort(tickets)
+	}
+	if len(followUps) > 0 {
+		before := len(tickets)
+		tickets = jira.AddFollowUpTasks(tickets, followUps)
+		fmt.Printf("Adding %d follow-up tasks from follow_up_rules\n", len(tickets)-before)
+	}
+	if reviewTasks {
+		tickets = jira.AddReviewTasks(tickets, cfg.ReviewEffortDays)
+	}
+	if slices.Contains(levels, "quarter") {
+		sortByQuarter(tickets)
+	}
 
-		// Create the .oplx directory
-		dirName := projectName + ".oplx"
-		if err := os.MkdirAll(dirName, 0755); err != nil {
-			log.Fatalf("Error creating directory %s: %v", dirName, err)
+	// Due dates an assignee cannot meet are worth knowing before opening the plan
+	for _, o := range report.Overcommitments(tickets, time.Now().In(cfg.Location), schedule.DefaultCalendar()) {
+		term.Warnf("%s has %.1fd of work due by %s (%s) but only %d working days left",
+			o.Assignee, o.Effort, o.DueDate.Format(cfg.DateLayout), strings.Join(o.Keys, ", "), o.Available)
+	}
+
+	switch outputFormat {
+	case "taskpaper":
+		writeTaskPaper(cfg, projectName, tickets, epics)
+		return
+	case "smartsheet":
+		writeSmartsheet(cfg, projectName, tickets, epics)
+		return
+	case "xer":
+		writeXER(cfg, projectName, tickets, epics)
+		return
+	case "omnijs":
+		writeOmniJS(cfg, projectName, tickets, epics)
+		return
+	}
+
+	// Stopping once the fetch is complete leaves the plan as it was
+	if ctx.Err() != nil && !interrupted {
+		fatal(exitJira, "Error: %v before the plan was written", ctx.Err())
+	}
+
+	// A package this run creates is removed again if writing it fails, so no
+	// broken package is left behind
+	_, statErr := os.Stat(dirName)
+	created := os.IsNotExist(statErr)
+	fail := func(format string, args ...any) {
+		if created {
+			os.RemoveAll(dirName)
+		}
+		fatal(exitWrite, format, args...)
+	}
+
+	// Create the .oplx directory
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		fail("Error creating directory %s: %v", dirName, err)
+	}
+
+	// Write Actual.xml
+	actualPath := filepath.Join(dirName, omniplan.ActualScenarioFile)
+
+	// Keep the previous plan around so changes can be reported after regeneration
+	var previous *omniplan.Scenario
+	if cfg.NotifyWebhookURL != "" {
+		if prev, err := omniplan.ReadScenarioFile(actualPath); err == nil {
+			previous = prev
+		} else if !os.IsNotExist(err) {
+			term.Warnf("Could not read previous plan for change notification: %v", err)
 		}
+	}
+
+	serializer := omniplan.NewSerializer(projectName)
+	if resourceProfiles && !interrupted {
+		// Failures are reported at the end of the run
+		profiles, _ := client.GetUserProfiles(ctx, tickets)
+		serializer.UserProfiles = profiles
+	}
+	serializer.GroupByEpic = epicGroup
+	serializer.MilestoneDone = milestoneDone
+	serializer.NestSubtasks = subtasksMode == "nest"
+	serializer.GroupBy = groupByFuncs(levels, epics)
+	if sprintPhases {
+		serializer.EpicPhase = lastSprint
+	}
+	if cfg.TeamField != "" {
+		serializer.Team = func(t jira.Ticket) string { return t.Fields[cfg.TeamField] }
+	}
+	serializer.PrimaryUnits = cfg.PrimaryAssigneeUnits
+	if wsjf {
+		serializer.WSJFScore = scoring.Score
+	}
+	serializer.BufferPercent = bufferPercent
+	serializer.MaxTaskDays = maxTaskDays
+	if cfg.RiskField != "" {
+		serializer.Risk = risk.Level
+	}
+	serializer.IssueTypePrefixes = cfg.IssueTypePrefixes
+	serializer.MaxTitleLength = cfg.MaxTitleLength
+	serializer.NoteTemplate = noteTemplate
+	serializer.Palette = stylePalette(cfg)
+	serializer.FormatVersion = oplxVersion
+	for _, m := range cfg.Milestones {
+		serializer.Milestones = append(serializer.Milestones, omniplan.Milestone{Name: m.Name, Date: m.Day, DependsOn: m.DependsOn})
+	}
+	// Serialize in full before replacing the previous Actual.xml
+	var actual bytes.Buffer
+	if err := serializer.Serialize(&actual, tickets, epics); err != nil {
+		fail("Error serializing to OmniPlan XML: %v", err)
+	}
+	if err := os.WriteFile(actualPath, actual.Bytes(), 0644); err != nil {
+		fail("Error writing %s: %v", actualPath, err)
+	}
 
-		// Write Actual.xml
-		actualPath := filepath.Join(dirName, "Actual.xml")
-		actualFile, err := os.Create(actualPath)
+	// Render __TOC.xml with the Jira columns and the package's scenarios
+	if err := omniplan.WritePackageTOC(dirName); err != nil {
+		fail("Error writing __TOC.xml: %v", err)
+	}
+
+	// Record this run in __changelog.xml, keeping the entries of earlier runs
+	comment := fmt.Sprintf("Imported %d tasks from Jira", imported)
+	if interrupted {
+		comment += " (partial: fetching was interrupted)"
+	}
+	if err := omniplan.AppendChangelog(dirName, time.Now(), changelogAuthor(cfg), comment); err != nil {
+		fail("Error writing %s: %v", omniplan.ChangelogFile, err)
+	}
+
+	err = omniplan.WriteManifest(dirName, &omniplan.Manifest{
+		Project:     projectName,
+		JQL:         jql,
+		Flags:       flags,
+		ConfigFile:  cfg.File,
+		GeneratedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		fail("Error writing manifest: %v", err)
+	}
+
+	fmt.Printf("Created OmniPlan package: %s\n", dirName)
+	if openPlan {
+		openInOmniPlan(dirName)
+	}
+
+	// An incomplete plan is not worth announcing or publishing
+	if interrupted {
+		term.Warnf("%s is partial; skipping notifications, uploads, attachments and email", dirName)
+		return
+	}
+
+	if previous != nil {
+		notifyChanges(ctx, cfg, projectName, previous, actualPath)
+	}
+
+	for _, destination := range append(slices.Clone(cfg.UploadDestinations), uploadTo...) {
+		if err := uploadPackage(ctx, cfg, destination, dirName); err != nil {
+			fatal(exitWrite, "Error uploading to %s: %v", destination, err)
+		}
+	}
+
+	if attachTo != "" {
+		zipped, err := zipPackage(dirName)
 		if err != nil {
-			log.Fatalf("Error creating file %s: %v", actualPath, err)
+			fatal(exitWrite, "Error zipping plan: %v", err)
+		}
+		if err := client.AttachFile(ctx, attachTo, filepath.Base(dirName)+".zip", bytes.NewReader(zipped)); err != nil {
+			fatalJira("attaching plan", err)
 		}
-		defer actualFile.Close()
+		fmt.Printf("Attached %s to %s\n", dirName, attachTo)
+	}
 
-		serializer := omniplan.NewSerializer(projectName)
-		serializer.GroupByEpic = epicGroup
-		serializer.MilestoneDone = milestoneDone
-		if err := serializer.Serialize(actualFile, tickets, epics); err != nil {
-			log.Fatalf("Error serializing to OmniPlan XML: %v", err)
+	if len(emailTo) > 0 {
+		if err := emailPackage(cfg, projectName, query, dirName); err != nil {
+			fatal(exitWrite, "Error emailing plan: %v", err)
 		}
+		fmt.Printf("Emailed %s to %s\n", dirName, strings.Join(emailTo, ", "))
+	}
+}
 
-		// Copy __TOC.xml
-		if err := copyTemplateFile(templateFS, "templates/__TOC.xml", filepath.Join(dirName, "__TOC.xml")); err != nil {
-			log.Fatalf("Error copying __TOC.xml: %v", err)
+// followUpRules converts the configured follow-up rules
+func followUpRules(cfg *config.Config) []jira.FollowUpRule {
+	rules := make([]jira.FollowUpRule, 0, len(cfg.FollowUpRules))
+	for _, r := range cfg.FollowUpRules {
+		rule := jira.FollowUpRule{IssueTypes: r.IssueTypes, Field: r.Field, Values: r.Values}
+		for _, t := range r.Tasks {
+			rule.Tasks = append(rule.Tasks, jira.FollowUpTask{Title: t.Title, EffortDays: t.EffortDays, Assignee: t.Assignee})
 		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// stylePalette converts the configured styles, nil when none are set
+func stylePalette(cfg *config.Config) *omniplan.Palette {
+	if cfg.Styles == (config.StylesConfig{}) {
+		return nil
+	}
+	// Colors were validated by config.Load
+	color := func(hex string) *omniplan.Color {
+		c, _ := omniplan.HexColor(hex)
+		return c
+	}
+	palette := &omniplan.Palette{Font: cfg.Styles.Font, FontSize: cfg.Styles.FontSize}
+	if cfg.Styles.GroupColor != "" {
+		palette.GroupColor = color(cfg.Styles.GroupColor)
+	}
+	if cfg.Styles.MilestoneColor != "" {
+		palette.MilestoneColor = color(cfg.Styles.MilestoneColor)
+	}
+	if cfg.Styles.CompletedColor != "" {
+		palette.CompletedColor = color(cfg.Styles.CompletedColor)
+	}
+	return palette
+}
 
-		// Copy __changelog.xml
-		if err := copyTemplateFile(templateFS, "templates/__changelog.xml", filepath.Join(dirName, "__changelog.xml")); err != nil {
-			log.Fatalf("Error copying __changelog.xml: %v", err)
+// mergeExtraTasks appends the manual tasks to the tickets, refusing IDs that
+// clash with Jira keys since dependencies are resolved by key
+func mergeExtraTasks(tickets, extras []jira.Ticket) []jira.Ticket {
+	if len(extras) == 0 {
+		return tickets
+	}
+
+	keys := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		keys[t.Key] = true
+	}
+	for _, e := range extras {
+		if keys[e.Key] {
+			fatal(exitConfig, "Error: extra task id %q is also a Jira issue in the query results; give it a different id", e.Key)
 		}
+	}
 
-		fmt.Printf("Created OmniPlan package: %s\n", dirName)
-	},
+	fmt.Printf("Adding %d extra tasks from %s\n", len(extras), extraTasksFile)
+	return append(tickets, extras...)
+}
+
+// writeTaskPaper writes the tickets as <project>.taskpaper for OmniFocus
+func writeTaskPaper(cfg *config.Config, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket) {
+	path := projectName + ".taskpaper"
+	err := writeReport(path, func(w io.Writer) error {
+		return taskpaper.Write(w, projectName, tickets, epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+	})
+	if err != nil {
+		fatal(exitWrite, "Error writing TaskPaper outline: %v", err)
+	}
 }
 
-// copyTemplateFile copies a file from the embedded filesystem to the destination path
-func copyTemplateFile(fs embed.FS, srcPath, dstPath string) error {
-	src, err := fs.Open(srcPath)
+// writeSmartsheet writes the tickets as <project>.xlsx for Smartsheet's import
+func writeSmartsheet(cfg *config.Config, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket) {
+	path := projectName + ".xlsx"
+	err := writeReport(path, func(w io.Writer) error {
+		return smartsheet.Write(w, projectName, tickets, epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open embedded file %s: %w", srcPath, err)
+		fatal(exitWrite, "Error writing Smartsheet workbook: %v", err)
 	}
-	defer src.Close()
+}
+
+// writeXER writes the tickets as <project>.xer for Primavera P6
+func writeXER(cfg *config.Config, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket) {
+	path := projectName + ".xer"
+	err := writeReport(path, func(w io.Writer) error {
+		return xer.Write(w, projectName, tickets, epics, time.Now().In(cfg.Location), schedule.DefaultCalendar())
+	})
+	if err != nil {
+		fatal(exitWrite, "Error writing XER file: %v", err)
+	}
+}
+
+// writeOmniJS writes the tickets as <project>.js, a script that creates or
+// updates them in the open OmniPlan document
+func writeOmniJS(cfg *config.Config, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket) {
+	path := projectName + ".js"
+	err := writeReport(path, func(w io.Writer) error {
+		return omnijs.Write(w, projectName, tickets, epics, cfg.IssueTypePrefixes)
+	})
+	if err != nil {
+		fatal(exitWrite, "Error writing Omni Automation script: %v", err)
+	}
+}
+
+// changedFlags returns the flags set on the command line as name -> value,
+// with list values comma-separated so they can be set again
+func changedFlags(fs *pflag.FlagSet) map[string]string {
+	flags := make(map[string]string)
+	fs.Visit(func(f *pflag.Flag) {
+		if f.Name == "no-color" || f.Name == "open" {
+			return // Output styling and opening, not part of how the plan was generated
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			flags[f.Name] = strings.Join(slice.GetSlice(), ",")
+			return
+		}
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
 
-	dst, err := os.Create(dstPath)
+// notifyChanges posts a summary of the differences between the previous plan
+// and the freshly written one to the configured webhook
+func notifyChanges(ctx context.Context, cfg *config.Config, projectName string, previous *omniplan.Scenario, actualPath string) {
+	current, err := omniplan.ReadScenarioFile(actualPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", dstPath, err)
+		term.Warnf("Could not read generated plan for change notification: %v", err)
+		return
+	}
+
+	diff := omniplan.DiffScenarios(previous, current)
+	if !diff.HasChanges() {
+		return
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, src); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+	notifier, err := notify.New(cfg.NotifyWebhookURL, cfg.NotifyWebhookType)
+	if err != nil {
+		term.Warnf("%v", err)
+		return
+	}
+	if err := notifier.NotifyChanges(ctx, projectName, diff); err != nil {
+		term.Warnf("Failed to send change notification: %v", err)
+		return
 	}
+	fmt.Println("Sent plan change notification")
+}
 
+// uploadPackage publishes the generated package to a configured destination
+func uploadPackage(ctx context.Context, cfg *config.Config, destination, dirName string) error {
+	uploader, err := upload.New(destination, upload.Options{
+		S3Region:       cfg.S3Region,
+		S3Endpoint:     cfg.S3Endpoint,
+		GCSAccessToken: cfg.GCSAccessToken,
+		WebDAVUsername: cfg.WebDAVUsername,
+		WebDAVPassword: cfg.WebDAVPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := upload.UploadDir(ctx, uploader, dirName); err != nil {
+		return err
+	}
+	fmt.Printf("Uploaded %s to %s\n", dirName, uploader.Destination())
 	return nil
 }
 
+// emailPackage mails the zipped package to the --email-to recipients
+func emailPackage(cfg *config.Config, projectName, jql, dirName string) error {
+	zipped, err := zipPackage(dirName)
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mail.Config{
+		Host:     cfg.SMTP.Host,
+		Port:     cfg.SMTP.Port,
+		Username: cfg.SMTP.Username,
+		Password: cfg.SMTP.Password,
+		From:     cfg.SMTP.From,
+	}, mail.Message{
+		To:      emailTo,
+		Subject: fmt.Sprintf("Project plan: %s", projectName),
+		Body:    fmt.Sprintf("Attached is the OmniPlan package %s, generated from the JQL query:\n\n%s\n", dirName, jql),
+		Attachments: []mail.Attachment{
+			{Filename: filepath.Base(dirName) + ".zip", ContentType: "application/zip", Data: zipped},
+		},
+	})
+}
+
+// zipPackage returns the package directory as a zip archive
+func zipPackage(dirName string) ([]byte, error) {
+	var zipped bytes.Buffer
+	if err := omniplan.ZipPackage(&zipped, dirName); err != nil {
+		return nil, err
+	}
+	return zipped.Bytes(), nil
+}
+
+// changelogAuthor is the author of changelog entries: changelog_author from
+// the configuration, or else the current user
+func changelogAuthor(cfg *config.Config) string {
+	if cfg.ChangelogAuthor != "" {
+		return cfg.ChangelogAuthor
+	}
+	if u, err := user.Current(); err == nil {
+		if u.Name != "" {
+			return u.Name
+		}
+		return u.Username
+	}
+	return ""
+}
+
+// openInOmniPlan opens the package in OmniPlan. Only macOS has OmniPlan, so
+// elsewhere it does nothing.
+func openInOmniPlan(dirName string) {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+	if err := exec.Command("open", dirName).Run(); err != nil {
+		term.Warnf("Could not open %s: %v", dirName, err)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(publishCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(roadmapCmd)
+	rootCmd.AddCommand(mondayCmd)
+	rootCmd.AddCommand(notionCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "Language of generated titles, notes and warnings, e.g. de or nb, or a .yaml message catalog (overrides the language setting)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also off when NO_COLOR is set or output is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", "error", "Exit non-zero on: error, or warning to also fail runs that printed warnings (exit status 6)")
+	rootCmd.PersistentFlags().StringVar(&annotationFormat, "annotations", "", "Print warnings and errors as CI annotations: github (GitHub Actions workflow commands)")
+	rootCmd.PersistentFlags().BoolVar(&debugHTTP, "debug-http", false, "Log every Jira request with its status and duration to stderr")
+	rootCmd.PersistentFlags().StringVar(&debugHTTPFile, "debug-http-file", "", "Also dump Jira request and response headers and bodies, with credentials redacted, to this file")
+	rootCmd.PersistentFlags().StringSliceVar(&queryVars, "var", nil, "Set query placeholders as name=value (e.g. --var project=PROJ)")
 	rootCmd.Flags().BoolVarP(&epicGroup, "epic-group", "e", false, "Group tasks by Epic")
 	rootCmd.Flags().BoolVarP(&milestoneDone, "milestone-done", "m", false, "Add a final 'Done' milestone")
+	rootCmd.Flags().BoolVar(&actualStart, "actual-start", false, "Derive actual start dates from issue changelogs")
+	rootCmd.Flags().StringSliceVar(&emailTo, "email-to", nil, "Email the zipped package to these addresses (requires smtp config)")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", "", "Group tasks by comma-separated levels, outermost first: epic, quarter, label:<prefix> or a Jira field ID or name")
+	rootCmd.Flags().BoolVar(&sprintPhases, "sprint-phases", false, "Split each Epic group into one phase per sprint (requires --epic-group)")
+	rootCmd.Flags().StringVar(&resolvedSince, "resolved-since", "", "Also include issues resolved within this window in the queried projects (e.g. 30d), marked complete")
+	rootCmd.Flags().BoolVar(&resourceProfiles, "resource-profiles", false, "Describe each assignee's resource with their Jira profile (time zone, email, avatar)")
+	rootCmd.Flags().BoolVar(&wsjf, "wsjf", false, "Order tasks by Weighted Shortest Job First from the wsjf fields in configuration")
+	rootCmd.Flags().BoolVar(&reviewTasks, "review-tasks", false, "Add a review task for the reviewer after each ticket with a reviewer_field set")
+	rootCmd.Flags().StringVar(&rollingWave, "rolling-wave", "", "Collapse epics projected to start beyond this horizon (e.g. 2q, 6m, 12w or 90d) into one summary task each")
+	rootCmd.Flags().Float64Var(&maxTaskDays, "max-task-days", 0, "Split tasks of more effort than this many days into sequential parts under a group")
+	rootCmd.Flags().IntVar(&bufferPercent, "buffers", 0, "Add critical chain feeding and project buffers of this percentage of the effort they protect (e.g. 50)")
+	rootCmd.Flags().StringVar(&subtasksMode, "subtasks", "flat", "Sub-task handling: flat, exclude, collapse (effort into parent) or nest (under parent)")
+	rootCmd.Flags().StringVar(&extraTasksFile, "extra-tasks", "", "Merge manual tasks from this YAML file into the plan")
+	rootCmd.Flags().BoolVar(&partialOutput, "partial", false, "On SIGINT, SIGTERM or run_timeout, write a plan of the tickets fetched so far instead of none")
+	rootCmd.Flags().BoolVar(&incremental, "incremental", false, "Fetch only issues updated since the previous run of this query")
+	rootCmd.Flags().StringSliceVar(&uploadTo, "upload-to", nil, "Also upload the package to these destinations, like upload_destinations in configuration")
+	rootCmd.Flags().StringVar(&attachTo, "attach-to", "", "Attach the zipped package to this Jira issue (e.g. PROJ-123)")
+	rootCmd.Flags().IntVar(&oplxVersion, "oplx-version", omniplan.FormatVersion4, "OmniPlan markup version: 4, or 2 for OmniPlan 2 and 3 (plain-text notes)")
+	rootCmd.Flags().BoolVar(&openPlan, "open", false, "Open the package in OmniPlan once written (macOS only; ignored elsewhere)")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "omniplan", "Output format: omniplan (.oplx package), taskpaper (OmniFocus outline), smartsheet (.xlsx for Smartsheet import), xer (Primavera P6) or omnijs (OmniPlan automation script)")
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fatal(exitError, "%v", err)
 	}
 }
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var scheduleList bool
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Regenerate the configured plans on their cron schedules",
+	Long: `Runs continuously, generating each plan in the jobs list of the configuration
+whenever its cron expression fires, e.g. every Monday at 07:00 with "0 7 * * mon".
+Expressions are in the configured timezone.
+
+Each run is a separate jql-to-plan process with the job's project, query and
+flags, writing to the job's output directory and uploading to its destinations.
+A failed run is reported and the job runs again at its next time. Stop the
+scheduler with Ctrl-C or SIGTERM.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		if len(cfg.Jobs) == 0 {
+			fatal(exitConfig, "Error: no jobs are configured; add a jobs list to the configuration (see 'jql-to-plan config')")
+		}
+		layout := cfg.DateLayout + " 15:04"
+
+		next := make([]time.Time, len(cfg.Jobs))
+		now := time.Now().In(cfg.Location)
+		for i, job := range cfg.Jobs {
+			next[i] = job.Schedule.Next(now)
+			when := "never"
+			if !next[i].IsZero() {
+				when = next[i].Format(layout)
+			}
+			fmt.Printf("%s (%s): next run %s\n", job.Name, job.Cron, when)
+		}
+		if scheduleList {
+			return
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			fatal(exitError, "Error finding the jql-to-plan executable: %v", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		for {
+			// Wake at least every minute, so a changed clock or a sleeping
+			// machine delays runs by no more than that
+			wait := time.Minute
+			pending := false
+			for _, t := range next {
+				if !t.IsZero() {
+					wait = min(wait, time.Until(t))
+					pending = true
+				}
+			}
+			if !pending {
+				fatal(exitConfig, "Error: no job will run again")
+			}
+
+			select {
+			case <-ctx.Done():
+				fmt.Println("Stopping the scheduler")
+				return
+			case <-time.After(max(wait, 0)):
+			}
+
+			for i, job := range cfg.Jobs {
+				if next[i].IsZero() || time.Now().Before(next[i]) {
+					continue
+				}
+				runJob(ctx, exe, cfg, job, layout)
+				next[i] = job.Schedule.Next(time.Now().In(cfg.Location))
+			}
+		}
+	},
+}
+
+// runJob generates a job's plan in a jql-to-plan process of its own, so a
+// failing run cannot stop the scheduler. The process reads the scheduler's
+// configuration file.
+func runJob(ctx context.Context, exe string, cfg *config.Config, job config.JobConfig, layout string) {
+	args := []string{job.Project, job.Query}
+	names := make([]string, 0, len(job.Flags))
+	for name := range job.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("--%s=%s", name, job.Flags[name]))
+	}
+	if len(job.Upload) > 0 {
+		args = append(args, "--upload-to="+strings.Join(job.Upload, ","))
+	}
+
+	c := exec.CommandContext(ctx, exe, args...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = os.Environ()
+	if cfg.File != "" {
+		path, err := filepath.Abs(cfg.File)
+		if err != nil {
+			term.Warnf("Job %q not run: %v", job.Name, err)
+			return
+		}
+		c.Env = append(c.Env, config.ConfigFileEnv+"="+path)
+	}
+	if job.Output != "" {
+		if err := os.MkdirAll(job.Output, 0755); err != nil {
+			term.Warnf("Job %q not run: %v", job.Name, err)
+			return
+		}
+		c.Dir = job.Output
+	}
+
+	started := time.Now()
+	fmt.Printf("%s Running %s\n", started.In(cfg.Location).Format(layout), job.Name)
+	if err := c.Run(); err != nil {
+		if ctx.Err() != nil {
+			return // Stopped along with the scheduler
+		}
+		term.Warnf("Job %q failed: %v", job.Name, err)
+		return
+	}
+	fmt.Printf("%s Finished %s in %s\n", time.Now().In(cfg.Location).Format(layout), job.Name, time.Since(started).Round(time.Second))
+}
+
+func init() {
+	scheduleCmd.Flags().BoolVar(&scheduleList, "list", false, "Print the jobs and their next run, then exit")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/config"
+	"github.com/gunnarrb/jql-to-plan/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [project] [JQL]",
+	Short: "Save the fetched plan state for later comparison",
+	Long: `Fetches the tickets matching the JQL query and saves them as a timestamped JSON snapshot
+under snapshot_dir/<project> (default $HOME/.jql-to-plan/snapshots).`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+
+		cfg := loadConfig()
+		requireEffortField(cfg)
+		jql := resolveQuery(cfg, args[1])
+		client := newJiraClient(cfg, clientOptions(cfg))
+
+		ctx, cancel := runContext(cfg)
+		defer cancel()
+
+		tickets, epics, err := client.GetTickets(ctx, jql)
+		if err != nil {
+			fatalJira("fetching tickets", err)
+		}
+
+		path, err := snapshot.Save(cfg.SnapshotDir, &snapshot.Snapshot{
+			Project: projectName,
+			JQL:     jql,
+			TakenAt: time.Now(),
+			Tickets: tickets,
+			Epics:   epics,
+		})
+		if err != nil {
+			fatal(exitWrite, "Error saving snapshot: %v", err)
+		}
+		fmt.Printf("Saved snapshot of %d tickets: %s\n", len(tickets), path)
+	},
+}
+
+var snapshotCompareCmd = &cobra.Command{
+	Use:   "compare [old.json new.json | project]",
+	Short: "Compare two snapshots",
+	Long: `Shows scope growth, effort drift and dependency changes between two snapshots.
+Pass two snapshot files, or a project name to compare its two most recent snapshots.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := args
+		if len(args) == 1 {
+			cfg, err := config.Load()
+			if err != nil && err != config.ErrConfigNotFound {
+				fatal(exitConfig, "Error loading config: %v", err)
+			}
+			dir := ""
+			if cfg != nil {
+				dir = cfg.SnapshotDir
+			}
+
+			all, err := snapshot.List(dir, args[0])
+			if err != nil {
+				fatal(exitError, "Error listing snapshots for %s: %v", args[0], err)
+			}
+			if len(all) < 2 {
+				fatal(exitError, "Error: project %s has %d snapshot(s); at least 2 are needed", args[0], len(all))
+			}
+			paths = all[len(all)-2:]
+		}
+
+		older, err := snapshot.Load(paths[0])
+		if err != nil {
+			fatal(exitError, "Error loading snapshot: %v", err)
+		}
+		newer, err := snapshot.Load(paths[1])
+		if err != nil {
+			fatal(exitError, "Error loading snapshot: %v", err)
+		}
+
+		if err := snapshot.Compare(older, newer).WriteText(os.Stdout); err != nil {
+			fatal(exitWrite, "Error writing comparison: %v", err)
+		}
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotCompareCmd)
+}
@@ -0,0 +1,140 @@
+package awssig
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Credentials, time and scope of the AWS SigV4 test suite
+var (
+	suiteCreds = Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	suiteTime  = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestSigningKey(t *testing.T) {
+	// From "Examples of how to derive a signing key for Signature Version 4"
+	got := hex.EncodeToString(signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam"))
+	if want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"; got != want {
+		t.Errorf("signingKey = %s, want %s", got, want)
+	}
+}
+
+func TestNewSigning_TestSuite(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		service     string
+
+		canonicalRequest string
+		stringToSign     string
+		signature        string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET", url: "https://example.amazonaws.com/",
+			canonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\nbb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+			signature:        "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET", url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			canonicalRequest: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			signature:        "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "get-space",
+			method: "GET", url: "https://example.amazonaws.com/example%20space/",
+			canonicalRequest: "GET\n/example%20space/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			signature:        "652487583200325589f1fba4c7e578f72c47cb61beeca81406b39ddec1366741",
+		},
+		{
+			name:   "get-utf8",
+			method: "GET", url: "https://example.amazonaws.com/%E1%88%B4",
+			canonicalRequest: "GET\n/%E1%88%B4\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			signature:        "8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85",
+		},
+		{
+			name:   "get-unreserved",
+			method: "GET", url: "https://example.amazonaws.com/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			signature: "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f",
+		},
+		{
+			name:   "post-x-www-form-urlencoded",
+			method: "POST", url: "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded", body: "Param1=value1",
+			canonicalRequest: "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\ncontent-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			signature:        "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			// The IAM ListUsers example of the SigV4 documentation
+			name:   "iam-list-users",
+			method: "GET", url: "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8", service: "iam",
+			canonicalRequest: "GET\n/\nAction=ListUsers&Version=2010-05-08\ncontent-type:application/x-www-form-urlencoded; charset=utf-8\nhost:iam.amazonaws.com\nx-amz-date:20150830T123600Z\n\ncontent-type;host;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/iam/aws4_request\nf536975d06c0309214f805bb90ccff089219ecd68b2577efef23edd43b7e1a59",
+			signature:        "5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Amz-Date", suiteTime.Format(amzDateFormat))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			service := tt.service
+			if service == "" {
+				service = "service"
+			}
+
+			s := newSigning(req, hashHex([]byte(tt.body)), "us-east-1", service, suiteCreds.SecretAccessKey, suiteTime)
+			if tt.canonicalRequest != "" && s.canonicalRequest != tt.canonicalRequest {
+				t.Errorf("canonical request:\n%s\nwant:\n%s", s.canonicalRequest, tt.canonicalRequest)
+			}
+			if tt.stringToSign != "" && s.stringToSign != tt.stringToSign {
+				t.Errorf("string to sign:\n%s\nwant:\n%s", s.stringToSign, tt.stringToSign)
+			}
+			if s.signature != tt.signature {
+				t.Errorf("signature = %s, want %s", s.signature, tt.signature)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: suiteCreds.SecretAccessKey, SessionToken: "token"}
+	req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.eu-north-1.amazonaws.com/Plan.oplx/Actual.xml", nil)
+	req.Header.Set("Content-Type", "application/xml")
+	payload := []byte("<scenario/>")
+	Sign(req, payload, "eu-north-1", "s3", creds, suiteTime.In(time.FixedZone("CEST", 2*3600)))
+
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s, want the time in UTC", got)
+	}
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != hashHex(payload) {
+		t.Errorf("X-Amz-Content-Sha256 = %s, want the payload hash", got)
+	}
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Errorf("X-Amz-Security-Token = %q, want the session token", got)
+	}
+
+	// The signature covers the headers Sign added
+	s := newSigning(req, hashHex(payload), "eu-north-1", "s3", creds.SecretAccessKey, suiteTime)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/eu-north-1/s3/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=" + s.signature
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+// Package cache keeps the last fetched tickets per query for incremental fetches.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// Entry is the cached result of a query
+type Entry struct {
+	JQL         string        `json:"jql"`
+	FetchedAt   time.Time     `json:"fetched_at"`    // Start of the last successful fetch
+	FullFetchAt time.Time     `json:"full_fetch_at"` // Start of the last full (non-incremental) fetch
+	Tickets     []jira.Ticket `json:"tickets"`
+}
+
+// Key derives a file-safe cache key from everything that affects a fetch
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Load reads the entry stored under key. A missing entry returns an error
+// satisfying os.IsNotExist.
+func Load(dir, key string) (*Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("decoding cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// Save stores the entry under key, replacing it atomically
+func Save(dir, key string, entry *Entry) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, key+".json"))
+}
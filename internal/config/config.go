@@ -1,23 +1,294 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/gunnarrb/jql-to-plan/internal/cron"
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/secrets"
 	"github.com/spf13/viper"
 )
 
+// hexColor matches the colors of StylesConfig
+var hexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
 var ErrConfigNotFound = errors.New("configuration file not found")
 
+// ConfigFileEnv is the environment variable that selects the configuration
+// file instead of .jql-to-plan.yaml in the current or home directory
+const ConfigFileEnv = "JQL_TO_PLAN_CONFIG"
+
 type Config struct {
+	// File is the configuration file that was read (empty when only environment variables are used)
+	File string `mapstructure:"-"`
+
 	JiraURL               string `mapstructure:"jira_url"`
 	JiraPAT               string `mapstructure:"jira_pat"`
 	EffortCustomFieldID   string `mapstructure:"effort_custom_field_id"`
 	EpicLinkCustomFieldID string `mapstructure:"epic_link_custom_field_id"`
+	EffortFieldName       string `mapstructure:"effort_field_name"`
+	EpicLinkFieldName     string `mapstructure:"epic_link_field_name"`
+	FlaggedCustomFieldID  string `mapstructure:"flagged_custom_field_id"`
+	APIVersion            string `mapstructure:"api_version"`
+
+	// JiraUsername and JiraPassword log in to a Jira session instead of using
+	// the PAT, and JiraSessionCookie is a session captured from a browser, for
+	// Data Center instances behind SSO proxies that block Bearer tokens
+	JiraUsername      string `mapstructure:"jira_username"`
+	JiraPassword      string `mapstructure:"jira_password"`
+	JiraSessionCookie string `mapstructure:"jira_session_cookie"`
+
+	// KerberosTokenCommand authenticates with Kerberos (SPNEGO) instead: the
+	// command prints a base64 token for the Jira host, which replaces {host}
+	// in its arguments (see jira.ClientOptions.NegotiateCommand)
+	KerberosTokenCommand []string `mapstructure:"kerberos_token_command"`
+
+	// TeamField is the Jira field (ID or name) holding a ticket's team.
+	// Unassigned tickets with a team are assigned to a team resource.
+	TeamField string `mapstructure:"team_field"`
+
+	// CoAssigneeField is a user or multi-user field (ID or name) whose users
+	// share each task with its assignee. PrimaryAssigneeUnits is the
+	// assignee's share; zero splits the work equally.
+	CoAssigneeField      string  `mapstructure:"co_assignee_field"`
+	PrimaryAssigneeUnits float64 `mapstructure:"primary_assignee_units"`
+
+	// ReviewerField is the user field (ID or name) holding a ticket's
+	// reviewer. With --review-tasks, each reviewed ticket gets a review task
+	// of ReviewEffort (default "0.25d"); ReviewEffortDays is it in days.
+	ReviewerField    string  `mapstructure:"reviewer_field"`
+	ReviewEffort     string  `mapstructure:"review_effort"`
+	ReviewEffortDays float64 `mapstructure:"-"`
+
+	// RiskField is the Jira field (ID or name) holding a ticket's risk or
+	// confidence level. RiskMultipliers pad the effort per level, matched
+	// case-insensitively.
+	RiskField       string             `mapstructure:"risk_field"`
+	RiskMultipliers map[string]float64 `mapstructure:"risk_multipliers"`
+
+	// InProgressStatuses and DoneStatuses drive changelog-based dates and reports
+	InProgressStatuses []string `mapstructure:"in_progress_statuses"`
+	DoneStatuses       []string `mapstructure:"done_statuses"`
+
+	// Per issue type behavior. Map keys are issue type names, matched
+	// case-insensitively. IssueTypeEfforts is read from the older
+	// issue_type_efforts, in days only, and holds DefaultEffort after Load.
+	ExcludeIssueTypes []string           `mapstructure:"exclude_issue_types"`
+	IssueTypeEfforts  map[string]float64 `mapstructure:"issue_type_efforts"`
+	IssueTypePrefixes map[string]string  `mapstructure:"issue_type_prefixes"`
+
+	// NoteTemplate is a Go text/template rendering a note for each task from
+	// its ticket. NoteFields are fields (IDs or names) fetched for it, in the
+	// ticket's Fields.
+	NoteTemplate string   `mapstructure:"note_template"`
+	NoteFields   []string `mapstructure:"note_fields"`
+
+	// MaxTitleLength caps task and epic titles, in characters; longer ones are
+	// cut with an ellipsis. Zero means no limit.
+	MaxTitleLength int `mapstructure:"max_title_length"`
+
+	// Styles is the palette generated plans are styled with
+	Styles StylesConfig `mapstructure:"styles"`
+
+	// DefaultEffort is the effort per issue type for tickets without an
+	// estimate, in days or as a duration such as "0.5d" or "4h". Load merges it
+	// into IssueTypeEfforts; for a type set in both, DefaultEffort wins.
+	DefaultEffort map[string]string `mapstructure:"default_effort"`
+
+	// Queries are saved JQL queries that can be passed by name instead of JQL.
+	// Variables fill {{name}} placeholders in queries (see jira.ExpandJQL).
+	Queries   map[string]string `mapstructure:"queries"`
+	Variables map[string]string `mapstructure:"variables"`
+
+	ProxyURL              string `mapstructure:"proxy_url"`
+	TLSCAFile             string `mapstructure:"tls_ca_file"`
+	TLSInsecureSkipVerify bool   `mapstructure:"tls_insecure_skip_verify"`
+
+	// Headers are sent with every Jira request. Names are case-insensitive.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// HoursPerDay and DaysPerWeek convert effort durations such as "2w 3d 4h"
+	// into days (default 8 and 5, as in Jira time tracking)
+	HoursPerDay float64 `mapstructure:"hours_per_day"`
+	DaysPerWeek float64 `mapstructure:"days_per_week"`
+
+	// EffortFieldUnit is the unit of plain numbers in the effort field: days
+	// (default), hours or points, which are DaysPerPoint days each (default 1)
+	EffortFieldUnit string  `mapstructure:"effort_field_unit"`
+	DaysPerPoint    float64 `mapstructure:"days_per_point"`
+
+	// Timezone is the IANA time zone (e.g. "Europe/Oslo") used for all dates,
+	// defaulting to the system time zone. Location is the loaded zone.
+	Timezone string         `mapstructure:"timezone"`
+	Location *time.Location `mapstructure:"-"`
+
+	// Language is the language of generated text such as milestone titles,
+	// notes and warnings: a built-in language code (see i18n.Languages) or a
+	// path to a YAML message catalog. Empty means English.
+	Language string `mapstructure:"language"`
+
+	// DateFormat is how reports print dates, e.g. "DD.MM.YYYY" (default
+	// "YYYY-MM-DD"). DateLayout is the equivalent Go time layout.
+	DateFormat string `mapstructure:"date_format"`
+	DateLayout string `mapstructure:"-"`
+
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	RunTimeout     time.Duration `mapstructure:"run_timeout"`
+
+	// EpicBatchSize is the number of epics fetched per request (default 50)
+	// and MaxJQLLength caps those queries for servers with small limits
+	EpicBatchSize int `mapstructure:"epic_batch_size"`
+	MaxJQLLength  int `mapstructure:"max_jql_length"`
+
+	NotifyWebhookURL  string `mapstructure:"notify_webhook_url"`
+	NotifyWebhookType string `mapstructure:"notify_webhook_type"`
+
+	UploadDestinations []string `mapstructure:"upload_destinations"`
+	S3Region           string   `mapstructure:"s3_region"`
+	S3Endpoint         string   `mapstructure:"s3_endpoint"`
+	GCSAccessToken     string   `mapstructure:"gcs_access_token"`
+	WebDAVUsername     string   `mapstructure:"webdav_username"`
+	WebDAVPassword     string   `mapstructure:"webdav_password"`
+
+	SMTP SMTPConfig `mapstructure:"smtp"`
+
+	Confluence ConfluenceConfig `mapstructure:"confluence"`
+
+	Monday MondayConfig `mapstructure:"monday"`
+
+	Notion NotionConfig `mapstructure:"notion"`
+
+	WSJF WSJFConfig `mapstructure:"wsjf"`
+
+	// FollowUpRules add standard downstream work, e.g. QA and deployment,
+	// after the tickets they match
+	FollowUpRules []FollowUpRuleConfig `mapstructure:"follow_up_rules"`
+
+	// Milestones are external commitments added to every plan as fixed-date milestones
+	Milestones []MilestoneConfig `mapstructure:"milestones"`
+
+	// ChangelogAuthor is the author of the plan's changelog entries (default: the current user)
+	ChangelogAuthor string `mapstructure:"changelog_author"`
+
+	// SnapshotDir is where 'snapshot' stores plan history (default $HOME/.jql-to-plan/snapshots)
+	SnapshotDir string `mapstructure:"snapshot_dir"`
+
+	// CacheDir holds the last fetch per query for --incremental (default $HOME/.jql-to-plan/cache)
+	CacheDir string `mapstructure:"cache_dir"`
+
+	// Jobs are plans that 'schedule' regenerates on cron schedules
+	Jobs []JobConfig `mapstructure:"jobs"`
+}
+
+// SMTPConfig holds the mail server settings used by --email-to
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// MilestoneConfig is an external commitment, e.g. a marketing launch
+type MilestoneConfig struct {
+	Name      string    `mapstructure:"name"`
+	Date      string    `mapstructure:"date"`       // YYYY-MM-DD
+	DependsOn []string  `mapstructure:"depends_on"` // Ticket or epic keys that must be done first
+	Day       time.Time `mapstructure:"-"`          // Date at midnight in Location
+}
+
+// JobConfig is a plan generated by 'schedule' whenever Cron fires
+type JobConfig struct {
+	Name    string            `mapstructure:"name"`    // Default: the project
+	Cron    string            `mapstructure:"cron"`    // Five-field cron expression in Location, or a macro such as @daily
+	Project string            `mapstructure:"project"` // Project name, as the first argument
+	Query   string            `mapstructure:"query"`   // JQL or a saved query name, as the second argument
+	Output  string            `mapstructure:"output"`  // Directory the plan is written to (default: the current directory)
+	Upload  []string          `mapstructure:"upload"`  // Destinations the plan is uploaded to, besides upload_destinations
+	Flags   map[string]string `mapstructure:"flags"`   // Command-line flags without dashes, e.g. epic-group: "true"
+
+	Schedule *cron.Schedule `mapstructure:"-"`
+}
+
+// WSJFConfig holds the Jira fields used by --wsjf. Fields are given by ID or name.
+type WSJFConfig struct {
+	CostOfDelayFields []string `mapstructure:"cost_of_delay_fields"` // Summed, e.g. business value, time criticality, risk reduction
+	JobSizeField      string   `mapstructure:"job_size_field"`       // Default: the ticket's effort in days
+}
+
+// StylesConfig is the font and colors of generated plans. Colors are hex
+// sRGB values such as "#1f6feb"; empty settings keep OmniPlan's defaults.
+type StylesConfig struct {
+	Font           string  `mapstructure:"font"`
+	FontSize       float64 `mapstructure:"font_size"`       // Points
+	GroupColor     string  `mapstructure:"group_color"`     // Gantt bars of groups, e.g. epics
+	MilestoneColor string  `mapstructure:"milestone_color"` // Milestone diamonds
+	CompletedColor string  `mapstructure:"completed_color"` // Text of completed tasks, also struck through
+}
+
+// FollowUpRuleConfig adds tasks after each ticket of IssueTypes (all when
+// empty) whose Field (ID or name, optional) has one of Values
+type FollowUpRuleConfig struct {
+	IssueTypes []string             `mapstructure:"issue_types"`
+	Field      string               `mapstructure:"field"`
+	Values     []string             `mapstructure:"values"`
+	Tasks      []FollowUpTaskConfig `mapstructure:"tasks"` // Done one after the other
+}
+
+// FollowUpTaskConfig is a task added by a follow-up rule
+type FollowUpTaskConfig struct {
+	Title      string  `mapstructure:"title"`
+	Effort     string  `mapstructure:"effort"` // Days, or a duration like "4h"
+	Assignee   string  `mapstructure:"assignee"`
+	EffortDays float64 `mapstructure:"-"`
+}
+
+// ConfluenceConfig holds the Confluence instance used by 'publish confluence'
+type ConfluenceConfig struct {
+	URL      string `mapstructure:"url"`
+	PAT      string `mapstructure:"pat"`
+	Space    string `mapstructure:"space"`
+	ParentID string `mapstructure:"parent_id"`
+}
+
+// MondayConfig holds the monday.com token and board column mapping used by
+// 'monday'. Columns are given by column ID.
+type MondayConfig struct {
+	Token   string              `mapstructure:"token"`
+	Columns MondayColumnsConfig `mapstructure:"columns"`
+}
+
+// MondayColumnsConfig maps board columns onto ticket fields, see monday.Mapping
+type MondayColumnsConfig struct {
+	Effort       string `mapstructure:"effort"`
+	Owner        string `mapstructure:"owner"`
+	Dependencies string `mapstructure:"dependencies"`
+	Status       string `mapstructure:"status"`
+	Dates        string `mapstructure:"dates"`
+}
+
+// NotionConfig holds the Notion integration token and database property
+// mapping used by 'notion'. Properties are given by name.
+type NotionConfig struct {
+	Token      string                 `mapstructure:"token"`
+	Properties NotionPropertiesConfig `mapstructure:"properties"`
+}
+
+// NotionPropertiesConfig maps database properties onto ticket fields, see notion.Mapping
+type NotionPropertiesConfig struct {
+	Status       string `mapstructure:"status"`
+	Estimate     string `mapstructure:"estimate"`
+	Owner        string `mapstructure:"owner"`
+	Dependencies string `mapstructure:"dependencies"`
+	Dates        string `mapstructure:"dates"`
 }
 
 func Load() (*Config, error) {
@@ -31,17 +302,21 @@ func Load() (*Config, error) {
 	// Also look for specific JIRA_ env vars as requested
 	v.BindEnv("jira_url", "JIRA_URL")
 	v.BindEnv("jira_pat", "JIRA_PAT")
+	v.BindEnv("jira_username", "JIRA_USERNAME")
+	v.BindEnv("jira_password", "JIRA_PASSWORD")
+	v.BindEnv("jira_session_cookie", "JIRA_SESSION_COOKIE")
+	v.BindEnv("monday.token", "MONDAY_API_TOKEN")
+	v.BindEnv("notion.token", "NOTION_TOKEN")
 
-	// Config file
-	v.SetConfigName(".jql-to-plan")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(".")
-	v.AddConfigPath("$HOME")
+	// Defaults
+	v.SetDefault("request_timeout", "60s")
+	v.SetDefault("review_effort", "0.25d")
 
+	searchConfigFile(v)
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// Check if essential env vars are present, if so, we can proceed without a config file
-			if os.Getenv("JIRA_URL") != "" && os.Getenv("JIRA_PAT") != "" {
+			if os.Getenv("JIRA_URL") != "" && (os.Getenv("JIRA_PAT") != "" || os.Getenv("JIRA_USERNAME") != "" || os.Getenv("JIRA_SESSION_COOKIE") != "") {
 				// Proceed with env vars
 			} else {
 				return nil, ErrConfigNotFound
@@ -49,15 +324,155 @@ func Load() (*Config, error) {
 		} else {
 			return nil, err
 		}
+	} else if err := ValidateFile(v.ConfigFileUsed()); err != nil {
+		return nil, fmt.Errorf("invalid configuration %s:\n%w", v.ConfigFileUsed(), err)
 	}
 
 	var c Config
 	if err := v.Unmarshal(&c); err != nil {
 		return nil, err
 	}
+	c.File = v.ConfigFileUsed()
+
+	// Transparently decrypt a PAT stored with 'config encrypt-pat', or fetch
+	// one kept in Vault or AWS Secrets Manager
+	pat, err := secrets.Resolve(context.Background(), c.JiraPAT)
+	if err != nil {
+		return nil, fmt.Errorf("resolving jira_pat: %w", err)
+	}
+	c.JiraPAT = pat
+
+	if c.Confluence.PAT, err = secrets.Resolve(context.Background(), c.Confluence.PAT); err != nil {
+		return nil, fmt.Errorf("resolving confluence.pat: %w", err)
+	}
+	if c.Monday.Token, err = secrets.Resolve(context.Background(), c.Monday.Token); err != nil {
+		return nil, fmt.Errorf("resolving monday.token: %w", err)
+	}
+	if c.Notion.Token, err = secrets.Resolve(context.Background(), c.Notion.Token); err != nil {
+		return nil, fmt.Errorf("resolving notion.token: %w", err)
+	}
+	if c.JiraPassword, err = secrets.Resolve(context.Background(), c.JiraPassword); err != nil {
+		return nil, fmt.Errorf("resolving jira_password: %w", err)
+	}
+	if c.JiraSessionCookie, err = secrets.Resolve(context.Background(), c.JiraSessionCookie); err != nil {
+		return nil, fmt.Errorf("resolving jira_session_cookie: %w", err)
+	}
+
+	switch c.EffortFieldUnit {
+	case "", "days", "hours", "points":
+	default:
+		return nil, fmt.Errorf("invalid effort_field_unit %q (expected days, hours or points)", c.EffortFieldUnit)
+	}
+
+	for issueType, effort := range c.DefaultEffort {
+		days, ok := jira.ParseEffortDays(effort, c.HoursPerDay, c.DaysPerWeek)
+		if !ok {
+			return nil, fmt.Errorf("default_effort for %s: invalid effort %q (expected days or a duration like 4h)", issueType, effort)
+		}
+		if c.IssueTypeEfforts == nil {
+			c.IssueTypeEfforts = make(map[string]float64, len(c.DefaultEffort))
+		}
+		c.IssueTypeEfforts[issueType] = days
+	}
+
+	for level, m := range c.RiskMultipliers {
+		if m <= 0 {
+			return nil, fmt.Errorf("risk_multipliers for %s: multiplier must be positive, got %v", level, m)
+		}
+	}
+
+	var ok bool
+	if c.ReviewEffortDays, ok = jira.ParseEffortDays(c.ReviewEffort, c.HoursPerDay, c.DaysPerWeek); !ok {
+		return nil, fmt.Errorf("invalid review_effort %q (expected days or a duration like 2h)", c.ReviewEffort)
+	}
+
+	for i, rule := range c.FollowUpRules {
+		if len(rule.Tasks) == 0 {
+			return nil, fmt.Errorf("follow_up_rules[%d] has no tasks", i)
+		}
+		if rule.Field != "" && len(rule.Values) == 0 {
+			return nil, fmt.Errorf("follow_up_rules[%d]: field %q needs values to match", i, rule.Field)
+		}
+		for j, task := range rule.Tasks {
+			if task.Title == "" {
+				return nil, fmt.Errorf("follow_up_rules[%d].tasks[%d] has no title", i, j)
+			}
+			if task.Effort == "" {
+				continue // Planned like a ticket without an estimate
+			}
+			if c.FollowUpRules[i].Tasks[j].EffortDays, ok = jira.ParseEffortDays(task.Effort, c.HoursPerDay, c.DaysPerWeek); !ok {
+				return nil, fmt.Errorf("follow-up task %q: invalid effort %q (expected days or a duration like 4h)", task.Title, task.Effort)
+			}
+		}
+	}
+
+	if c.MaxTitleLength < 0 {
+		return nil, fmt.Errorf("invalid max_title_length %d (expected characters, 0 for no limit)", c.MaxTitleLength)
+	}
+
+	if c.Styles.FontSize < 0 {
+		return nil, fmt.Errorf("invalid styles.font_size %v (expected points)", c.Styles.FontSize)
+	}
+	for key, color := range map[string]string{
+		"group_color":     c.Styles.GroupColor,
+		"milestone_color": c.Styles.MilestoneColor,
+		"completed_color": c.Styles.CompletedColor,
+	} {
+		if color != "" && !hexColor.MatchString(color) {
+			return nil, fmt.Errorf("invalid styles.%s %q (expected a hex color like #1f6feb)", key, color)
+		}
+	}
+
+	if c.PrimaryAssigneeUnits < 0 || c.PrimaryAssigneeUnits >= 1 {
+		return nil, fmt.Errorf("invalid primary_assignee_units %v (expected a share of at least 0 and below 1, leaving the rest to co-assignees)", c.PrimaryAssigneeUnits)
+	}
+
+	c.Location = time.Local
+	if c.Timezone != "" {
+		if c.Location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+		}
+	}
+
+	for i, m := range c.Milestones {
+		if m.Name == "" {
+			return nil, fmt.Errorf("milestones[%d] has no name", i)
+		}
+		if c.Milestones[i].Day, err = time.ParseInLocation("2006-01-02", m.Date, c.Location); err != nil {
+			return nil, fmt.Errorf("milestone %q: invalid date %q (expected YYYY-MM-DD)", m.Name, m.Date)
+		}
+	}
+
+	for i, job := range c.Jobs {
+		if job.Project == "" || job.Query == "" {
+			return nil, fmt.Errorf("jobs[%d] needs a project and a query", i)
+		}
+		if job.Name == "" {
+			c.Jobs[i].Name = job.Project
+		}
+		if c.Jobs[i].Schedule, err = cron.Parse(job.Cron); err != nil {
+			return nil, fmt.Errorf("job %q: %w", c.Jobs[i].Name, err)
+		}
+	}
+
+	c.DateLayout = DefaultDateLayout
+	if c.DateFormat != "" {
+		if c.DateLayout, err = dateLayout(c.DateFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if c.SnapshotDir == "" {
+			c.SnapshotDir = filepath.Join(home, ".jql-to-plan", "snapshots")
+		}
+		if c.CacheDir == "" {
+			c.CacheDir = filepath.Join(home, ".jql-to-plan", "cache")
+		}
+	}
 
 	// Basic validation (though caller might do more specific checks)
-	if c.JiraURL == "" || c.JiraPAT == "" {
+	if c.JiraURL == "" || !c.HasCredentials() {
 		// Just a warning or error? For now let's just log, caller might validate
 		log.Println("Warning: JIRA_URL or JIRA_PAT not found in config or environment")
 	}
@@ -65,6 +480,43 @@ func Load() (*Config, error) {
 	return &c, nil
 }
 
+// SessionAuth reports whether Jira is accessed with a session, from a login,
+// Kerberos or a captured cookie, instead of the PAT
+func (c *Config) SessionAuth() bool {
+	return c.JiraUsername != "" || c.JiraSessionCookie != "" || len(c.KerberosTokenCommand) > 0
+}
+
+// HasCredentials reports whether a PAT or session authentication is set
+func (c *Config) HasCredentials() bool {
+	return c.JiraPAT != "" || c.SessionAuth()
+}
+
+// searchConfigFile sets v to read .jql-to-plan.yaml from the current or home
+// directory, unless ConfigFileEnv names the file
+func searchConfigFile(v *viper.Viper) {
+	v.SetConfigName(".jql-to-plan")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("$HOME")
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		v.SetConfigFile(path)
+	}
+}
+
+// FindFile returns the configuration file Load reads, or ErrConfigNotFound
+// if there is none
+func FindFile() (string, error) {
+	v := viper.New()
+	searchConfigFile(v)
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return "", ErrConfigNotFound
+		}
+		return "", err
+	}
+	return v.ConfigFileUsed(), nil
+}
+
 // GetConfigPath returns the path to the config file, or where it should be.
 func GetConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// load loads the configuration file with the given content
+func load(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	t.Setenv(ConfigFileEnv, writeConfig(t, content))
+	return Load()
+}
+
+func TestLoad_DefaultEffort(t *testing.T) {
+	c, err := load(t, `jira_url: https://jira.example.com
+jira_pat: secret
+hours_per_day: 8
+issue_type_efforts:
+  Bug: 1
+  Epic: 10
+default_effort:
+  bug: "4h"
+  Story: "2d"
+  Spike: "1w"
+`)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// Both are matched case-insensitively, and default_effort wins for Bug
+	want := map[string]float64{"bug": 0.5, "epic": 10, "story": 2, "spike": 5}
+	if !reflect.DeepEqual(c.IssueTypeEfforts, want) {
+		t.Errorf("IssueTypeEfforts = %v, want %v", c.IssueTypeEfforts, want)
+	}
+}
+
+func TestLoad_DefaultEffortInvalid(t *testing.T) {
+	_, err := load(t, `jira_url: https://jira.example.com
+jira_pat: secret
+default_effort:
+  Bug: "soon"
+`)
+	if err == nil || !strings.Contains(err.Error(), `default_effort for bug: invalid effort "soon"`) {
+		t.Errorf("Expected an invalid effort error, got %v", err)
+	}
+}
+
+func TestLoad_PrimaryAssigneeUnits(t *testing.T) {
+	tests := []struct {
+		units string
+		ok    bool
+	}{
+		{"0", true}, // Split equally
+		{"0.6", true},
+		{"0.99", true},
+		{"1", false}, // Nothing would be left for co-assignees
+		{"1.5", false},
+		{"-0.1", false},
+	}
+	for _, tt := range tests {
+		_, err := load(t, "jira_url: https://jira.example.com\njira_pat: secret\nprimary_assignee_units: "+tt.units+"\n")
+		if tt.ok && err != nil {
+			t.Errorf("primary_assignee_units %s: Load failed: %v", tt.units, err)
+		}
+		if !tt.ok && (err == nil || !strings.Contains(err.Error(), "invalid primary_assignee_units "+tt.units+" (expected a share of at least 0 and below 1")) {
+			t.Errorf("primary_assignee_units %s: expected it to be rejected, got %v", tt.units, err)
+		}
+	}
+}
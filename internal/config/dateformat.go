@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultDateLayout is the Go layout used for report dates when date_format is unset
+const DefaultDateLayout = "2006-01-02"
+
+// dateTokens maps date_format tokens to Go layout elements, longest first so
+// that e.g. MMM is not read as MM followed by M
+var dateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"dddd", "Monday"},
+	{"ddd", "Mon"},
+	{"DD", "02"},
+	{"D", "2"},
+}
+
+// dateLayout converts a date_format such as "DD.MM.YYYY" or "D MMM YYYY" into
+// a Go time layout. Only the tokens above, spaces and punctuation are allowed,
+// and the format must include a year, month and day.
+func dateLayout(format string) (string, error) {
+	var layout strings.Builder
+	var hasYear, hasMonth, hasDay bool
+
+	for rest := format; rest != ""; {
+		matched := false
+		for _, t := range dateTokens {
+			if strings.HasPrefix(rest, t.token) {
+				layout.WriteString(t.layout)
+				rest = rest[len(t.token):]
+				switch t.token[0] {
+				case 'Y':
+					hasYear = true
+				case 'M':
+					hasMonth = true
+				case 'D':
+					hasDay = true
+				}
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		r := []rune(rest)[0]
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return "", fmt.Errorf("unexpected %q in date_format %q (use YYYY, YY, MMMM, MMM, MM, M, DD, D, dddd, ddd)", r, format)
+		}
+		layout.WriteRune(r)
+		rest = rest[len(string(r)):]
+	}
+
+	if !hasYear || !hasMonth || !hasDay {
+		return "", fmt.Errorf("date_format %q must include a year, month and day", format)
+	}
+	return layout.String(), nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// migration upgrades one outdated form of the configuration in place,
+// returning what it changed. Migrations check the shape of the file rather
+// than a version, so running them again changes nothing.
+type migration func(root *yaml.Node) []string
+
+// migrations are applied in order by Migrate
+var migrations = []migration{
+	migrateIssueTypeEfforts,
+}
+
+// Migrate upgrades a YAML configuration file to the current schema. Comments
+// are kept, though the file is reformatted if anything changed. It returns the
+// upgraded file and a description of each change, or data unchanged and no
+// changes if it is already current.
+func Migrate(data []byte) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+
+	var changes []string
+	for _, migrate := range migrations {
+		changes = append(changes, migrate(doc.Content[0])...)
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), changes, nil
+}
+
+// migrateIssueTypeEfforts moves issue_type_efforts, in days only, into
+// default_effort, which also takes durations. Entries already in
+// default_effort took precedence, so their issue_type_efforts are dropped.
+func migrateIssueTypeEfforts(root *yaml.Node) []string {
+	old := mappingIndex(root, "issue_type_efforts")
+	if old < 0 {
+		return nil
+	}
+	efforts := root.Content[old+1]
+	if efforts.Kind != yaml.MappingNode {
+		return nil // Invalid, left for validation to report
+	}
+
+	target := mappingIndex(root, "default_effort")
+	if target < 0 {
+		// Take the place, and the comments, of issue_type_efforts
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "default_effort",
+			HeadComment: root.Content[old].HeadComment, LineComment: root.Content[old].LineComment}
+		root.Content = slices.Insert(root.Content, old, key, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		target, old = old, old+2
+	} else if comment := root.Content[old].HeadComment; comment != "" {
+		key := root.Content[target]
+		key.HeadComment = strings.TrimSpace(comment + "\n" + key.HeadComment)
+	}
+	defaults := root.Content[target+1]
+	if defaults.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var changes []string
+	for i := 0; i+1 < len(efforts.Content); i += 2 {
+		issueType, days := efforts.Content[i], efforts.Content[i+1]
+		if existing := mappingIndex(defaults, issueType.Value); existing >= 0 {
+			changes = append(changes, fmt.Sprintf("dropped issue_type_efforts.%s (%s); default_effort.%s (%s) takes precedence",
+				issueType.Value, days.Value, defaults.Content[existing].Value, defaults.Content[existing+1].Value))
+			continue
+		}
+		value := days.Value
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			value += "d"
+		}
+		defaults.Content = append(defaults.Content, issueType,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: value, LineComment: days.LineComment})
+		changes = append(changes, fmt.Sprintf("moved issue_type_efforts.%s (%s) to default_effort.%s (%q)", issueType.Value, days.Value, issueType.Value, value))
+	}
+
+	root.Content = slices.Delete(root.Content, old, old+2)
+	if len(changes) == 0 {
+		changes = append(changes, "removed empty issue_type_efforts")
+	}
+	return changes
+}
+
+// mappingIndex returns the index of key in a mapping node's content, matched
+// case-insensitively like viper does, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
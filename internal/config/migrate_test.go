@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		changes []string
+	}{
+		{
+			name: "issue_type_efforts moves into default_effort",
+			in: `# Jira
+jira_url: https://jira.example.com
+# Efforts per type
+issue_type_efforts:
+  Bug: 0.5 # Quick fixes
+  Story: 2
+team_field: Team
+`,
+			want: `# Jira
+jira_url: https://jira.example.com
+# Efforts per type
+default_effort:
+  Bug: "0.5d" # Quick fixes
+  Story: "2d"
+team_field: Team
+`,
+			changes: []string{
+				`moved issue_type_efforts.Bug (0.5) to default_effort.Bug ("0.5d")`,
+				`moved issue_type_efforts.Story (2) to default_effort.Story ("2d")`,
+			},
+		},
+		{
+			name: "default_effort takes precedence",
+			in: `jira_url: https://jira.example.com
+default_effort:
+  Bug: "4h"
+issue_type_efforts:
+  Bug: 1
+  Epic: 10
+`,
+			want: `jira_url: https://jira.example.com
+default_effort:
+  Bug: "4h"
+  Epic: "10d"
+`,
+			changes: []string{
+				"dropped issue_type_efforts.Bug (1); default_effort.Bug (4h) takes precedence",
+				`moved issue_type_efforts.Epic (10) to default_effort.Epic ("10d")`,
+			},
+		},
+		{
+			name: "empty issue_type_efforts is removed",
+			in: `jira_url: https://jira.example.com
+issue_type_efforts: {}
+`,
+			want: `jira_url: https://jira.example.com
+default_effort: {}
+`,
+			changes: []string{"removed empty issue_type_efforts"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes, err := Migrate([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Migrate failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Migrate =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("changes = %q, want %q", changes, tt.changes)
+			}
+
+			// Running it again changes nothing
+			again, changes, err := Migrate(got)
+			if err != nil || string(again) != string(got) || changes != nil {
+				t.Errorf("second Migrate = %q, %q, %v, want it unchanged", again, changes, err)
+			}
+		})
+	}
+}
+
+func TestMigrate_UpToDate(t *testing.T) {
+	// Formatting Migrate would change is kept as it is when nothing migrates
+	in := []byte(`jira_url:   "https://jira.example.com"
+
+default_effort: {Bug: 0.5d}   # Inline
+`)
+	got, changes, err := Migrate(in)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if string(got) != string(in) || changes != nil {
+		t.Errorf("Migrate = %q, %q, want the input byte for byte and no changes", got, changes)
+	}
+}
+
+func TestMigrate_Invalid(t *testing.T) {
+	if _, _, err := Migrate([]byte("jira_url: [unclosed\n")); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
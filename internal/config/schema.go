@@ -0,0 +1,301 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// customFieldID is the form of the *_custom_field_id settings, with or
+// without the customfield_ prefix
+var customFieldID = regexp.MustCompile(`^(customfield_)?\d+$`)
+
+// jqlFieldID matches the cf[12345] form used in JQL, a common mistake
+var jqlFieldID = regexp.MustCompile(`^(?i)cf\[(\d+)\]$`)
+
+// ValidateFile checks the YAML configuration file at path against the Config
+// struct, so that typos and wrong types are reported with their line instead
+// of being ignored. All problems are returned joined in one error.
+func ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+
+	var problems []problem
+	validateNode(doc.Content[0], reflect.TypeOf(Config{}), "", &problems)
+	problems = append(problems, validateSettings(doc.Content[0])...)
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = fmt.Errorf("line %d: %s", p.line, p.msg)
+	}
+	return errors.Join(errs...)
+}
+
+// problem is something wrong at a line of the configuration file
+type problem struct {
+	line int
+	msg  string
+}
+
+// validateNode checks node against the type t the setting at path decodes
+// into, appending what is wrong to problems
+func validateNode(node *yaml.Node, t reflect.Type, path string, problems *[]problem) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return // Unset, as if left out
+	}
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, problem{node.Line, fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		if !isScalar(node) {
+			fail("%s must be a duration like 90s or 5m, got %s", path, describe(node))
+		} else if _, err := time.ParseDuration(node.Value); err != nil {
+			if _, err := strconv.ParseFloat(node.Value, 64); err == nil {
+				// viper would read a bare number as nanoseconds
+				fail("%s %s has no unit, expected a duration such as %ss", path, describe(node), node.Value)
+			} else {
+				fail("%s must be a duration like 90s or 5m, got %s", path, describe(node))
+			}
+		}
+		return
+	case t.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			fail("%s must be a mapping of settings, got %s", label(path), describe(node))
+			return
+		}
+		keys := structKeys(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := keys[strings.ToLower(key.Value)]
+			if !ok {
+				*problems = append(*problems, unknownKey(key, path, keys))
+				continue
+			}
+			validateNode(value, field.Type, join(path, key.Value), problems)
+		}
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			fail("%s must be a mapping, got %s", path, describe(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			validateNode(node.Content[i+1], t.Elem(), path+"."+node.Content[i].Value, problems)
+		}
+	case t.Kind() == reflect.Slice:
+		// A comma-separated string is decoded into a list of strings
+		if t.Elem().Kind() == reflect.String && isScalar(node) {
+			return
+		}
+		if node.Kind != yaml.SequenceNode {
+			fail("%s must be a list, got %s", path, describe(node))
+			return
+		}
+		for i, item := range node.Content {
+			validateNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case t.Kind() == reflect.String:
+		if !isScalar(node) {
+			fail("%s must be a single value, got %s", path, describe(node))
+		}
+	case t.Kind() == reflect.Bool:
+		if _, err := strconv.ParseBool(node.Value); !isScalar(node) || err != nil {
+			fail("%s must be true or false, got %s", path, describe(node))
+		}
+	case t.Kind() == reflect.Int:
+		if _, err := strconv.Atoi(node.Value); !isScalar(node) || err != nil {
+			fail("%s must be a whole number, got %s", path, describe(node))
+		}
+	case t.Kind() == reflect.Float64:
+		if _, err := strconv.ParseFloat(node.Value, 64); !isScalar(node) || err != nil {
+			fail("%s must be a number, got %s", path, describe(node))
+		}
+	}
+}
+
+// validateSettings checks what the types cannot: field ID formats and
+// settings that contradict each other
+func validateSettings(root *yaml.Node) []problem {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	values := make(map[string]*yaml.Node, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		values[strings.ToLower(root.Content[i].Value)] = root.Content[i+1]
+	}
+
+	// Settings that take a field by name instead of by ID
+	byName := map[string]string{
+		"effort_custom_field_id":    "effort_field_name",
+		"epic_link_custom_field_id": "epic_link_field_name",
+	}
+
+	var problems []problem
+	for _, key := range []string{"effort_custom_field_id", "epic_link_custom_field_id", "flagged_custom_field_id"} {
+		node, ok := values[key]
+		if !ok || !isScalar(node) || node.Value == "" || customFieldID.MatchString(node.Value) {
+			continue
+		}
+		hint := "expected digits, optionally prefixed by customfield_, e.g. 10016"
+		if m := jqlFieldID.FindStringSubmatch(node.Value); m != nil {
+			hint = fmt.Sprintf("use %s; cf[%s] is the JQL form", m[1], m[1])
+		} else if name := byName[key]; name != "" {
+			hint += fmt.Sprintf("; set %s to select the field by name", name)
+		}
+		problems = append(problems, problem{node.Line, fmt.Sprintf("%s %q is not a custom field ID (%s)", key, node.Value, hint)})
+	}
+
+	if user, ok := values["jira_username"]; ok && user.Value != "" {
+		if _, ok := values["jira_password"]; !ok {
+			problems = append(problems, problem{user.Line, "jira_username needs jira_password to log in"})
+		}
+		if cookie, ok := values["jira_session_cookie"]; ok && cookie.Value != "" {
+			problems = append(problems, problem{cookie.Line, "jira_session_cookie cannot be combined with jira_username, which logs in to a new session"})
+		}
+	}
+
+	if command, ok := values["kerberos_token_command"]; ok && command.Tag != "!!null" {
+		for _, other := range []string{"jira_username", "jira_session_cookie"} {
+			if node, ok := values[other]; ok && node.Value != "" {
+				problems = append(problems, problem{node.Line, other + " cannot be combined with kerberos_token_command"})
+			}
+		}
+		if isScalar(command) {
+			problems = append(problems, problem{command.Line, "kerberos_token_command must be a list of the command and its arguments, e.g. [\"jira-spnego\", \"{host}\"]"})
+		}
+	}
+
+	if skip, ok := values["tls_insecure_skip_verify"]; ok && skip.Value == "true" {
+		if ca, ok := values["tls_ca_file"]; ok && ca.Value != "" {
+			problems = append(problems, problem{ca.Line, "tls_ca_file cannot be combined with tls_insecure_skip_verify, which ignores it"})
+		}
+	}
+	return problems
+}
+
+// structKeys returns the fields of a config struct by lowercase key, leaving
+// out the ones computed by Load
+func structKeys(t reflect.Type) map[string]reflect.StructField {
+	keys := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		keys[tag] = field
+	}
+	return keys
+}
+
+// unknownKey reports an unknown key, suggesting the closest known one
+func unknownKey(key *yaml.Node, path string, keys map[string]reflect.StructField) problem {
+	msg := fmt.Sprintf("unknown key '%s'", key.Value)
+	if path != "" {
+		msg += " in " + path
+	}
+	if suggestion := closestKey(strings.ToLower(key.Value), keys); suggestion != "" {
+		msg += fmt.Sprintf("; did you mean '%s'?", suggestion)
+	}
+	return problem{key.Line, msg}
+}
+
+// closestKey returns the known key most likely meant by key, or "" if none
+// is close. Keys sharing more words win, so "epic_custom_field" suggests
+// "epic_link_custom_field_id"; edit distance breaks ties and catches typos.
+func closestKey(key string, keys map[string]reflect.StructField) string {
+	words := strings.Split(key, "_")
+	best, bestShared, bestDistance := "", 0, 0
+	for candidate := range keys {
+		shared := 0
+		for _, word := range words {
+			if slices.Contains(strings.Split(candidate, "_"), word) {
+				shared++
+			}
+		}
+		d := editDistance(key, candidate)
+		if best == "" || shared > bestShared ||
+			shared == bestShared && (d < bestDistance || d == bestDistance && candidate < best) {
+			best, bestShared, bestDistance = candidate, shared, d
+		}
+	}
+	if best == "" || 2*bestShared < len(words) && bestDistance > max(len(key), len(best))/2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func isScalar(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode
+}
+
+// describe names what a node holds for error messages
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// label names the setting at path, or the file itself at the top
+func label(path string) string {
+	if path == "" {
+		return "the configuration"
+	}
+	return path
+}
+
+// join appends key to the setting path
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
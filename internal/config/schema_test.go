@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a configuration file with the given content and
+// returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	return path
+}
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // One problem per line, in order
+	}{
+		{
+			name: "valid",
+			content: `jira_url: https://jira.example.com
+request_timeout: 90s
+run_timeout: 0
+smtp:
+  host: smtp.example.com
+  port: 587
+`,
+		},
+		{
+			name:    "empty file",
+			content: "",
+		},
+		{
+			name:    "unknown key with a suggestion",
+			content: "jira_ulr: https://jira.example.com\n",
+			want:    []string{"line 1: unknown key 'jira_ulr'; did you mean 'jira_url'?"},
+		},
+		{
+			name:    "unknown key without a suggestion",
+			content: "colour_scheme: dark\n",
+			want:    []string{"line 1: unknown key 'colour_scheme'"},
+		},
+		{
+			name:    "unknown nested key",
+			content: "smtp:\n  hots: smtp.example.com\n",
+			want:    []string{"line 2: unknown key 'hots' in smtp; did you mean 'host'?"},
+		},
+		{
+			name:    "wrong type",
+			content: "epic_batch_size: fifty\ntls_insecure_skip_verify: maybe\n",
+			want: []string{
+				`line 1: epic_batch_size must be a whole number, got "fifty"`,
+				`line 2: tls_insecure_skip_verify must be true or false, got "maybe"`,
+			},
+		},
+		{
+			name:    "wrong nesting",
+			content: "smtp: smtp.example.com\nhost: smtp.example.com\n",
+			want: []string{
+				`line 1: smtp must be a mapping of settings, got "smtp.example.com"`,
+				"line 2: unknown key 'host'",
+			},
+		},
+		{
+			name:    "unitless duration",
+			content: "request_timeout: 60\n",
+			want:    []string{`line 1: request_timeout "60" has no unit, expected a duration such as 60s`},
+		},
+		{
+			name:    "invalid duration",
+			content: "run_timeout: soon\n",
+			want:    []string{`line 1: run_timeout must be a duration like 90s or 5m, got "soon"`},
+		},
+		{
+			name:    "custom field in JQL form",
+			content: "effort_custom_field_id: cf[10016]\n",
+			want:    []string{`line 1: effort_custom_field_id "cf[10016]" is not a custom field ID (use 10016; cf[10016] is the JQL form)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFile(writeConfig(t, tt.content))
+			var got []string
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateFile =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	keys := structKeys(reflect.TypeOf(Config{}))
+	tests := []struct {
+		key, want string
+	}{
+		{"jira_ulr", "jira_url"},                           // Transposed letters
+		{"request_timout", "request_timeout"},              // Missing letter
+		{"epic_custom_field", "epic_link_custom_field_id"}, // Shared words
+		{"effort_field", "effort_field_name"},              // Ties on words, broken by distance
+		{"xyz", ""},                                        // Nothing close
+		{"something_completely_different", ""},
+	}
+	for _, tt := range tests {
+		if got := closestKey(tt.key, keys); got != tt.want {
+			t.Errorf("closestKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"jira_ulr", "jira_url", 2},
+		{"flaw", "lawn", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package confluence creates and updates Confluence pages through the REST API.
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client talks to the Confluence content REST API with a personal access token
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New creates a Client for the Confluence instance at baseURL
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Page identifies a published page
+type Page struct {
+	ID      string
+	Version int
+	URL     string
+}
+
+// content is the subset of the content resource used here
+type content struct {
+	ID        string     `json:"id,omitempty"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Space     *space     `json:"space,omitempty"`
+	Ancestors []ancestor `json:"ancestors,omitempty"`
+	Version   *version   `json:"version,omitempty"`
+	Body      *body      `json:"body,omitempty"`
+	Links     *links     `json:"_links,omitempty"`
+}
+
+type links struct {
+	Base  string `json:"base"`
+	WebUI string `json:"webui"`
+}
+
+type space struct {
+	Key string `json:"key"`
+}
+
+type ancestor struct {
+	ID string `json:"id"`
+}
+
+type version struct {
+	Number int `json:"number"`
+}
+
+type body struct {
+	Storage storage `json:"storage"`
+}
+
+type storage struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+// Publish creates the page titled title in spaceKey, or updates it if it
+// already exists. html is Confluence storage format (XHTML). parentID is
+// optional and only used when the page is created.
+func (c *Client) Publish(ctx context.Context, spaceKey, title, parentID, html string) (*Page, error) {
+	existing, err := c.find(ctx, spaceKey, title)
+	if err != nil {
+		return nil, err
+	}
+
+	page := content{
+		Type:  "page",
+		Title: title,
+		Space: &space{Key: spaceKey},
+		Body:  &body{Storage: storage{Value: html, Representation: "storage"}},
+	}
+
+	var result content
+	if existing == nil {
+		if parentID != "" {
+			page.Ancestors = []ancestor{{ID: parentID}}
+		}
+		err = c.do(ctx, http.MethodPost, "rest/api/content", page, &result)
+	} else {
+		page.ID = existing.ID
+		page.Version = &version{Number: existing.Version.Number + 1}
+		err = c.do(ctx, http.MethodPut, "rest/api/content/"+url.PathEscape(existing.ID), page, &result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	published := &Page{ID: result.ID}
+	if result.Links != nil {
+		published.URL = result.Links.Base + result.Links.WebUI
+	}
+	if result.Version != nil {
+		published.Version = result.Version.Number
+	}
+	return published, nil
+}
+
+// find looks up a page by space and title, returning nil if it does not exist
+func (c *Client) find(ctx context.Context, spaceKey, title string) (*content, error) {
+	query := url.Values{}
+	query.Set("spaceKey", spaceKey)
+	query.Set("title", title)
+	query.Set("expand", "version")
+
+	var result struct {
+		Results []content `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodGet, "rest/api/content?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	page := result.Results[0]
+	if page.Version == nil {
+		page.Version = &version{}
+	}
+	return &page, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, payload, v interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/"+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned status %s: %s", method, strings.SplitN(path, "?", 2)[0], resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,135 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeConfluence keeps pages by title and records the pages written
+type fakeConfluence struct {
+	t       *testing.T
+	pages   map[string]content
+	written []content
+	methods []string
+}
+
+func (f *fakeConfluence) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer conf-pat" {
+		f.t.Errorf("Authorization = %q, want the Bearer PAT", r.Header.Get("Authorization"))
+	}
+	f.methods = append(f.methods, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/content":
+		q := r.URL.Query()
+		if q.Get("spaceKey") != "PLAN" || q.Get("expand") != "version" {
+			f.t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		results := []content{}
+		if page, ok := f.pages[q.Get("title")]; ok {
+			results = append(results, page)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content",
+		r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/wiki/rest/api/content/"):
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var page content
+	if err := json.NewDecoder(r.Body).Decode(&page); err != nil {
+		f.t.Fatalf("decoding page: %v", err)
+	}
+	f.written = append(f.written, page)
+	if r.Method == http.MethodPost {
+		page.ID = fmt.Sprint(100 + len(f.pages))
+		page.Version = &version{Number: 1}
+	} else {
+		current := f.pages[page.Title]
+		if page.ID != current.ID || strings.TrimPrefix(r.URL.Path, "/wiki/rest/api/content/") != current.ID {
+			f.t.Errorf("updating %s at %s, want page %s", page.ID, r.URL.Path, current.ID)
+		}
+		if page.Version == nil || page.Version.Number != current.Version.Number+1 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": "Version must be incremented on update"}`))
+			return
+		}
+	}
+	f.pages[page.Title] = page
+	page.Links = &links{Base: "https://wiki.example.com/wiki", WebUI: "/spaces/PLAN/pages/" + page.ID}
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestPublish_CreateThenUpdate(t *testing.T) {
+	fake := &fakeConfluence{t: t, pages: map[string]content{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := New(server.URL+"/wiki/", "conf-pat")
+	ctx := context.Background()
+
+	const html = `<h1>Forecast</h1><table><tr><td>PROJ &amp; co</td></tr></table>`
+	page, err := client.Publish(ctx, "PLAN", "Q3 forecast", "42", html)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if page.ID != "100" || page.Version != 1 || page.URL != "https://wiki.example.com/wiki/spaces/PLAN/pages/100" {
+		t.Errorf("created page = %+v", page)
+	}
+	created := fake.written[0]
+	if created.Type != "page" || created.Title != "Q3 forecast" || created.Space == nil || created.Space.Key != "PLAN" || created.ID != "" || created.Version != nil {
+		t.Errorf("unexpected new page %+v", created)
+	}
+	if len(created.Ancestors) != 1 || created.Ancestors[0].ID != "42" {
+		t.Errorf("ancestors = %+v, want the parent 42", created.Ancestors)
+	}
+	if created.Body == nil || created.Body.Storage.Value != html || created.Body.Storage.Representation != "storage" {
+		t.Errorf("body = %+v, want the HTML in storage format", created.Body)
+	}
+
+	// Publishing again updates the page with the next version
+	page, err = client.Publish(ctx, "PLAN", "Q3 forecast", "42", "<p>Updated</p>")
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if page.ID != "100" || page.Version != 2 {
+		t.Errorf("updated page = %+v, want page 100 at version 2", page)
+	}
+	updated := fake.written[1]
+	if updated.ID != "100" || updated.Version == nil || updated.Version.Number != 2 || updated.Body.Storage.Value != "<p>Updated</p>" {
+		t.Errorf("unexpected update %+v", updated)
+	}
+	if len(updated.Ancestors) != 0 {
+		t.Errorf("an update must not move the page, got ancestors %+v", updated.Ancestors)
+	}
+
+	want := []string{
+		"GET /wiki/rest/api/content",
+		"POST /wiki/rest/api/content",
+		"GET /wiki/rest/api/content",
+		"PUT /wiki/rest/api/content/100",
+	}
+	if strings.Join(fake.methods, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(fake.methods, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPublish_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("  no permission to view space PLAN\n"))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, "conf-pat").Publish(context.Background(), "PLAN", "Q3 forecast", "", "<p/>")
+	want := "GET rest/api/content returned status 403 Forbidden: no permission to view space PLAN"
+	if err == nil || err.Error() != want {
+		t.Errorf("Publish = %v, want %q", err, want)
+	}
+}
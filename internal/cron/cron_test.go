@@ -0,0 +1,68 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"0 7 * * mon", "2026-03-09 07:00"},
+		{"0 7 * * 1-5", "2026-03-05 07:00"},
+		{"*/15 * * * *", "2026-03-04 10:45"},
+		{"30 10 * * *", "2026-03-05 10:30"},
+		{"0 9,17 * * *", "2026-03-04 17:00"},
+		{"0 0 1 */3 *", "2026-04-01 00:00"},
+		{"0 0 29 2 *", "2028-02-29 00:00"},
+		{"0 8 1 * sun", "2026-03-08 08:00"}, // The 1st or a Sunday
+		{"0 6 * * 7", "2026-03-08 06:00"},
+		{"@weekly", "2026-03-08 00:00"},
+		{"@monthly", "2026-04-01 00:00"},
+		{"0 0 1 jan *", "2027-01-01 00:00"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("Next(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !never.Next(from).IsZero() {
+		t.Errorf("Expected February 30 never to come")
+	}
+}
+
+func TestSchedule_Next_Location(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip("No time zone data")
+	}
+	s, _ := Parse("0 7 * * *")
+	// Clocks go forward on 2026-03-29
+	got := s.Next(time.Date(2026, 3, 28, 8, 0, 0, 0, oslo))
+	if got.Format("2006-01-02 15:04 MST") != "2026-03-29 07:00 CEST" {
+		t.Errorf("Expected 07:00 local time after the change, got %v", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package csvplan reads and writes plans as CSV, one ticket per row, for
+// exchange with spreadsheets and other planning tools.
+package csvplan
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// Header is the first row of a plan CSV. Reading needs the Key and Summary
+// columns only; the others may be left out or reordered.
+var Header = []string{"Key", "Summary", "Issue Type", "Status", "Status Category", "Assignee", "Co-Assignees", "Effort Days", "Epic", "Parent", "Depends On", "Due Date", "Actual Start", "Link"}
+
+// listSeparator joins the values of the list columns
+const listSeparator = ", "
+
+// dateFormat is the layout of the Due Date column
+const dateFormat = "2006-01-02"
+
+// Write writes tickets as CSV with the Header columns
+func Write(w io.Writer, tickets []jira.Ticket) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		var due, started string
+		if !t.DueDate.IsZero() {
+			due = t.DueDate.Format(dateFormat)
+		}
+		if !t.ActualStart.IsZero() {
+			started = t.ActualStart.Format(time.RFC3339)
+		}
+		row := []string{
+			t.Key,
+			t.Summary,
+			t.IssueType,
+			t.Status,
+			t.StatusCategory,
+			t.Assignee,
+			strings.Join(t.CoAssignees, listSeparator),
+			strconv.FormatFloat(t.EffortDays, 'f', -1, 64),
+			t.EpicLink,
+			t.Parent,
+			strings.Join(t.DependencyKeys, listSeparator),
+			due,
+			started,
+			t.Link,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Read reads tickets from CSV with a header row naming Header columns, as
+// Write produces. Header names are matched case-insensitively and unknown
+// columns are ignored. Due dates are midnight in loc.
+func Read(r io.Reader, loc *time.Location) ([]jira.Ticket, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"key", "summary"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+
+	tickets := make([]jira.Ticket, 0, len(records)-1)
+	for n, record := range records[1:] {
+		row := n + 2 // Line number, counting the header
+		get := func(name string) string {
+			if i, ok := columns[strings.ToLower(name)]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		t := jira.Ticket{
+			Key:            get("Key"),
+			Summary:        get("Summary"),
+			IssueType:      get("Issue Type"),
+			Status:         get("Status"),
+			StatusCategory: get("Status Category"),
+			Assignee:       get("Assignee"),
+			CoAssignees:    splitList(get("Co-Assignees")),
+			EpicLink:       get("Epic"),
+			Parent:         get("Parent"),
+			DependencyKeys: splitList(get("Depends On")),
+			Link:           get("Link"),
+		}
+		if t.Key == "" {
+			return nil, fmt.Errorf("row %d: missing key", row)
+		}
+		if t.StatusCategory == "" {
+			t.StatusCategory = "new"
+		}
+		if s := get("Effort Days"); s != "" {
+			if t.EffortDays, err = strconv.ParseFloat(s, 64); err != nil || t.EffortDays < 0 {
+				return nil, fmt.Errorf("row %d: invalid effort %q", row, s)
+			}
+		}
+		if s := get("Due Date"); s != "" {
+			if t.DueDate, err = time.ParseInLocation(dateFormat, s, loc); err != nil {
+				return nil, fmt.Errorf("row %d: invalid due date %q (expected YYYY-MM-DD)", row, s)
+			}
+		}
+		if s := get("Actual Start"); s != "" {
+			if t.ActualStart, err = time.Parse(time.RFC3339, s); err != nil {
+				return nil, fmt.Errorf("row %d: invalid actual start %q (expected RFC 3339)", row, s)
+			}
+		}
+		tickets = append(tickets, t)
+	}
+	return tickets, nil
+}
+
+// splitList splits a list column, dropping empty values
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
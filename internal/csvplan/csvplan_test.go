@@ -0,0 +1,69 @@
+package csvplan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+func TestWriteRead(t *testing.T) {
+	started := time.Date(2025, 3, 3, 9, 30, 0, 0, time.UTC)
+	tickets := []jira.Ticket{
+		{Key: "PROJ-1", Summary: "Login, with SSO", Status: "In Progress", StatusCategory: "indeterminate", Assignee: "Alice", CoAssignees: []string{"Bob", "Carol"}, EffortDays: 2.5, EpicLink: "PROJ-100", ActualStart: started},
+		{Key: "PROJ-2", Summary: "Logout", DependencyKeys: []string{"PROJ-1", "OPS-7"}, DueDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), Parent: "PROJ-1"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, tickets); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf, time.UTC)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(got))
+	}
+
+	first := got[0]
+	if first.Summary != "Login, with SSO" || first.Assignee != "Alice" || strings.Join(first.CoAssignees, "|") != "Bob|Carol" || first.EffortDays != 2.5 || first.EpicLink != "PROJ-100" {
+		t.Errorf("unexpected first ticket %+v", first)
+	}
+	if !first.ActualStart.Equal(started) || first.StatusCategory != "indeterminate" {
+		t.Errorf("expected the actual start and status to survive, got %+v", first)
+	}
+	second := got[1]
+	if strings.Join(second.DependencyKeys, "|") != "PROJ-1|OPS-7" || second.Parent != "PROJ-1" || !second.DueDate.Equal(tickets[1].DueDate) {
+		t.Errorf("unexpected second ticket %+v", second)
+	}
+	if second.StatusCategory != "new" {
+		t.Errorf("expected tickets without a status category to be new, got %q", second.StatusCategory)
+	}
+}
+
+func TestRead_PartialColumns(t *testing.T) {
+	got, err := Read(strings.NewReader("summary,KEY,Effort Days,Notes\nWrite docs,DOC-1,3,ignored\n"), time.UTC)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Key != "DOC-1" || got[0].Summary != "Write docs" || got[0].EffortDays != 3 {
+		t.Errorf("unexpected tickets %+v", got)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing column": "Key\nPROJ-1\n",
+		"missing key":    "Key,Summary\n,Nameless\n",
+		"invalid effort": "Key,Summary,Effort Days\nPROJ-1,A,lots\n",
+		"invalid date":   "Key,Summary,Due Date\nPROJ-1,A,1 April\n",
+	}
+	for name, input := range tests {
+		if _, err := Read(strings.NewReader(input), time.UTC); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
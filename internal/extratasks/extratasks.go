@@ -0,0 +1,76 @@
+// Package extratasks loads manual tasks that have no Jira ticket, such as
+// procurement, hiring or vendor deliverables, so they can be merged into a plan.
+package extratasks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"go.yaml.in/yaml/v3"
+)
+
+// Task is one entry of the extra tasks file
+type Task struct {
+	ID        string   `yaml:"id"` // Referenced by depends_on (defaults to the title)
+	Title     string   `yaml:"title"`
+	Effort    string   `yaml:"effort"` // Days, or a duration like "2w 3d"
+	Assignee  string   `yaml:"assignee"`
+	Epic      string   `yaml:"epic"`       // Epic key to group the task under with --epic-group
+	DependsOn []string `yaml:"depends_on"` // Jira keys or IDs of other entries
+}
+
+// file is the layout of the extra tasks file
+type file struct {
+	Tasks []Task `yaml:"tasks"`
+}
+
+// Load reads the extra tasks file at path and converts its entries into
+// tickets, so they are planned like Jira issues. Effort durations use
+// hoursPerDay and daysPerWeek (zero means the Jira defaults).
+func Load(path string, hoursPerDay, daysPerWeek float64) ([]jira.Ticket, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	tickets := make([]jira.Ticket, 0, len(f.Tasks))
+	seen := make(map[string]bool, len(f.Tasks))
+	for i, t := range f.Tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			return nil, fmt.Errorf("%s: task %d has no title", path, i+1)
+		}
+		id := t.ID
+		if id == "" {
+			id = t.Title
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%s: duplicate task id %q", path, id)
+		}
+		seen[id] = true
+
+		var effort float64
+		if t.Effort != "" {
+			var ok bool
+			if effort, ok = jira.ParseEffortDays(t.Effort, hoursPerDay, daysPerWeek); !ok {
+				return nil, fmt.Errorf("%s: task %q has invalid effort %q", path, t.Title, t.Effort)
+			}
+		}
+
+		tickets = append(tickets, jira.Ticket{
+			Key:            id,
+			Summary:        t.Title,
+			Assignee:       t.Assignee,
+			EffortDays:     effort,
+			EpicLink:       t.Epic,
+			DependencyKeys: t.DependsOn,
+		})
+	}
+	return tickets, nil
+}
@@ -0,0 +1,63 @@
+package extratasks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+tasks:
+  - id: laptops
+    title: Order laptops
+    effort: 2
+    assignee: Alice
+  - title: Hire contractor
+    effort: 1w 2d
+    depends_on: [laptops, PROJ-7]
+  - title: Vendor SDK delivery
+    epic: EPIC-1
+`)
+
+	tickets, err := Load(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(tickets) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(tickets))
+	}
+
+	if got := tickets[0]; got.Key != "laptops" || got.Summary != "Order laptops" || got.EffortDays != 2 || got.Assignee != "Alice" {
+		t.Errorf("unexpected first task %+v", got)
+	}
+	if got := tickets[1]; got.Key != "Hire contractor" || got.EffortDays != 7 || strings.Join(got.DependencyKeys, ",") != "laptops,PROJ-7" {
+		t.Errorf("unexpected second task %+v", got)
+	}
+	if got := tickets[2]; got.EpicLink != "EPIC-1" || got.EffortDays != 0 {
+		t.Errorf("unexpected third task %+v", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no title":     "tasks:\n  - id: a\n",
+		"duplicate id": "tasks:\n  - id: a\n    title: A\n  - id: a\n    title: B\n",
+		"bad effort":   "tasks:\n  - title: A\n    effort: soon\n",
+	}
+	for name, content := range tests {
+		if _, err := Load(writeFile(t, content), 0, 0); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package i18n translates the text jql-to-plan generates, such as milestone
+// titles, notes and warnings. Messages are looked up by their English text,
+// format verbs included, in the catalog of the language set with
+// SetLanguage; untranslated messages stay in English.
+//
+// A catalog is a YAML map from English messages to translations:
+//
+//	"Done": "Erledigt"
+//	"%s Done": "%s erledigt"
+//	"Ticket %s: %s has missing or 0 effort": "Ticket %s: %s hat keinen Aufwand"
+//
+// Translations may reorder arguments with explicit indexes such as %[2]s.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// locales holds the built-in catalogs, one <language>.yaml per language
+//
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// catalog is the active translation table, nil for English
+var catalog atomic.Pointer[map[string]string]
+
+// Languages returns the codes of the built-in languages, English included
+func Languages() []string {
+	languages := []string{"en"}
+	entries, _ := locales.ReadDir("locales")
+	for _, e := range entries {
+		languages = append(languages, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// SetLanguage makes T translate into lang: a built-in language code such as
+// "de" (regional variants like "de_CH.UTF-8" fall back to it), a path to a
+// catalog file ending in .yaml or .yml, or "" or "en" for English.
+func SetLanguage(lang string) error {
+	if ext := filepath.Ext(lang); ext == ".yaml" || ext == ".yml" {
+		data, err := os.ReadFile(lang)
+		if err != nil {
+			return err
+		}
+		return load(data, lang)
+	}
+
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "_-."); i >= 0 {
+		code = code[:i]
+	}
+	if code == "" || code == "en" {
+		catalog.Store(nil)
+		return nil
+	}
+	data, err := locales.ReadFile("locales/" + code + ".yaml")
+	if err != nil {
+		return fmt.Errorf("unsupported language %q (expected one of %s, or a .yaml catalog)", lang, strings.Join(Languages(), ", "))
+	}
+	return load(data, lang)
+}
+
+// load parses a catalog and makes it the active one
+func load(data []byte, name string) error {
+	var messages map[string]string
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("reading catalog %s: %w", name, err)
+	}
+	catalog.Store(&messages)
+	return nil
+}
+
+// T returns the translation of msg, or msg if it has none
+func T(msg string) string {
+	if messages := catalog.Load(); messages != nil {
+		if translated, ok := (*messages)[msg]; ok && translated != "" {
+			return translated
+		}
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format with args
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
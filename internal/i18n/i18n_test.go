@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage("")
+
+	tests := []struct {
+		lang, msg, want string
+	}{
+		{"", "Done", "Done"},
+		{"de", "Done", "Erledigt"},
+		{"de_CH.UTF-8", "Part %d", "Teil %d"},
+		{"NB", "Done", "Ferdig"},
+		{"nb", "Not in the catalog", "Not in the catalog"},
+		{"en-GB", "Done", "Done"},
+	}
+	for _, tt := range tests {
+		if err := SetLanguage(tt.lang); err != nil {
+			t.Fatalf("SetLanguage(%q) failed: %v", tt.lang, err)
+		}
+		if got := T(tt.msg); got != tt.want {
+			t.Errorf("%s: T(%q) = %q, want %q", tt.lang, tt.msg, got, tt.want)
+		}
+	}
+
+	if err := SetLanguage("xx"); err == nil {
+		t.Error("Expected an error for an unknown language")
+	}
+	if !slices.Equal(Languages(), []string{"de", "en", "nb"}) {
+		t.Errorf("Unexpected languages %v", Languages())
+	}
+}
+
+func TestSetLanguage_Catalog(t *testing.T) {
+	defer SetLanguage("")
+
+	path := filepath.Join(t.TempDir(), "sv.yaml")
+	if err := os.WriteFile(path, []byte("\"%s Done\": \"%s klart\"\n\"Ticket %s depends on %s\": \"%[2]s blockerar %[1]s\"\n\"Part %d\": \"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLanguage(path); err != nil {
+		t.Fatalf("SetLanguage failed: %v", err)
+	}
+	if got := Sprintf("%s Done", "Beta"); got != "Beta klart" {
+		t.Errorf("Expected the catalog's translation, got %q", got)
+	}
+	if got := Sprintf("Ticket %s depends on %s", "A-1", "A-2"); got != "A-2 blockerar A-1" {
+		t.Errorf("Expected reordered arguments, got %q", got)
+	}
+	if got := Sprintf("Part %d", 2); got != "Part 2" {
+		t.Errorf("Expected English for an empty translation, got %q", got)
+	}
+
+	if err := SetLanguage(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing catalog")
+	}
+}
+
+// verbs matches fmt verbs, with an optional explicit argument index
+var verbs = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*[\d.]*[a-zA-Z%]`)
+
+func TestCatalogs_KeepVerbs(t *testing.T) {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, _ := locales.ReadFile("locales/" + e.Name())
+		var messages map[string]string
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		for msg, translated := range messages {
+			if want, got := len(verbs.FindAllString(msg, -1)), len(verbs.FindAllString(translated, -1)); want != got {
+				t.Errorf("%s: %q has %d format verbs, its translation %q has %d", e.Name(), msg, want, translated, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// AttachFile uploads r as an attachment named filename on the issue, then
+// removes older attachments with the same name so only the latest remains
+func (c *Client) AttachFile(ctx context.Context, issueKey, filename string, r io.Reader) error {
+	issue, resp, err := c.onpremiseClient.Issue.Get(ctx, issueKey, &onpremise.GetQueryOptions{Fields: "attachment"})
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", issueKey, responseError(resp, err, ""))
+	}
+
+	var previous []string
+	if issue.Fields != nil {
+		for _, a := range issue.Fields.Attachments {
+			if a != nil && a.Filename == filename {
+				previous = append(previous, a.ID)
+			}
+		}
+	}
+
+	if _, resp, err := c.onpremiseClient.Issue.PostAttachment(ctx, issueKey, r, filename); err != nil {
+		return fmt.Errorf("attaching %s to %s: %w", filename, issueKey, responseError(resp, err, ""))
+	}
+
+	for _, id := range previous {
+		resp, err := c.onpremiseClient.Issue.DeleteAttachment(ctx, id)
+		if err != nil {
+			term.Warnf("Could not remove previous attachment %s from %s: %v", id, issueKey, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package jira
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAttachFile(t *testing.T) {
+	var uploaded string
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/PROJ-1":
+			w.Write([]byte(`{"key": "PROJ-1", "fields": {"attachment": [
+				{"id": "10", "filename": "Plan.oplx.zip"},
+				{"id": "11", "filename": "other.png"}
+			]}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/PROJ-1/attachments":
+			if r.Header.Get("X-Atlassian-Token") == "" {
+				t.Error("Attachment upload should disable XSRF checks")
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("Reading upload: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			uploaded = header.Filename + ":" + string(data)
+			w.Write([]byte(`[{"id": "12", "filename": "Plan.oplx.zip"}]`))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/rest/api/2/attachment/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/rest/api/2/attachment/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if err := client.AttachFile(context.Background(), "PROJ-1", "Plan.oplx.zip", strings.NewReader("zipdata")); err != nil {
+		t.Fatalf("AttachFile failed: %v", err)
+	}
+	if uploaded != "Plan.oplx.zip:zipdata" {
+		t.Errorf("Unexpected upload %q", uploaded)
+	}
+	if len(deleted) != 1 || deleted[0] != "10" {
+		t.Errorf("Expected only the previous plan attachment to be removed, got %v", deleted)
+	}
+}
@@ -0,0 +1,73 @@
+package jira
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// changelogTimeLayout is the timestamp format Jira uses in changelog histories
+const changelogTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// DefaultInProgressStatuses are the statuses that mark work as started
+var DefaultInProgressStatuses = []string{"In Progress"}
+
+// StatusChange is a single status transition from an issue changelog
+type StatusChange struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// statusChanges extracts the status transitions from a changelog in chronological order
+func statusChanges(changelog *onpremise.Changelog) []StatusChange {
+	if changelog == nil {
+		return nil
+	}
+
+	var changes []StatusChange
+	for _, history := range changelog.Histories {
+		at, err := time.Parse(changelogTimeLayout, history.Created)
+		if err != nil {
+			continue
+		}
+		for _, item := range history.Items {
+			if item.Field == "status" {
+				changes = append(changes, StatusChange{At: at, From: item.FromString, To: item.ToString})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].At.Before(changes[j].At)
+	})
+	return changes
+}
+
+// firstTransitionTo returns the time of the first transition into any of the
+// given statuses, or the zero time if there is none
+func firstTransitionTo(changes []StatusChange, statuses []string) time.Time {
+	for _, change := range changes {
+		for _, status := range statuses {
+			if strings.EqualFold(change.To, status) {
+				return change.At
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// lastTransitionTo returns the time of the last transition into any of the
+// given statuses, or the zero time if there is none
+func lastTransitionTo(changes []StatusChange, statuses []string) time.Time {
+	for i := len(changes) - 1; i >= 0; i-- {
+		for _, status := range statuses {
+			if strings.EqualFold(changes[i].To, status) {
+				return changes[i].At
+			}
+		}
+	}
+	return time.Time{}
+}
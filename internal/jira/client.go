@@ -4,18 +4,50 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/andygrunwald/go-jira/v2/cloud"
 	"github.com/andygrunwald/go-jira/v2/onpremise"
+
+	"github.com/gunnarrb/jql-to-plan/internal/term"
 )
 
 type Client struct {
 	cloudClient           *cloud.Client
 	onpremiseClient       *onpremise.Client
 	isCloud               bool
+	apiVersion            string
+	epicBatchSize         int
+	maxJQLLength          int
+	noBulkFetch           atomic.Bool // Set once the bulk fetch endpoint turned out to be missing
 	effortCustomFieldID   string
 	epicLinkCustomFieldID string
+	effortFieldName       string
+	epicLinkFieldName     string
+	flaggedCustomFieldID  string
+	fetchChangelog        bool
+	inProgressStatuses    []string
+	doneStatuses          []string
+	excludeIssueTypes     map[string]bool    // Lower-cased issue type names
+	issueTypeEfforts      map[string]float64 // Keyed by lower-cased issue type name
+	loc                   *time.Location     // Time zone for dates; nil means time.Local
+	hoursPerDay           float64            // For effort durations; zero means DefaultHoursPerDay
+	daysPerWeek           float64            // For effort durations; zero means DefaultDaysPerWeek
+	effortUnit            string             // Unit of plain effort numbers; empty means EffortUnitDays
+	daysPerPoint          float64            // For EffortUnitPoints; zero means 1
+	extraFields           []string           // Additional fields by ID or name, reported in Ticket.Fields
+	extraFieldIDs         map[string]string  // Resolved IDs of extraFields
+	coAssigneeField       string             // One of extraFields, see ClientOptions.CoAssigneeField
+	reviewerField         string             // One of extraFields, see ClientOptions.ReviewerField
+	sprintField           string             // One of extraFields, see ClientOptions.SprintField
+	targetStartField      string             // One of extraFields, see ClientOptions.TargetStartField
+	targetEndField        string             // One of extraFields, see ClientOptions.TargetEndField
+	blockingLinkType      string             // See ClientOptions.BlockingLinkType
+	problems              problemLog         // Issues that could not be read fully
 }
 
 type Ticket struct {
@@ -23,23 +55,50 @@ type Ticket struct {
 	Summary        string
 	Link           string
 	Assignee       string
+	CoAssignees    []string // Display names of the users of ClientOptions.CoAssigneeField, other than Assignee
+	AssigneeID     string   // Account ID (Cloud) or username (Server) of the assignee, for GetUserProfiles
+	Reviewer       string   // Display name of the user in ClientOptions.ReviewerField
+	IssueType      string
 	Status         string
-	EffortDays     float64  // Effort in days from custom field cf[10105]
-	EpicLink       string   // Key of the Epic this ticket belongs to
-	DependencyKeys []string // Keys of tickets this ticket depends on
+	StatusCategory string    // Jira status category key: "new", "indeterminate" or "done"
+	EffortDays     float64   // Effort in days from custom field cf[10105]
+	EpicLink       string    // Key of the Epic this ticket belongs to
+	Parent         string    // Key of the parent issue if this is a sub-task
+	Labels         []string  // Jira labels
+	DependencyKeys []string  // Keys of tickets this ticket depends on
+	ActualStart    time.Time // First transition to an in-progress status (zero if unknown)
+	Flagged        bool      // Marked as impeded via the Flagged field
+	DueDate        time.Time // Jira due date at midnight in the client's time zone (zero if unset)
+	SprintEnd      time.Time // End of the last sprint in ClientOptions.SprintField, like DueDate
+	TargetStart    time.Time // Date in ClientOptions.TargetStartField, like DueDate
+	TargetEnd      time.Time // Date in ClientOptions.TargetEndField, like DueDate
+
+	// Fields holds the display values of ClientOptions.ExtraFields, keyed by
+	// the field as requested (unset fields are missing)
+	Fields map[string]string
 }
 
 // NewClient creates a new Jira client.
 // We'll simplisticly assume PAT implies simple Bearer auth or similar.
 // go-jira v2 splits cloud and onpremise.
 // For widely compatible PAT usage, we often just need a standard HTTP client that adds the header.
-func NewClient(endpoint, pat, effortCustomFieldID, epicLinkCustomFieldID string) (*Client, error) {
-	// Create a transport that adds the auth header
-	tp := &patTransport{
+func NewClient(endpoint, pat, effortCustomFieldID, epicLinkCustomFieldID string, opts ClientOptions) (*Client, error) {
+	base, err := newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a transport that adds the auth header, or the session cookie
+	var tp http.RoundTripper = &patTransport{
 		PAT:  pat,
-		Base: http.DefaultTransport,
+		Base: newTraceTransport(base, pat, opts),
+	}
+	if opts.SessionCookie != "" || opts.Username != "" || len(opts.NegotiateCommand) > 0 {
+		if tp, err = newSessionTransport(endpoint, opts, newTraceTransport(base, pat, opts)); err != nil {
+			return nil, err
+		}
 	}
-	httpClient := &http.Client{Transport: tp}
+	httpClient := &http.Client{Transport: tp, Timeout: opts.RequestTimeout}
 
 	// Simple heuristic: if endpoint contains "atlassian.net", it's likely cloud, but
 	// for a generic CLI handling "JIRA_ENDPOINT", onpremise logic is often safer for custom domains
@@ -57,20 +116,96 @@ func NewClient(endpoint, pat, effortCustomFieldID, epicLinkCustomFieldID string)
 		return nil, err
 	}
 
-	if effortCustomFieldID != "" && !strings.HasPrefix(effortCustomFieldID, "customfield_") {
-		effortCustomFieldID = "customfield_" + effortCustomFieldID
+	apiVersion := opts.APIVersion
+	if apiVersion == "" {
+		apiVersion = APIVersion2
+	}
+	if apiVersion != APIVersion2 && apiVersion != APIVersion3 {
+		return nil, fmt.Errorf("unsupported api_version %q (expected %q or %q)", opts.APIVersion, APIVersion2, APIVersion3)
+	}
+
+	epicBatchSize := opts.EpicBatchSize
+	if epicBatchSize == 0 {
+		epicBatchSize = defaultEpicBatchSize
+	}
+	if epicBatchSize < 0 || opts.MaxJQLLength < 0 {
+		return nil, fmt.Errorf("invalid epic_batch_size %d or max_jql_length %d (expected positive numbers)", opts.EpicBatchSize, opts.MaxJQLLength)
+	}
+
+	effortCustomFieldID = normalizeFieldID(effortCustomFieldID)
+	epicLinkCustomFieldID = normalizeFieldID(epicLinkCustomFieldID)
+
+	inProgressStatuses := opts.InProgressStatuses
+	if len(inProgressStatuses) == 0 {
+		inProgressStatuses = DefaultInProgressStatuses
 	}
-	if epicLinkCustomFieldID != "" && !strings.HasPrefix(epicLinkCustomFieldID, "customfield_") {
-		epicLinkCustomFieldID = "customfield_" + epicLinkCustomFieldID
+	doneStatuses := opts.DoneStatuses
+	if len(doneStatuses) == 0 {
+		doneStatuses = DefaultDoneStatuses
+	}
+
+	excludeIssueTypes := make(map[string]bool, len(opts.ExcludeIssueTypes))
+	for _, name := range opts.ExcludeIssueTypes {
+		excludeIssueTypes[strings.ToLower(name)] = true
+	}
+	issueTypeEfforts := make(map[string]float64, len(opts.IssueTypeEfforts))
+	for name, days := range opts.IssueTypeEfforts {
+		issueTypeEfforts[strings.ToLower(name)] = days
+	}
+
+	// The user and date fields are resolved and fetched like the extra fields
+	extraFields := slices.Clip(opts.ExtraFields)
+	for _, field := range []string{opts.CoAssigneeField, opts.ReviewerField, opts.SprintField, opts.TargetStartField, opts.TargetEndField} {
+		if field != "" {
+			extraFields = append(extraFields, field)
+		}
 	}
 
 	return &Client{
 		onpremiseClient:       client,
+		apiVersion:            apiVersion,
+		epicBatchSize:         epicBatchSize,
+		maxJQLLength:          opts.MaxJQLLength,
 		effortCustomFieldID:   effortCustomFieldID,
 		epicLinkCustomFieldID: epicLinkCustomFieldID,
+		effortFieldName:       opts.EffortFieldName,
+		epicLinkFieldName:     opts.EpicLinkFieldName,
+		flaggedCustomFieldID:  normalizeFieldID(opts.FlaggedCustomFieldID),
+		fetchChangelog:        opts.FetchChangelog,
+		inProgressStatuses:    inProgressStatuses,
+		doneStatuses:          doneStatuses,
+		excludeIssueTypes:     excludeIssueTypes,
+		issueTypeEfforts:      issueTypeEfforts,
+		loc:                   opts.Location,
+		hoursPerDay:           opts.HoursPerDay,
+		daysPerWeek:           opts.DaysPerWeek,
+		effortUnit:            opts.EffortUnit,
+		daysPerPoint:          opts.DaysPerPoint,
+		extraFields:           extraFields,
+		coAssigneeField:       opts.CoAssigneeField,
+		reviewerField:         opts.ReviewerField,
+		sprintField:           opts.SprintField,
+		targetStartField:      opts.TargetStartField,
+		targetEndField:        opts.TargetEndField,
+		blockingLinkType:      opts.BlockingLinkType,
 	}, nil
 }
 
+// BrowseURL returns the web page of the issue, derived from its REST API link.
+// Links that are not REST API URLs are returned unchanged.
+func (t Ticket) BrowseURL() string {
+	i := strings.Index(t.Link, "/rest/api/")
+	if i < 0 || t.Key == "" {
+		return t.Link
+	}
+	return t.Link[:i] + "/browse/" + t.Key
+}
+
+// IsDone reports whether the ticket is in a done status category
+func (t Ticket) IsDone() bool {
+	return t.StatusCategory == "done"
+}
+
 type patTransport struct {
 	PAT  string
 	Base http.RoundTripper
@@ -81,40 +216,104 @@ func (t *patTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return t.Base.RoundTrip(req)
 }
 
+// GetTickets fetches the tickets matching jql and the epics they belong to.
+// If ctx is cancelled during the search, the tickets of the pages fetched so
+// far are returned along with the error, without their epics. Assignee names
+// are merged per user (see MergeAssignees).
 func (c *Client) GetTickets(ctx context.Context, jql string) ([]Ticket, map[string]Ticket, error) {
 	if c.onpremiseClient == nil {
 		return nil, nil, fmt.Errorf("client not initialized")
 	}
 
-	// Search implementation - include custom field for effort and issuelinks
-	fields := []string{"summary", "assignee", "status", "issuelinks", c.effortCustomFieldID}
+	// Each page is converted as it arrives, so the raw issues of only one
+	// page are held at a time
+	stream := c.StreamTickets(ctx, jql)
+	var tickets []Ticket
+	for t := range stream.C {
+		tickets = append(tickets, t)
+	}
+	stream.DropExcluded(tickets)
+	MergeAssignees(tickets)
+	if err := stream.Err(); err != nil {
+		if ctx.Err() != nil && len(tickets) > 0 {
+			return tickets, make(map[string]Ticket), err
+		}
+		return nil, nil, err
+	}
+	return tickets, c.epicsFor(ctx, tickets), nil
+}
+
+// ticketFields lists the fields fetched for tickets: the standard ones plus
+// the configured custom fields for effort, epic link and flagged and any extra fields
+func (c *Client) ticketFields() []string {
+	fields := []string{"summary", "issuetype", "assignee", "status", "issuelinks", "parent", "labels", "duedate"}
+	if c.effortCustomFieldID != "" {
+		fields = append(fields, c.effortCustomFieldID)
+	}
 	if c.epicLinkCustomFieldID != "" {
 		fields = append(fields, c.epicLinkCustomFieldID)
 	}
+	if c.flaggedCustomFieldID != "" {
+		fields = append(fields, c.flaggedCustomFieldID)
+	}
+	for _, field := range c.extraFields {
+		fields = append(fields, c.extraFieldID(field))
+	}
+	return fields
+}
 
-	issues, _, err := c.onpremiseClient.Issue.Search(ctx, jql, &onpremise.SearchOptions{
-		Fields:     fields,
-		StartAt:    0,
-		MaxResults: 1000, // Fetch up to 1000 for now
-	})
-	if err != nil {
-		return nil, nil, err
+// ticketExpand returns the expand parameter for ticket searches
+func (c *Client) ticketExpand() string {
+	if c.fetchChangelog {
+		return "changelog"
+	}
+	return ""
+}
+
+// epicsFor fetches the epics the tickets belong to. Failures are only
+// recorded (see FetchFailures), as the plan can still be built from the epic
+// keys.
+func (c *Client) epicsFor(ctx context.Context, tickets []Ticket) map[string]Ticket {
+	if c.epicLinkCustomFieldID == "" {
+		return make(map[string]Ticket)
 	}
+	epics, _ := c.fetchEpics(ctx, epicKeys(tickets))
+	return epics
+}
 
-	var tickets []Ticket
+// ticketsFromIssues converts search results into tickets, dropping excluded
+// issue types and any dependencies on them
+func (c *Client) ticketsFromIssues(issues []onpremise.Issue) []Ticket {
+	excluded := make(map[string]bool)
+	tickets := c.ticketsFromPage(issues, excluded)
+	dropExcluded(tickets, excluded)
+	return tickets
+}
+
+// ticketsFromPage converts a page of search results into tickets, leaving
+// out excluded issue types and adding their keys to excluded
+func (c *Client) ticketsFromPage(issues []onpremise.Issue, excluded map[string]bool) []Ticket {
+	tickets := make([]Ticket, 0, len(issues))
 	for _, i := range issues {
-		var assignee string
-		if i.Fields.Assignee != nil {
-			assignee = i.Fields.Assignee.DisplayName
-			if assignee == "" {
-				assignee = i.Fields.Assignee.Name
-			}
+		if !c.checkReadable(i) {
+			continue
+		}
+		issueType := strings.ToLower(i.Fields.Type.Name)
+		if c.excludeIssueTypes[issueType] {
+			excluded[i.Key] = true
+			continue
 		}
 
-		// Extract effort from custom field
-		effortDays, found := extractEffortDays(i.Fields.Unknowns, c.effortCustomFieldID)
+		assignee := assigneeName(i.Fields.Assignee)
+
+		// Extract effort from custom field, falling back to the issue type default
+		effortDays, found := c.effortDays(i.Fields.Unknowns)
 		if !found || effortDays == 0 {
-			fmt.Printf("Warning: Ticket %s: %s has missing or 0 effort\n", i.Key, i.Fields.Summary)
+			if typeEffort, ok := c.issueTypeEfforts[issueType]; ok {
+				effortDays = typeEffort
+			} else {
+				term.Warnf("Ticket %s: %s has missing or 0 effort", i.Key, readableSummary(i))
+			}
 		}
 
 		// Extract Epic Link
@@ -127,81 +326,112 @@ func (c *Client) GetTickets(ctx context.Context, jql string) ([]Ticket, map[stri
 			}
 		}
 
-		var dependencyKeys []string
-		for _, link := range i.Fields.IssueLinks {
-			if link.Type.Name == "Dependent" && link.OutwardIssue != nil {
-				dependencyKeys = append(dependencyKeys, link.OutwardIssue.Key)
-			}
+		var flagged bool
+		if c.flaggedCustomFieldID != "" {
+			flagged = isFlagged(i.Fields.Unknowns[c.flaggedCustomFieldID])
+		}
+
+		// Derive the actual start from the changelog
+		var actualStart time.Time
+		if c.fetchChangelog {
+			actualStart = c.inLocation(firstTransitionTo(statusChanges(i.Changelog), c.inProgressStatuses))
+		}
+
+		// Cloud also reports an epic as the parent of standard issues, so only
+		// sub-tasks record theirs
+		var parent string
+		if i.Fields.Type.Subtask && i.Fields.Parent != nil {
+			parent = i.Fields.Parent.Key
 		}
 
+		status, statusCategory := readableStatus(i.Fields)
+		raw := c.rawFields(i.Fields)
 		tickets = append(tickets, Ticket{
 			Key:            i.Key,
-			Summary:        i.Fields.Summary,
+			Summary:        readableSummary(i),
 			Link:           i.Self,
 			Assignee:       assignee,
-			Status:         i.Fields.Status.Name,
+			AssigneeID:     assigneeID(i.Fields.Assignee),
+			IssueType:      i.Fields.Type.Name,
+			Status:         status,
+			StatusCategory: statusCategory,
 			EffortDays:     effortDays,
 			EpicLink:       epicLink,
-			DependencyKeys: dependencyKeys,
+			Parent:         parent,
+			Labels:         i.Fields.Labels,
+			DependencyKeys: c.dependencyKeys(i.Fields.IssueLinks),
+			ActualStart:    actualStart,
+			Flagged:        flagged,
+			DueDate:        c.dateInLocation(time.Time(i.Fields.Duedate)),
+			Fields:         c.extraFieldValues(raw),
+			CoAssignees:    c.coAssignees(raw, assignee),
+			Reviewer:       c.reviewer(raw),
+			SprintEnd:      c.sprintEnd(raw),
+			TargetStart:    c.dateField(raw, c.targetStartField),
+			TargetEnd:      c.dateField(raw, c.targetEndField),
 		})
 	}
 
-	// Fetch Epic details if any epics were found
-	epicMap := make(map[string]Ticket)
-	if c.epicLinkCustomFieldID != "" {
-		uniqueEpics := make(map[string]bool)
-		for _, t := range tickets {
-			if t.EpicLink != "" {
-				uniqueEpics[t.EpicLink] = true
-			}
-		}
-
-		if len(uniqueEpics) > 0 {
-			var epicKeys []string
-			for key := range uniqueEpics {
-				epicKeys = append(epicKeys, key)
-			}
+	return tickets
+}
 
-			// Batch fetch epics in chunks of 50 (Jira limit is often around 50-100 for IN clause)
-			// For simplicity in this CLI, we'll just try to fetch all if < 50, or chunk if needed.
-			// Let's implement a simple chunking.
-			chunkSize := 50
-			for i := 0; i < len(epicKeys); i += chunkSize {
-				end := i + chunkSize
-				if end > len(epicKeys) {
-					end = len(epicKeys)
-				}
-				batchKeys := epicKeys[i:end]
-
-				jql := fmt.Sprintf("key in (%s)", strings.Join(batchKeys, ","))
-				// We don't need the custom fields for the Epic itself, just Summary/Status
-				epics, _, err := c.onpremiseClient.Issue.Search(ctx, jql, &onpremise.SearchOptions{
-					Fields:     []string{"summary", "status", "issuelinks"}, // Include issuelinks if we ever need dependencies of epics
-					StartAt:    0,
-					MaxResults: 1000,
-				})
-				if err != nil {
-					fmt.Printf("Warning: Failed to fetch epic details: %v\n", err)
-					continue
-				}
+// dropExcluded removes dependencies on the excluded issues from the tickets
+func dropExcluded(tickets []Ticket, excluded map[string]bool) {
+	if len(excluded) == 0 {
+		return
+	}
+	for i := range tickets {
+		tickets[i].DependencyKeys = slices.DeleteFunc(tickets[i].DependencyKeys, func(key string) bool { return excluded[key] })
+	}
+}
 
-				for _, e := range epics {
-					epicMap[e.Key] = Ticket{
-						Key:     e.Key,
-						Summary: e.Fields.Summary,
-						Link:    e.Self,
-						Status:  e.Fields.Status.Name,
-					}
-				}
-			}
+// dependencyKeys returns the keys of issues linked as "Dependent" outward
+// links, or as inward links of the blocking link type
+func (c *Client) dependencyKeys(links []*onpremise.IssueLink) []string {
+	var keys []string
+	for _, link := range links {
+		switch {
+		case link.Type.Name == "Dependent" && link.OutwardIssue != nil:
+			keys = append(keys, link.OutwardIssue.Key)
+		case c.blockingLinkType != "" && strings.EqualFold(link.Type.Name, c.blockingLinkType) && link.InwardIssue != nil:
+			keys = append(keys, link.InwardIssue.Key)
 		}
 	}
+	return keys
+}
+
+// normalizeFieldID turns a bare numeric custom field ID into its customfield_ form
+func normalizeFieldID(id string) string {
+	if id != "" && !strings.HasPrefix(id, "customfield_") {
+		return "customfield_" + id
+	}
+	return id
+}
+
+// isFlagged reports whether a Flagged field value is set. Jira stores it as a
+// list of selected options (e.g. [{"value": "Impediment"}]), empty when unflagged.
+func isFlagged(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		return len(v) > 0
+	case string:
+		return v != ""
+	default:
+		return true
+	}
+}
 
-	return tickets, epicMap, nil
+// effortDays extracts the effort of an issue in days with the client's units
+func (c *Client) effortDays(unknowns map[string]interface{}) (float64, bool) {
+	return extractEffortDays(unknowns, c.effortCustomFieldID, c.hoursPerDay, c.daysPerWeek, effortUnitDays(c.effortUnit, c.hoursPerDay, c.daysPerPoint))
 }
 
-// extractEffortDays extracts the effort in days from the custom field map
-func extractEffortDays(unknowns map[string]interface{}, effortFieldID string) (float64, bool) {
+// extractEffortDays extracts the effort in days from the custom field map.
+// Plain numbers are multiplied by unitDays, the days per unit of the field.
+// Strings may be Jira-style durations, converted with hoursPerDay and daysPerWeek.
+func extractEffortDays(unknowns map[string]interface{}, effortFieldID string, hoursPerDay, daysPerWeek, unitDays float64) (float64, bool) {
 	if unknowns == nil {
 		return 0, false
 	}
@@ -214,14 +444,21 @@ func extractEffortDays(unknowns map[string]interface{}, effortFieldID string) (f
 	// The custom field could be a number (float64 or int) or a string
 	switch v := val.(type) {
 	case float64:
-		return v, true
+		return v * unitDays, true
 	case int:
-		return float64(v), true
+		return float64(v) * unitDays, true
 	case string:
-		// Try to parse as float
+		// Plain numbers are the common case, then durations like "2w 3d 4h",
+		// then Sscanf for a leading number as in "3 SP"
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f * unitDays, true
+		}
+		if days, ok := parseDurationDays(v, hoursPerDay, daysPerWeek); ok {
+			return days, true
+		}
 		var f float64
 		if _, err := fmt.Sscanf(v, "%f", &f); err == nil {
-			return f, true
+			return f * unitDays, true
 		}
 		return 0, false
 	default:
@@ -0,0 +1,200 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// benchmarkIssues builds n search results with effort, epic links and a
+// dependency on the previous issue
+func benchmarkIssues(n int) []onpremise.Issue {
+	issues := make([]onpremise.Issue, n)
+	for i := range issues {
+		var links []*onpremise.IssueLink
+		if i > 0 {
+			links = append(links, &onpremise.IssueLink{
+				Type:         onpremise.IssueLinkType{Name: "Dependent"},
+				OutwardIssue: &onpremise.Issue{Key: fmt.Sprintf("TASK-%d", i-1)},
+			})
+		}
+		issues[i] = onpremise.Issue{
+			Key:  fmt.Sprintf("TASK-%d", i),
+			Self: fmt.Sprintf("https://jira.example.com/rest/api/2/issue/%d", i),
+			Fields: &onpremise.IssueFields{
+				Summary:    fmt.Sprintf("Task %d", i),
+				Assignee:   &onpremise.User{DisplayName: fmt.Sprintf("User %d", i%25)},
+				Status:     &onpremise.Status{Name: "To Do"},
+				IssueLinks: links,
+				Unknowns: map[string]interface{}{
+					"customfield_10105": "2.5",
+					"customfield_10100": fmt.Sprintf("EPIC-%d", i%100),
+				},
+			},
+		}
+	}
+	return issues
+}
+
+func BenchmarkTicketsFromIssues(b *testing.B) {
+	issues := benchmarkIssues(10000)
+	c := &Client{effortCustomFieldID: "customfield_10105", epicLinkCustomFieldID: "customfield_10100"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		c.ticketsFromIssues(issues)
+	}
+}
+
+func TestExtractEffortDays(t *testing.T) {
+	tests := []struct {
+		val   interface{}
+		want  float64
+		found bool
+	}{
+		{val: 2.5, want: 2.5, found: true},
+		{val: 3, want: 3, found: true},
+		{val: "1.5", want: 1.5, found: true},
+		{val: " 4 ", want: 4, found: true},
+		{val: "2d", want: 2, found: true},
+		{val: "2w 3d 4h", want: 13.5, found: true},
+		{val: "1.5d", want: 1.5, found: true},
+		{val: "1d4h", want: 1.5, found: true},
+		{val: "6H 30m", want: 0.8125, found: true},
+		{val: "3 SP", want: 3, found: true},
+		{val: "n/a", found: false},
+		{val: nil, found: false},
+	}
+	for _, tt := range tests {
+		got, found := extractEffortDays(map[string]interface{}{"customfield_1": tt.val}, "customfield_1", 0, 0, 1)
+		if got != tt.want || found != tt.found {
+			t.Errorf("extractEffortDays(%v) = %v, %v; want %v, %v", tt.val, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestTicketsFromIssues_IssueTypes(t *testing.T) {
+	issue := func(key, issueType string, deps ...string) onpremise.Issue {
+		var links []*onpremise.IssueLink
+		for _, dep := range deps {
+			links = append(links, &onpremise.IssueLink{
+				Type:         onpremise.IssueLinkType{Name: "Dependent"},
+				OutwardIssue: &onpremise.Issue{Key: dep},
+			})
+		}
+		return onpremise.Issue{Key: key, Fields: &onpremise.IssueFields{
+			Type:       onpremise.IssueType{Name: issueType},
+			Status:     &onpremise.Status{Name: "To Do"},
+			IssueLinks: links,
+		}}
+	}
+
+	c := &Client{
+		excludeIssueTypes: map[string]bool{"sub-task": true},
+		issueTypeEfforts:  map[string]float64{"bug": 0.5},
+	}
+	tickets := c.ticketsFromIssues([]onpremise.Issue{
+		issue("TASK-1", "Story", "TASK-2", "TASK-3"),
+		issue("TASK-2", "Sub-task"),
+		issue("TASK-3", "Bug"),
+	})
+
+	if len(tickets) != 2 {
+		t.Fatalf("Expected the Sub-task to be excluded, got %d tickets", len(tickets))
+	}
+	if deps := tickets[0].DependencyKeys; len(deps) != 1 || deps[0] != "TASK-3" {
+		t.Errorf("Expected the dependency on the excluded ticket to be dropped, got %v", deps)
+	}
+	if tickets[1].IssueType != "Bug" || tickets[1].EffortDays != 0.5 {
+		t.Errorf("Expected a Bug with the 0.5d type default, got %+v", tickets[1])
+	}
+}
+
+func TestExtractEffortDays_ConfiguredUnits(t *testing.T) {
+	// 6-hour days and 4-day weeks: 1w 3h = 4 + 0.5 days
+	got, found := extractEffortDays(map[string]interface{}{"customfield_1": "1w 3h"}, "customfield_1", 6, 4, 1)
+	if !found || got != 4.5 {
+		t.Errorf("extractEffortDays(1w 3h) = %v, %v; want 4.5, true", got, found)
+	}
+}
+
+func TestEffortDays_Unit(t *testing.T) {
+	tests := []struct {
+		unit string
+		val  interface{}
+		want float64
+	}{
+		{unit: "", val: 2.0, want: 2},
+		{unit: EffortUnitHours, val: 12.0, want: 1.5},
+		{unit: EffortUnitHours, val: "4", want: 0.5},
+		{unit: EffortUnitHours, val: "1d 4h", want: 1.5}, // Durations carry their own units
+		{unit: EffortUnitPoints, val: 3.0, want: 1.5},
+		{unit: EffortUnitPoints, val: "3 SP", want: 1.5},
+	}
+	for _, tt := range tests {
+		c := &Client{effortCustomFieldID: "customfield_1", effortUnit: tt.unit, daysPerPoint: 0.5}
+		got, found := c.effortDays(map[string]interface{}{"customfield_1": tt.val})
+		if !found || got != tt.want {
+			t.Errorf("effortDays(%v) in %q = %v, %v; want %v, true", tt.val, tt.unit, got, found, tt.want)
+		}
+	}
+}
+
+func TestTicketsFromIssues_Parent(t *testing.T) {
+	issue := func(key string, subtask bool, parent string) onpremise.Issue {
+		return onpremise.Issue{Key: key, Fields: &onpremise.IssueFields{
+			Type:   onpremise.IssueType{Name: "Task", Subtask: subtask},
+			Status: &onpremise.Status{Name: "To Do"},
+			Parent: &onpremise.Parent{Key: parent},
+		}}
+	}
+
+	tickets := (&Client{}).ticketsFromIssues([]onpremise.Issue{
+		issue("TASK-1", false, "EPIC-1"), // Cloud reports the epic as parent
+		issue("TASK-2", true, "TASK-1"),
+	})
+	if tickets[0].Parent != "" || tickets[1].Parent != "TASK-1" {
+		t.Errorf("Expected only the sub-task to record its parent, got %q and %q", tickets[0].Parent, tickets[1].Parent)
+	}
+}
+
+func TestGetTickets_CancelledKeepsFetchedPages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if startAt := r.URL.Query().Get("startAt"); startAt != "" && startAt != "0" {
+			// Interrupted while the second page is on its way
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		issue := map[string]interface{}{
+			"key": "WEB-1",
+			"fields": map[string]interface{}{
+				"summary":   "First page",
+				"issuetype": map[string]interface{}{"name": "Story"},
+				"status":    map[string]interface{}{"name": "To Do", "statusCategory": map[string]interface{}{"key": "new"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{issue}, "total": 2})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tickets, epics, err := client.GetTickets(ctx, "project = WEB")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].Key != "WEB-1" || epics == nil {
+		t.Errorf("expected the first page's ticket and no epics, got %+v and %v", tickets, epics)
+	}
+}
@@ -0,0 +1,108 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// StatusError is returned when Jira answered, but with a non-2xx status.
+// The common causes are returned as AuthError, NotFoundError, RateLimitError
+// and JQLSyntaxError, which wrap it.
+type StatusError struct {
+	StatusCode int
+	Messages   []string // Jira's error messages, if any
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ServerInfo is the subset of /serverInfo shown by diagnostics
+type ServerInfo struct {
+	Version        string `json:"version"`
+	DeploymentType string `json:"deploymentType"`
+	ServerTitle    string `json:"serverTitle"`
+}
+
+// FieldCheck is the outcome of resolving one configured custom field
+type FieldCheck struct {
+	Setting string
+	ID      string // Resolved field ID
+	Name    string // Field display name in Jira
+	Err     error
+}
+
+// getJSON performs an authenticated GET and decodes the response into v.
+// Non-2xx responses are returned as *StatusError or one of its wrappers.
+func (c *Client) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := c.onpremiseClient.NewRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.onpremiseClient.Do(req, v)
+	if err != nil {
+		if resp != nil {
+			return responseError(resp, onpremise.NewJiraError(resp, err), "")
+		}
+		return err
+	}
+	return nil
+}
+
+// ServerInfo fetches the Jira server version and deployment type
+func (c *Client) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	var info ServerInfo
+	if err := c.getJSON(ctx, "rest/api/2/serverInfo", &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// CurrentUser returns the display name of the user the PAT belongs to
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	var user onpremise.User
+	if err := c.getJSON(ctx, "rest/api/2/myself", &user); err != nil {
+		return "", err
+	}
+	return assigneeName(&user), nil
+}
+
+// CheckFields resolves the configured custom fields against all fields
+// visible to the user, without needing a query that returns issues
+func (c *Client) CheckFields(ctx context.Context) ([]FieldCheck, error) {
+	var fields []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := c.getJSON(ctx, "rest/api/2/field", &fields); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(fields))
+	for _, f := range fields {
+		names[f.ID] = f.Name
+	}
+
+	configured := []struct{ setting, id, name string }{
+		{"effort_custom_field_id", c.effortCustomFieldID, c.effortFieldName},
+		{"epic_link_custom_field_id", c.epicLinkCustomFieldID, c.epicLinkFieldName},
+		{"flagged_custom_field_id", c.flaggedCustomFieldID, ""},
+	}
+
+	var checks []FieldCheck
+	for _, f := range configured {
+		if f.id == "" && f.name == "" {
+			continue
+		}
+		id, err := resolveField(names, f.id, f.name, f.setting)
+		checks = append(checks, FieldCheck{Setting: f.setting, ID: id, Name: names[id], Err: err})
+	}
+	return checks, nil
+}
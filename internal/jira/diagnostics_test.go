@@ -0,0 +1,64 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			if r.Header.Get("Authorization") != "Bearer good" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"name": "jdoe", "displayName": "Jane Doe"}`))
+		case "/rest/api/2/field":
+			w.Write([]byte(`[{"id": "customfield_10105", "name": "Story Points"}, {"id": "customfield_10100", "name": "Epic Link"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "good", "10105", "", ClientOptions{EpicLinkFieldName: "Epic Link", FlaggedCustomFieldID: "10021"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+
+	user, err := client.CurrentUser(ctx)
+	if err != nil || user != "Jane Doe" {
+		t.Errorf("CurrentUser() = %q, %v; want Jane Doe", user, err)
+	}
+
+	checks, err := client.CheckFields(ctx)
+	if err != nil {
+		t.Fatalf("CheckFields failed: %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("Expected 3 field checks, got %d", len(checks))
+	}
+	if checks[0].Err != nil || checks[0].Name != "Story Points" {
+		t.Errorf("Unexpected effort check: %+v", checks[0])
+	}
+	if checks[1].Err != nil || checks[1].ID != "customfield_10100" {
+		t.Errorf("Expected the epic link to resolve by name, got %+v", checks[1])
+	}
+	if checks[2].Err == nil {
+		t.Error("Expected the missing flagged field to fail")
+	}
+
+	bad, err := NewClient(server.URL, "bad", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	var statusErr *StatusError
+	if _, err := bad.CurrentUser(ctx); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 StatusError, got %v", err)
+	}
+}
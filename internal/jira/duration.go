@@ -0,0 +1,95 @@
+package jira
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Defaults for converting effort durations into days, matching Jira's time tracking defaults
+const (
+	DefaultHoursPerDay = 8
+	DefaultDaysPerWeek = 5
+)
+
+// Units of plain numbers in the effort field
+const (
+	EffortUnitDays   = "days"
+	EffortUnitHours  = "hours"
+	EffortUnitPoints = "points"
+)
+
+// durationPartPattern matches one part of a Jira-style duration such as "2w" or "1.5d"
+var durationPartPattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*([wdhm])`)
+
+// ParseEffortDays parses an effort value given as a number of days or as a
+// Jira-style duration, as accepted in the effort field
+func ParseEffortDays(s string, hoursPerDay, daysPerWeek float64) (float64, bool) {
+	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return f, true
+	}
+	return parseDurationDays(s, hoursPerDay, daysPerWeek)
+}
+
+// parseDurationDays converts a Jira-style duration like "2w 3d 4h" or "1.5d"
+// into days. Weeks are daysPerWeek days, hours are 1/hoursPerDay of a day and
+// m is minutes; zero values use the defaults. The whole string must be made
+// of duration parts.
+func parseDurationDays(s string, hoursPerDay, daysPerWeek float64) (float64, bool) {
+	if hoursPerDay <= 0 {
+		hoursPerDay = DefaultHoursPerDay
+	}
+	if daysPerWeek <= 0 {
+		daysPerWeek = DefaultDaysPerWeek
+	}
+
+	parts := durationPartPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(parts) == 0 {
+		return 0, false
+	}
+
+	var days float64
+	last := 0
+	for _, p := range parts {
+		if strings.TrimSpace(s[last:p[0]]) != "" {
+			return 0, false
+		}
+		last = p[1]
+
+		n, err := strconv.ParseFloat(s[p[2]:p[3]], 64)
+		if err != nil {
+			return 0, false
+		}
+		switch strings.ToLower(s[p[4]:p[5]]) {
+		case "w":
+			days += n * daysPerWeek
+		case "d":
+			days += n
+		case "h":
+			days += n / hoursPerDay
+		case "m":
+			days += n / hoursPerDay / 60
+		}
+	}
+	if strings.TrimSpace(s[last:]) != "" {
+		return 0, false
+	}
+	return days, true
+}
+
+// effortUnitDays returns how many days one unit of a plain effort number is:
+// 1 for days, 1/hoursPerDay for hours and daysPerPoint (default 1) for points
+func effortUnitDays(unit string, hoursPerDay, daysPerPoint float64) float64 {
+	switch unit {
+	case EffortUnitHours:
+		if hoursPerDay <= 0 {
+			hoursPerDay = DefaultHoursPerDay
+		}
+		return 1 / hoursPerDay
+	case EffortUnitPoints:
+		if daysPerPoint > 0 {
+			return daysPerPoint
+		}
+	}
+	return 1
+}
@@ -0,0 +1,216 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+const (
+	// defaultEpicBatchSize is the number of keys per "key in (...)" query,
+	// unless ClientOptions.EpicBatchSize says otherwise
+	defaultEpicBatchSize = 50
+	// bulkFetchLimit is the most keys /rest/api/3/issue/bulkfetch accepts
+	bulkFetchLimit = 100
+	// epicFetchWorkers bounds the number of concurrent epic queries
+	epicFetchWorkers = 4
+)
+
+// epicKeys returns the distinct epic links of the tickets, sorted
+func epicKeys(tickets []Ticket) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, t := range tickets {
+		if t.EpicLink != "" && !seen[t.EpicLink] {
+			seen[t.EpicLink] = true
+			keys = append(keys, t.EpicLink)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keyChunks splits keys into chunks of at most size keys whose "key in
+// (...)" query is at most maxJQL long, if maxJQL is positive. A key that is
+// too long on its own still gets a chunk.
+func keyChunks(keys []string, size, maxJQL int) [][]string {
+	var chunks [][]string
+	start, length := 0, len(keysJQL(nil))
+	for i, key := range keys {
+		added := len(key)
+		if i > start {
+			added++ // The comma
+		}
+		if i > start && (i-start == size || maxJQL > 0 && length+added > maxJQL) {
+			chunks = append(chunks, keys[start:i])
+			start, length, added = i, len(keysJQL(nil)), len(key)
+		}
+		length += added
+	}
+	if start < len(keys) {
+		chunks = append(chunks, keys[start:])
+	}
+	return chunks
+}
+
+// keysJQL returns the query for the issues with the keys
+func keysJQL(keys []string) string {
+	return fmt.Sprintf("key in (%s)", strings.Join(keys, ","))
+}
+
+// fetchEpics loads epic details in chunks, querying several chunks at once.
+// Epics from chunks that succeed are returned even when others fail; the
+// failures are recorded with the epics they lost, and joined into the
+// returned error.
+func (c *Client) fetchEpics(ctx context.Context, keys []string) (map[string]Ticket, error) {
+	chunks := keyChunks(keys, c.epicBatchSize, c.maxJQLLength)
+
+	// Epics carry their own estimate, deadline and cross-epic links
+	fields := []string{"summary", "issuetype", "status", "issuelinks", "duedate"}
+	if c.effortCustomFieldID != "" {
+		fields = append(fields, c.effortCustomFieldID)
+	}
+	for _, field := range []string{c.targetStartField, c.targetEndField} {
+		if field != "" {
+			fields = append(fields, c.extraFieldID(field))
+		}
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		errs  = make([]error, len(chunks))
+		epics = make(map[string]Ticket, len(keys))
+	)
+	sem := make(chan struct{}, epicFetchWorkers)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			issues, err := c.fetchIssues(ctx, chunk, fields)
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs[i] = err
+			fetched := make(map[string]bool, len(issues))
+			for _, e := range issues {
+				fetched[e.Key] = true
+				if c.checkReadable(e) {
+					epics[e.Key] = c.epicFromIssue(e)
+				}
+			}
+			if err != nil {
+				var lost []string
+				for _, key := range chunk {
+					if !fetched[key] {
+						lost = append(lost, key)
+					}
+				}
+				c.problems.fail(FetchFailure{What: "epic details", Keys: lost, Err: err})
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	return epics, errors.Join(errs...)
+}
+
+// fetchIssues loads the issues with the keys, using the bulk fetch endpoint
+// on Cloud and a "key in (...)" search elsewhere. Issues that do not exist
+// are left out.
+func (c *Client) fetchIssues(ctx context.Context, keys []string, fields []string) ([]onpremise.Issue, error) {
+	if c.apiVersion == APIVersion3 && !c.noBulkFetch.Load() {
+		issues, err := c.bulkFetch(ctx, keys, fields)
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			return issues, err
+		}
+		c.noBulkFetch.Store(true) // Not available on this server, so search
+	}
+	return c.searchKeys(ctx, keys, fields)
+}
+
+// searchKeys searches for the issues with the keys. When Jira rejects the
+// query, which happens when it is too long for the server or names an issue
+// that was deleted, the keys are split in halves that are searched on their
+// own, so only the offending key is lost.
+func (c *Client) searchKeys(ctx context.Context, keys []string, fields []string) ([]onpremise.Issue, error) {
+	issues, err := c.search(ctx, keysJQL(keys), fields, "")
+	if err == nil {
+		return issues, nil
+	}
+
+	var statusErr *StatusError
+	rejected := errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusBadRequest || statusErr.StatusCode == http.StatusRequestURITooLong)
+	if !rejected || len(keys) == 1 {
+		if len(keys) == 1 {
+			return issues, fmt.Errorf("epic %s: %w", keys[0], err)
+		}
+		return issues, fmt.Errorf("epics %s..%s: %w", keys[0], keys[len(keys)-1], err)
+	}
+
+	half := len(keys) / 2
+	first, firstErr := c.searchKeys(ctx, keys[:half], fields)
+	second, secondErr := c.searchKeys(ctx, keys[half:], fields)
+	return append(first, second...), errors.Join(firstErr, secondErr)
+}
+
+// bulkFetchResult is the response of /rest/api/3/issue/bulkfetch
+type bulkFetchResult struct {
+	Issues []onpremise.Issue `json:"issues"`
+}
+
+// bulkFetch loads the issues with the keys from the Cloud bulk fetch
+// endpoint, which takes keys in the request body instead of a JQL query
+func (c *Client) bulkFetch(ctx context.Context, keys []string, fields []string) ([]onpremise.Issue, error) {
+	var all []onpremise.Issue
+	for start := 0; start < len(keys); start += bulkFetchLimit {
+		batch := keys[start:min(start+bulkFetchLimit, len(keys))]
+		body := map[string]interface{}{"issueIdsOrKeys": batch, "fields": fields}
+		req, err := c.onpremiseClient.NewRequest(ctx, http.MethodPost, "rest/api/3/issue/bulkfetch", body)
+		if err != nil {
+			return all, err
+		}
+
+		var result bulkFetchResult
+		resp, err := c.onpremiseClient.Do(req, &result)
+		if err != nil {
+			return all, responseError(resp, onpremise.NewJiraError(resp, err), "")
+		}
+		all = append(all, result.Issues...)
+	}
+	return all, nil
+}
+
+// epicFromIssue converts an epic search result into a ticket. Missing effort is
+// normal for epics, so unlike regular tickets it is not warned about.
+func (c *Client) epicFromIssue(e onpremise.Issue) Ticket {
+	effortDays, _ := c.effortDays(e.Fields.Unknowns)
+	status, statusCategory := readableStatus(e.Fields)
+	raw := c.rawFields(e.Fields)
+	return Ticket{
+		Key:            e.Key,
+		Summary:        readableSummary(e),
+		Link:           e.Self,
+		IssueType:      e.Fields.Type.Name,
+		Status:         status,
+		StatusCategory: statusCategory,
+		EffortDays:     effortDays,
+		DependencyKeys: c.dependencyKeys(e.Fields.IssueLinks),
+		DueDate:        c.dateInLocation(time.Time(e.Fields.Duedate)),
+		TargetStart:    c.dateField(raw, c.targetStartField),
+		TargetEnd:      c.dateField(raw, c.targetEndField),
+	}
+}
@@ -0,0 +1,205 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEpicServer answers "key in (...)" searches with one issue per key and
+// fails any query that mentions failKey
+func newEpicServer(failKey string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jql := r.URL.Query().Get("jql")
+		if strings.Contains(jql, failKey) {
+			http.Error(w, `{"errorMessages":["boom"]}`, http.StatusInternalServerError)
+			return
+		}
+
+		list := strings.TrimSuffix(strings.TrimPrefix(jql, "key in ("), ")")
+		var issues []map[string]interface{}
+		for _, key := range strings.Split(list, ",") {
+			issues = append(issues, map[string]interface{}{
+				"key": key,
+				"fields": map[string]interface{}{
+					"summary": "Summary of " + key,
+					"status":  map[string]interface{}{"name": "Open", "statusCategory": map[string]interface{}{"key": "new"}},
+					"duedate": "2026-03-31",
+					"issuelinks": []map[string]interface{}{
+						{"type": map[string]interface{}{"name": "Dependent"}, "outwardIssue": map[string]interface{}{"key": "EPIC-000"}},
+					},
+				},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues, "total": len(issues)})
+	}))
+}
+
+func TestFetchEpics(t *testing.T) {
+	server := newEpicServer("EPIC-FAIL")
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "10100", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	var keys []string
+	for i := 0; i < 3*defaultEpicBatchSize; i++ {
+		keys = append(keys, fmt.Sprintf("EPIC-%03d", i))
+	}
+
+	epics, err := client.fetchEpics(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("fetchEpics failed: %v", err)
+	}
+	if len(epics) != len(keys) {
+		t.Fatalf("Expected %d epics, got %d", len(keys), len(epics))
+	}
+	epic := epics["EPIC-123"]
+	if epic.Summary != "Summary of EPIC-123" {
+		t.Errorf("Unexpected epic: %+v", epic)
+	}
+	if epic.DueDate.Format("2006-01-02") != "2026-03-31" {
+		t.Errorf("Expected due date 2026-03-31, got %v", epic.DueDate)
+	}
+	if len(epic.DependencyKeys) != 1 || epic.DependencyKeys[0] != "EPIC-000" {
+		t.Errorf("Expected a dependency on EPIC-000, got %v", epic.DependencyKeys)
+	}
+
+	// A failing chunk is reported while the other chunks still load
+	epics, err = client.fetchEpics(context.Background(), append(keys, "EPIC-FAIL"))
+	if err == nil || !strings.Contains(err.Error(), "EPIC-FAIL") {
+		t.Errorf("Expected an error naming the failed chunk, got %v", err)
+	}
+	if len(epics) != 3*defaultEpicBatchSize {
+		t.Errorf("Expected the %d epics from healthy chunks, got %d", 3*defaultEpicBatchSize, len(epics))
+	}
+	failures := client.FetchFailures()
+	if len(failures) != 1 || fmt.Sprint(failures[0].Keys) != "[EPIC-FAIL]" {
+		t.Errorf("Expected a failure recording the lost EPIC-FAIL, got %v", failures)
+	}
+}
+
+func TestKeyChunks(t *testing.T) {
+	keys := []string{"A-1", "A-2", "A-3", "A-4", "A-5"}
+
+	if got := keyChunks(keys, 2, 0); fmt.Sprint(got) != "[[A-1 A-2] [A-3 A-4] [A-5]]" {
+		t.Errorf("chunks of 2 = %v", got)
+	}
+	// "key in (A-1,A-2)" is 16 characters, one more key makes 20
+	if got := keyChunks(keys, 50, 19); fmt.Sprint(got) != "[[A-1 A-2] [A-3 A-4] [A-5]]" {
+		t.Errorf("chunks of at most 19 characters = %v", got)
+	}
+	// A key longer than the limit on its own still gets fetched
+	if got := keyChunks(keys, 50, 5); len(got) != len(keys) {
+		t.Errorf("expected one chunk per key, got %v", got)
+	}
+	if got := keyChunks(nil, 50, 0); len(got) != 0 {
+		t.Errorf("expected no chunks, got %v", got)
+	}
+}
+
+func TestFetchEpics_SplitsRejectedQueries(t *testing.T) {
+	epicServer := newEpicServer("never")
+	defer epicServer.Close()
+
+	// Rejects queries naming a deleted epic, or longer than 60 characters
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jql := r.URL.Query().Get("jql")
+		switch {
+		case strings.Contains(jql, "EPIC-GONE"):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorMessages": ["An issue with key 'EPIC-GONE' does not exist for field 'key'."]}`))
+		case len(jql) > 60:
+			w.WriteHeader(http.StatusRequestURITooLong)
+		default:
+			epicServer.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{EpicBatchSize: 20})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	var keys []string
+	for i := 0; i < 20; i++ {
+		keys = append(keys, fmt.Sprintf("EPIC-%03d", i))
+	}
+
+	epics, err := client.fetchEpics(context.Background(), append(keys, "EPIC-GONE"))
+	if len(epics) != len(keys) {
+		t.Errorf("expected all %d existing epics, got %d", len(keys), len(epics))
+	}
+	if err == nil || !strings.Contains(err.Error(), "epic EPIC-GONE:") {
+		t.Errorf("expected an error naming only the deleted epic, got %v", err)
+	}
+}
+
+func TestFetchEpics_BulkFetch(t *testing.T) {
+	var bulkRequests, searches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/issue/bulkfetch" {
+			searches++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{}, "isLast": true})
+			return
+		}
+		bulkRequests++
+		var body struct {
+			Keys []string `json:"issueIdsOrKeys"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var issues []map[string]interface{}
+		for _, key := range body.Keys {
+			issues = append(issues, map[string]interface{}{"key": key, "fields": map[string]interface{}{
+				"summary": "Summary of " + key,
+				"status":  map[string]interface{}{"name": "Open", "statusCategory": map[string]interface{}{"key": "new"}},
+			}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{APIVersion: APIVersion3, EpicBatchSize: 150})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	var keys []string
+	for i := 0; i < 150; i++ {
+		keys = append(keys, fmt.Sprintf("EPIC-%03d", i))
+	}
+	epics, err := client.fetchEpics(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("fetchEpics failed: %v", err)
+	}
+	if len(epics) != len(keys) || epics["EPIC-149"].Summary != "Summary of EPIC-149" {
+		t.Errorf("expected %d epics from bulk fetch, got %d", len(keys), len(epics))
+	}
+	if bulkRequests != 2 || searches != 0 {
+		t.Errorf("expected 2 bulk requests of at most 100 keys and no searches, got %d and %d", bulkRequests, searches)
+	}
+
+	// Servers without the endpoint fall back to searching
+	epicServer := newEpicServer("never")
+	defer epicServer.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/3/issue/bulkfetch" {
+			http.NotFound(w, r)
+			return
+		}
+		epicServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer fallback.Close()
+	client, err = NewClient(fallback.URL, "pat", "", "", ClientOptions{APIVersion: APIVersion3})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if epics, err = client.fetchEpics(context.Background(), keys[:3]); err != nil || len(epics) != 3 {
+		t.Errorf("expected 3 epics from the search fallback, got %d (%v)", len(epics), err)
+	}
+}
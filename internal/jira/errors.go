@@ -0,0 +1,128 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// AuthError is returned when Jira rejects the credentials (HTTP 401) or the
+// user may not see what was requested (HTTP 403)
+type AuthError struct {
+	*StatusError
+}
+
+func (e *AuthError) Error() string {
+	return "not authorized: " + e.StatusError.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.StatusError
+}
+
+// NotFoundError is returned when an issue, user or endpoint does not exist
+// (HTTP 404)
+type NotFoundError struct {
+	*StatusError
+}
+
+func (e *NotFoundError) Error() string {
+	return "not found: " + e.StatusError.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.StatusError
+}
+
+// RateLimitError is returned when Jira throttles requests (HTTP 429)
+type RateLimitError struct {
+	*StatusError
+	RetryAfter time.Duration // Wait Jira asked for, zero if it did not say
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.StatusError)
+	}
+	return "rate limited: " + e.StatusError.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.StatusError
+}
+
+// JQLSyntaxError is returned when Jira rejects a search query (HTTP 400).
+// The StatusError's Messages say what is wrong with it.
+type JQLSyntaxError struct {
+	*StatusError
+	JQL string
+}
+
+func (e *JQLSyntaxError) Error() string {
+	if len(e.Messages) > 0 {
+		return "invalid JQL: " + strings.Join(e.Messages, "; ")
+	}
+	return "invalid JQL: " + e.StatusError.Error()
+}
+
+func (e *JQLSyntaxError) Unwrap() error {
+	return e.StatusError
+}
+
+// responseError classifies the error of a Jira request by the response's
+// status code. err should already be decoded by onpremise.NewJiraError, so
+// Jira's error messages are kept. jql is the query of searches, else "".
+// Errors without a response, such as network failures, are returned as is.
+func responseError(resp *onpremise.Response, err error, jql string) error {
+	if resp == nil || resp.Response == nil {
+		return err
+	}
+
+	statusErr := &StatusError{StatusCode: resp.StatusCode, Err: err}
+	var jiraErr *onpremise.Error
+	if errors.As(err, &jiraErr) {
+		statusErr.Messages = append(statusErr.Messages, jiraErr.ErrorMessages...)
+		fields := make([]string, 0, len(jiraErr.Errors))
+		for field := range jiraErr.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			statusErr.Messages = append(statusErr.Messages, field+": "+jiraErr.Errors[field])
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{statusErr}
+	case http.StatusNotFound:
+		return &NotFoundError{statusErr}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{StatusError: statusErr, RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	case http.StatusBadRequest:
+		if jql != "" {
+			return &JQLSyntaxError{StatusError: statusErr, JQL: jql}
+		}
+	}
+	return statusErr
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now).Round(time.Second)
+	}
+	return 0
+}
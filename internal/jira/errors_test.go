@@ -0,0 +1,82 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("jql") {
+		case "project = SECRET":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case "project = BUSY":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "project = = WEB":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorMessages": ["Error in the JQL Query: '=' is unexpected."], "errors": {}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+
+	_, _, err = client.GetTickets(ctx, "project = SECRET")
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an AuthError, got %v", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Errorf("expected typed errors to wrap a StatusError, got %v", err)
+	}
+
+	_, _, err = client.GetTickets(ctx, "project = BUSY")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 30*time.Second {
+		t.Errorf("expected a RateLimitError retrying after 30s, got %v", err)
+	}
+
+	_, _, err = client.GetTickets(ctx, "project = = WEB")
+	var jqlErr *JQLSyntaxError
+	if !errors.As(err, &jqlErr) || jqlErr.JQL != "project = = WEB" {
+		t.Fatalf("expected a JQLSyntaxError, got %v", err)
+	}
+	if want := "invalid JQL: Error in the JQL Query: '=' is unexpected."; jqlErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", jqlErr.Error(), want)
+	}
+
+	_, err = client.ServerInfo(ctx)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected a NotFoundError, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"Mon, 02 Jun 2025 12:01:30 GMT": 90 * time.Second,
+		"Mon, 02 Jun 2025 11:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for header, want := range tests {
+		if got := retryAfter(header, now); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
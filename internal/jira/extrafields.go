@@ -0,0 +1,179 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// legacySprintName extracts the name from a Jira Server sprint value such as
+// "com.atlassian.greenhopper.service.sprint.Sprint@1f[id=1,state=ACTIVE,name=Sprint 1,...]"
+var legacySprintName = regexp.MustCompile(`\[.*\bname=([^,\]]*)`)
+
+// resolveExtraFields maps each requested extra field, given as a field ID
+// (e.g. "components" or "customfield_12345") or a display name (e.g. "Team"),
+// to its field ID
+func (c *Client) resolveExtraFields(names map[string]string) error {
+	c.extraFieldIDs = make(map[string]string, len(c.extraFields))
+	for _, field := range c.extraFields {
+		id := expandNumericFieldID(field)
+		if _, ok := names[id]; !ok {
+			id = ""
+			for fieldID, fieldName := range names {
+				if strings.EqualFold(fieldName, field) {
+					id = fieldID
+					break
+				}
+			}
+			if id == "" {
+				return fmt.Errorf("no field '%s' found in Jira", field)
+			}
+		}
+		c.extraFieldIDs[field] = id
+	}
+	return nil
+}
+
+// extraFieldID returns the resolved ID of an extra field, or the field as
+// given when it could not be resolved (e.g. nothing matched the query)
+func (c *Client) extraFieldID(field string) string {
+	if id, ok := c.extraFieldIDs[field]; ok {
+		return id
+	}
+	return expandNumericFieldID(field)
+}
+
+// rawFields returns the fields of an issue in their JSON shape, keyed by
+// field ID, or nil when no extra fields are requested. Standard fields are
+// decoded into typed struct fields, so this is how all fields are looked up
+// by ID.
+func (c *Client) rawFields(fields *onpremise.IssueFields) map[string]interface{} {
+	if len(c.extraFields) == 0 || fields == nil {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	return raw
+}
+
+// extraFieldValues returns the display values of the extra fields in raw
+// (see rawFields), keyed by the field as requested. Unset fields are left out.
+func (c *Client) extraFieldValues(raw map[string]interface{}) map[string]string {
+	if raw == nil {
+		return nil
+	}
+	values := make(map[string]string, len(c.extraFields))
+	for _, field := range c.extraFields {
+		if value := fieldString(raw[c.extraFieldID(field)]); value != "" {
+			values[field] = value
+		}
+	}
+	return values
+}
+
+// coAssignees returns the display names of the users in the co-assignee
+// field, leaving out the assignee
+func (c *Client) coAssignees(raw map[string]interface{}, assignee string) []string {
+	if c.coAssigneeField == "" || raw == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range userNames(raw[c.extraFieldID(c.coAssigneeField)]) {
+		if name != assignee && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// reviewer returns the display name of the first user in the reviewer field
+func (c *Client) reviewer(raw map[string]interface{}) string {
+	if c.reviewerField == "" || raw == nil {
+		return ""
+	}
+	if names := userNames(raw[c.extraFieldID(c.reviewerField)]); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// userNames returns the display names, or else the usernames, of the users in
+// a user or multi-user field value. Unlike fieldString, it keeps display
+// names apart, since they may contain commas.
+func userNames(val interface{}) []string {
+	users, ok := val.([]interface{})
+	if !ok {
+		users = []interface{}{val} // Single-user field
+	}
+
+	var names []string
+	for _, u := range users {
+		user, ok := u.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := user["displayName"].(string)
+		if name == "" {
+			name, _ = user["name"].(string)
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fieldString renders a field value for display. Options, users, versions and
+// similar objects use their value or name; lists are joined with ", ".
+func fieldString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		if m := legacySprintName.FindStringSubmatch(v); m != nil {
+			return m[1]
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "displayName", "key"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+		return ""
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if s := fieldString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// expandNumericFieldID turns a bare numeric ID into its customfield_ form,
+// leaving names and other IDs unchanged
+func expandNumericFieldID(field string) string {
+	if _, err := strconv.Atoi(field); err == nil {
+		return "customfield_" + field
+	}
+	return field
+}
@@ -0,0 +1,114 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+func TestResolveExtraFields(t *testing.T) {
+	names := map[string]string{
+		"components":        "Component/s",
+		"customfield_12345": "Team",
+	}
+
+	c := &Client{extraFields: []string{"components", "team", "12345"}}
+	if err := c.resolveExtraFields(names); err != nil {
+		t.Fatalf("resolveExtraFields failed: %v", err)
+	}
+	for field, want := range map[string]string{"components": "components", "team": "customfield_12345", "12345": "customfield_12345"} {
+		if got := c.extraFieldID(field); got != want {
+			t.Errorf("extraFieldID(%q) = %q, want %q", field, got, want)
+		}
+	}
+
+	c = &Client{extraFields: []string{"Squad"}}
+	if err := c.resolveExtraFields(names); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestTicketsFromIssues_ExtraFields(t *testing.T) {
+	var issue onpremise.Issue
+	if err := json.Unmarshal([]byte(`{
+		"key": "TASK-1",
+		"fields": {
+			"summary": "Checkout",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"components": [{"name": "Backend"}, {"name": "API"}],
+			"customfield_12345": {"value": "Payments"},
+			"customfield_10020": ["com.atlassian.greenhopper.service.sprint.Sprint@1f[id=7,state=ACTIVE,name=Sprint 7,startDate=<null>]"],
+			"customfield_10030": 3
+		}
+	}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{
+		extraFields:   []string{"components", "team", "customfield_10020", "customfield_10030", "customfield_10040"},
+		extraFieldIDs: map[string]string{"team": "customfield_12345"},
+	}
+	tickets := c.ticketsFromIssues([]onpremise.Issue{issue})
+
+	want := map[string]string{
+		"components":        "Backend, API",
+		"team":              "Payments",
+		"customfield_10020": "Sprint 7",
+		"customfield_10030": "3",
+	}
+	got := tickets[0].Fields
+	if len(got) != len(want) {
+		t.Errorf("Fields = %v, want %v", got, want)
+	}
+	for field, value := range want {
+		if got[field] != value {
+			t.Errorf("Fields[%q] = %q, want %q", field, got[field], value)
+		}
+	}
+}
+
+func TestTicketsFromIssues_CoAssignees(t *testing.T) {
+	var issues []onpremise.Issue
+	if err := json.Unmarshal([]byte(`[{
+		"key": "TASK-1",
+		"fields": {
+			"summary": "Pairing",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"assignee": {"name": "ada", "displayName": "Ada Lovelace"},
+			"customfield_10050": [
+				{"name": "ada", "displayName": "Ada Lovelace"},
+				{"name": "doej", "displayName": "Doe, Jane"},
+				{"name": "bob"}
+			]
+		}
+	}, {
+		"key": "TASK-2",
+		"fields": {
+			"summary": "Single user field",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"customfield_10050": {"name": "doej", "displayName": "Doe, Jane"}
+		}
+	}]`), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{
+		extraFields:     []string{"Helpers"},
+		extraFieldIDs:   map[string]string{"Helpers": "customfield_10050"},
+		coAssigneeField: "Helpers",
+	}
+	tickets := c.ticketsFromIssues(issues)
+
+	// Display names may contain commas, so they are kept apart
+	if got := fmt.Sprintf("%q", tickets[0].CoAssignees); got != `["Doe, Jane" "bob"]` {
+		t.Errorf("TASK-1 co-assignees = %s, want the users other than the assignee", got)
+	}
+	if got := fmt.Sprintf("%q", tickets[1].CoAssignees); got != `["Doe, Jane"]` {
+		t.Errorf("TASK-2 co-assignees = %s, want the single user", got)
+	}
+}
@@ -0,0 +1,125 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// fieldProbeResult is a single-issue search response including the field-name map
+type fieldProbeResult struct {
+	Issues []onpremise.Issue `json:"issues"`
+	Names  map[string]string `json:"names"`
+}
+
+// probeFields fetches one issue matching jql with all fields and expand=names,
+// returning the field ID -> display name map and the sample issue (if any)
+func (c *Client) probeFields(ctx context.Context, jql string) (map[string]string, *onpremise.Issue, error) {
+	query := url.Values{}
+	query.Set("jql", jql)
+	query.Set("fields", "*all")
+	query.Set("expand", "names")
+	query.Set("maxResults", "1")
+
+	endpoint := "rest/api/2/search"
+	if c.apiVersion == APIVersion3 {
+		endpoint = "rest/api/3/search/jql"
+	}
+
+	req, err := c.onpremiseClient.NewRequest(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result fieldProbeResult
+	resp, err := c.onpremiseClient.Do(req, &result)
+	if err != nil {
+		return nil, nil, responseError(resp, onpremise.NewJiraError(resp, err), jql)
+	}
+
+	var sample *onpremise.Issue
+	if len(result.Issues) > 0 {
+		sample = &result.Issues[0]
+	}
+	return result.Names, sample, nil
+}
+
+// resolveFields validates the configured custom field IDs against the names
+// Jira reports for the query. Fields configured by name are resolved to their
+// IDs, replacing (and warning about) a mismatching configured ID.
+func (c *Client) resolveFields(ctx context.Context, jql string) error {
+	names, sample, err := c.probeFields(ctx, jql)
+	if err != nil {
+		return fmt.Errorf("resolving custom field names: %w", err)
+	}
+	if len(names) == 0 {
+		// Nothing matched the query, so there is nothing to validate against
+		return nil
+	}
+
+	c.effortCustomFieldID, err = resolveField(names, c.effortCustomFieldID, c.effortFieldName, "effort_custom_field_id")
+	if err != nil {
+		return err
+	}
+	if c.epicLinkCustomFieldID != "" || c.epicLinkFieldName != "" {
+		c.epicLinkCustomFieldID, err = resolveField(names, c.epicLinkCustomFieldID, c.epicLinkFieldName, "epic_link_custom_field_id")
+		if err != nil {
+			return err
+		}
+	}
+	if err := c.resolveExtraFields(names); err != nil {
+		return err
+	}
+
+	if sample == nil || sample.Fields == nil {
+		return nil
+	}
+
+	// Check that the sample values have the shape we expect
+	if val, ok := sample.Fields.Unknowns[c.effortCustomFieldID]; ok && val != nil {
+		if _, numeric := c.effortDays(sample.Fields.Unknowns); !numeric {
+			return fmt.Errorf("%s is '%s', not an effort field: issue %s has a non-numeric value (%T)",
+				c.effortCustomFieldID, names[c.effortCustomFieldID], sample.Key, val)
+		}
+	}
+	if c.epicLinkCustomFieldID != "" {
+		if val, ok := sample.Fields.Unknowns[c.epicLinkCustomFieldID]; ok && val != nil {
+			if _, isKey := val.(string); !isKey {
+				return fmt.Errorf("%s is '%s', not an epic link field: issue %s has a non-key value (%T)",
+					c.epicLinkCustomFieldID, names[c.epicLinkCustomFieldID], sample.Key, val)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveField returns the field ID to use given a configured ID and/or name
+func resolveField(names map[string]string, id, name, setting string) (string, error) {
+	if name != "" {
+		for fieldID, fieldName := range names {
+			if strings.EqualFold(fieldName, name) {
+				if id != "" && id != fieldID {
+					term.Warnf("%s is set to %s ('%s'), but field '%s' is %s; using %s",
+						setting, id, names[id], name, fieldID, fieldID)
+				}
+				return fieldID, nil
+			}
+		}
+		return "", fmt.Errorf("no field named '%s' found in Jira", name)
+	}
+
+	if id == "" {
+		return "", nil
+	}
+	if _, ok := names[id]; !ok {
+		return "", fmt.Errorf("%s %s does not exist in Jira (or is not visible to this user)", setting, id)
+	}
+	return id, nil
+}
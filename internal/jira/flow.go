@@ -0,0 +1,89 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// DefaultDoneStatuses are the statuses that mark work as finished
+var DefaultDoneStatuses = []string{"Done", "Closed", "Resolved"}
+
+// FlowRecord captures the lifecycle timestamps of an issue
+type FlowRecord struct {
+	Key       string
+	Summary   string
+	IssueType string
+	Assignee  string
+	Created   time.Time
+	Started   time.Time // First transition to an in-progress status (zero if never started)
+	Resolved  time.Time // Resolution date or last transition to a done status (zero if open)
+}
+
+// LeadTime is the time from creation to resolution
+func (r FlowRecord) LeadTime() (time.Duration, bool) {
+	if r.Created.IsZero() || r.Resolved.IsZero() {
+		return 0, false
+	}
+	return r.Resolved.Sub(r.Created), true
+}
+
+// CycleTime is the time from starting work to resolution
+func (r FlowRecord) CycleTime() (time.Duration, bool) {
+	if r.Started.IsZero() || r.Resolved.IsZero() || r.Resolved.Before(r.Started) {
+		return 0, false
+	}
+	return r.Resolved.Sub(r.Started), true
+}
+
+// GetFlowRecords fetches the issues matching jql with their changelogs and
+// derives creation, start and resolution times for each
+func (c *Client) GetFlowRecords(ctx context.Context, jql string) ([]FlowRecord, error) {
+	if c.onpremiseClient == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	fields := []string{"summary", "issuetype", "assignee", "status", "created", "resolutiondate"}
+	issues, err := c.search(ctx, jql, fields, "changelog")
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]FlowRecord, 0, len(issues))
+	for _, i := range issues {
+		if !c.checkReadable(i) {
+			continue
+		}
+		changes := statusChanges(i.Changelog)
+
+		resolved := time.Time(i.Fields.Resolutiondate)
+		if resolved.IsZero() {
+			resolved = lastTransitionTo(changes, c.doneStatuses)
+		}
+
+		records = append(records, FlowRecord{
+			Key:       i.Key,
+			Summary:   readableSummary(i),
+			IssueType: i.Fields.Type.Name,
+			Assignee:  assigneeName(i.Fields.Assignee),
+			Created:   c.inLocation(time.Time(i.Fields.Created)),
+			Started:   c.inLocation(firstTransitionTo(changes, c.inProgressStatuses)),
+			Resolved:  c.inLocation(resolved),
+		})
+	}
+
+	return records, nil
+}
+
+// assigneeName returns the display name of a user, falling back to the username
+func assigneeName(user *onpremise.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Name
+}
@@ -0,0 +1,96 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// FollowUpRule adds standard downstream work, such as QA or deployment, that
+// the team does not ticket separately after each ticket it matches
+type FollowUpRule struct {
+	// IssueTypes the rule applies to, matched case-insensitively; empty
+	// matches all
+	IssueTypes []string
+	// Field is a key of Ticket.Fields, so it must also be requested as
+	// ClientOptions.ExtraFields. When set, only tickets with one of Values
+	// match, case-insensitively; a list field matches on any of its values.
+	Field  string
+	Values []string
+	// Tasks run one after the other once the ticket is done
+	Tasks []FollowUpTask
+}
+
+// FollowUpTask is a task added by a FollowUpRule
+type FollowUpTask struct {
+	Title      string
+	EffortDays float64
+	Assignee   string // Optional
+}
+
+// Matches reports whether the rule applies to a ticket
+func (r FollowUpRule) Matches(t Ticket) bool {
+	if len(r.IssueTypes) > 0 && !slices.ContainsFunc(r.IssueTypes, func(s string) bool { return strings.EqualFold(s, t.IssueType) }) {
+		return false
+	}
+	if r.Field == "" {
+		return true
+	}
+	value := t.Fields[r.Field]
+	for _, want := range r.Values {
+		if strings.EqualFold(want, value) {
+			return true
+		}
+		for _, part := range strings.Split(value, ", ") {
+			if strings.EqualFold(want, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddFollowUpTasks returns the tickets with the tasks of each matching rule
+// after them. Each rule's tasks form a chain starting from the ticket; they
+// are titled "<title>: <summary>" and keyed "<key> <title>".
+func AddFollowUpTasks(tickets []Ticket, rules []FollowUpRule) []Ticket {
+	result := make([]Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		result = append(result, t)
+		for _, rule := range rules {
+			if !rule.Matches(t) {
+				continue
+			}
+			previous := t.Key
+			for _, task := range rule.Tasks {
+				f := followUp(t, t.Key+" "+task.Title, task.Title+": "+t.Summary, task.Assignee, task.EffortDays, previous)
+				result = append(result, f)
+				previous = f.Key
+			}
+		}
+	}
+	return result
+}
+
+// followUp creates a task for work following a ticket. It is grouped like the
+// ticket, has no Jira link and is done when the ticket is.
+func followUp(t Ticket, key, summary, assignee string, effortDays float64, dependsOn string) Ticket {
+	f := t
+	f.Key = key
+	f.Summary = summary
+	f.Link = ""
+	f.Assignee = assignee
+	f.AssigneeID = ""
+	f.CoAssignees = nil
+	f.Reviewer = ""
+	f.IssueType = ""
+	f.EffortDays = effortDays
+	f.DependencyKeys = []string{dependsOn}
+	f.ActualStart = time.Time{}
+	f.Flagged = false
+	if !t.IsDone() {
+		f.Status = ""
+		f.StatusCategory = "new"
+	}
+	return f
+}
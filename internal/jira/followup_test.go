@@ -0,0 +1,44 @@
+package jira
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAddFollowUpTasks(t *testing.T) {
+	rules := []FollowUpRule{{
+		IssueTypes: []string{"story"},
+		Field:      "Components",
+		Values:     []string{"Payments"},
+		Tasks: []FollowUpTask{
+			{Title: "QA", EffortDays: 0.5, Assignee: "Tester"},
+			{Title: "Deploy", EffortDays: 0.25},
+		},
+	}}
+	tickets := []Ticket{
+		{Key: "PAY-1", Summary: "Refunds", IssueType: "Story", Fields: map[string]string{"Components": "Core, Payments"}},
+		{Key: "PAY-2", Summary: "Bug", IssueType: "Bug", Fields: map[string]string{"Components": "Payments"}},
+		{Key: "PAY-3", Summary: "Search", IssueType: "Story", Fields: map[string]string{"Components": "Search"}},
+		{Key: "PAY-4", Summary: "Shipped", IssueType: "Story", StatusCategory: "done", Fields: map[string]string{"Components": "payments"}},
+	}
+	got := AddFollowUpTasks(tickets, rules)
+
+	var keys []string
+	for _, t := range got {
+		keys = append(keys, t.Key)
+	}
+	if want := "[PAY-1 PAY-1 QA PAY-1 Deploy PAY-2 PAY-3 PAY-4 PAY-4 QA PAY-4 Deploy]"; fmt.Sprint(keys) != want {
+		t.Fatalf("Keys = %v, want %s", keys, want)
+	}
+
+	qa, deploy := got[1], got[2]
+	if qa.Summary != "QA: Refunds" || qa.Assignee != "Tester" || qa.EffortDays != 0.5 || fmt.Sprint(qa.DependencyKeys) != "[PAY-1]" {
+		t.Errorf("QA = %+v, want half a day for the tester after PAY-1", qa)
+	}
+	if deploy.Assignee != "" || deploy.EffortDays != 0.25 || fmt.Sprint(deploy.DependencyKeys) != "[PAY-1 QA]" {
+		t.Errorf("Deploy = %+v, want a quarter day after the QA task", deploy)
+	}
+	if qa.IsDone() || !got[6].IsDone() {
+		t.Error("Follow-ups should be done exactly when their ticket is")
+	}
+}
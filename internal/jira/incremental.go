@@ -0,0 +1,94 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// orderByPattern matches a trailing ORDER BY clause, which must stay at the
+// end of the query when conditions are added
+var orderByPattern = regexp.MustCompile(`(?is)\s+order\s+by\s+.*$`)
+
+// splitOrderBy separates the JQL conditions from a trailing ORDER BY clause
+func splitOrderBy(jql string) (conditions, orderBy string) {
+	loc := orderByPattern.FindStringIndex(jql)
+	if loc == nil {
+		return strings.TrimSpace(jql), ""
+	}
+	return strings.TrimSpace(jql[:loc[0]]), jql[loc[0]:]
+}
+
+// updatedSince is a JQL condition matching issues updated since t. A relative
+// window is used so the Jira user's time zone does not matter, with a minute
+// of margin since Jira compares at minute granularity.
+func updatedSince(t time.Time) string {
+	minutes := int(math.Ceil(time.Since(t).Minutes())) + 1
+	return fmt.Sprintf("updated >= -%dm", minutes)
+}
+
+// GetTicketsSince refreshes previous, the result of an earlier GetTickets for
+// the same query, by fetching only issues updated since then. Updated issues
+// that still match replace or extend previous, and previous tickets that were
+// updated but no longer match are dropped. Issues deleted from Jira are not
+// detected, so callers should still run a full GetTickets now and then.
+func (c *Client) GetTicketsSince(ctx context.Context, jql string, since time.Time, previous []Ticket) ([]Ticket, map[string]Ticket, error) {
+	if c.onpremiseClient == nil {
+		return nil, nil, fmt.Errorf("client not initialized")
+	}
+
+	if err := c.resolveFields(ctx, jql); err != nil {
+		return nil, nil, err
+	}
+
+	conditions, orderBy := splitOrderBy(jql)
+	window := updatedSince(since)
+
+	issues, err := c.search(ctx, fmt.Sprintf("(%s) AND %s%s", conditions, window, orderBy), c.ticketFields(), c.ticketExpand())
+	if err != nil {
+		return nil, nil, err
+	}
+	changed := c.ticketsFromIssues(issues)
+
+	// Updated issues outside the query; only their keys are needed
+	left, err := c.search(ctx, fmt.Sprintf("%s AND NOT (%s)", window, conditions), []string{"status"}, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	removed := make(map[string]bool, len(left))
+	for _, i := range left {
+		removed[i.Key] = true
+	}
+
+	tickets := mergeTickets(previous, changed, removed)
+	MergeAssignees(tickets)
+	return tickets, c.epicsFor(ctx, tickets), nil
+}
+
+// mergeTickets applies changed and removed tickets to previous, keeping the
+// previous order and appending new tickets at the end
+func mergeTickets(previous, changed []Ticket, removed map[string]bool) []Ticket {
+	byKey := make(map[string]Ticket, len(changed))
+	for _, t := range changed {
+		byKey[t.Key] = t
+	}
+
+	merged := make([]Ticket, 0, len(previous)+len(changed))
+	for _, t := range previous {
+		if update, ok := byKey[t.Key]; ok {
+			merged = append(merged, update)
+			delete(byKey, t.Key)
+		} else if !removed[t.Key] {
+			merged = append(merged, t)
+		}
+	}
+	for _, t := range changed {
+		if _, isNew := byKey[t.Key]; isNew {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
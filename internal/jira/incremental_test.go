@@ -0,0 +1,49 @@
+package jira
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSplitOrderBy(t *testing.T) {
+	tests := []struct {
+		jql, conditions, orderBy string
+	}{
+		{"project = PROJ", "project = PROJ", ""},
+		{"project = PROJ ORDER BY rank ASC", "project = PROJ", " ORDER BY rank ASC"},
+		{"project = PROJ\norder by created", "project = PROJ", "\norder by created"},
+		{`summary ~ "work order" AND project = PROJ`, `summary ~ "work order" AND project = PROJ`, ""},
+	}
+	for _, tt := range tests {
+		conditions, orderBy := splitOrderBy(tt.jql)
+		if conditions != tt.conditions || orderBy != tt.orderBy {
+			t.Errorf("splitOrderBy(%q) = %q, %q; want %q, %q", tt.jql, conditions, orderBy, tt.conditions, tt.orderBy)
+		}
+	}
+}
+
+func TestUpdatedSince(t *testing.T) {
+	if got := updatedSince(time.Now().Add(-90 * time.Minute)); got != "updated >= -92m" {
+		t.Errorf("updatedSince = %q, want updated >= -92m", got)
+	}
+}
+
+func TestMergeTickets(t *testing.T) {
+	previous := []Ticket{{Key: "A-1", Summary: "old"}, {Key: "A-2"}, {Key: "A-3"}}
+	changed := []Ticket{{Key: "A-4"}, {Key: "A-1", Summary: "new"}}
+	removed := map[string]bool{"A-2": true}
+
+	merged := mergeTickets(previous, changed, removed)
+
+	var keys []string
+	for _, m := range merged {
+		keys = append(keys, m.Key)
+	}
+	if got := fmt.Sprint(keys); got != "[A-1 A-3 A-4]" {
+		t.Fatalf("merged keys = %s, want [A-1 A-3 A-4]", got)
+	}
+	if merged[0].Summary != "new" {
+		t.Errorf("A-1 summary = %q, want the updated ticket", merged[0].Summary)
+	}
+}
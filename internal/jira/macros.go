@@ -0,0 +1,64 @@
+package jira
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// placeholderPattern matches {{name}} placeholders, allowing inner spaces
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// relativeDatePattern matches today, today-14d or today+2w
+var relativeDatePattern = regexp.MustCompile(`^today(?:([+-])(\d+)([dw]))?$`)
+
+// builtinMacros expand to JQL functions, so they follow the Jira user's view
+var builtinMacros = map[string]string{
+	"me":            "currentUser()",
+	"sprint.active": "openSprints()",
+	"sprint.future": "futureSprints()",
+	"sprint.closed": "closedSprints()",
+}
+
+// ExpandJQL replaces {{name}} placeholders in jql. Names are looked up in vars
+// first (case-insensitively, values inserted verbatim), then the built-ins:
+// {{me}}, {{sprint.active}}, {{sprint.future}}, {{sprint.closed}} and
+// {{today}} with an optional offset such as {{today-14d}} or {{today+2w}},
+// which expands to a quoted date relative to now. Unknown names are an error.
+func ExpandJQL(jql string, vars map[string]string, now time.Time) (string, error) {
+	var unknown []string
+	expanded := placeholderPattern.ReplaceAllStringFunc(jql, func(match string) string {
+		name := strings.ToLower(placeholderPattern.FindStringSubmatch(match)[1])
+
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if value, ok := builtinMacros[name]; ok {
+			return value
+		}
+		if m := relativeDatePattern.FindStringSubmatch(name); m != nil {
+			date := now
+			if m[1] != "" {
+				n, _ := strconv.Atoi(m[2])
+				if m[3] == "w" {
+					n *= 7
+				}
+				if m[1] == "-" {
+					n = -n
+				}
+				date = date.AddDate(0, 0, n)
+			}
+			return `"` + date.Format("2006-01-02") + `"`
+		}
+
+		unknown = append(unknown, match)
+		return match
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholder(s) %s", strings.Join(unknown, ", "))
+	}
+	return expanded, nil
+}
@@ -0,0 +1,45 @@
+package jira
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandJQL(t *testing.T) {
+	now := time.Date(2026, 3, 16, 10, 0, 0, 0, time.UTC)
+	vars := map[string]string{"project": "PROJ", "team": `"Core Team"`}
+
+	tests := []struct {
+		jql, want string
+	}{
+		{"project = {{project}}", "project = PROJ"},
+		{"project = {{ Project }} AND team = {{team}}", `project = PROJ AND team = "Core Team"`},
+		{"assignee = {{me}} AND sprint in {{sprint.active}}", "assignee = currentUser() AND sprint in openSprints()"},
+		{"updated >= {{today-14d}} AND duedate <= {{today+2w}}", `updated >= "2026-03-02" AND duedate <= "2026-03-30"`},
+		{"created < {{today}}", `created < "2026-03-16"`},
+		{"summary ~ \"{braces}\"", "summary ~ \"{braces}\""},
+	}
+	for _, tt := range tests {
+		got, err := ExpandJQL(tt.jql, vars, now)
+		if err != nil {
+			t.Errorf("ExpandJQL(%q) error: %v", tt.jql, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ExpandJQL(%q) = %q, want %q", tt.jql, got, tt.want)
+		}
+	}
+}
+
+func TestExpandJQL_Unknown(t *testing.T) {
+	if _, err := ExpandJQL("project = {{prjoect}} AND {{today-3m}}", nil, time.Now()); err == nil {
+		t.Fatal("expected an error for unknown placeholders")
+	}
+}
+
+func TestExpandJQL_VariablesOverrideBuiltins(t *testing.T) {
+	got, err := ExpandJQL("assignee = {{me}}", map[string]string{"me": "jdoe"}, time.Now())
+	if err != nil || got != "assignee = jdoe" {
+		t.Errorf("ExpandJQL = %q, %v; want assignee = jdoe", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/jiratest"
+)
+
+// seedPipeline returns a fake Jira with two pages of WEB stories in an epic,
+// one depending on the other
+func seedPipeline(t *testing.T) *jiratest.Server {
+	t.Helper()
+	srv := jiratest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.PageSize = 2
+	srv.AddField("customfield_10105", "Effort")
+	srv.AddField("customfield_10100", "Epic Link")
+	srv.AddIssues(
+		jiratest.Issue{Key: "WEB-10", Summary: "Checkout", IssueType: "Epic", DueDate: "2026-06-30"},
+		jiratest.Issue{Key: "WEB-1", Summary: "Cart", Assignee: "Ada", Fields: map[string]interface{}{"customfield_10105": 2, "customfield_10100": "WEB-10"}},
+		jiratest.Issue{Key: "WEB-2", Summary: "Payment", Assignee: "Linus", Status: "In Progress", StatusCategory: "indeterminate", DependsOn: []string{"WEB-1"}, Fields: map[string]interface{}{"customfield_10105": 3, "customfield_10100": "WEB-10"}},
+		jiratest.Issue{Key: "WEB-3", Summary: "Receipt", Labels: []string{"email"}, Fields: map[string]interface{}{"customfield_10105": 1, "customfield_10100": "WEB-10"}},
+	)
+	srv.SetQuery("project = WEB AND type != Epic", "WEB-1", "WEB-2", "WEB-3")
+	return srv
+}
+
+func TestGetTickets_FakeServer(t *testing.T) {
+	for _, apiVersion := range []string{APIVersion2, APIVersion3} {
+		srv := seedPipeline(t)
+		client, err := NewClient(srv.URL, "pat", "customfield_10105", "customfield_10100", ClientOptions{APIVersion: apiVersion})
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+
+		tickets, epics, err := client.GetTickets(context.Background(), "project = WEB AND type != Epic")
+		if err != nil {
+			t.Fatalf("API %s: GetTickets failed: %v", apiVersion, err)
+		}
+		if len(tickets) != 3 {
+			t.Fatalf("API %s: expected 3 tickets over two pages, got %+v", apiVersion, tickets)
+		}
+		payment := tickets[1]
+		if payment.Key != "WEB-2" || payment.EffortDays != 3 || payment.EpicLink != "WEB-10" || payment.Assignee != "Linus" ||
+			payment.StatusCategory != "indeterminate" || len(payment.DependencyKeys) != 1 || payment.DependencyKeys[0] != "WEB-1" {
+			t.Errorf("API %s: unexpected ticket %+v", apiVersion, payment)
+		}
+		epic, ok := epics["WEB-10"]
+		if !ok || epic.Summary != "Checkout" || epic.DueDate.IsZero() {
+			t.Errorf("API %s: expected the epic with its due date, got %+v", apiVersion, epics)
+		}
+	}
+}
+
+func TestGetTickets_FakeServerRateLimited(t *testing.T) {
+	srv := seedPipeline(t)
+	client, err := NewClient(srv.URL, "pat", "customfield_10105", "customfield_10100", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	srv.Throttle(100, time.Minute)
+
+	_, _, err = client.GetTickets(context.Background(), "project = WEB")
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != time.Minute {
+		t.Fatalf("Expected a rate limit error asking for a minute, got %v", err)
+	}
+}
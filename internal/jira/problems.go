@@ -0,0 +1,117 @@
+package jira
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// IssueProblem is an issue in the results that could not be read fully,
+// typically because of its security level or field-level permissions
+type IssueProblem struct {
+	Key     string
+	Fields  []string // Fields that were not readable, planned with defaults
+	Skipped bool     // Nothing was readable, so the issue was left out
+}
+
+func (p IssueProblem) String() string {
+	if p.Skipped {
+		return fmt.Sprintf("%s is not readable (security level or permissions); skipped", p.Key)
+	}
+	return fmt.Sprintf("%s: %s not readable (field permissions); planned with defaults", p.Key, strings.Join(p.Fields, ", "))
+}
+
+// FetchFailure is a part of a fetch that failed without failing the whole
+// fetch, such as a chunk of epics or a user profile
+type FetchFailure struct {
+	What string   // What could not be fetched, e.g. "epic details"
+	Keys []string // Affected issues or users
+	Err  error
+}
+
+func (f FetchFailure) String() string {
+	return fmt.Sprintf("%s for %s: %v", f.What, strings.Join(f.Keys, ", "), f.Err)
+}
+
+// problemLog collects the issue problems and fetch failures of a client,
+// which may come from concurrent fetches. They are reported once at the end
+// of a run rather than as they happen.
+type problemLog struct {
+	mu       sync.Mutex
+	problems []IssueProblem
+	failures []FetchFailure
+}
+
+// add records a problem
+func (l *problemLog) add(p IssueProblem) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.problems = append(l.problems, p)
+}
+
+// fail records a failure
+func (l *problemLog) fail(f FetchFailure) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = append(l.failures, f)
+}
+
+// IssueProblems returns the issues the client could not read fully so far
+func (c *Client) IssueProblems() []IssueProblem {
+	c.problems.mu.Lock()
+	defer c.problems.mu.Unlock()
+	return append([]IssueProblem(nil), c.problems.problems...)
+}
+
+// FetchFailures returns the parts of fetches that failed so far without
+// failing the fetch, leaving the results degraded
+func (c *Client) FetchFailures() []FetchFailure {
+	c.problems.mu.Lock()
+	defer c.problems.mu.Unlock()
+	return append([]FetchFailure(nil), c.problems.failures...)
+}
+
+// checkReadable reports whether an issue can be planned, recording a
+// problem if it is missing fields it cannot be planned without. Issues with
+// no fields at all are skipped; missing status, type or summary are filled in
+// by readableStatus and readableSummary.
+func (c *Client) checkReadable(i onpremise.Issue) bool {
+	if i.Fields == nil {
+		c.problems.add(IssueProblem{Key: i.Key, Skipped: true})
+		return false
+	}
+	var missing []string
+	if i.Fields.Summary == "" {
+		missing = append(missing, "summary")
+	}
+	if i.Fields.Type.Name == "" {
+		missing = append(missing, "issuetype")
+	}
+	if i.Fields.Status == nil {
+		missing = append(missing, "status")
+	}
+	if len(missing) > 0 {
+		c.problems.add(IssueProblem{Key: i.Key, Fields: missing})
+	}
+	return true
+}
+
+// readableStatus returns the status name and category of an issue, empty
+// (not started) if the status is not readable
+func readableStatus(fields *onpremise.IssueFields) (string, string) {
+	if fields.Status == nil {
+		return "", ""
+	}
+	return fields.Status.Name, fields.Status.StatusCategory.Key
+}
+
+// readableSummary returns the summary of an issue, or its key if the summary
+// is not readable
+func readableSummary(i onpremise.Issue) string {
+	if i.Fields.Summary == "" {
+		return i.Key
+	}
+	return i.Fields.Summary
+}
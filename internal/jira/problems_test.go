@@ -0,0 +1,41 @@
+package jira
+
+import (
+	"testing"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+func TestTicketsFromIssues_UnreadableIssues(t *testing.T) {
+	c := &Client{}
+	tickets := c.ticketsFromIssues([]onpremise.Issue{
+		{Key: "SEC-1"},
+		{Key: "SEC-2", Fields: &onpremise.IssueFields{Type: onpremise.IssueType{Name: "Story"}}},
+		{Key: "SEC-3", Fields: &onpremise.IssueFields{
+			Summary: "Readable",
+			Type:    onpremise.IssueType{Name: "Task"},
+			Status:  &onpremise.Status{Name: "Done", StatusCategory: onpremise.StatusCategory{Key: "done"}},
+		}},
+	})
+
+	if len(tickets) != 2 {
+		t.Fatalf("Expected the issue without fields to be skipped, got %d tickets", len(tickets))
+	}
+	if got := tickets[0]; got.Key != "SEC-2" || got.Summary != "SEC-2" || got.Status != "" || got.StatusCategory != "" {
+		t.Errorf("Expected SEC-2 planned with its key as summary and no status, got %+v", got)
+	}
+	if got := tickets[1]; got.Status != "Done" || got.StatusCategory != "done" {
+		t.Errorf("Expected SEC-3 to keep its status, got %+v", got)
+	}
+
+	problems := c.IssueProblems()
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", problems)
+	}
+	if !problems[0].Skipped || problems[0].Key != "SEC-1" {
+		t.Errorf("Expected SEC-1 to be skipped, got %+v", problems[0])
+	}
+	if p := problems[1]; p.Skipped || p.Key != "SEC-2" || len(p.Fields) != 2 || p.Fields[0] != "summary" || p.Fields[1] != "status" {
+		t.Errorf("Expected SEC-2 to miss summary and status, got %+v", p)
+	}
+}
@@ -0,0 +1,69 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// resolvedWindowPattern matches a relative JQL window such as 30d or 4w
+var resolvedWindowPattern = regexp.MustCompile(`^\d+[dw]$`)
+
+// ValidResolvedWindow reports whether window is usable with GetResolvedSince
+func ValidResolvedWindow(window string) bool {
+	return resolvedWindowPattern.MatchString(window)
+}
+
+// GetResolvedSince fetches the issues resolved within window (e.g. "30d") in
+// the projects of tickets, leaving out tickets themselves. It adds recently
+// completed work to a query that only returns open issues, and must follow a
+// GetTickets call, whose field resolution it reuses.
+func (c *Client) GetResolvedSince(ctx context.Context, window string, tickets []Ticket) ([]Ticket, map[string]Ticket, error) {
+	if c.onpremiseClient == nil {
+		return nil, nil, fmt.Errorf("client not initialized")
+	}
+	if !ValidResolvedWindow(window) {
+		return nil, nil, fmt.Errorf("invalid window %q (expected days or weeks, e.g. 30d or 4w)", window)
+	}
+
+	projects := projectKeys(tickets)
+	if len(projects) == 0 {
+		return nil, map[string]Ticket{}, nil
+	}
+
+	jql := fmt.Sprintf("project in (%s) AND resolved >= -%s ORDER BY resolved", strings.Join(projects, ", "), window)
+	issues, err := c.search(ctx, jql, c.ticketFields(), c.ticketExpand())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		known[t.Key] = true
+	}
+	var resolved []Ticket
+	for _, t := range c.ticketsFromIssues(issues) {
+		if !known[t.Key] {
+			resolved = append(resolved, t)
+		}
+	}
+	return resolved, c.epicsFor(ctx, resolved), nil
+}
+
+// projectKeys returns the sorted project keys of the tickets, taken from
+// their issue keys
+func projectKeys(tickets []Ticket) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, t := range tickets {
+		project, _, found := strings.Cut(t.Key, "-")
+		if found && project != "" && !seen[project] {
+			seen[project] = true
+			keys = append(keys, project)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
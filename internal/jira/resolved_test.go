@@ -0,0 +1,49 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetResolvedSince(t *testing.T) {
+	var gotJQL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotJQL = r.URL.Query().Get("jql")
+		var issues []map[string]interface{}
+		for _, key := range []string{"WEB-1", "WEB-9"} {
+			issues = append(issues, map[string]interface{}{
+				"key": key,
+				"fields": map[string]interface{}{
+					"summary": "Summary of " + key,
+					"status":  map[string]interface{}{"name": "Done", "statusCategory": map[string]interface{}{"key": "done"}},
+				},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues, "total": len(issues)})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	open := []Ticket{{Key: "WEB-1"}, {Key: "API-2"}, {Key: "WEB-3"}}
+	resolved, _, err := client.GetResolvedSince(context.Background(), "30d", open)
+	if err != nil {
+		t.Fatalf("GetResolvedSince failed: %v", err)
+	}
+	if want := "project in (API, WEB) AND resolved >= -30d ORDER BY resolved"; gotJQL != want {
+		t.Errorf("JQL = %q, want %q", gotJQL, want)
+	}
+	if len(resolved) != 1 || resolved[0].Key != "WEB-9" || !resolved[0].IsDone() {
+		t.Errorf("Expected only the done WEB-9 not already in the plan, got %+v", resolved)
+	}
+
+	if _, _, err := client.GetResolvedSince(context.Background(), "30 days", open); err == nil {
+		t.Error("Expected an error for an invalid window")
+	}
+}
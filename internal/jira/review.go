@@ -0,0 +1,19 @@
+package jira
+
+// ReviewKeySuffix is appended to a ticket's key to form its review task's key
+const ReviewKeySuffix = " review"
+
+// AddReviewTasks returns the tickets with a "Review: <summary>" task after
+// each ticket that has a reviewer. The review is assigned to the reviewer,
+// takes effortDays and depends on the ticket, so review latency shows in the
+// schedule. It is grouped like the ticket and done when the ticket is.
+func AddReviewTasks(tickets []Ticket, effortDays float64) []Ticket {
+	result := make([]Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		result = append(result, t)
+		if t.Reviewer != "" {
+			result = append(result, followUp(t, t.Key+ReviewKeySuffix, "Review: "+t.Summary, t.Reviewer, effortDays, t.Key))
+		}
+	}
+	return result
+}
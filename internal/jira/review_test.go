@@ -0,0 +1,58 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+func TestTicketsFromIssues_Reviewer(t *testing.T) {
+	var issues []onpremise.Issue
+	if err := json.Unmarshal([]byte(`[{
+		"key": "TASK-1",
+		"fields": {
+			"summary": "Reviewed",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"customfield_10060": {"name": "doej", "displayName": "Doe, Jane"}
+		}
+	}]`), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{
+		extraFields:   []string{"Reviewer"},
+		extraFieldIDs: map[string]string{"Reviewer": "customfield_10060"},
+		reviewerField: "Reviewer",
+	}
+	if got := c.ticketsFromIssues(issues)[0].Reviewer; got != "Doe, Jane" {
+		t.Errorf("Reviewer = %q, want the display name", got)
+	}
+}
+
+func TestAddReviewTasks(t *testing.T) {
+	tickets := []Ticket{
+		{Key: "TASK-1", Summary: "Build it", Assignee: "Ada", Reviewer: "Grace", EffortDays: 3, Status: "In Progress", StatusCategory: "indeterminate", EpicLink: "EPIC-1", Link: "https://jira/rest/api/2/issue/1"},
+		{Key: "TASK-2", Summary: "Unreviewed", Assignee: "Ada"},
+		{Key: "TASK-3", Summary: "Shipped", Reviewer: "Grace", Status: "Done", StatusCategory: "done"},
+	}
+	got := AddReviewTasks(tickets, 0.25)
+
+	if len(got) != 5 || got[1].Key != "TASK-1 review" || got[2].Key != "TASK-2" || got[4].Key != "TASK-3 review" {
+		t.Fatalf("Expected each review right after its ticket, got %+v", got)
+	}
+	review := got[1]
+	if review.Summary != "Review: Build it" || review.Assignee != "Grace" || review.EffortDays != 0.25 {
+		t.Errorf("Review = %+v, want a quarter day for the reviewer", review)
+	}
+	if len(review.DependencyKeys) != 1 || review.DependencyKeys[0] != "TASK-1" {
+		t.Errorf("Review depends on %v, want TASK-1", review.DependencyKeys)
+	}
+	if review.EpicLink != "EPIC-1" || review.Link != "" || review.IsDone() || review.Status != "" {
+		t.Errorf("Review should stay in the epic, without a Jira link and not started, got %+v", review)
+	}
+	if !got[4].IsDone() {
+		t.Error("The review of a done ticket should be done")
+	}
+}
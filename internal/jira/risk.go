@@ -0,0 +1,37 @@
+package jira
+
+import "strings"
+
+// RiskPadding scales effort by the multiplier of each ticket's risk level.
+// Field is a key of Ticket.Fields, so it must also be requested as
+// ClientOptions.ExtraFields.
+type RiskPadding struct {
+	Field string
+	// Multipliers by risk level, e.g. {"High": 1.5}. Levels are matched
+	// case-insensitively; levels without a multiplier are not padded.
+	Multipliers map[string]float64
+}
+
+// Level returns the risk level of a ticket and its multiplier, 1 when the
+// level has none. The level is "" for tickets without a risk.
+func (r RiskPadding) Level(t Ticket) (string, float64) {
+	level := t.Fields[r.Field]
+	if level == "" {
+		return "", 1
+	}
+	for name, m := range r.Multipliers {
+		if strings.EqualFold(name, level) {
+			return level, m
+		}
+	}
+	return level, 1
+}
+
+// Apply multiplies the effort of each ticket by its risk multiplier
+func (r RiskPadding) Apply(tickets []Ticket) {
+	for i := range tickets {
+		if _, m := r.Level(tickets[i]); m != 1 {
+			tickets[i].EffortDays *= m
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package jira
+
+import "testing"
+
+func TestRiskPadding(t *testing.T) {
+	r := RiskPadding{Field: "Risk", Multipliers: map[string]float64{"high": 1.5, "Low": 0.9}}
+	tickets := []Ticket{
+		{Key: "A-1", EffortDays: 2, Fields: map[string]string{"Risk": "High"}},
+		{Key: "A-2", EffortDays: 2, Fields: map[string]string{"Risk": "Medium"}},
+		{Key: "A-3", EffortDays: 2},
+		{Key: "A-4", EffortDays: 10, Fields: map[string]string{"Risk": "low"}},
+	}
+
+	if level, m := r.Level(tickets[0]); level != "High" || m != 1.5 {
+		t.Errorf("Level(A-1) = %q, %v, want High, 1.5", level, m)
+	}
+	if level, m := r.Level(tickets[1]); level != "Medium" || m != 1 {
+		t.Errorf("Level(A-2) = %q, %v, want Medium, 1", level, m)
+	}
+	if level, m := r.Level(tickets[2]); level != "" || m != 1 {
+		t.Errorf("Level(A-3) = %q, %v, want no risk", level, m)
+	}
+
+	r.Apply(tickets)
+	for i, want := range []float64{3, 2, 2, 9} {
+		if tickets[i].EffortDays != want {
+			t.Errorf("%s effort = %v, want %v", tickets[i].Key, tickets[i].EffortDays, want)
+		}
+	}
+}
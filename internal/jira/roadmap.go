@@ -0,0 +1,182 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Roadmap is an Advanced Roadmaps plan (Jira Plans), reduced to what decides
+// the tickets and dates of a generated plan
+type Roadmap struct {
+	ID   int64
+	Name string
+	JQL  string // The issues in the plan's scope, less its exclusions
+
+	// TargetStartField and TargetEndField are the date fields the plan is
+	// scheduled by, for ClientOptions. An empty TargetEndField means the
+	// plan ends work on the Jira due date; an empty TargetStartField means
+	// it has no start dates.
+	TargetStartField string
+	TargetEndField   string
+}
+
+// Advanced Roadmaps' own date fields, resolved by name
+const (
+	targetStartFieldName = "Target start"
+	targetEndFieldName   = "Target end"
+)
+
+// roadmapPlan is the part of /rest/api/3/plans/plan/{id} a Roadmap is made of
+type roadmapPlan struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	IssueSources []struct {
+		Type  string `json:"type"` // Board, Project or Filter
+		Value int64  `json:"value"`
+	} `json:"issueSources"`
+	ExclusionRules struct {
+		IssueIDs                          []int64 `json:"issueIds"`
+		IssueTypeIDs                      []int64 `json:"issueTypeIds"`
+		NumberOfDaysToShowCompletedIssues int     `json:"numberOfDaysToShowCompletedIssues"`
+		ReleaseIDs                        []int64 `json:"releaseIds"`
+		WorkStatusCategoryIDs             []int64 `json:"workStatusCategoryIds"`
+		WorkStatusIDs                     []int64 `json:"workStatusIds"`
+	} `json:"exclusionRules"`
+	Scheduling struct {
+		StartDate roadmapDate `json:"startDate"`
+		EndDate   roadmapDate `json:"endDate"`
+	} `json:"scheduling"`
+}
+
+// roadmapDate is the field a plan takes start or end dates from
+type roadmapDate struct {
+	Type              string `json:"type"` // DueDate, TargetStartDate, TargetEndDate or DateCustomField
+	DateCustomFieldID int64  `json:"dateCustomFieldId"`
+}
+
+// GetRoadmap reads an Advanced Roadmaps plan by its ID, as shown in the plan's
+// URL. Plans can only be read through the Jira Cloud REST API.
+func (c *Client) GetRoadmap(ctx context.Context, id string) (*Roadmap, error) {
+	if c.onpremiseClient == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	var plan roadmapPlan
+	if err := c.getJSON(ctx, "rest/api/3/plans/plan/"+url.PathEscape(id), &plan); err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("plan %s: %w (reading plans needs Jira Cloud with Advanced Roadmaps)", id, err)
+		}
+		return nil, fmt.Errorf("plan %s: %w", id, err)
+	}
+
+	jql, err := c.roadmapJQL(ctx, &plan)
+	if err != nil {
+		return nil, fmt.Errorf("plan %s: %w", id, err)
+	}
+	return &Roadmap{
+		ID:               plan.ID,
+		Name:             plan.Name,
+		JQL:              jql,
+		TargetStartField: roadmapDateField(plan.Scheduling.StartDate, targetStartFieldName),
+		TargetEndField:   roadmapDateField(plan.Scheduling.EndDate, targetEndFieldName),
+	}, nil
+}
+
+// roadmapJQL returns the query for the issues of the plan: those of any of
+// its sources, less the excluded ones
+func (c *Client) roadmapJQL(ctx context.Context, plan *roadmapPlan) (string, error) {
+	var sources []string
+	for _, source := range plan.IssueSources {
+		switch source.Type {
+		case "Project":
+			sources = append(sources, fmt.Sprintf("project = %d", source.Value))
+		case "Filter":
+			sources = append(sources, fmt.Sprintf("filter = %d", source.Value))
+		case "Board":
+			// A board's issues are those of its filter
+			var config struct {
+				Filter struct {
+					ID string `json:"id"`
+				} `json:"filter"`
+			}
+			if err := c.getJSON(ctx, fmt.Sprintf("rest/agile/1.0/board/%d/configuration", source.Value), &config); err != nil {
+				return "", fmt.Errorf("board %d: %w", source.Value, err)
+			}
+			sources = append(sources, "filter = "+config.Filter.ID)
+		default:
+			return "", fmt.Errorf("unsupported issue source type %q", source.Type)
+		}
+	}
+	if len(sources) == 0 {
+		return "", fmt.Errorf("the plan has no issue sources")
+	}
+
+	clauses := []string{"(" + strings.Join(sources, " OR ") + ")"}
+	rules := plan.ExclusionRules
+	if len(rules.IssueIDs) > 0 {
+		clauses = append(clauses, "id not in "+idList(rules.IssueIDs))
+	}
+	if len(rules.IssueTypeIDs) > 0 {
+		clauses = append(clauses, "issuetype not in "+idList(rules.IssueTypeIDs))
+	}
+	if len(rules.WorkStatusIDs) > 0 {
+		clauses = append(clauses, "status not in "+idList(rules.WorkStatusIDs))
+	}
+	if len(rules.WorkStatusCategoryIDs) > 0 {
+		clauses = append(clauses, "statusCategory not in "+idList(rules.WorkStatusCategoryIDs))
+	}
+	// "not in" alone would also drop the issues without a release
+	if len(rules.ReleaseIDs) > 0 {
+		clauses = append(clauses, "(fixVersion is EMPTY OR fixVersion not in "+idList(rules.ReleaseIDs)+")")
+	}
+	if days := rules.NumberOfDaysToShowCompletedIssues; days > 0 {
+		clauses = append(clauses, fmt.Sprintf("(statusCategory != Done OR resolved >= -%dd)", days))
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// roadmapDateField returns the field a plan date is taken from, with
+// targetName standing for the plan's own target date field, or "" for the
+// due date
+func roadmapDateField(date roadmapDate, targetName string) string {
+	switch date.Type {
+	case "TargetStartDate", "TargetEndDate":
+		return targetName
+	case "DateCustomField":
+		return "customfield_" + strconv.FormatInt(date.DateCustomFieldID, 10)
+	}
+	return ""
+}
+
+// idList formats IDs as a JQL list, e.g. "(10001, 10002)"
+func idList(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// dateField returns the date in the field of raw (see rawFields), at
+// midnight in the client's time zone, or zero if the field is "" or unset.
+// Date-time values count by their date.
+func (c *Client) dateField(raw map[string]interface{}, field string) time.Time {
+	if field == "" || raw == nil {
+		return time.Time{}
+	}
+	s, _ := raw[c.extraFieldID(field)].(string)
+	if len(s) < len("2006-01-02") {
+		return time.Time{}
+	}
+	d, err := time.ParseInLocation("2006-01-02", s[:len("2006-01-02")], c.location())
+	if err != nil {
+		return time.Time{}
+	}
+	return d
+}
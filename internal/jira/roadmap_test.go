@@ -0,0 +1,83 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+func TestGetRoadmap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/plans/plan/42":
+			_, _ = w.Write([]byte(`{
+				"id": 42,
+				"name": "Payments 2026",
+				"issueSources": [{"type": "Project", "value": 10000}, {"type": "Board", "value": 7}],
+				"exclusionRules": {"issueTypeIds": [10003], "releaseIds": [20001, 20002], "numberOfDaysToShowCompletedIssues": 30},
+				"scheduling": {"startDate": {"type": "TargetStartDate"}, "endDate": {"type": "DateCustomField", "dateCustomFieldId": 10150}}
+			}`))
+		case "/rest/agile/1.0/board/7/configuration":
+			_, _ = w.Write([]byte(`{"filter": {"id": "10400"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{APIVersion: APIVersion3})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	roadmap, err := client.GetRoadmap(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetRoadmap failed: %v", err)
+	}
+	want := "(project = 10000 OR filter = 10400) AND issuetype not in (10003) AND " +
+		"(fixVersion is EMPTY OR fixVersion not in (20001, 20002)) AND (statusCategory != Done OR resolved >= -30d)"
+	if roadmap.Name != "Payments 2026" || roadmap.JQL != want {
+		t.Errorf("roadmap = %q with JQL %q, want Payments 2026 with %q", roadmap.Name, roadmap.JQL, want)
+	}
+	if roadmap.TargetStartField != "Target start" || roadmap.TargetEndField != "customfield_10150" {
+		t.Errorf("date fields = %q, %q", roadmap.TargetStartField, roadmap.TargetEndField)
+	}
+
+	var notFound *NotFoundError
+	if _, err := client.GetRoadmap(context.Background(), "43"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a NotFoundError for a missing plan, got %v", err)
+	}
+}
+
+func TestTicketsFromIssues_RoadmapDates(t *testing.T) {
+	c := &Client{
+		extraFields:      []string{"customfield_10140", "customfield_10150"},
+		targetStartField: "customfield_10140",
+		targetEndField:   "customfield_10150",
+		blockingLinkType: "Blocks",
+		loc:              time.UTC,
+	}
+	tickets := c.ticketsFromIssues([]onpremise.Issue{{Key: "PAY-2", Fields: &onpremise.IssueFields{
+		Summary: "Checkout",
+		Type:    onpremise.IssueType{Name: "Story"},
+		Status:  &onpremise.Status{Name: "To Do"},
+		IssueLinks: []*onpremise.IssueLink{
+			{Type: onpremise.IssueLinkType{Name: "Blocks"}, InwardIssue: &onpremise.Issue{Key: "PAY-1"}},
+			{Type: onpremise.IssueLinkType{Name: "Blocks"}, OutwardIssue: &onpremise.Issue{Key: "PAY-3"}},
+		},
+		Unknowns: map[string]interface{}{"customfield_10140": "2026-03-02", "customfield_10150": "2026-03-13"},
+	}}})
+
+	got := tickets[0]
+	if !got.TargetStart.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || !got.TargetEnd.Equal(time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("target dates = %v..%v, want 2026-03-02..2026-03-13", got.TargetStart, got.TargetEnd)
+	}
+	if len(got.DependencyKeys) != 1 || got.DependencyKeys[0] != "PAY-1" {
+		t.Errorf("Expected a dependency on the blocking PAY-1 only, got %v", got.DependencyKeys)
+	}
+}
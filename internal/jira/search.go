@@ -0,0 +1,116 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// Supported Jira REST API versions
+const (
+	// APIVersion2 uses the classic /rest/api/2/search endpoint (Server, Data Center and Cloud)
+	APIVersion2 = "2"
+	// APIVersion3 uses the Cloud /rest/api/3/search/jql endpoint with token-based pagination
+	APIVersion3 = "3"
+)
+
+// searchPageSize is the number of issues requested per page
+const searchPageSize = 100
+
+// search runs a JQL query and returns all matching issues, following pagination.
+// expand is passed through to Jira (e.g. "changelog") and may be empty. On
+// error, the issues of the pages fetched so far are returned with it.
+func (c *Client) search(ctx context.Context, jql string, fields []string, expand string) ([]onpremise.Issue, error) {
+	var all []onpremise.Issue
+	err := c.searchPages(ctx, jql, fields, expand, func(issues []onpremise.Issue) error {
+		all = append(all, issues...)
+		return nil
+	})
+	return all, err
+}
+
+// searchPages runs a JQL query like search, but hands each page of issues to
+// page as it arrives instead of collecting them. It stops at the first error,
+// from Jira or from page.
+func (c *Client) searchPages(ctx context.Context, jql string, fields []string, expand string, page func([]onpremise.Issue) error) error {
+	if c.apiVersion == APIVersion3 {
+		return c.searchJQL(ctx, jql, fields, expand, page)
+	}
+	return c.searchLegacy(ctx, jql, fields, expand, page)
+}
+
+// searchLegacy pages through /rest/api/2/search using startAt offsets
+func (c *Client) searchLegacy(ctx context.Context, jql string, fields []string, expand string, page func([]onpremise.Issue) error) error {
+	startAt := 0
+	for {
+		issues, resp, err := c.onpremiseClient.Issue.Search(ctx, jql, &onpremise.SearchOptions{
+			Fields:     fields,
+			StartAt:    startAt,
+			MaxResults: searchPageSize,
+			Expand:     expand,
+		})
+		if err != nil {
+			return responseError(resp, err, jql)
+		}
+		if err := page(issues); err != nil {
+			return err
+		}
+
+		// Servers may cap maxResults below what we asked for, so trust the response
+		startAt += len(issues)
+		if len(issues) == 0 || resp == nil || startAt >= resp.Total {
+			return nil
+		}
+	}
+}
+
+// searchJQLResult is the response of /rest/api/3/search/jql
+type searchJQLResult struct {
+	Issues        []onpremise.Issue `json:"issues"`
+	NextPageToken string            `json:"nextPageToken"`
+	IsLast        bool              `json:"isLast"`
+}
+
+// searchJQL pages through the Cloud /rest/api/3/search/jql endpoint using nextPageToken
+func (c *Client) searchJQL(ctx context.Context, jql string, fields []string, expand string, page func([]onpremise.Issue) error) error {
+	nextPageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", strings.Join(fields, ","))
+		query.Set("maxResults", strconv.Itoa(searchPageSize))
+		if expand != "" {
+			query.Set("expand", expand)
+		}
+		if nextPageToken != "" {
+			query.Set("nextPageToken", nextPageToken)
+		}
+
+		req, err := c.onpremiseClient.NewRequest(ctx, http.MethodGet, "rest/api/3/search/jql?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+
+		var result searchJQLResult
+		resp, err := c.onpremiseClient.Do(req, &result)
+		if err != nil {
+			return responseError(resp, onpremise.NewJiraError(resp, err), jql)
+		}
+		if err := page(result.Issues); err != nil {
+			return err
+		}
+
+		if result.IsLast || result.NextPageToken == "" {
+			return nil
+		}
+		if result.NextPageToken == nextPageToken {
+			return fmt.Errorf("search pagination did not advance (nextPageToken %q)", nextPageToken)
+		}
+		nextPageToken = result.NextPageToken
+	}
+}
@@ -0,0 +1,216 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// sessionTransport authenticates with a Jira session cookie instead of a
+// Bearer PAT, for Data Center instances behind SSO proxies that block
+// Authorization headers. The session is either captured from a browser, or
+// obtained by logging in with a username and password or with Kerberos,
+// again whenever it expires.
+type sessionTransport struct {
+	LoginURL  string // {endpoint}/rest/auth/1/session
+	Username  string
+	Password  string
+	Negotiate []string // Command printing a SPNEGO token, see ClientOptions
+	Base      http.RoundTripper
+
+	mu         sync.Mutex
+	cookies    []*http.Cookie // Current session, nil before logging in
+	generation int            // Counts logins, so concurrent requests log in once
+}
+
+// newSessionTransport returns a transport authenticating with the session
+// cookie or login in opts
+func newSessionTransport(endpoint string, opts ClientOptions, base http.RoundTripper) (*sessionTransport, error) {
+	t := &sessionTransport{
+		LoginURL:  strings.TrimRight(endpoint, "/") + loginPath,
+		Username:  opts.Username,
+		Password:  opts.Password,
+		Negotiate: opts.NegotiateCommand,
+		Base:      base,
+	}
+	if opts.SessionCookie != "" {
+		cookies, err := http.ParseCookie(opts.SessionCookie)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session cookie (expected name=value pairs separated by \"; \"): %w", err)
+		}
+		t.cookies = cookies
+	}
+	return t, nil
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cookies, generation, err := t.session(req, -1)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.Base.RoundTrip(withCookies(req, cookies))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !t.canLogin() {
+		return resp, err
+	}
+
+	// The session expired: log in again and retry once, if the body allows it
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	if cookies, _, err = t.session(req, generation); err != nil {
+		return nil, err
+	}
+	retry := withCookies(req, cookies)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.Base.RoundTrip(retry)
+}
+
+// session returns the session cookies and their generation. It logs in if
+// there is no session yet, or if the session of generation expired and no
+// other request has logged in again since.
+func (t *sessionTransport) session(req *http.Request, expired int) ([]*http.Cookie, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.canLogin() || (t.cookies != nil && t.generation != expired) {
+		return t.cookies, t.generation, nil
+	}
+
+	cookies, err := t.login(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	t.cookies = cookies
+	t.generation++
+	return cookies, t.generation, nil
+}
+
+// canLogin reports whether a new session can be started when the current one
+// expires, as opposed to a captured cookie
+func (t *sessionTransport) canLogin() bool {
+	return t.Username != "" || len(t.Negotiate) > 0
+}
+
+// login starts a session with the username and password, or with a Kerberos
+// ticket. SSO proxies may add cookies of their own, so all cookies set by the
+// response are kept.
+func (t *sessionTransport) login(orig *http.Request) ([]*http.Cookie, error) {
+	var req *http.Request
+	var who string
+	if len(t.Negotiate) > 0 {
+		token, err := negotiateToken(orig.Context(), t.Negotiate, orig.URL.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		// Reading the current session authenticates and starts one
+		if req, err = http.NewRequestWithContext(orig.Context(), http.MethodGet, t.LoginURL, nil); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Negotiate "+token)
+		who = "with Kerberos"
+	} else {
+		body, err := json.Marshal(map[string]string{"username": t.Username, "password": t.Password})
+		if err != nil {
+			return nil, err
+		}
+		if req, err = http.NewRequestWithContext(orig.Context(), http.MethodPost, t.LoginURL, bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		who = "as " + t.Username
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("logging in: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Err: fmt.Errorf("logging in %s failed", who)}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, &AuthError{statusErr}
+		}
+		return nil, statusErr
+	}
+
+	cookies := resp.Cookies()
+	var session struct {
+		Session struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"session"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err == nil && session.Session.Name != "" && !hasCookie(cookies, session.Session.Name) {
+		cookies = append(cookies, &http.Cookie{Name: session.Session.Name, Value: session.Session.Value})
+	}
+	if len(cookies) == 0 {
+		return nil, errors.New("logging in: Jira returned no session cookie")
+	}
+	// Keep the new session out of the --debug-http-file dump
+	if trace, ok := t.Base.(*traceTransport); ok {
+		for _, c := range cookies {
+			trace.addSecret(c.Value)
+		}
+	}
+	return cookies, nil
+}
+
+// negotiateToken runs command to get a base64 SPNEGO token for host, with
+// "{host}" in its arguments replaced by the host. Go has no GSSAPI of its
+// own, so the token comes from the system's Kerberos libraries through a
+// helper, using the ticket cache filled by kinit.
+func negotiateToken(ctx context.Context, command []string, host string) (string, error) {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = strings.ReplaceAll(arg, "{host}", host)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", fmt.Errorf("getting a Kerberos token from %s: %w", args[0], err)
+	}
+	token := strings.TrimSpace(string(out))
+	if _, err := base64.StdEncoding.DecodeString(token); err != nil || token == "" {
+		return "", fmt.Errorf("getting a Kerberos token from %s: expected a base64 token, got %q", args[0], token)
+	}
+	return token, nil
+}
+
+// withCookies returns a copy of req carrying the session cookies. Cookie
+// authenticated writes, such as attachments, also need Jira's XSRF check off.
+func withCookies(req *http.Request, cookies []*http.Cookie) *http.Request {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return req
+}
+
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, c := range cookies {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
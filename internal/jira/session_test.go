@@ -0,0 +1,145 @@
+package jira
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSessionLogin(t *testing.T) {
+	logins := 0
+	session := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("session requests must not send a Bearer token")
+		}
+		if r.URL.Path == "/rest/auth/1/session" {
+			var creds map[string]string
+			json.NewDecoder(r.Body).Decode(&creds)
+			if creds["username"] != "planner" || creds["password"] != "secret" {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			logins++
+			session = string(rune('0' + logins))
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: session})
+			w.Write([]byte(`{"session": {"name": "JSESSIONID", "value": "` + session + `"}}`))
+			return
+		}
+		if c, err := r.Cookie("JSESSIONID"); err != nil || c.Value != session {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"version": "9.12.0"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "", "", ClientOptions{Username: "planner", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.ServerInfo(ctx); err != nil {
+		t.Fatalf("ServerInfo failed: %v", err)
+	}
+	if _, err := client.ServerInfo(ctx); err != nil || logins != 1 {
+		t.Fatalf("expected the session to be reused, got %d logins, err %v", logins, err)
+	}
+
+	// Expire the session on the server; the client logs in again
+	session = "expired"
+	if _, err := client.ServerInfo(ctx); err != nil || logins != 2 {
+		t.Fatalf("expected a second login after expiry, got %d logins, err %v", logins, err)
+	}
+
+	wrong, err := NewClient(server.URL, "", "", "", ClientOptions{Username: "planner", Password: "wrong"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	_, err = wrong.ServerInfo(ctx)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("expected an AuthError for a failed login, got %v", err)
+	}
+}
+
+func TestSessionCookie(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sso, err1 := r.Cookie("SSO_TOKEN")
+		jsession, err2 := r.Cookie("JSESSIONID")
+		if err1 != nil || err2 != nil || sso.Value != "abc" || jsession.Value != "xyz" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"version": "9.12.0"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "", "", ClientOptions{SessionCookie: "SSO_TOKEN=abc; JSESSIONID=xyz"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.ServerInfo(context.Background()); err != nil {
+		t.Fatalf("ServerInfo failed: %v", err)
+	}
+
+	if _, err := NewClient(server.URL, "", "", "", ClientOptions{SessionCookie: "not a cookie"}); err == nil {
+		t.Error("expected an error for a malformed session cookie")
+	}
+}
+
+func TestSessionNegotiate(t *testing.T) {
+	if _, err := exec.LookPath("base64"); err != nil {
+		t.Skip("base64 is not available")
+	}
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/auth/1/session" {
+			// The helper's token is the host name, base64 encoded
+			if r.Header.Get("Authorization") != "Negotiate "+base64.StdEncoding.EncodeToString([]byte("127.0.0.1")) {
+				w.Header().Set("WWW-Authenticate", "Negotiate")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "kerberos"})
+			w.Write([]byte(`{"name": "planner"}`))
+			return
+		}
+		if c, err := r.Cookie("JSESSIONID"); err != nil || c.Value != "kerberos" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"version": "9.12.0"}`))
+	}))
+	defer server.Close()
+
+	helper := []string{"sh", "-c", `printf %s "$1" | base64`, "sh", "{host}"}
+	client, err := NewClient(server.URL, "", "", "", ClientOptions{NegotiateCommand: helper})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	for range 2 {
+		if _, err := client.ServerInfo(context.Background()); err != nil {
+			t.Fatalf("ServerInfo failed: %v", err)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("expected the Kerberos session to be reused, got %d logins", logins)
+	}
+
+	failing, err := NewClient(server.URL, "", "", "", ClientOptions{NegotiateCommand: []string{"sh", "-c", "echo 'no ticket' >&2; exit 1"}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := failing.ServerInfo(context.Background()); err == nil || !strings.Contains(err.Error(), "no ticket") {
+		t.Errorf("expected the helper's error, got %v", err)
+	}
+}
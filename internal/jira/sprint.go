@@ -0,0 +1,37 @@
+package jira
+
+import (
+	"regexp"
+	"time"
+)
+
+// legacySprintEnd extracts the end date from a Jira Server sprint value (see
+// legacySprintName)
+var legacySprintEnd = regexp.MustCompile(`\[.*\bendDate=([^,\]]*)`)
+
+// sprintEnd returns the end date of the last sprint in the sprint field, at
+// midnight in the client's time zone, or the zero time when it has none
+func (c *Client) sprintEnd(raw map[string]interface{}) time.Time {
+	if c.sprintField == "" || raw == nil {
+		return time.Time{}
+	}
+	sprints, ok := raw[c.extraFieldID(c.sprintField)].([]interface{})
+	if !ok || len(sprints) == 0 {
+		return time.Time{}
+	}
+
+	var end string
+	switch s := sprints[len(sprints)-1].(type) {
+	case map[string]interface{}:
+		end, _ = s["endDate"].(string)
+	case string:
+		if m := legacySprintEnd.FindStringSubmatch(s); m != nil {
+			end = m[1]
+		}
+	}
+	t, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return time.Time{}
+	}
+	return c.dateInLocation(c.inLocation(t))
+}
@@ -0,0 +1,65 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+func TestTicketsFromIssues_SprintEnd(t *testing.T) {
+	var issues []onpremise.Issue
+	if err := json.Unmarshal([]byte(`[{
+		"key": "CLOUD-1",
+		"fields": {
+			"summary": "Cloud sprints",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"customfield_10020": [
+				{"id": 6, "name": "Sprint 6", "endDate": "2025-06-13T15:00:00.000Z"},
+				{"id": 7, "name": "Sprint 7", "endDate": "2025-06-27T23:30:00.000Z"}
+			]
+		}
+	}, {
+		"key": "SERVER-1",
+		"fields": {
+			"summary": "Server sprint",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"},
+			"customfield_10020": ["com.atlassian.greenhopper.service.sprint.Sprint@1f[id=7,state=ACTIVE,name=Sprint 7,startDate=2025-06-16T09:00:00.000+02:00,endDate=2025-06-27T17:00:00.000+02:00]"]
+		}
+	}, {
+		"key": "NONE-1",
+		"fields": {
+			"summary": "Backlog",
+			"issuetype": {"name": "Task"},
+			"status": {"name": "To Do"}
+		}
+	}]`), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	c := &Client{
+		extraFields:   []string{"Sprint"},
+		extraFieldIDs: map[string]string{"Sprint": "customfield_10020"},
+		sprintField:   "Sprint",
+		loc:           oslo,
+	}
+	tickets := c.ticketsFromIssues(issues)
+
+	// The last sprint counts, on its day in the client's time zone
+	if want := time.Date(2025, 6, 28, 0, 0, 0, 0, oslo); !tickets[0].SprintEnd.Equal(want) {
+		t.Errorf("CLOUD-1 sprint end = %s, want %s", tickets[0].SprintEnd, want)
+	}
+	if want := time.Date(2025, 6, 27, 0, 0, 0, 0, oslo); !tickets[1].SprintEnd.Equal(want) {
+		t.Errorf("SERVER-1 sprint end = %s, want %s", tickets[1].SprintEnd, want)
+	}
+	if !tickets[2].SprintEnd.IsZero() {
+		t.Errorf("NONE-1 sprint end = %s, want none", tickets[2].SprintEnd)
+	}
+}
@@ -0,0 +1,80 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// TicketStream delivers the tickets of a query while its pages are still
+// being fetched, see StreamTickets
+type TicketStream struct {
+	// C receives the tickets in query order, and is closed when the query is
+	// done or fails
+	C <-chan Ticket
+
+	done     chan struct{} // Closed once err and excluded are final
+	err      error
+	excluded map[string]bool
+}
+
+// StreamTickets runs jql like GetTickets, sending each ticket on the
+// stream's channel as soon as its page of results is converted. Callers
+// process tickets while later pages are fetched, and only one page of raw
+// issues is held at a time. The fetch stops when ctx is cancelled; a caller
+// that stops reading early must cancel ctx.
+//
+// Epics are not fetched; pass the tickets to GetEpics once the stream is
+// done. Tickets may depend on issues of excluded types that come later in the
+// results, which DropExcluded removes. Assignee names are as Jira reports
+// them; MergeAssignees makes them consistent.
+func (c *Client) StreamTickets(ctx context.Context, jql string) *TicketStream {
+	tickets := make(chan Ticket, searchPageSize)
+	s := &TicketStream{C: tickets, done: make(chan struct{}), excluded: make(map[string]bool)}
+
+	go func() {
+		defer close(s.done)
+		defer close(tickets)
+
+		if c.onpremiseClient == nil {
+			s.err = fmt.Errorf("client not initialized")
+			return
+		}
+		if s.err = c.resolveFields(ctx, jql); s.err != nil {
+			return
+		}
+		s.err = c.searchPages(ctx, jql, c.ticketFields(), c.ticketExpand(), func(issues []onpremise.Issue) error {
+			for _, t := range c.ticketsFromPage(issues, s.excluded) {
+				select {
+				case tickets <- t:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}()
+	return s
+}
+
+// Err returns the error that ended the stream, or nil once all tickets were
+// sent. It waits for C to be closed.
+func (s *TicketStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// DropExcluded removes dependencies on issues of excluded types from tickets
+// received from the stream. It waits for C to be closed, as the excluded
+// issues are only all known then.
+func (s *TicketStream) DropExcluded(tickets []Ticket) {
+	<-s.done
+	dropExcluded(tickets, s.excluded)
+}
+
+// GetEpics fetches the epics the tickets belong to, as GetTickets does.
+// Failures are recorded (see FetchFailures) rather than returned.
+func (c *Client) GetEpics(ctx context.Context, tickets []Ticket) map[string]Ticket {
+	return c.epicsFor(ctx, tickets)
+}
@@ -0,0 +1,67 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamTickets(t *testing.T) {
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issue := func(key, issueType string, dependsOn string) map[string]interface{} {
+			fields := map[string]interface{}{
+				"summary":   key,
+				"issuetype": map[string]interface{}{"name": issueType},
+				"status":    map[string]interface{}{"name": "To Do", "statusCategory": map[string]interface{}{"key": "new"}},
+			}
+			if dependsOn != "" {
+				fields["issuelinks"] = []interface{}{map[string]interface{}{
+					"type":         map[string]interface{}{"name": "Dependent"},
+					"outwardIssue": map[string]interface{}{"key": dependsOn},
+				}}
+			}
+			return map[string]interface{}{"key": key, "fields": fields}
+		}
+		if r.URL.Query().Get("startAt") == "1" {
+			// The first page's ticket is delivered before the second page is fetched
+			select {
+			case <-received:
+			case <-time.After(5 * time.Second):
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{issue("WEB-2", "Test", "")}, "total": 2})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{issue("WEB-1", "Story", "WEB-2")}, "total": 2})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{ExcludeIssueTypes: []string{"Test"}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	stream := client.StreamTickets(context.Background(), "project = WEB")
+
+	var tickets []Ticket
+	for ticket := range stream.C {
+		if len(tickets) == 0 {
+			close(received)
+		}
+		tickets = append(tickets, ticket)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].Key != "WEB-1" || len(tickets[0].DependencyKeys) != 1 {
+		t.Fatalf("Expected WEB-1, still depending on the excluded WEB-2, got %+v", tickets)
+	}
+	stream.DropExcluded(tickets)
+	if len(tickets[0].DependencyKeys) != 0 {
+		t.Errorf("Expected the dependency on the excluded issue dropped, got %v", tickets[0].DependencyKeys)
+	}
+}
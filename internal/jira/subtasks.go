@@ -0,0 +1,63 @@
+package jira
+
+import "slices"
+
+// ExcludeSubtasks drops sub-tasks and any dependencies on them
+func ExcludeSubtasks(tickets []Ticket) []Ticket {
+	removed := make(map[string]bool)
+	kept := make([]Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		if t.Parent != "" {
+			removed[t.Key] = true
+			continue
+		}
+		kept = append(kept, t)
+	}
+
+	for i := range kept {
+		kept[i].DependencyKeys = slices.DeleteFunc(slices.Clone(kept[i].DependencyKeys), func(key string) bool { return removed[key] })
+	}
+	return kept
+}
+
+// CollapseSubtasks folds sub-tasks into their parent: the parent's effort grows
+// by theirs and their dependencies, and dependencies on them, move to the
+// parent. Sub-tasks whose parent is not among the tickets are kept.
+func CollapseSubtasks(tickets []Ticket) []Ticket {
+	present := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		present[t.Key] = true
+	}
+
+	parentOf := make(map[string]string) // Collapsed sub-task key -> parent key
+	effort := make(map[string]float64)
+	deps := make(map[string][]string)
+	for _, t := range tickets {
+		if t.Parent != "" && present[t.Parent] {
+			parentOf[t.Key] = t.Parent
+			effort[t.Parent] += t.EffortDays
+			deps[t.Parent] = append(deps[t.Parent], t.DependencyKeys...)
+		}
+	}
+
+	collapsed := make([]Ticket, 0, len(tickets)-len(parentOf))
+	for _, t := range tickets {
+		if _, ok := parentOf[t.Key]; ok {
+			continue
+		}
+		t.EffortDays += effort[t.Key]
+
+		var keys []string
+		for _, key := range append(slices.Clone(t.DependencyKeys), deps[t.Key]...) {
+			if parent, ok := parentOf[key]; ok {
+				key = parent
+			}
+			if key != t.Key && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		t.DependencyKeys = keys
+		collapsed = append(collapsed, t)
+	}
+	return collapsed
+}
@@ -0,0 +1,45 @@
+package jira
+
+import (
+	"fmt"
+	"testing"
+)
+
+func subtaskFixture() []Ticket {
+	return []Ticket{
+		{Key: "A-1", EffortDays: 1, DependencyKeys: []string{"A-3"}},
+		{Key: "A-2", Parent: "A-1", EffortDays: 2, DependencyKeys: []string{"B-1"}},
+		{Key: "A-3", EffortDays: 1},
+		{Key: "A-4", Parent: "A-3", EffortDays: 0.5},
+		{Key: "B-1", DependencyKeys: []string{"A-2", "A-4"}},
+		{Key: "C-2", Parent: "C-1", EffortDays: 3}, // Parent not in the results
+	}
+}
+
+func summarize(tickets []Ticket) string {
+	var s string
+	for _, t := range tickets {
+		s += fmt.Sprintf("%s:%g%v ", t.Key, t.EffortDays, t.DependencyKeys)
+	}
+	return s
+}
+
+func TestExcludeSubtasks(t *testing.T) {
+	got := summarize(ExcludeSubtasks(subtaskFixture()))
+	want := "A-1:1[A-3] A-3:1[] B-1:0[] "
+	if got != want {
+		t.Errorf("ExcludeSubtasks = %q, want %q", got, want)
+	}
+}
+
+func TestCollapseSubtasks(t *testing.T) {
+	fixture := subtaskFixture()
+	got := summarize(CollapseSubtasks(fixture))
+	want := "A-1:3[A-3 B-1] A-3:1.5[] B-1:0[A-1 A-3] C-2:3[] "
+	if got != want {
+		t.Errorf("CollapseSubtasks = %q, want %q", got, want)
+	}
+	if fmt.Sprint(fixture[4].DependencyKeys) != "[A-2 A-4]" {
+		t.Errorf("CollapseSubtasks modified its input: %v", fixture[4].DependencyKeys)
+	}
+}
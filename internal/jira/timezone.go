@@ -0,0 +1,29 @@
+package jira
+
+import "time"
+
+// location is the time zone dates are interpreted in, defaulting to the local one
+func (c *Client) location() *time.Location {
+	if c.loc == nil {
+		return time.Local
+	}
+	return c.loc
+}
+
+// inLocation expresses an instant, such as a changelog timestamp, in the
+// client's time zone. The zero time is returned unchanged.
+func (c *Client) inLocation(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(c.location())
+}
+
+// dateInLocation maps a calendar date from Jira, which carries no time zone,
+// onto midnight of that date in the client's time zone
+func (c *Client) dateInLocation(d time.Time) time.Time {
+	if d.IsZero() {
+		return d
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, c.location())
+}
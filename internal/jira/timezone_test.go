@@ -0,0 +1,31 @@
+package jira
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClient_DatesInLocation(t *testing.T) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	c := &Client{loc: zone}
+
+	// Jira dates carry no zone and decode as UTC midnight
+	due := c.dateInLocation(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 31, 0, 0, 0, 0, zone); !due.Equal(want) || due.Location() != zone {
+		t.Errorf("dateInLocation = %v, want %v", due, want)
+	}
+
+	// Timestamps keep their instant but read in the configured zone
+	at := time.Date(2026, 3, 31, 2, 30, 0, 0, time.UTC)
+	local := c.inLocation(at)
+	if !local.Equal(at) || local.Day() != 30 {
+		t.Errorf("inLocation = %v, want the same instant on March 30", local)
+	}
+
+	if !c.dateInLocation(time.Time{}).IsZero() || !c.inLocation(time.Time{}).IsZero() {
+		t.Error("zero times must stay zero")
+	}
+	if (&Client{}).location() != time.Local {
+		t.Error("location should default to time.Local")
+	}
+}
@@ -0,0 +1,191 @@
+package jira
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTracedBody bounds how much of each body is dumped, so attachment uploads
+// do not flood the dump file
+const maxTracedBody = 64 << 10
+
+// redacted replaces credentials in traces
+const redacted = "REDACTED"
+
+// loginPath is the Jira login resource, whose bodies carry the password and
+// the new session and are never dumped
+const loginPath = "/rest/auth/1/session"
+
+// sensitiveHeaders are never dumped, whatever their value
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// traceTransport logs every request with its status and duration and, if
+// Bodies is set, dumps the headers and bodies with credentials redacted
+type traceTransport struct {
+	Log     io.Writer // One line per request
+	Bodies  io.Writer // Request and response dumps, nil for none
+	Secrets []string  // Values redacted wherever they appear, such as the PAT
+	Base    http.RoundTripper
+
+	mu sync.Mutex // Keeps the lines and dumps of concurrent requests apart
+}
+
+// addSecret redacts value from then on, such as a session cookie obtained by
+// logging in
+func (t *traceTransport) addSecret(value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Secrets = append(t.Secrets, value)
+}
+
+// newTraceTransport wraps base with tracing as configured in opts, or returns
+// base unchanged if tracing is off
+func newTraceTransport(base http.RoundTripper, pat string, opts ClientOptions) http.RoundTripper {
+	if opts.Trace == nil && opts.TraceBodies == nil {
+		return base
+	}
+	secrets := []string{pat, opts.Password}
+	if cookies, err := http.ParseCookie(opts.SessionCookie); err == nil {
+		for _, c := range cookies {
+			secrets = append(secrets, c.Value)
+		}
+	}
+	for _, value := range opts.Headers {
+		secrets = append(secrets, value)
+	}
+	return &traceTransport{Log: opts.Trace, Bodies: opts.TraceBodies, Secrets: secrets, Base: base}
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if t.Bodies != nil && req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	started := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	elapsed := time.Since(started).Round(time.Millisecond)
+
+	var respBody []byte
+	if t.Bodies != nil && resp != nil && resp.Body != nil {
+		var readErr error
+		respBody, readErr = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		// The caller still sees a body that fails part way
+		var rest io.Reader = bytes.NewReader(nil)
+		if readErr != nil {
+			rest = errReader{readErr}
+		}
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(respBody), rest))
+	}
+
+	var outcome string
+	if err != nil {
+		outcome = "error: " + err.Error()
+	} else {
+		outcome = resp.Status
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Log != nil {
+		fmt.Fprintf(t.Log, "%s %s -> %s (%s)\n", req.Method, t.redact(req.URL.String()), t.redact(outcome), elapsed)
+	}
+	if t.Bodies != nil {
+		if strings.HasSuffix(req.URL.Path, loginPath) {
+			// JSON escapes the password, so redacting its value is not enough
+			reqBody, respBody = loginBody(reqBody), loginBody(respBody)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "> %s %s\n", req.Method, t.redact(req.URL.String()))
+		t.writeHeaders(&b, "> ", req.Header)
+		t.writeBody(&b, reqBody)
+		if err != nil {
+			fmt.Fprintf(&b, "< %s (%s)\n", t.redact(outcome), elapsed)
+		} else {
+			fmt.Fprintf(&b, "< %s %s (%s)\n", resp.Proto, resp.Status, elapsed)
+			t.writeHeaders(&b, "< ", resp.Header)
+			t.writeBody(&b, respBody)
+		}
+		b.WriteString("\n")
+		io.WriteString(t.Bodies, b.String())
+	}
+	return resp, err
+}
+
+// writeHeaders writes the headers sorted by name, prefixed by prefix
+func (t *traceTransport) writeHeaders(b *strings.Builder, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", prefix, name, t.redact(value))
+		}
+	}
+}
+
+// writeBody writes body, truncated to maxTracedBody, after an empty line
+func (t *traceTransport) writeBody(b *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	if len(body) > maxTracedBody {
+		b.WriteString(t.redact(string(body[:maxTracedBody])))
+		fmt.Fprintf(b, "\n... (%d more bytes)\n", len(body)-maxTracedBody)
+		return
+	}
+	b.WriteString(t.redact(string(body)))
+	if body[len(body)-1] != '\n' {
+		b.WriteString("\n")
+	}
+}
+
+// loginBody stands in for the body of a login request or response
+func loginBody(body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	return []byte("[login body redacted]")
+}
+
+// redact replaces the secrets in s
+func (t *traceTransport) redact(s string) string {
+	for _, secret := range t.Secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+// errReader returns err once the traced part of a body has been read
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
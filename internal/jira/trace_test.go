@@ -0,0 +1,97 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// A misbehaving gateway echoing the key must not leak it into the dump
+		w.Write([]byte(`{"version": "9.12.0", "serverTitle": "` + r.Header.Get("X-Api-Key") + `"}`))
+	}))
+	defer server.Close()
+
+	var log, bodies bytes.Buffer
+	client, err := NewClient(server.URL, "secret-pat", "", "", ClientOptions{
+		Headers:     map[string]string{"X-Api-Key": "gateway-key"},
+		Trace:       &log,
+		TraceBodies: &bodies,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	info, err := client.ServerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerInfo failed: %v", err)
+	}
+	if info.Version != "9.12.0" || info.ServerTitle != "gateway-key" {
+		t.Errorf("traced response body was not passed on intact: %+v", info)
+	}
+
+	if line := log.String(); !strings.HasPrefix(line, "GET "+server.URL+"/rest/api/2/serverInfo -> 200 OK (") {
+		t.Errorf("unexpected trace line %q", line)
+	}
+
+	dump := bodies.String()
+	for _, secret := range []string{"secret-pat", "gateway-key"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, dump)
+		}
+	}
+	for _, want := range []string{"> Authorization: REDACTED", "< HTTP/1.1 200 OK", `"version": "9.12.0"`} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump is missing %q:\n%s", want, dump)
+		}
+	}
+}
+
+func TestTraceTransport_Login(t *testing.T) {
+	const password = `p&ss<word>`
+	const session = "8F3A2C9D1E7B"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/auth/1/session" {
+			var creds map[string]string
+			json.NewDecoder(r.Body).Decode(&creds)
+			if creds["password"] != password {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"session": {"name": "JSESSIONID", "value": "` + session + `"}}`))
+			return
+		}
+		// A response echoing the session must not leak it either
+		w.Write([]byte(`{"version": "9.12.0", "serverTitle": "` + r.Header.Get("Cookie") + `"}`))
+	}))
+	defer server.Close()
+
+	var bodies bytes.Buffer
+	client, err := NewClient(server.URL, "", "", "", ClientOptions{Username: "planner", Password: password, TraceBodies: &bodies})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.ServerInfo(context.Background()); err != nil {
+		t.Fatalf("ServerInfo failed: %v", err)
+	}
+
+	dump := bodies.String()
+	escaped, _ := json.Marshal(password)
+	for _, secret := range []string{password, strings.Trim(string(escaped), `"`), session} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, dump)
+		}
+	}
+	if n := strings.Count(dump, "[login body redacted]"); n != 2 {
+		t.Errorf("expected the login request and response bodies to be redacted, got %d in:\n%s", n, dump)
+	}
+	if !strings.Contains(dump, `"serverTitle": "JSESSIONID=REDACTED"`) {
+		t.Errorf("expected the echoed session to be redacted:\n%s", dump)
+	}
+}
@@ -0,0 +1,190 @@
+package jira
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ClientOptions holds optional settings for the HTTP transport used to reach Jira
+type ClientOptions struct {
+	// APIVersion selects the REST API used for searches: "2" (default) or "3" for
+	// the Cloud /rest/api/3/search/jql endpoint
+	APIVersion string
+
+	// EffortFieldName and EpicLinkFieldName select custom fields by display name,
+	// overriding the configured IDs when they disagree
+	EffortFieldName   string
+	EpicLinkFieldName string
+
+	// FlaggedCustomFieldID is the Flagged field used to detect impeded issues
+	FlaggedCustomFieldID string
+
+	// FetchChangelog expands issue changelogs to derive actual start dates
+	FetchChangelog bool
+	// InProgressStatuses mark work as started (defaults to DefaultInProgressStatuses)
+	InProgressStatuses []string
+	// DoneStatuses mark work as finished (defaults to DefaultDoneStatuses)
+	DoneStatuses []string
+
+	// ExcludeIssueTypes drops tickets of these issue types from results
+	ExcludeIssueTypes []string
+	// IssueTypeEfforts is the effort in days used for tickets of a type that
+	// have no effort set. Issue type names are case-insensitive.
+	IssueTypeEfforts map[string]float64
+
+	// EpicBatchSize is the number of epics fetched per request (default 50).
+	// MaxJQLLength caps the length of those "key in (...)" queries for
+	// servers with small URL or JQL limits (zero means no limit).
+	EpicBatchSize int
+	MaxJQLLength  int
+
+	// ExtraFields are additional fields to fetch, by ID (e.g. "components" or
+	// "customfield_12345") or display name, reported in Ticket.Fields
+	ExtraFields []string
+
+	// CoAssigneeField is a user or multi-user field, by ID or display name,
+	// whose users share the work with the assignee (Ticket.CoAssignees)
+	CoAssigneeField string
+
+	// ReviewerField is a user field, by ID or display name, holding the
+	// reviewer of a ticket (Ticket.Reviewer)
+	ReviewerField string
+
+	// SprintField is the sprint field, by ID or display name, whose last
+	// sprint's end date is reported in Ticket.SprintEnd
+	SprintField string
+
+	// TargetStartField and TargetEndField are date fields, by ID or display
+	// name, reported in Ticket.TargetStart and Ticket.TargetEnd, such as the
+	// "Target start" and "Target end" fields of Advanced Roadmaps
+	TargetStartField string
+	TargetEndField   string
+
+	// BlockingLinkType is an issue link type whose inward issues must be done
+	// first, such as the "Blocks" type Advanced Roadmaps uses for
+	// dependencies. Outward "Dependent" links are always dependencies.
+	BlockingLinkType string
+
+	// ProxyURL routes all Jira requests through this proxy: an http:// or
+	// https:// proxy, or a socks5:// one such as an SSH dynamic tunnel
+	// ("ssh -D 1080"), which resolves the Jira host name itself. When empty,
+	// the standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply.
+	ProxyURL string
+
+	// TLSCAFile is a PEM bundle of additional CAs trusted for the Jira server
+	TLSCAFile string
+	// TLSInsecureSkipVerify disables certificate verification entirely
+	TLSInsecureSkipVerify bool
+
+	// RequestTimeout bounds each individual HTTP request (zero means no limit)
+	RequestTimeout time.Duration
+
+	// HoursPerDay and DaysPerWeek convert effort durations such as "2w 3d 4h"
+	// into days (default 8 and 5)
+	HoursPerDay float64
+	DaysPerWeek float64
+
+	// EffortUnit is the unit of plain numbers in the effort field:
+	// EffortUnitDays (default), EffortUnitHours or EffortUnitPoints, which
+	// are DaysPerPoint days each (default 1)
+	EffortUnit   string
+	DaysPerPoint float64
+
+	// Location is the time zone used for due dates and changelog timestamps
+	// (defaults to time.Local)
+	Location *time.Location
+
+	// Headers are added to every request, e.g. API gateway keys or a custom User-Agent
+	Headers map[string]string
+
+	// SessionCookie authenticates with a session captured from a browser, as
+	// "name=value" pairs separated by "; ", instead of the PAT
+	SessionCookie string
+	// Username and Password log in to a session instead of using the PAT,
+	// logging in again when the session expires
+	Username string
+	Password string
+
+	// NegotiateCommand authenticates with Kerberos (SPNEGO) instead of the
+	// PAT. The command prints a base64 SPNEGO token for the Jira host, which
+	// replaces "{host}" in its arguments; the session it starts is reused.
+	NegotiateCommand []string
+
+	// Trace receives one line per request with its method, URL, status and
+	// duration (nil for none)
+	Trace io.Writer
+	// TraceBodies receives the headers and bodies of every request and
+	// response, with the PAT and Headers values redacted (nil for none)
+	TraceBodies io.Writer
+}
+
+// newTransport builds the base HTTP round tripper from the client options
+func newTransport(opts ClientOptions) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q", opts.ProxyURL)
+		}
+		// net/http speaks SOCKS5 itself, with any user:password as credentials
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("invalid proxy_url %q (expected an http, https or socks5 URL)", opts.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.TLSCAFile != "" || opts.TLSInsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: opts.TLSInsecureSkipVerify,
+		}
+
+		if opts.TLSCAFile != "" {
+			pem, err := os.ReadFile(opts.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading tls_ca_file: %w", err)
+			}
+			// Extend the system pool so public endpoints keep working
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("tls_ca_file %s contains no valid PEM certificates", opts.TLSCAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if len(opts.Headers) > 0 {
+		return &headerTransport{Headers: opts.Headers, Base: transport}, nil
+	}
+
+	return transport, nil
+}
+
+// headerTransport sets a fixed set of headers on every request
+type headerTransport struct {
+	Headers map[string]string
+	Base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, value := range t.Headers {
+		req.Header.Set(name, value)
+	}
+	return t.Base.RoundTrip(req)
+}
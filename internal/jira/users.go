@@ -0,0 +1,125 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/andygrunwald/go-jira/v2/onpremise"
+)
+
+// UserProfile is the part of a Jira user profile shown on plan resources.
+// Jira does not expose job titles, so none is included.
+type UserProfile struct {
+	DisplayName string
+	Email       string // Empty when hidden by the user's privacy settings
+	TimeZone    string // IANA name, e.g. "Europe/Oslo"
+	AvatarURL   string // Largest avatar available
+}
+
+// assigneeID returns the identifier GetUserProfiles looks a user up by
+func assigneeID(user *onpremise.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.AccountID != "" {
+		return user.AccountID
+	}
+	return user.Name
+}
+
+// MergeAssignees makes assignee names identify users, as writers key
+// resources by Ticket.Assignee. A user whose name differs between tickets
+// (e.g. renamed since some were cached) gets the name on most of them, the
+// alphabetically first on a tie. Different users with the same name are told
+// apart by their ID, e.g. "Sam Lee (slee2)". Tickets without an assignee ID
+// are left as they are.
+func MergeAssignees(tickets []Ticket) {
+	counts := make(map[string]map[string]int) // ID -> name -> tickets
+	for _, t := range tickets {
+		if t.AssigneeID == "" {
+			continue
+		}
+		if counts[t.AssigneeID] == nil {
+			counts[t.AssigneeID] = make(map[string]int)
+		}
+		// Cached tickets may carry the name given by an earlier merge
+		counts[t.AssigneeID][strings.TrimSuffix(t.Assignee, " ("+t.AssigneeID+")")]++
+	}
+
+	names := make(map[string]string, len(counts))
+	users := make(map[string]int) // Name -> users with it
+	for id, byName := range counts {
+		var best string
+		for name, n := range byName {
+			if best == "" || n > byName[best] || n == byName[best] && name < best {
+				best = name
+			}
+		}
+		names[id] = best
+		users[best]++
+	}
+	for id, name := range names {
+		if users[name] > 1 {
+			names[id] = fmt.Sprintf("%s (%s)", name, id)
+		}
+	}
+
+	for i := range tickets {
+		if name, ok := names[tickets[i].AssigneeID]; ok {
+			tickets[i].Assignee = name
+		}
+	}
+}
+
+// GetUserProfiles fetches the profile of each distinct assignee of tickets,
+// keyed by Ticket.Assignee. Profiles that could be fetched are returned even
+// when others fail; the failures are recorded (see FetchFailures) and joined
+// into the returned error.
+func (c *Client) GetUserProfiles(ctx context.Context, tickets []Ticket) (map[string]UserProfile, error) {
+	if c.onpremiseClient == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	// Cloud looks users up by account ID, Server by username
+	param := "username"
+	if c.apiVersion == APIVersion3 {
+		param = "accountId"
+	}
+
+	profiles := make(map[string]UserProfile)
+	tried := make(map[string]bool)
+	var errs []error
+	for _, t := range tickets {
+		if t.AssigneeID == "" || tried[t.Assignee] {
+			continue
+		}
+		tried[t.Assignee] = true
+
+		var user onpremise.User
+		if err := c.getJSON(ctx, "rest/api/2/user?"+url.Values{param: {t.AssigneeID}}.Encode(), &user); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", t.Assignee, err))
+			c.problems.fail(FetchFailure{What: "user profile", Keys: []string{t.Assignee}, Err: err})
+			continue
+		}
+		profiles[t.Assignee] = UserProfile{
+			DisplayName: assigneeName(&user),
+			Email:       user.EmailAddress,
+			TimeZone:    user.TimeZone,
+			AvatarURL:   largestAvatar(user.AvatarUrls),
+		}
+	}
+	return profiles, errors.Join(errs...)
+}
+
+// largestAvatar returns the URL of the largest avatar size set
+func largestAvatar(a onpremise.AvatarUrls) string {
+	for _, u := range []string{a.Four8X48, a.Three2X32, a.Two4X24, a.One6X16} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUserProfiles(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/2/user" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("username") {
+		case "ada":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":         "ada",
+				"displayName":  "Ada Lovelace",
+				"emailAddress": "ada@example.com",
+				"timeZone":     "Europe/London",
+				"avatarUrls":   map[string]string{"16x16": "https://jira/avatar/16", "48x48": "https://jira/avatar/48"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "pat", "", "", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	tickets := []Ticket{
+		{Key: "A-1", Assignee: "Ada Lovelace", AssigneeID: "ada"},
+		{Key: "A-2", Assignee: "Ada Lovelace", AssigneeID: "ada"},
+		{Key: "A-3", Assignee: "Gone User", AssigneeID: "gone"},
+		{Key: "A-4"},
+	}
+	profiles, err := client.GetUserProfiles(context.Background(), tickets)
+	if err == nil {
+		t.Error("Expected an error for the user that could not be fetched")
+	}
+	want := UserProfile{DisplayName: "Ada Lovelace", Email: "ada@example.com", TimeZone: "Europe/London", AvatarURL: "https://jira/avatar/48"}
+	if len(profiles) != 1 || profiles["Ada Lovelace"] != want {
+		t.Errorf("profiles = %+v, want only Ada Lovelace: %+v", profiles, want)
+	}
+	if failures := client.FetchFailures(); len(failures) != 1 || failures[0].Keys[0] != "Gone User" {
+		t.Errorf("Expected a failure recording Gone User, got %v", failures)
+	}
+	if requests != 2 {
+		t.Errorf("Expected one request per distinct assignee, got %d", requests)
+	}
+}
+
+func TestMergeAssignees(t *testing.T) {
+	tickets := []Ticket{
+		{Key: "A-1", Assignee: "Sam Lee", AssigneeID: "slee"},
+		{Key: "A-2", Assignee: "Sam Lee", AssigneeID: "slee2"},
+		{Key: "A-3", Assignee: "Ann Ray", AssigneeID: "aray"},
+		{Key: "A-4", Assignee: "Ann Ray-Berg", AssigneeID: "aray"},
+		{Key: "A-5", Assignee: "Ann Ray-Berg", AssigneeID: "aray"},
+		{Key: "A-6", Assignee: "Bo Ek", AssigneeID: "bek"},
+		{Key: "A-7", Assignee: "Bo Eklund", AssigneeID: "bek"},
+		{Key: "A-8", Assignee: "Sam Lee (slee2)", AssigneeID: "slee2"},
+		{Key: "A-9", Assignee: "Review Board"},
+	}
+	MergeAssignees(tickets)
+
+	want := []string{"Sam Lee (slee)", "Sam Lee (slee2)", "Ann Ray-Berg", "Ann Ray-Berg", "Ann Ray-Berg", "Bo Ek", "Bo Ek", "Sam Lee (slee2)", "Review Board"}
+	for i, ticket := range tickets {
+		if ticket.Assignee != want[i] {
+			t.Errorf("%s: got assignee %q, want %q", ticket.Key, ticket.Assignee, want[i])
+		}
+	}
+
+	// Once the namesake is gone, the earlier merge's suffix goes too
+	rest := []Ticket{{Key: "A-2", Assignee: "Sam Lee (slee2)", AssigneeID: "slee2"}, {Key: "A-8", Assignee: "Sam Lee", AssigneeID: "slee2"}}
+	MergeAssignees(rest)
+	if rest[0].Assignee != "Sam Lee" || rest[1].Assignee != "Sam Lee" {
+		t.Errorf("Expected the plain name back, got %+v", rest)
+	}
+}
@@ -0,0 +1,76 @@
+package jira
+
+import (
+	"sort"
+	"strconv"
+)
+
+// WSJF scores tickets by Weighted Shortest Job First: the cost of delay
+// divided by the job size. The fields are keys of Ticket.Fields, so they must
+// also be requested as ClientOptions.ExtraFields.
+type WSJF struct {
+	// CostOfDelayFields are summed into the cost of delay, e.g. business
+	// value, time criticality and risk reduction
+	CostOfDelayFields []string
+	// JobSizeField holds the job size; EffortDays is used when empty
+	JobSizeField string
+}
+
+// Score returns the WSJF of a ticket, and false when none of its cost of
+// delay fields is set, a value is not a number or the job size is not positive
+func (w WSJF) Score(t Ticket) (float64, bool) {
+	var costOfDelay float64
+	found := false
+	for _, field := range w.CostOfDelayFields {
+		val, ok := t.Fields[field]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, false
+		}
+		costOfDelay += n
+		found = true
+	}
+	if !found {
+		return 0, false
+	}
+
+	size := t.EffortDays
+	if w.JobSizeField != "" {
+		n, err := strconv.ParseFloat(t.Fields[w.JobSizeField], 64)
+		if err != nil {
+			return 0, false
+		}
+		size = n
+	}
+	if size <= 0 {
+		return 0, false
+	}
+	return costOfDelay / size, true
+}
+
+// Sort orders tickets by descending score. Tickets without a score go last;
+// ties keep their order.
+func (w WSJF) Sort(tickets []Ticket) {
+	type scoredTicket struct {
+		ticket Ticket
+		score  float64
+		ok     bool
+	}
+	scored := make([]scoredTicket, len(tickets))
+	for i, t := range tickets {
+		score, ok := w.Score(t)
+		scored[i] = scoredTicket{t, score, ok}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].ok != scored[j].ok {
+			return scored[i].ok
+		}
+		return scored[i].score > scored[j].score
+	})
+	for i, s := range scored {
+		tickets[i] = s.ticket
+	}
+}
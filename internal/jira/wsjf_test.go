@@ -0,0 +1,54 @@
+package jira
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWSJF_Score(t *testing.T) {
+	w := WSJF{CostOfDelayFields: []string{"Business Value", "Time Criticality", "Risk Reduction"}}
+
+	tests := []struct {
+		name   string
+		ticket Ticket
+		want   float64
+		ok     bool
+	}{
+		{"all fields", Ticket{EffortDays: 2, Fields: map[string]string{"Business Value": "8", "Time Criticality": "5", "Risk Reduction": "3"}}, 8, true},
+		{"missing field counts as zero", Ticket{EffortDays: 4, Fields: map[string]string{"Business Value": "8"}}, 2, true},
+		{"no cost of delay", Ticket{EffortDays: 1}, 0, false},
+		{"not a number", Ticket{EffortDays: 1, Fields: map[string]string{"Business Value": "high"}}, 0, false},
+		{"no size", Ticket{Fields: map[string]string{"Business Value": "8"}}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := w.Score(tt.ticket)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: Score = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	sized := WSJF{CostOfDelayFields: []string{"Business Value"}, JobSizeField: "Job Size"}
+	if got, ok := sized.Score(Ticket{EffortDays: 10, Fields: map[string]string{"Business Value": "13", "Job Size": "2"}}); got != 6.5 || !ok {
+		t.Errorf("Score with job size field = %v, %v, want 6.5, true", got, ok)
+	}
+}
+
+func TestWSJF_Sort(t *testing.T) {
+	w := WSJF{CostOfDelayFields: []string{"Value"}}
+	tickets := []Ticket{
+		{Key: "A-1", EffortDays: 1},
+		{Key: "A-2", EffortDays: 4, Fields: map[string]string{"Value": "8"}},
+		{Key: "A-3", EffortDays: 1, Fields: map[string]string{"Value": "8"}},
+		{Key: "A-4"},
+		{Key: "A-5", EffortDays: 2, Fields: map[string]string{"Value": "4"}},
+	}
+	w.Sort(tickets)
+
+	var keys []string
+	for _, t := range tickets {
+		keys = append(keys, t.Key)
+	}
+	if got, want := fmt.Sprint(keys), "[A-3 A-2 A-5 A-1 A-4]"; got != want {
+		t.Errorf("Sort = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// smtpSession is what a fake SMTP server received
+type smtpSession struct {
+	auth  string
+	from  string
+	rcpts []string
+	data  []byte
+}
+
+// smtpServer is a fake SMTP server on localhost accepting one message,
+// without STARTTLS so the test can read it
+func smtpServer(t *testing.T) (port int, received <-chan smtpSession) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		var s smtpSession
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				s.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+				reply("235 Authenticated")
+			case "MAIL":
+				s.from = line
+				reply("250 OK")
+			case "RCPT":
+				s.rcpts = append(s.rcpts, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				var data bytes.Buffer
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				s.data = data.Bytes()
+				reply("250 Queued")
+			case "QUIT":
+				reply("221 Bye")
+				done <- s
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port, done
+}
+
+func TestSend(t *testing.T) {
+	port, received := smtpServer(t)
+	// Long enough to be wrapped over several base64 lines
+	plan := bytes.Repeat([]byte("<task id=\"t1\"/>\n"), 20)
+
+	err := Send(Config{Host: "localhost", Port: port, Username: "planner", Password: "secret", From: "plans@example.com"}, Message{
+		To:      []string{"ada@example.com", "bo@example.com"},
+		Subject: "Plan für Q3",
+		Body:    "The plan is attached.",
+		Attachments: []Attachment{
+			{Filename: "Q3 plan.oplx.zip", ContentType: "application/zip", Data: plan},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	s := <-received
+
+	if auth, _ := base64.StdEncoding.DecodeString(s.auth); string(auth) != "\x00planner\x00secret" {
+		t.Errorf("AUTH PLAIN = %q, want the username and password", auth)
+	}
+	if s.from != "MAIL FROM:<plans@example.com>" && !strings.HasPrefix(s.from, "MAIL FROM:<plans@example.com> ") {
+		t.Errorf("unexpected %q", s.from)
+	}
+	if strings.Join(s.rcpts, "|") != "RCPT TO:<ada@example.com>|RCPT TO:<bo@example.com>" {
+		t.Errorf("unexpected recipients %q", s.rcpts)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(s.data))
+	if err != nil {
+		t.Fatalf("reading the message: %v", err)
+	}
+	var dec mime.WordDecoder
+	subject, _ := dec.DecodeHeader(msg.Header.Get("Subject"))
+	for header, want := range map[string]string{
+		"From":         "plans@example.com",
+		"To":           "ada@example.com, bo@example.com",
+		"MIME-Version": "1.0",
+	} {
+		if got := msg.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if subject != "Plan für Q3" || !strings.HasPrefix(msg.Header.Get("Subject"), "=?utf-8?q?") {
+		t.Errorf("Subject = %q, want it Q-encoded", msg.Header.Get("Subject"))
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("invalid Date: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	body, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading the body part: %v", err)
+	}
+	if text, _ := io.ReadAll(body); string(text) != "The plan is attached." || body.Header.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf("body part %q with %v", text, body.Header)
+	}
+
+	attachment, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading the attachment part: %v", err)
+	}
+	if attachment.FileName() != "Q3 plan.oplx.zip" || attachment.Header.Get("Content-Type") != "application/zip" ||
+		attachment.Header.Get("Content-Transfer-Encoding") != "base64" {
+		t.Errorf("unexpected attachment headers %v", attachment.Header)
+	}
+	encoded, _ := io.ReadAll(attachment)
+	lines := strings.Split(strings.TrimRight(string(encoded), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Errorf("expected the attachment over several lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("line %d is %d characters, over 76", i, len(line))
+		}
+	}
+	if data, err := base64.StdEncoding.DecodeString(strings.Join(lines, "")); err != nil || !bytes.Equal(data, plan) {
+		t.Errorf("attachment decodes to %q, %v, want the plan", data, err)
+	}
+
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("expected two parts, got another: %v", err)
+	}
+}
+
+func TestSend_Invalid(t *testing.T) {
+	msg := Message{To: []string{"ada@example.com"}}
+	tests := map[string]struct {
+		cfg Config
+		msg Message
+	}{
+		"smtp host is not configured":         {Config{From: "plans@example.com"}, msg},
+		"smtp from address is not configured": {Config{Host: "localhost"}, msg},
+		"no recipients given":                 {Config{Host: "localhost", From: "plans@example.com"}, Message{}},
+	}
+	for want, tt := range tests {
+		if err := Send(tt.cfg, tt.msg); err == nil || err.Error() != want {
+			t.Errorf("Send = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestSend_Unreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	err = Send(Config{Host: "127.0.0.1", Port: port, From: "plans@example.com"}, Message{To: []string{"ada@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), strconv.Itoa(port)) {
+		t.Errorf("expected a connection error for port %d, got %v", port, err)
+	}
+}
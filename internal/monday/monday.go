@@ -0,0 +1,292 @@
+// Package monday reads monday.com boards through the GraphQL API as plan
+// tickets, with a configurable mapping of board columns onto effort, owners,
+// dependencies, status and dates.
+package monday
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// DefaultURL is the monday.com GraphQL endpoint
+const DefaultURL = "https://api.monday.com/v2"
+
+// apiVersion pins the API version, as its schema changes between versions
+const apiVersion = "2024-10"
+
+// pageSize is the most items monday.com returns per page
+const pageSize = 500
+
+// Client talks to the monday.com GraphQL API with a personal API token
+type Client struct {
+	URL        string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New creates a Client for the monday.com API
+func New(token string) *Client {
+	return &Client{
+		URL:        DefaultURL,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Mapping names the board columns, by column ID, that tickets are read from.
+// Unmapped columns are left out: without an effort column every item is
+// planned without an estimate.
+type Mapping struct {
+	Effort       string // Numbers or text column, in days or a duration like "4h"
+	Owner        string // People column; the first person is the assignee, the others co-assignees
+	Dependencies string // Dependency column, listing the items an item waits for
+	Status       string // Status column; labels marked done in monday.com count as done
+	Dates        string // Date column (the due date) or timeline column (target start and end)
+
+	// HoursPerDay and DaysPerWeek convert effort durations, zero for the defaults
+	HoursPerDay float64
+	DaysPerWeek float64
+}
+
+// Board is a board read as a plan. Its groups are the epics of the tickets,
+// keyed by group ID.
+type Board struct {
+	Name    string
+	Tickets []jira.Ticket
+	Epics   map[string]jira.Ticket
+}
+
+// item is an item as queried by itemFields
+type item struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Group struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"group"`
+	ColumnValues []columnValue `json:"column_values"`
+}
+
+// columnValue holds the fields of the column value types itemFields asks for
+type columnValue struct {
+	ID            string   `json:"id"`
+	Type          string   `json:"type"`
+	Text          string   `json:"text"`
+	IsDone        bool     `json:"is_done"`
+	LinkedItemIDs []string `json:"linked_item_ids"`
+	Date          string   `json:"date"`
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+}
+
+// itemsPage is a page of items, with the cursor of the next page if any
+type itemsPage struct {
+	Cursor string `json:"cursor"`
+	Items  []item `json:"items"`
+}
+
+// itemFields is the part of an item read for a ticket
+const itemFields = `fragment itemFields on Item {
+  id name url
+  group { id title }
+  column_values(ids: $columns) {
+    id type text
+    ... on StatusValue { is_done }
+    ... on DependencyValue { linked_item_ids }
+    ... on DateValue { date }
+    ... on TimelineValue { from to }
+  }
+}`
+
+// ReadBoard reads all items of a board as tickets keyed by item ID, with the
+// columns of m. Dates are midnight in loc.
+func (c *Client) ReadBoard(ctx context.Context, boardID string, m Mapping, loc *time.Location) (*Board, error) {
+	var columns []string
+	for _, id := range []string{m.Effort, m.Owner, m.Dependencies, m.Status, m.Dates} {
+		if id != "" {
+			columns = append(columns, id)
+		}
+	}
+	vars := map[string]interface{}{"board": []string{boardID}, "columns": columns, "limit": pageSize}
+
+	var first struct {
+		Boards []struct {
+			Name      string    `json:"name"`
+			ItemsPage itemsPage `json:"items_page"`
+		} `json:"boards"`
+	}
+	query := `query ($board: [ID!], $columns: [String!], $limit: Int!) {
+  boards(ids: $board) { name items_page(limit: $limit) { cursor items { ...itemFields } } }
+}
+` + itemFields
+	if err := c.query(ctx, query, vars, &first); err != nil {
+		return nil, err
+	}
+	if len(first.Boards) == 0 {
+		return nil, fmt.Errorf("board %s not found", boardID)
+	}
+
+	board := &Board{Name: first.Boards[0].Name, Epics: make(map[string]jira.Ticket)}
+	page := first.Boards[0].ItemsPage
+	for {
+		for _, it := range page.Items {
+			t, err := ticket(it, m, loc)
+			if err != nil {
+				return nil, fmt.Errorf("item %s (%s): %w", it.ID, it.Name, err)
+			}
+			board.Tickets = append(board.Tickets, t)
+			if it.Group.ID != "" {
+				board.Epics[it.Group.ID] = jira.Ticket{Key: it.Group.ID, Summary: it.Group.Title, StatusCategory: "new"}
+			}
+		}
+		if page.Cursor == "" {
+			return board, nil
+		}
+
+		var next struct {
+			NextItemsPage itemsPage `json:"next_items_page"`
+		}
+		query := `query ($cursor: String!, $columns: [String!], $limit: Int!) {
+  next_items_page(cursor: $cursor, limit: $limit) { cursor items { ...itemFields } }
+}
+` + itemFields
+		vars := map[string]interface{}{"cursor": page.Cursor, "columns": columns, "limit": pageSize}
+		if err := c.query(ctx, query, vars, &next); err != nil {
+			return nil, err
+		}
+		page = next.NextItemsPage
+	}
+}
+
+// ticket converts an item into a ticket, reading the mapped columns
+func ticket(it item, m Mapping, loc *time.Location) (jira.Ticket, error) {
+	t := jira.Ticket{
+		Key:            it.ID,
+		Summary:        it.Name,
+		Link:           it.URL,
+		StatusCategory: "new",
+		EpicLink:       it.Group.ID,
+	}
+	for _, v := range it.ColumnValues {
+		switch v.ID {
+		case m.Effort:
+			if text := strings.TrimSpace(v.Text); text != "" {
+				days, ok := jira.ParseEffortDays(text, m.HoursPerDay, m.DaysPerWeek)
+				if !ok || days < 0 {
+					return t, fmt.Errorf("invalid effort %q (expected days or a duration like 4h)", v.Text)
+				}
+				t.EffortDays = days
+			}
+		case m.Owner:
+			// People columns list display names separated by ", "
+			for i, name := range strings.Split(v.Text, ", ") {
+				if name = strings.TrimSpace(name); name == "" {
+					continue
+				} else if i == 0 {
+					t.Assignee = name
+				} else {
+					t.CoAssignees = append(t.CoAssignees, name)
+				}
+			}
+		case m.Dependencies:
+			t.DependencyKeys = v.LinkedItemIDs
+		case m.Status:
+			t.Status = v.Text
+			if v.IsDone {
+				t.StatusCategory = "done"
+			}
+		case m.Dates:
+			var err error
+			switch v.Type {
+			case "timeline":
+				if t.TargetStart, err = parseDate(v.From, loc); err != nil {
+					return t, err
+				}
+				if t.TargetEnd, err = parseDate(v.To, loc); err != nil {
+					return t, err
+				}
+				t.DueDate = t.TargetEnd
+			default:
+				if t.DueDate, err = parseDate(v.Date, loc); err != nil {
+					return t, err
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// parseDate parses the date part of a monday.com date, zero if empty
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if len(s) > len("2006-01-02") {
+		s = s[:len("2006-01-02")]
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
+
+// query runs a GraphQL query and decodes its data into v
+func (c *Client) query(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	// monday.com takes the token as is, without a scheme
+	req.Header.Set("Authorization", c.Token)
+	req.Header.Set("API-Version", apiVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("monday.com returned status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	// Errors come with a 200 status, as a GraphQL error list or, for
+	// authentication and rate limits, a single message
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if result.ErrorMessage != "" {
+		return fmt.Errorf("monday.com: %s", result.ErrorMessage)
+	}
+	if len(result.Errors) > 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("monday.com: %s", strings.Join(msgs, "; "))
+	}
+	return json.Unmarshal(result.Data, v)
+}
@@ -0,0 +1,80 @@
+package monday
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadBoard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			_, _ = w.Write([]byte(`{"error_message": "Not Authenticated"}`))
+			return
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if strings.Contains(req.Query, "next_items_page") {
+			_, _ = w.Write([]byte(`{"data": {"next_items_page": {"cursor": null, "items": [
+				{"id": "3", "name": "Launch", "url": "https://acme.monday.com/boards/1/pulses/3", "group": {"id": "release", "title": "Release"},
+				 "column_values": [
+					{"id": "status", "type": "status", "text": "Done", "is_done": true},
+					{"id": "date", "type": "timeline", "text": "2026-03-02 - 2026-03-13", "from": "2026-03-02", "to": "2026-03-13"}
+				 ]}
+			]}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"boards": [{"name": "Payments", "items_page": {"cursor": "page2", "items": [
+			{"id": "1", "name": "API", "group": {"id": "build", "title": "Build"},
+			 "column_values": [
+				{"id": "effort", "type": "numbers", "text": "3"},
+				{"id": "owner", "type": "people", "text": "Ada Lovelace, Grace Hopper"},
+				{"id": "status", "type": "status", "text": "Working on it", "is_done": false}
+			 ]},
+			{"id": "2", "name": "Checkout", "group": {"id": "build", "title": "Build"},
+			 "column_values": [
+				{"id": "effort", "type": "text", "text": "4h"},
+				{"id": "deps", "type": "dependency", "text": "API", "linked_item_ids": ["1"]}
+			 ]}
+		]}}]}}`))
+	}))
+	defer server.Close()
+
+	client := New("token")
+	client.URL = server.URL
+	mapping := Mapping{Effort: "effort", Owner: "owner", Dependencies: "deps", Status: "status", Dates: "date"}
+	board, err := client.ReadBoard(context.Background(), "1", mapping, time.UTC)
+	if err != nil {
+		t.Fatalf("ReadBoard failed: %v", err)
+	}
+
+	if board.Name != "Payments" || len(board.Tickets) != 3 {
+		t.Fatalf("Expected the 3 items of both pages of Payments, got %q with %d", board.Name, len(board.Tickets))
+	}
+	api, checkout, launch := board.Tickets[0], board.Tickets[1], board.Tickets[2]
+	if api.EffortDays != 3 || api.Assignee != "Ada Lovelace" || len(api.CoAssignees) != 1 || api.StatusCategory != "new" || api.EpicLink != "build" {
+		t.Errorf("API = %+v", api)
+	}
+	if checkout.EffortDays != 0.5 || len(checkout.DependencyKeys) != 1 || checkout.DependencyKeys[0] != "1" {
+		t.Errorf("Expected Checkout to take half a day after API, got %+v", checkout)
+	}
+	if launch.StatusCategory != "done" || launch.TargetStart.Format("2006-01-02") != "2026-03-02" || launch.TargetEnd.Format("2006-01-02") != "2026-03-13" {
+		t.Errorf("Expected Launch done with its timeline as target dates, got %+v", launch)
+	}
+	if board.Epics["release"].Summary != "Release" || len(board.Epics) != 2 {
+		t.Errorf("Expected the groups as epics, got %+v", board.Epics)
+	}
+
+	client.Token = "wrong"
+	if _, err := client.ReadBoard(context.Background(), "1", mapping, time.UTC); err == nil || !strings.Contains(err.Error(), "Not Authenticated") {
+		t.Errorf("Expected the authentication error, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package mspdi reads Microsoft Project XML (MSPDI) files and converts their
+// tasks into tickets, so legacy MS Project plans are written like Jira ones.
+package mspdi
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+)
+
+// dateFormat is the layout of MSPDI dates, which carry no time zone
+const dateFormat = "2006-01-02T15:04:05"
+
+// unassigned is the resource UID MS Project uses for assignments without a resource
+const unassigned = "-65535"
+
+// project is the subset of the MSPDI schema that is converted
+type project struct {
+	Name          string       `xml:"Name"`
+	Title         string       `xml:"Title"`
+	MinutesPerDay float64      `xml:"MinutesPerDay"`
+	Tasks         []task       `xml:"Tasks>Task"`
+	Resources     []resource   `xml:"Resources>Resource"`
+	Assignments   []assignment `xml:"Assignments>Assignment"`
+}
+
+type task struct {
+	UID             string `xml:"UID"`
+	Name            string `xml:"Name"`
+	WBS             string `xml:"WBS"`
+	OutlineLevel    int    `xml:"OutlineLevel"`
+	Summary         bool   `xml:"Summary"`
+	Milestone       bool   `xml:"Milestone"`
+	Null            bool   `xml:"IsNull"`
+	Start           string `xml:"Start"`
+	ActualStart     string `xml:"ActualStart"`
+	Deadline        string `xml:"Deadline"`
+	Work            string `xml:"Work"`
+	Duration        string `xml:"Duration"`
+	PercentComplete int    `xml:"PercentComplete"`
+	Predecessors    []struct {
+		UID string `xml:"PredecessorUID"`
+	} `xml:"PredecessorLink"`
+}
+
+type resource struct {
+	UID  string `xml:"UID"`
+	Name string `xml:"Name"`
+}
+
+type assignment struct {
+	TaskUID     string  `xml:"TaskUID"`
+	ResourceUID string  `xml:"ResourceUID"`
+	Units       float64 `xml:"Units"`
+}
+
+// Plan is an MS Project plan in the form the serializers take
+type Plan struct {
+	Name string // Project title, or else its name
+
+	// Tickets are the tasks keyed by WBS code (or UID when it has none). Summary
+	// tasks are the Parent of the tasks beneath them, for nesting as groups.
+	Tickets []jira.Ticket
+
+	// Milestones are the plan's milestones, fixed at their scheduled date
+	Milestones []omniplan.Milestone
+}
+
+// ReadFile reads the MSPDI file at path (see Read)
+func ReadFile(path string, loc *time.Location) (*Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	plan, err := Read(f, loc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return plan, nil
+}
+
+// Read converts an MSPDI document. Dates are taken to be in loc.
+//
+// Work (or, without work, the duration) becomes the effort, in days of the
+// project's MinutesPerDay. The assignee is the resource with the most units,
+// the others are co-assignees. Completed tasks are done, started ones in
+// progress. All predecessor links are read as finish-to-start; links to
+// milestones pass through to the milestone's own predecessors.
+func Read(r io.Reader, loc *time.Location) (*Plan, error) {
+	var p project
+	if err := xml.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+
+	hoursPerDay := 8.0
+	if p.MinutesPerDay > 0 {
+		hoursPerDay = p.MinutesPerDay / 60
+	}
+
+	resourceNames := make(map[string]string, len(p.Resources))
+	for _, res := range p.Resources {
+		resourceNames[res.UID] = res.Name
+	}
+	taskAssignments := make(map[string][]assignment)
+	for _, a := range p.Assignments {
+		if a.ResourceUID != unassigned && resourceNames[a.ResourceUID] != "" {
+			taskAssignments[a.TaskUID] = append(taskAssignments[a.TaskUID], a)
+		}
+	}
+
+	// The project summary task (outline level 0) and blank rows are skipped
+	tasks := slices.DeleteFunc(slices.Clone(p.Tasks), func(t task) bool {
+		return t.OutlineLevel == 0 || t.Null || strings.TrimSpace(t.Name) == ""
+	})
+
+	keys := make(map[string]string, len(tasks)) // UID -> key
+	milestones := make(map[string]task)         // UID -> milestone
+	for _, t := range tasks {
+		keys[t.UID] = t.WBS
+		if t.WBS == "" {
+			keys[t.UID] = t.UID
+		}
+		if t.Milestone && !t.Summary {
+			milestones[t.UID] = t
+		}
+	}
+
+	plan := &Plan{Name: p.Title}
+	if plan.Name == "" {
+		plan.Name = strings.TrimSuffix(p.Name, ".mpp")
+	}
+
+	var parents []task // Enclosing summary tasks, outermost first
+	for _, t := range tasks {
+		for len(parents) > 0 && parents[len(parents)-1].OutlineLevel >= t.OutlineLevel {
+			parents = parents[:len(parents)-1]
+		}
+		parent := ""
+		if len(parents) > 0 {
+			parent = keys[parents[len(parents)-1].UID]
+		}
+		if t.Summary {
+			parents = append(parents, t)
+		}
+
+		deps := predecessorKeys(t, keys, milestones, map[string]bool{})
+		if _, ok := milestones[t.UID]; ok {
+			date, err := parseDate(t.Start, loc)
+			if err != nil {
+				return nil, fmt.Errorf("milestone %q: %w", t.Name, err)
+			}
+			plan.Milestones = append(plan.Milestones, omniplan.Milestone{Name: t.Name, Date: midnight(date), DependsOn: deps})
+			continue
+		}
+
+		ticket := jira.Ticket{
+			Key:            keys[t.UID],
+			Summary:        t.Name,
+			Parent:         parent,
+			DependencyKeys: deps,
+		}
+		switch {
+		case t.PercentComplete >= 100:
+			ticket.Status, ticket.StatusCategory = "Done", "done"
+		case t.PercentComplete > 0 || t.ActualStart != "":
+			ticket.Status, ticket.StatusCategory = "In Progress", "indeterminate"
+		default:
+			ticket.Status, ticket.StatusCategory = "To Do", "new"
+		}
+
+		hours, err := parseDuration(t.Work)
+		if err == nil && hours == 0 {
+			hours, err = parseDuration(t.Duration)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("task %q: %w", t.Name, err)
+		}
+		ticket.EffortDays = hours / hoursPerDay
+
+		if t.ActualStart != "" {
+			if ticket.ActualStart, err = parseDate(t.ActualStart, loc); err != nil {
+				return nil, fmt.Errorf("task %q: %w", t.Name, err)
+			}
+		}
+		if t.Deadline != "" {
+			deadline, err := parseDate(t.Deadline, loc)
+			if err != nil {
+				return nil, fmt.Errorf("task %q: %w", t.Name, err)
+			}
+			ticket.DueDate = midnight(deadline)
+		}
+
+		// The resource doing most of the work is the assignee
+		as := taskAssignments[t.UID]
+		slices.SortStableFunc(as, func(a, b assignment) int { return cmp.Compare(b.Units, a.Units) })
+		for i, a := range as {
+			if i == 0 {
+				ticket.Assignee = resourceNames[a.ResourceUID]
+			} else if name := resourceNames[a.ResourceUID]; name != ticket.Assignee && !slices.Contains(ticket.CoAssignees, name) {
+				ticket.CoAssignees = append(ticket.CoAssignees, name)
+			}
+		}
+
+		plan.Tickets = append(plan.Tickets, ticket)
+	}
+	return plan, nil
+}
+
+// predecessorKeys returns the keys of a task's predecessors, replacing
+// milestones by their own predecessors
+func predecessorKeys(t task, keys map[string]string, milestones map[string]task, seen map[string]bool) []string {
+	var deps []string
+	for _, pred := range t.Predecessors {
+		if seen[pred.UID] {
+			continue
+		}
+		seen[pred.UID] = true
+		if m, ok := milestones[pred.UID]; ok {
+			deps = append(deps, predecessorKeys(m, keys, milestones, seen)...)
+		} else if key, ok := keys[pred.UID]; ok {
+			deps = append(deps, key)
+		}
+	}
+	return deps
+}
+
+// durationPattern matches the ISO 8601 durations of MSPDI, e.g. PT16H30M0S
+var durationPattern = regexp.MustCompile(`^PT(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?$`)
+
+// parseDuration returns an MSPDI duration in hours; empty means zero
+func parseDuration(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var hours float64
+	for i, scale := range []float64{1, 60, 3600} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		hours += n / scale
+	}
+	return hours, nil
+}
+
+// parseDate parses an MSPDI date in loc
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(dateFormat, s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
+
+// midnight returns the start of t's day
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,100 @@
+package mspdi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<Project xmlns="http://schemas.microsoft.com/project">
+  <Name>Migration.mpp</Name>
+  <Title>Data Centre Migration</Title>
+  <MinutesPerDay>480</MinutesPerDay>
+  <Tasks>
+    <Task><UID>0</UID><Name>Data Centre Migration</Name><OutlineLevel>0</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>1</UID><Name>Preparation</Name><WBS>1</WBS><OutlineLevel>1</OutlineLevel><Summary>1</Summary></Task>
+    <Task><UID>2</UID><Name>Inventory</Name><WBS>1.1</WBS><OutlineLevel>2</OutlineLevel>
+      <Work>PT24H0M0S</Work><PercentComplete>100</PercentComplete><ActualStart>2025-03-03T08:00:00</ActualStart></Task>
+    <Task><UID>3</UID><Name>Order hardware</Name><WBS>1.2</WBS><OutlineLevel>2</OutlineLevel>
+      <Work>PT0H0M0S</Work><Duration>PT4H30M0S</Duration><Deadline>2025-04-01T17:00:00</Deadline>
+      <PredecessorLink><PredecessorUID>2</PredecessorUID><Type>1</Type></PredecessorLink></Task>
+    <Task><UID>4</UID><Name>Ready to move</Name><WBS>1.3</WBS><OutlineLevel>2</OutlineLevel><Milestone>1</Milestone>
+      <Start>2025-04-14T17:00:00</Start>
+      <PredecessorLink><PredecessorUID>3</PredecessorUID></PredecessorLink></Task>
+    <Task><UID>5</UID><Name>Move racks</Name><WBS>2</WBS><OutlineLevel>1</OutlineLevel><Work>PT80H0M0S</Work>
+      <PredecessorLink><PredecessorUID>4</PredecessorUID></PredecessorLink></Task>
+  </Tasks>
+  <Resources>
+    <Resource><UID>0</UID></Resource>
+    <Resource><UID>1</UID><Name>Alice</Name></Resource>
+    <Resource><UID>2</UID><Name>Bob</Name></Resource>
+  </Resources>
+  <Assignments>
+    <Assignment><TaskUID>2</TaskUID><ResourceUID>1</ResourceUID><Units>1</Units></Assignment>
+    <Assignment><TaskUID>5</TaskUID><ResourceUID>1</ResourceUID><Units>0.5</Units></Assignment>
+    <Assignment><TaskUID>5</TaskUID><ResourceUID>2</ResourceUID><Units>1</Units></Assignment>
+    <Assignment><TaskUID>3</TaskUID><ResourceUID>-65535</ResourceUID><Units>1</Units></Assignment>
+  </Assignments>
+</Project>`
+
+func TestRead(t *testing.T) {
+	plan, err := Read(strings.NewReader(sample), time.UTC)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if plan.Name != "Data Centre Migration" {
+		t.Errorf("expected the project title, got %q", plan.Name)
+	}
+	if len(plan.Tickets) != 4 {
+		t.Fatalf("expected 4 tasks, got %d: %+v", len(plan.Tickets), plan.Tickets)
+	}
+
+	if got := plan.Tickets[0]; got.Key != "1" || got.Parent != "" || got.EffortDays != 0 {
+		t.Errorf("unexpected summary task %+v", got)
+	}
+	inventory := plan.Tickets[1]
+	if inventory.Key != "1.1" || inventory.Parent != "1" || inventory.EffortDays != 3 || !inventory.IsDone() || inventory.Assignee != "Alice" {
+		t.Errorf("unexpected first task %+v", inventory)
+	}
+	if want := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC); !inventory.ActualStart.Equal(want) {
+		t.Errorf("expected actual start %v, got %v", want, inventory.ActualStart)
+	}
+
+	order := plan.Tickets[2]
+	if order.EffortDays != 4.5/8 || order.Assignee != "" || order.StatusCategory != "new" {
+		t.Errorf("expected the duration as effort and no assignee, got %+v", order)
+	}
+	if strings.Join(order.DependencyKeys, ",") != "1.1" {
+		t.Errorf("expected a dependency on 1.1, got %v", order.DependencyKeys)
+	}
+	if want := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !order.DueDate.Equal(want) {
+		t.Errorf("expected due date %v, got %v", want, order.DueDate)
+	}
+
+	move := plan.Tickets[3]
+	if move.Parent != "" || move.EffortDays != 10 {
+		t.Errorf("unexpected last task %+v", move)
+	}
+	if move.Assignee != "Bob" || strings.Join(move.CoAssignees, ",") != "Alice" {
+		t.Errorf("expected Bob assigned with Alice as co-assignee, got %q and %v", move.Assignee, move.CoAssignees)
+	}
+	if strings.Join(move.DependencyKeys, ",") != "1.2" {
+		t.Errorf("expected the milestone's predecessor as dependency, got %v", move.DependencyKeys)
+	}
+
+	if len(plan.Milestones) != 1 {
+		t.Fatalf("expected 1 milestone, got %d", len(plan.Milestones))
+	}
+	m := plan.Milestones[0]
+	if m.Name != "Ready to move" || !m.Date.Equal(time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)) || strings.Join(m.DependsOn, ",") != "1.2" {
+		t.Errorf("unexpected milestone %+v", m)
+	}
+}
+
+func TestRead_InvalidDuration(t *testing.T) {
+	doc := `<Project><Tasks><Task><UID>1</UID><Name>A</Name><OutlineLevel>1</OutlineLevel><Work>16 hours</Work></Task></Tasks></Project>`
+	if _, err := Read(strings.NewReader(doc), time.UTC); err == nil || !strings.Contains(err.Error(), "invalid duration") {
+		t.Errorf("expected an invalid duration error, got %v", err)
+	}
+}
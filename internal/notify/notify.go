@@ -0,0 +1,147 @@
+// Package notify posts plan change summaries to chat webhooks (Slack, Microsoft Teams).
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+)
+
+// Supported webhook kinds
+const (
+	KindSlack = "slack"
+	KindTeams = "teams"
+)
+
+// secondsPerDay matches the 8-hour working day used by the serializer
+const secondsPerDay = 8 * 3600
+
+// maxListedTasks caps how many individual tickets are listed per section
+const maxListedTasks = 10
+
+// Notifier posts messages to an incoming webhook
+type Notifier struct {
+	WebhookURL string
+	Kind       string
+	HTTPClient *http.Client
+}
+
+// New creates a Notifier for the given webhook URL and kind.
+// An empty kind defaults to Slack.
+func New(webhookURL, kind string) (*Notifier, error) {
+	kind = strings.ToLower(kind)
+	if kind == "" {
+		kind = KindSlack
+	}
+	if kind != KindSlack && kind != KindTeams {
+		return nil, fmt.Errorf("unsupported webhook type %q (expected %q or %q)", kind, KindSlack, KindTeams)
+	}
+
+	return &Notifier{
+		WebhookURL: webhookURL,
+		Kind:       kind,
+		HTTPClient: http.DefaultClient,
+	}, nil
+}
+
+// NotifyChanges posts a summary of diff for the named project
+func (n *Notifier) NotifyChanges(ctx context.Context, projectName string, diff *omniplan.ScenarioDiff) error {
+	title := fmt.Sprintf("Plan %s was regenerated", projectName)
+	return n.post(ctx, title, FormatDiff(diff))
+}
+
+func (n *Notifier) post(ctx context.Context, title, text string) error {
+	var payload interface{}
+	switch n.Kind {
+	case KindTeams:
+		// Teams renders single newlines as spaces, so paragraphs need blank lines
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			"summary":  title,
+			"title":    title,
+			"text":     strings.ReplaceAll(text, "\n", "\n\n"),
+		}
+	default:
+		payload = map[string]string{
+			"text": fmt.Sprintf("*%s*\n%s", title, text),
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// FormatDiff renders a human readable summary of the plan changes
+func FormatDiff(diff *omniplan.ScenarioDiff) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Total effort change: %s\n", formatDays(diff.EffortDelta, true))
+
+	writeTasks(&b, "New tickets", diff.Added, func(c omniplan.TaskChange) string {
+		return formatDays(c.NewEffort, false)
+	})
+	writeTasks(&b, "Removed tickets", diff.Removed, func(c omniplan.TaskChange) string {
+		return formatDays(c.OldEffort, false)
+	})
+	writeTasks(&b, "Effort changes", diff.EffortChanged, func(c omniplan.TaskChange) string {
+		return fmt.Sprintf("%s → %s", formatDays(c.OldEffort, false), formatDays(c.NewEffort, false))
+	})
+
+	if len(diff.SlippedMilestones) > 0 {
+		fmt.Fprintf(&b, "Slipped milestones (%d):\n", len(diff.SlippedMilestones))
+		for _, m := range diff.SlippedMilestones {
+			fmt.Fprintf(&b, "• %s (%s of upstream work)\n", m.Title, formatDays(m.NewEffort-m.OldEffort, true))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeTasks(b *strings.Builder, heading string, changes []omniplan.TaskChange, detail func(omniplan.TaskChange) string) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d):\n", heading, len(changes))
+	for i, c := range changes {
+		if i == maxListedTasks {
+			fmt.Fprintf(b, "• …and %d more\n", len(changes)-maxListedTasks)
+			break
+		}
+		fmt.Fprintf(b, "• %s %s (%s)\n", c.Key, c.Title, detail(c))
+	}
+}
+
+// formatDays converts seconds of effort into a day count, optionally signed
+func formatDays(seconds int64, signed bool) string {
+	days := float64(seconds) / secondsPerDay
+	if signed {
+		return fmt.Sprintf("%+.1fd", days)
+	}
+	return fmt.Sprintf("%.1fd", days)
+}
@@ -0,0 +1,334 @@
+// Package notion reads Notion databases through the REST API as plan tickets,
+// with a configurable mapping of database properties onto status, estimate,
+// owner, dependencies and dates.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// DefaultURL is the Notion API endpoint
+const DefaultURL = "https://api.notion.com/v1"
+
+// apiVersion pins the API version, as its responses change between versions
+const apiVersion = "2022-06-28"
+
+// pageSize is the most pages Notion returns per query
+const pageSize = 100
+
+// Client talks to the Notion API with an integration token
+type Client struct {
+	URL        string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New creates a Client for the Notion API
+func New(token string) *Client {
+	return &Client{
+		URL:        DefaultURL,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Mapping names the database properties, by name, that tickets are read
+// from. Unmapped properties are left out: without an estimate property every
+// page is planned without an estimate.
+type Mapping struct {
+	Status       string // Status, select or checkbox property
+	Estimate     string // Number property in days, or text or select with a duration like "4h"
+	Owner        string // People property; the first person is the assignee, the others co-assignees
+	Dependencies string // Relation property listing the pages a page waits for, e.g. "Blocked by"
+	Dates        string // Date property: a single date is the due date, a range the target start and end
+
+	// HoursPerDay and DaysPerWeek convert estimate durations, zero for the defaults
+	HoursPerDay float64
+	DaysPerWeek float64
+}
+
+// Database is a database read as a plan
+type Database struct {
+	Name    string
+	Tickets []jira.Ticket
+}
+
+// database is the part of a database object used here
+type database struct {
+	Title      []richText `json:"title"`
+	Properties map[string]struct {
+		Type   string `json:"type"`
+		Status *struct {
+			Options []option `json:"options"`
+			Groups  []struct {
+				Name      string   `json:"name"`
+				OptionIDs []string `json:"option_ids"`
+			} `json:"groups"`
+		} `json:"status"`
+	} `json:"properties"`
+}
+
+// page is a database entry
+type page struct {
+	ID         string                   `json:"id"`
+	URL        string                   `json:"url"`
+	Properties map[string]propertyValue `json:"properties"`
+}
+
+// propertyValue holds the fields of the property types read here
+type propertyValue struct {
+	Type     string     `json:"type"`
+	Title    []richText `json:"title"`
+	RichText []richText `json:"rich_text"`
+	Number   *float64   `json:"number"`
+	Select   *option    `json:"select"`
+	Status   *option    `json:"status"`
+	Checkbox bool       `json:"checkbox"`
+	People   []struct {
+		Name string `json:"name"`
+	} `json:"people"`
+	Relation []struct {
+		ID string `json:"id"`
+	} `json:"relation"`
+	Date *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"date"`
+	Formula *struct {
+		Number *float64 `json:"number"`
+		String string   `json:"string"`
+	} `json:"formula"`
+}
+
+type richText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type option struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// plainText joins rich text into a string
+func plainText(parts []richText) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p.PlainText)
+	}
+	return b.String()
+}
+
+// ReadDatabase reads all pages of a database as tickets keyed by page ID, with
+// the properties of m. Dates are midnight in loc.
+func (c *Client) ReadDatabase(ctx context.Context, databaseID string, m Mapping, loc *time.Location) (*Database, error) {
+	var db database
+	if err := c.do(ctx, http.MethodGet, "databases/"+url.PathEscape(databaseID), nil, &db); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{m.Status, m.Estimate, m.Owner, m.Dependencies, m.Dates} {
+		if _, ok := db.Properties[name]; name != "" && !ok {
+			return nil, fmt.Errorf("database has no property %q", name)
+		}
+	}
+	categories := statusCategories(db, m.Status)
+
+	result := &Database{Name: plainText(db.Title)}
+	body := map[string]interface{}{"page_size": pageSize}
+	for {
+		var resp struct {
+			Results    []page `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.do(ctx, http.MethodPost, "databases/"+url.PathEscape(databaseID)+"/query", body, &resp); err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Results {
+			t, err := ticket(p, m, categories, loc)
+			if err != nil {
+				return nil, fmt.Errorf("page %s: %w", p.URL, err)
+			}
+			result.Tickets = append(result.Tickets, t)
+		}
+		if !resp.HasMore {
+			return result, nil
+		}
+		body["start_cursor"] = resp.NextCursor
+	}
+}
+
+// statusCategories maps the options of a status property to status
+// categories by their Notion group: "To-do", "In progress" or "Complete"
+func statusCategories(db database, name string) map[string]string {
+	categories := make(map[string]string)
+	prop, ok := db.Properties[name]
+	if !ok || prop.Status == nil {
+		return categories
+	}
+	names := make(map[string]string, len(prop.Status.Options))
+	for _, o := range prop.Status.Options {
+		names[o.ID] = o.Name
+	}
+	for _, g := range prop.Status.Groups {
+		category := "new"
+		switch strings.ToLower(g.Name) {
+		case "in progress":
+			category = "indeterminate"
+		case "complete":
+			category = "done"
+		}
+		for _, id := range g.OptionIDs {
+			categories[names[id]] = category
+		}
+	}
+	return categories
+}
+
+// ticket converts a page into a ticket, reading the mapped properties
+func ticket(p page, m Mapping, categories map[string]string, loc *time.Location) (jira.Ticket, error) {
+	t := jira.Ticket{Key: p.ID, Link: p.URL, StatusCategory: "new"}
+	for _, v := range p.Properties {
+		if v.Type == "title" {
+			t.Summary = plainText(v.Title)
+		}
+	}
+
+	if v, ok := p.Properties[m.Status]; ok && m.Status != "" {
+		switch {
+		case v.Status != nil:
+			t.Status = v.Status.Name
+			if category, ok := categories[v.Status.Name]; ok {
+				t.StatusCategory = category
+			}
+		case v.Select != nil:
+			t.Status = v.Select.Name
+		case v.Type == "checkbox" && v.Checkbox:
+			t.Status = "Done"
+			t.StatusCategory = "done"
+		}
+	}
+
+	if v, ok := p.Properties[m.Estimate]; ok && m.Estimate != "" {
+		var text string
+		switch {
+		case v.Number != nil:
+			t.EffortDays = *v.Number
+		case v.Formula != nil && v.Formula.Number != nil:
+			t.EffortDays = *v.Formula.Number
+		case v.Formula != nil:
+			text = v.Formula.String
+		case v.Select != nil:
+			text = v.Select.Name
+		default:
+			text = plainText(v.RichText)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			days, ok := jira.ParseEffortDays(text, m.HoursPerDay, m.DaysPerWeek)
+			if !ok {
+				return t, fmt.Errorf("invalid estimate %q (expected days or a duration like 4h)", text)
+			}
+			t.EffortDays = days
+		}
+		if t.EffortDays < 0 {
+			return t, fmt.Errorf("invalid estimate %v", t.EffortDays)
+		}
+	}
+
+	if v, ok := p.Properties[m.Owner]; ok && m.Owner != "" {
+		for i, person := range v.People {
+			if i == 0 {
+				t.Assignee = person.Name
+			} else {
+				t.CoAssignees = append(t.CoAssignees, person.Name)
+			}
+		}
+	}
+
+	if v, ok := p.Properties[m.Dependencies]; ok && m.Dependencies != "" {
+		for _, r := range v.Relation {
+			t.DependencyKeys = append(t.DependencyKeys, r.ID)
+		}
+	}
+
+	if v, ok := p.Properties[m.Dates]; ok && m.Dates != "" && v.Date != nil {
+		start, err := parseDate(v.Date.Start, loc)
+		if err != nil {
+			return t, err
+		}
+		end, err := parseDate(v.Date.End, loc)
+		if err != nil {
+			return t, err
+		}
+		if end.IsZero() {
+			t.DueDate = start
+		} else {
+			t.TargetStart, t.TargetEnd, t.DueDate = start, end, end
+		}
+	}
+	return t, nil
+}
+
+// parseDate parses the date part of a Notion date, zero if empty
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if len(s) > len("2006-01-02") {
+		s = s[:len("2006-01-02")]
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, payload, v interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+"/"+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Notion-Version", apiVersion)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Notion explains errors in a JSON object
+		var notionErr struct {
+			Message string `json:"message"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if json.Unmarshal(msg, &notionErr) == nil && notionErr.Message != "" {
+			return fmt.Errorf("Notion returned status %s: %s", resp.Status, notionErr.Message)
+		}
+		return fmt.Errorf("Notion returned status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,97 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadDatabase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"object": "error", "status": 401, "message": "API token is invalid."}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/databases/db1":
+			_, _ = w.Write([]byte(`{"title": [{"plain_text": "Road"}, {"plain_text": "map"}], "properties": {
+				"Name": {"type": "title"},
+				"Status": {"type": "status", "status": {
+					"options": [{"id": "a", "name": "Not started"}, {"id": "b", "name": "Building"}, {"id": "c", "name": "Shipped"}],
+					"groups": [{"name": "To-do", "option_ids": ["a"]}, {"name": "In progress", "option_ids": ["b"]}, {"name": "Complete", "option_ids": ["c"]}]
+				}},
+				"Estimate": {"type": "rich_text"},
+				"Assignee": {"type": "people"},
+				"Blocked by": {"type": "relation"},
+				"Dates": {"type": "date"}
+			}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/databases/db1/query":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["start_cursor"] == nil {
+				_, _ = w.Write([]byte(`{"has_more": true, "next_cursor": "c2", "results": [
+					{"id": "p1", "url": "https://www.notion.so/p1", "properties": {
+						"Name": {"type": "title", "title": [{"plain_text": "API"}]},
+						"Status": {"type": "status", "status": {"name": "Building"}},
+						"Estimate": {"type": "rich_text", "rich_text": [{"plain_text": "1w"}]},
+						"Assignee": {"type": "people", "people": [{"name": "Ada Lovelace"}, {"name": "Grace Hopper"}]},
+						"Dates": {"type": "date", "date": {"start": "2026-03-02", "end": "2026-03-13"}}
+					}}
+				]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"has_more": false, "results": [
+				{"id": "p2", "properties": {
+					"Name": {"type": "title", "title": [{"plain_text": "Launch"}]},
+					"Status": {"type": "status", "status": {"name": "Shipped"}},
+					"Estimate": {"type": "rich_text", "rich_text": []},
+					"Blocked by": {"type": "relation", "relation": [{"id": "p1"}]},
+					"Dates": {"type": "date", "date": {"start": "2026-03-20T10:00:00.000+01:00"}}
+				}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"object": "error", "status": 404, "message": "Could not find database."}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New("token")
+	client.URL = server.URL
+	mapping := Mapping{Status: "Status", Estimate: "Estimate", Owner: "Assignee", Dependencies: "Blocked by", Dates: "Dates"}
+	db, err := client.ReadDatabase(context.Background(), "db1", mapping, time.UTC)
+	if err != nil {
+		t.Fatalf("ReadDatabase failed: %v", err)
+	}
+
+	if db.Name != "Roadmap" || len(db.Tickets) != 2 {
+		t.Fatalf("Expected the pages of both result pages of Roadmap, got %q with %d", db.Name, len(db.Tickets))
+	}
+	api, launch := db.Tickets[0], db.Tickets[1]
+	if api.Summary != "API" || api.EffortDays != 5 || api.StatusCategory != "indeterminate" || api.Assignee != "Ada Lovelace" || len(api.CoAssignees) != 1 {
+		t.Errorf("API = %+v", api)
+	}
+	if api.TargetStart.Format("2006-01-02") != "2026-03-02" || api.TargetEnd.Format("2006-01-02") != "2026-03-13" {
+		t.Errorf("Expected the date range as target dates, got %v..%v", api.TargetStart, api.TargetEnd)
+	}
+	if launch.StatusCategory != "done" || launch.EffortDays != 0 || len(launch.DependencyKeys) != 1 || launch.DependencyKeys[0] != "p1" {
+		t.Errorf("Launch = %+v", launch)
+	}
+	if launch.DueDate.Format("2006-01-02") != "2026-03-20" || !launch.TargetEnd.IsZero() {
+		t.Errorf("Expected a single date as due date, got %v", launch.DueDate)
+	}
+
+	mapping.Owner = "Owner"
+	if _, err := client.ReadDatabase(context.Background(), "db1", mapping, time.UTC); err == nil || !strings.Contains(err.Error(), `"Owner"`) {
+		t.Errorf("Expected an error for the missing property, got %v", err)
+	}
+	client.Token = "wrong"
+	if _, err := client.ReadDatabase(context.Background(), "db1", mapping, time.UTC); err == nil || !strings.Contains(err.Error(), "API token is invalid") {
+		t.Errorf("Expected Notion's error message, got %v", err)
+	}
+}
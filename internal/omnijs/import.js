@@ -0,0 +1,91 @@
+// Creates or updates the plan's tasks in the OmniPlan document the script runs
+// in. Tasks are matched by their "Jira Key" custom data, so running a newer
+// script updates them in place: new tickets are added to their epic group,
+// existing tasks keep their place, and removed tickets are left alone.
+(() => {
+	const scenario = document.project.actual;
+
+	// Tasks and groups already in the document, by Jira key
+	const byKey = {};
+	const walk = (task) => {
+		task.subtasks.forEach((child) => {
+			const key = child.customValue("Jira Key");
+			if (key) {
+				byKey[key] = child;
+			}
+			walk(child);
+		});
+	};
+	walk(scenario.rootTask);
+
+	const resources = {};
+	const resource = (name) => {
+		if (!resources[name]) {
+			let r = scenario.resourceNamed(name);
+			if (!r) {
+				r = scenario.rootResource.addMember();
+				r.name = name;
+				r.type = ResourceType.staff;
+			}
+			resources[name] = r;
+		}
+		return resources[name];
+	};
+
+	let created = 0;
+	let updated = 0;
+	const upsert = (item, parent) => {
+		let task = byKey[item.key];
+		if (task) {
+			updated++;
+		} else {
+			task = parent.addSubtask();
+			byKey[item.key] = task;
+			created++;
+		}
+		task.title = item.title;
+		task.setCustomValue("Jira Key", item.key);
+		task.setCustomValue("Jira Link", item.link);
+		task.setCustomValue("Jira Status", item.status);
+		return task;
+	};
+
+	plan.epics.forEach((epic) => upsert(epic, scenario.rootTask));
+
+	// Parents first, so sub-tasks can be added below them
+	const parents = new Set(plan.tasks.map((item) => item.parent));
+	const ordered = plan.tasks.filter((item) => !item.parent).concat(plan.tasks.filter((item) => item.parent));
+	ordered.forEach((item) => {
+		const parent = byKey[item.parent] || byKey[item.epic] || scenario.rootTask;
+		const task = upsert(item, parent);
+		task.setCustomValue("Jira Type", item.type);
+
+		// A parent's effort and completion roll up from its sub-tasks
+		if (!parents.has(item.key)) {
+			task.effort = item.effort;
+			if (item.done) {
+				task.completed = 1;
+			} else if (task.completed >= 1) {
+				task.completed = 0;
+			}
+		}
+
+		item.assignees.forEach((name) => {
+			if (!task.assignments.some((a) => a.resource.name === name)) {
+				task.assign(resource(name));
+			}
+		});
+	});
+
+	plan.tasks.forEach((item) => {
+		const task = byKey[item.key];
+		item.dependsOn.forEach((key) => {
+			const prerequisite = byKey[key];
+			if (prerequisite && prerequisite !== task && !task.prerequisites.some((d) => d.prerequisiteTask === prerequisite)) {
+				task.addPrerequisite(prerequisite);
+			}
+		});
+	});
+
+	console.log(`${plan.project}: ${created} tasks created, ${updated} updated`);
+})();
@@ -0,0 +1,115 @@
+// Package omnijs exports tickets as an Omni Automation script that creates or
+// updates the plan's tasks when run in OmniPlan, as an alternative to
+// writing the .oplx package.
+package omnijs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// script applies the plan, declared before it as the constant plan
+//
+//go:embed import.js
+var script string
+
+// hoursPerDay converts estimates to effort, matching the serializer
+const hoursPerDay = 8
+
+// plan is the data the script applies
+type plan struct {
+	Project string `json:"project"`
+	Epics   []item `json:"epics"`
+	Tasks   []item `json:"tasks"`
+}
+
+// item is an epic group or a task, keyed by its Jira key
+type item struct {
+	Key       string   `json:"key"`
+	Title     string   `json:"title"`
+	Link      string   `json:"link"`
+	Status    string   `json:"status"`
+	Type      string   `json:"type,omitempty"`
+	Epic      string   `json:"epic,omitempty"`
+	Parent    string   `json:"parent,omitempty"`
+	Effort    int64    `json:"effort"` // Seconds, as OmniPlan counts effort
+	Done      bool     `json:"done"`
+	Assignees []string `json:"assignees"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// Write renders a script that applies the tickets to the open OmniPlan
+// document: one group per epic, with its tickets as tasks, sub-tasks below
+// their parent, assignments and finish-to-start dependencies. Task titles
+// start with the prefix of their issue type in prefixes, as in generated
+// packages, so the script can update those too.
+func Write(w io.Writer, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket, prefixes map[string]string) error {
+	p := plan{Project: projectName, Epics: []item{}, Tasks: make([]item, 0, len(tickets))}
+	keys := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		keys[t.Key] = true
+	}
+	seen := make(map[string]bool)
+	for _, t := range tickets {
+		if t.EpicLink != "" && !seen[t.EpicLink] {
+			seen[t.EpicLink] = true
+			epic, ok := epics[t.EpicLink]
+			if !ok || epic.Summary == "" {
+				epic.Summary = t.EpicLink
+			}
+			p.Epics = append(p.Epics, item{Key: t.EpicLink, Title: epic.Summary, Link: epic.Link, Status: epic.Status, Assignees: []string{}, DependsOn: []string{}})
+		}
+
+		task := item{
+			Key:       t.Key,
+			Title:     issueTypePrefix(prefixes, t.IssueType) + t.Summary,
+			Link:      t.Link,
+			Status:    t.Status,
+			Type:      t.IssueType,
+			Epic:      t.EpicLink,
+			Effort:    int64(schedule.EffortHours(t, hoursPerDay) * 3600),
+			Done:      t.IsDone(),
+			Assignees: []string{},
+			DependsOn: []string{},
+		}
+		if keys[t.Parent] {
+			task.Parent = t.Parent
+		}
+		for _, name := range append([]string{t.Assignee}, t.CoAssignees...) {
+			if name != "" {
+				task.Assignees = append(task.Assignees, name)
+			}
+		}
+		for _, key := range t.DependencyKeys {
+			if keys[key] {
+				task.DependsOn = append(task.DependsOn, key)
+			}
+		}
+		p.Tasks = append(p.Tasks, task)
+	}
+
+	// JSON escapes the characters that would end a JavaScript string early
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "// %s, generated by jql-to-plan. Run it in OmniPlan's Automation Console\n// with the plan open.\nconst plan = %s;\n\n%s", strings.Join(strings.Fields(projectName), " "), data, script)
+	return err
+}
+
+// issueTypePrefix returns the title prefix for an issue type, see
+// omniplan.Serializer.IssueTypePrefixes
+func issueTypePrefix(prefixes map[string]string, issueType string) string {
+	for name, prefix := range prefixes {
+		if issueType != "" && strings.EqualFold(name, issueType) {
+			return prefix
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package omnijs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+func TestWrite(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Build API", IssueType: "Bug", Assignee: "Alice", CoAssignees: []string{"Bob"}, EffortDays: 1.5, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "\"Quoted\" docs", EpicLink: "EPIC-1", DependencyKeys: []string{"TASK-1", "OTHER-1"}, StatusCategory: "done"},
+		{Key: "TASK-3", Summary: "Review", Parent: "TASK-2"},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Backend", Link: "https://jira/browse/EPIC-1"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Payments\nQ1", tickets, epics, map[string]string{"bug": "🐞 "}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "// Payments Q1, generated by jql-to-plan.") || !strings.HasSuffix(out, script) {
+		t.Fatalf("Expected a header comment, the plan and the script, got:\n%s", out)
+	}
+
+	start := strings.Index(out, "const plan = ") + len("const plan = ")
+	end := strings.Index(out, ";\n\n")
+	var p plan
+	if err := json.Unmarshal([]byte(out[start:end]), &p); err != nil {
+		t.Fatalf("The plan is not JSON: %v", err)
+	}
+	if len(p.Epics) != 1 || p.Epics[0].Title != "Backend" || p.Epics[0].Link != "https://jira/browse/EPIC-1" {
+		t.Errorf("Epics = %+v", p.Epics)
+	}
+	if len(p.Tasks) != 3 {
+		t.Fatalf("Expected 3 tasks, got %+v", p.Tasks)
+	}
+	api, docs, review := p.Tasks[0], p.Tasks[1], p.Tasks[2]
+	if api.Title != "🐞 Build API" || api.Effort != 12*3600 || len(api.Assignees) != 2 || api.Epic != "EPIC-1" {
+		t.Errorf("API = %+v", api)
+	}
+	if docs.Title != `"Quoted" docs` || !docs.Done || len(docs.DependsOn) != 1 || docs.DependsOn[0] != "TASK-1" {
+		t.Errorf("Expected docs done after TASK-1 only, got %+v", docs)
+	}
+	if review.Parent != "TASK-2" || review.Effort != 8*3600 {
+		t.Errorf("Expected the review below docs with the default effort, got %+v", review)
+	}
+}
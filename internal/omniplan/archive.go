@@ -0,0 +1,50 @@
+package omniplan
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ZipPackage writes the .oplx package directory at dir to w as a zip archive.
+// Entries keep the package directory name as their top-level folder so the
+// archive extracts to a ready-to-open package.
+func ZipPackage(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	base := filepath.Base(dir)
+
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := zw.Create(path.Join(base, filepath.ToSlash(rel)))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("zipping package %s: %w", dir, err)
+	}
+
+	return zw.Close()
+}
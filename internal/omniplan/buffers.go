@@ -0,0 +1,134 @@
+package omniplan
+
+import "github.com/gunnarrb/jql-to-plan/internal/i18n"
+
+// Buffer task titles, in English (see i18n.T)
+const (
+	FeedingBufferTitle = "Feeding buffer"
+	ProjectBufferTitle = "Project buffer"
+)
+
+// bufferTargets are the tasks addBuffers protects, by ID
+type bufferTargets struct {
+	epicMilestones []string // Each waits on its epic group
+	milestones     []string // External milestones
+	done           string   // The Done milestone, if any
+}
+
+// addBuffers inserts critical chain buffers sized at BufferPercent of the
+// remaining effort of the work they protect: a feeding buffer at the end of
+// each epic group, one before each external milestone and a project buffer
+// before the Done milestone. The largest chain into Done is the critical
+// chain; the project buffer protects it instead of a feeding buffer.
+func (s *Serializer) addBuffers(ids *idGenerator, tasks []Task, refs []Reference, targets bufferTargets) ([]Task, []Reference) {
+	index := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+
+	// Measure all work before adding buffers, so buffers never count
+	work := make(map[string]int64)
+	var measure func(id string) int64
+	measure = func(id string) int64 {
+		if w, ok := work[id]; ok {
+			return w
+		}
+		work[id] = 0 // Guards against cycles
+		i, ok := index[id]
+		if !ok {
+			return 0
+		}
+		var w int64
+		switch t := tasks[i]; t.Type {
+		case "group":
+			for _, c := range t.ChildTasks {
+				w += measure(c.IDRef)
+			}
+		case "milestone":
+			for _, p := range t.Prerequisites {
+				w += measure(p.IDRef)
+			}
+		default:
+			w = t.Effort - t.EffortDone
+		}
+		work[id] = w
+		return w
+	}
+	for _, id := range append(append([]string{targets.done}, targets.epicMilestones...), targets.milestones...) {
+		measure(id)
+	}
+
+	// The critical chain is the Done milestone's largest input
+	var critical string
+	if i, ok := index[targets.done]; ok {
+		for _, p := range tasks[i].Prerequisites {
+			if critical == "" || work[p.IDRef] > work[critical] {
+				critical = p.IDRef
+			}
+		}
+	}
+
+	for _, id := range targets.epicMilestones {
+		milestone := tasks[index[id]]
+		if id == critical || len(milestone.Prerequisites) == 0 {
+			continue
+		}
+		groupIndex := index[milestone.Prerequisites[0].IDRef]
+		buffer, ok := s.bufferTask(ids, i18n.T(FeedingBufferTitle), work[id])
+		if !ok {
+			continue
+		}
+		for _, c := range tasks[groupIndex].ChildTasks {
+			buffer.Prerequisites = append(buffer.Prerequisites, PrerequisiteTask{IDRef: c.IDRef})
+		}
+		tasks[groupIndex].ChildTasks = append(tasks[groupIndex].ChildTasks, Reference{IDRef: buffer.ID})
+		tasks = append(tasks, buffer)
+	}
+
+	bufferMilestone := func(id, title string) {
+		i, ok := index[id]
+		if !ok {
+			return
+		}
+		buffer, ok := s.bufferTask(ids, title, work[id])
+		if !ok {
+			return
+		}
+		buffer.Prerequisites = tasks[i].Prerequisites
+		tasks[i].Prerequisites = []PrerequisiteTask{{IDRef: buffer.ID}}
+		tasks = append(tasks, buffer)
+		for r, ref := range refs {
+			if ref.IDRef == id {
+				refs = append(refs[:r], append([]Reference{{IDRef: buffer.ID}}, refs[r:]...)...)
+				break
+			}
+		}
+	}
+	for _, id := range targets.milestones {
+		if id != critical {
+			bufferMilestone(id, i18n.T(FeedingBufferTitle)+": "+tasks[index[id]].Title)
+		}
+	}
+	if critical != "" {
+		// Sized on the critical chain alone, not on all work into Done
+		work[targets.done] = work[critical]
+		bufferMilestone(targets.done, i18n.T(ProjectBufferTitle))
+	}
+	return tasks, refs
+}
+
+// bufferTask creates a buffer of BufferPercent of the given effort, rounded
+// to whole hours, and false when there is nothing to buffer
+func (s *Serializer) bufferTask(ids *idGenerator, title string, effort int64) (Task, bool) {
+	const hour = 3600
+	buffer := (effort*int64(s.BufferPercent)/100 + hour/2) / hour * hour
+	if buffer <= 0 {
+		return Task{}, false
+	}
+	return Task{
+		ID:          ids.newID("t"),
+		Title:       title,
+		Effort:      buffer,
+		Recalculate: "duration",
+	}, true
+}
@@ -0,0 +1,67 @@
+package omniplan
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// ChangelogFile is the name of the revision history inside a .oplx package
+const ChangelogFile = "__changelog.xml"
+
+// ChangelogVersion is the OmniPlan document version recorded in the changelog
+const ChangelogVersion = "4.0"
+
+// Changelog is the revision history OmniPlan keeps next to the plan
+type Changelog struct {
+	XMLName xml.Name         `xml:"changelog"`
+	XMLNS   string           `xml:"xmlns,attr"`
+	Version string           `xml:"version"`
+	Entries []ChangelogEntry `xml:"entry"`
+}
+
+// ChangelogEntry is one revision of the plan
+type ChangelogEntry struct {
+	Date    string `xml:"date"` // DateFormat, in UTC
+	Author  string `xml:"author,omitempty"`
+	Comment string `xml:"comment"`
+}
+
+// AppendChangelog adds an entry to the changelog of a package directory,
+// starting a new changelog when there is none (or it cannot be read)
+func AppendChangelog(dir string, at time.Time, author, comment string) error {
+	path := filepath.Join(dir, ChangelogFile)
+
+	changelog := &Changelog{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := xml.Unmarshal(data, changelog); err != nil {
+			term.Warnf("Starting a new %s, the existing one is unreadable: %v", ChangelogFile, err)
+			changelog = &Changelog{}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	changelog.XMLNS = Namespace
+	changelog.Version = ChangelogVersion
+	changelog.Entries = append(changelog.Entries, ChangelogEntry{
+		Date:    at.UTC().Format(DateFormat),
+		Author:  SanitizeText(author),
+		Comment: SanitizeText(comment),
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(changelog); err != nil {
+		return fmt.Errorf("encoding changelog: %w", err)
+	}
+	buf.WriteString("\n")
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
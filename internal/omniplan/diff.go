@@ -0,0 +1,146 @@
+package omniplan
+
+import "sort"
+
+// TaskChange describes a single Jira-backed task that differs between two scenarios
+type TaskChange struct {
+	Key       string
+	Title     string
+	OldEffort int64
+	NewEffort int64
+}
+
+// MilestoneChange describes a milestone whose upstream effort has grown
+type MilestoneChange struct {
+	Title     string
+	OldEffort int64
+	NewEffort int64
+}
+
+// ScenarioDiff summarizes what changed between a previous and a regenerated plan
+type ScenarioDiff struct {
+	Added             []TaskChange
+	Removed           []TaskChange
+	EffortChanged     []TaskChange
+	EffortDelta       int64 // Total effort change in seconds
+	SlippedMilestones []MilestoneChange
+}
+
+// HasChanges reports whether the diff contains anything worth reporting
+func (d *ScenarioDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.EffortChanged) > 0 || len(d.SlippedMilestones) > 0
+}
+
+// DiffScenarios compares two scenarios by their Jira keys.
+// Milestones are matched by title and count as slipped when the total effort
+// of the work they depend on has grown.
+func DiffScenarios(before, after *Scenario) *ScenarioDiff {
+	diff := &ScenarioDiff{}
+
+	oldTasks := leafTasksByKey(before)
+	newTasks := leafTasksByKey(after)
+
+	for key, nt := range newTasks {
+		ot, ok := oldTasks[key]
+		if !ok {
+			diff.Added = append(diff.Added, TaskChange{Key: key, Title: nt.Title, NewEffort: nt.Effort})
+			diff.EffortDelta += nt.Effort
+			continue
+		}
+		if ot.Effort != nt.Effort {
+			diff.EffortChanged = append(diff.EffortChanged, TaskChange{Key: key, Title: nt.Title, OldEffort: ot.Effort, NewEffort: nt.Effort})
+			diff.EffortDelta += nt.Effort - ot.Effort
+		}
+	}
+	for key, ot := range oldTasks {
+		if _, ok := newTasks[key]; !ok {
+			diff.Removed = append(diff.Removed, TaskChange{Key: key, Title: ot.Title, OldEffort: ot.Effort})
+			diff.EffortDelta -= ot.Effort
+		}
+	}
+
+	oldMilestones := milestoneEfforts(before)
+	for title, newEffort := range milestoneEfforts(after) {
+		if oldEffort, ok := oldMilestones[title]; ok && newEffort > oldEffort {
+			diff.SlippedMilestones = append(diff.SlippedMilestones, MilestoneChange{Title: title, OldEffort: oldEffort, NewEffort: newEffort})
+		}
+	}
+
+	sortChanges(diff.Added)
+	sortChanges(diff.Removed)
+	sortChanges(diff.EffortChanged)
+	sort.Slice(diff.SlippedMilestones, func(i, j int) bool {
+		return diff.SlippedMilestones[i].Title < diff.SlippedMilestones[j].Title
+	})
+
+	return diff
+}
+
+// leafTasksByKey indexes the non-group, non-milestone tasks of a scenario by Jira key
+func leafTasksByKey(s *Scenario) map[string]Task {
+	result := make(map[string]Task)
+	if s == nil {
+		return result
+	}
+	for _, t := range s.Tasks {
+		if t.Type == "group" || t.Type == "milestone" {
+			continue
+		}
+		if key := t.UserDataValue(UserDataJiraKey); key != "" {
+			result[key] = t
+		}
+	}
+	return result
+}
+
+// milestoneEfforts returns, per milestone title, the total effort of all tasks
+// the milestone transitively depends on
+func milestoneEfforts(s *Scenario) map[string]int64 {
+	result := make(map[string]int64)
+	if s == nil {
+		return result
+	}
+
+	byID := make(map[string]Task, len(s.Tasks))
+	for _, t := range s.Tasks {
+		byID[t.ID] = t
+	}
+
+	for _, t := range s.Tasks {
+		if t.Type != "milestone" {
+			continue
+		}
+		visited := make(map[string]bool)
+		var total int64
+		var walk func(id string)
+		walk = func(id string) {
+			if visited[id] {
+				return
+			}
+			visited[id] = true
+			task, ok := byID[id]
+			if !ok {
+				return
+			}
+			total += task.Effort
+			for _, child := range task.ChildTasks {
+				walk(child.IDRef)
+			}
+			for _, prereq := range task.Prerequisites {
+				walk(prereq.IDRef)
+			}
+		}
+		for _, prereq := range t.Prerequisites {
+			walk(prereq.IDRef)
+		}
+		result[t.Title] = total
+	}
+
+	return result
+}
+
+func sortChanges(changes []TaskChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+}
@@ -0,0 +1,73 @@
+package omniplan
+
+import (
+	"strings"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// groupTree collects the GroupBy group tasks, nested one level per GroupBy
+// function, in first-seen order so IDs are stable
+type groupTree struct {
+	nodes []groupNode
+	index map[string]int // Group path joined with "\x00" -> node index
+}
+
+// groupNode is a group task with its tickets and sub-groups in first-seen order
+type groupNode struct {
+	title    string
+	parent   int         // Index of the parent node, or -1 at the top level
+	slot     int         // Position in the parent's children
+	children []Reference // Sub-group entries are filled in by assignIDs
+	id       string
+}
+
+// add returns the node for a group path, creating it and its ancestors as needed
+func (g *groupTree) add(path []string) int {
+	if g.index == nil {
+		g.index = make(map[string]int)
+	}
+	parent := -1
+	for depth := range path {
+		key := strings.Join(path[:depth+1], "\x00")
+		node, ok := g.index[key]
+		if !ok {
+			node = len(g.nodes)
+			g.nodes = append(g.nodes, groupNode{title: path[depth], parent: parent})
+			if parent >= 0 {
+				g.nodes[node].slot = len(g.nodes[parent].children)
+				g.nodes[parent].children = append(g.nodes[parent].children, Reference{})
+			}
+			g.index[key] = node
+		}
+		parent = node
+	}
+	return parent
+}
+
+// assignIDs gives every group an ID, in creation order, and fills in the
+// sub-group references of their parents
+func (g *groupTree) assignIDs(ids *idGenerator) {
+	for i := range g.nodes {
+		node := &g.nodes[i]
+		node.id = ids.newID("t")
+		if node.parent >= 0 {
+			g.nodes[node.parent].children[node.slot] = Reference{IDRef: node.id}
+		}
+	}
+}
+
+// groupPath returns the GroupBy groups of a ticket, outermost first, skipping
+// levels where it has no group
+func (s *Serializer) groupPath(ticket jira.Ticket) []string {
+	if s.GroupByEpic {
+		return nil
+	}
+	var path []string
+	for _, groupOf := range s.GroupBy {
+		if group := groupOf(ticket); group != "" {
+			path = append(path, group)
+		}
+	}
+	return path
+}
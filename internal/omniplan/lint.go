@@ -0,0 +1,202 @@
+package omniplan
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LintIssue is a structural problem found in a package
+type LintIssue struct {
+	File    string // File within the package the issue was found in
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return i.File + ": " + i.Message
+}
+
+// LintPackage checks the package directory at dir for missing files, scenario
+// IDs that disagree with __TOC.xml, duplicate IDs, references to unknown tasks
+// or resources and tasks or resources that are not reachable from the top.
+// The returned error is only for packages that cannot be checked at all.
+func LintPackage(dir string) ([]LintIssue, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a package directory", dir)
+	}
+
+	var issues []LintIssue
+	listed, err := tocScenarios(filepath.Join(dir, TOCFile))
+	if err != nil {
+		issues = append(issues, LintIssue{TOCFile, err.Error()})
+	}
+
+	found, err := PackageScenarios(dir)
+	if err != nil {
+		issues = append(issues, LintIssue{".", err.Error()})
+	}
+	onDisk := make(map[string]TOCScenario, len(found))
+	for _, s := range found {
+		onDisk[s.Filename] = s
+	}
+	if _, ok := onDisk[ActualScenarioFile]; !ok && len(found) > 0 {
+		issues = append(issues, LintIssue{".", fmt.Sprintf("missing %s", ActualScenarioFile)})
+	}
+
+	for _, s := range listed {
+		actual, ok := onDisk[s.Filename]
+		switch {
+		case !ok:
+			issues = append(issues, LintIssue{TOCFile, fmt.Sprintf("scenario %q refers to missing file %s", s.Name, s.Filename)})
+		case actual.ID != s.ID:
+			issues = append(issues, LintIssue{TOCFile, fmt.Sprintf("scenario %q has id %s, but %s has id %s", s.Name, s.ID, s.Filename, actual.ID)})
+		}
+	}
+	if listed != nil {
+		inTOC := make(map[string]bool, len(listed))
+		for _, s := range listed {
+			inTOC[s.Filename] = true
+		}
+		for _, s := range found {
+			if !inTOC[s.Filename] {
+				issues = append(issues, LintIssue{TOCFile, fmt.Sprintf("scenario file %s is not listed", s.Filename)})
+			}
+		}
+	}
+
+	for _, s := range found {
+		scenario, err := ReadScenarioFile(filepath.Join(dir, s.Filename))
+		if err != nil {
+			issues = append(issues, LintIssue{s.Filename, err.Error()})
+			continue
+		}
+		for _, message := range lintScenario(scenario) {
+			issues = append(issues, LintIssue{s.Filename, message})
+		}
+	}
+	return issues, nil
+}
+
+// tocScenarios reads the scenario entries of a __TOC.xml file
+func tocScenarios(path string) ([]TOCScenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("missing")
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var toc struct {
+		Scenarios []struct {
+			ID       string `xml:"id,attr"`
+			Name     string `xml:"name,attr"`
+			Filename string `xml:"filename,attr"`
+		} `xml:"project>scenario"`
+	}
+	if err := xml.NewDecoder(f).Decode(&toc); err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	scenarios := make([]TOCScenario, 0, len(toc.Scenarios))
+	for _, s := range toc.Scenarios {
+		scenarios = append(scenarios, TOCScenario{ID: s.ID, Name: s.Name, Filename: s.Filename})
+	}
+	if len(scenarios) == 0 {
+		return scenarios, fmt.Errorf("lists no scenarios")
+	}
+	return scenarios, nil
+}
+
+// lintScenario checks the task and resource graph of a scenario
+func lintScenario(s *Scenario) []string {
+	var messages []string
+	report := func(format string, args ...interface{}) {
+		messages = append(messages, fmt.Sprintf(format, args...))
+	}
+
+	tasks := make(map[string]*Task, len(s.Tasks))
+	for i := range s.Tasks {
+		t := &s.Tasks[i]
+		if _, dup := tasks[t.ID]; dup {
+			report("duplicate task id %s", t.ID)
+			continue
+		}
+		tasks[t.ID] = t
+	}
+	resources := make(map[string]*Resource, len(s.Resources))
+	for i := range s.Resources {
+		r := &s.Resources[i]
+		if _, dup := resources[r.ID]; dup {
+			report("duplicate resource id %s", r.ID)
+			continue
+		}
+		resources[r.ID] = r
+	}
+
+	// Broken references
+	if _, ok := tasks[s.TopTask.IDRef]; !ok {
+		if s.TopTask.IDRef == "" {
+			report("missing top-task")
+		} else {
+			report("top-task refers to unknown task %s", s.TopTask.IDRef)
+		}
+	}
+	if _, ok := resources[s.TopResource.IDRef]; !ok {
+		if s.TopResource.IDRef == "" {
+			report("missing top-resource")
+		} else {
+			report("top-resource refers to unknown resource %s", s.TopResource.IDRef)
+		}
+	}
+	taskParents := make(map[string]string)
+	for _, t := range s.Tasks {
+		for _, child := range t.ChildTasks {
+			if _, ok := tasks[child.IDRef]; !ok {
+				report("task %s has unknown child task %s", t.ID, child.IDRef)
+			} else if parent, seen := taskParents[child.IDRef]; seen {
+				report("task %s is a child of both %s and %s", child.IDRef, parent, t.ID)
+			} else {
+				taskParents[child.IDRef] = t.ID
+			}
+		}
+		for _, prereq := range t.Prerequisites {
+			if _, ok := tasks[prereq.IDRef]; !ok {
+				report("task %s has unknown prerequisite %s", t.ID, prereq.IDRef)
+			}
+		}
+		for _, a := range t.Assignments {
+			if _, ok := resources[a.IDRef]; !ok {
+				report("task %s is assigned to unknown resource %s", t.ID, a.IDRef)
+			}
+		}
+	}
+	resourceParents := make(map[string]bool)
+	for _, r := range s.Resources {
+		for _, child := range r.ChildResources {
+			if _, ok := resources[child.IDRef]; !ok {
+				report("resource %s has unknown child resource %s", r.ID, child.IDRef)
+			}
+			resourceParents[child.IDRef] = true
+		}
+	}
+
+	// Orphans: everything but the top elements needs a parent
+	for _, t := range s.Tasks {
+		if _, ok := taskParents[t.ID]; !ok && t.ID != s.TopTask.IDRef {
+			report("task %s (%q) is not in the task outline", t.ID, t.Title)
+		}
+	}
+	for _, r := range s.Resources {
+		if !resourceParents[r.ID] && r.ID != s.TopResource.IDRef {
+			report("resource %s (%q) is not in the resource outline", r.ID, r.Name)
+		}
+	}
+	return messages
+}
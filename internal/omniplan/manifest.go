@@ -0,0 +1,44 @@
+package omniplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the name of the generation manifest inside a .oplx package
+const ManifestFile = "jql-to-plan.json"
+
+// Manifest records how a package was generated so it can be refreshed later
+type Manifest struct {
+	Project     string            `json:"project"`
+	JQL         string            `json:"jql"`
+	Flags       map[string]string `json:"flags,omitempty"`       // Command-line flags that were set, by name
+	ConfigFile  string            `json:"config_file,omitempty"` // Configuration file used, empty for environment only
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// WriteManifest stores the manifest in the package directory
+func WriteManifest(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644)
+}
+
+// ReadManifest loads the manifest of a package directory
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	return &m, nil
+}
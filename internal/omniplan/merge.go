@@ -0,0 +1,119 @@
+package omniplan
+
+// MergeScenarios combines scenarios, e.g. team plans, into one programme
+// scenario named name. Each scenario's task outline becomes a group titled
+// with its entry in titles. Resources with the same name and type are merged
+// into one, so a person planned by two teams is levelled across both; the top
+// resources are replaced by one for the programme. All IDs are reassigned.
+// Elements the Scenario type does not model, and style definitions, are not
+// carried over.
+func MergeScenarios(name string, scenarios []*Scenario, titles []string) *Scenario {
+	ids := &idGenerator{}
+	merged := &Scenario{
+		XMLNS: Namespace,
+		OPNS:  Namespace,
+		ID:    ids.newID("gen"),
+	}
+	topResource := Resource{ID: "r-1", Name: name, Type: "Project"}
+	topTask := Task{ID: "t-1", Type: "group", Recalculate: "duration"}
+	merged.TopResource = Reference{IDRef: topResource.ID}
+	merged.TopTask = Reference{IDRef: topTask.ID}
+
+	var resources []Resource
+	byName := make(map[[2]string]string) // Name and type -> merged resource ID
+	kept := make(map[string]bool)        // Merged resources already added
+	placed := make(map[string]bool)      // Merged resources already in the outline
+
+	for i, s := range scenarios {
+		if i == 0 {
+			merged.Granularity = s.Granularity
+			merged.CriticalPaths = s.CriticalPaths
+		}
+
+		// Leaf resources merge by name; groups are kept per scenario
+		resourceIDs := make(map[string]string, len(s.Resources))
+		for _, r := range s.Resources {
+			if r.ID == s.TopResource.IDRef {
+				continue
+			}
+			key := [2]string{r.Name, r.Type}
+			if id, ok := byName[key]; ok && len(r.ChildResources) == 0 {
+				resourceIDs[r.ID] = id
+				continue
+			}
+			resourceIDs[r.ID] = ids.newID("r")
+			if len(r.ChildResources) == 0 {
+				byName[key] = resourceIDs[r.ID]
+			}
+		}
+		remapResources := func(refs []Reference) []Reference {
+			var out []Reference
+			for _, ref := range refs {
+				if id, ok := resourceIDs[ref.IDRef]; ok && !placed[id] {
+					out = append(out, Reference{IDRef: id})
+					placed[id] = true
+				}
+			}
+			return out
+		}
+		for _, r := range s.Resources {
+			id, ok := resourceIDs[r.ID]
+			if r.ID == s.TopResource.IDRef {
+				topResource.ChildResources = append(topResource.ChildResources, remapResources(r.ChildResources)...)
+				continue
+			}
+			if !ok || kept[id] {
+				continue // Merged into an earlier resource
+			}
+			kept[id] = true
+			r.ID = id
+			r.ChildResources = remapResources(r.ChildResources)
+			resources = append(resources, r)
+		}
+
+		// The top task becomes a group for the scenario
+		taskIDs := make(map[string]string, len(s.Tasks))
+		for _, t := range s.Tasks {
+			taskIDs[t.ID] = ids.newID("t")
+		}
+		for _, t := range s.Tasks {
+			t.ID = taskIDs[t.ID]
+			t.ChildTasks = remapRefs(t.ChildTasks, taskIDs)
+			prereqs := t.Prerequisites
+			t.Prerequisites = nil
+			for _, p := range prereqs {
+				if id, ok := taskIDs[p.IDRef]; ok {
+					t.Prerequisites = append(t.Prerequisites, PrerequisiteTask{IDRef: id, Kind: p.Kind})
+				}
+			}
+			assignments := t.Assignments
+			t.Assignments = nil
+			for _, a := range assignments {
+				if id, ok := resourceIDs[a.IDRef]; ok {
+					t.Assignments = append(t.Assignments, Assignment{IDRef: id, Units: a.Units})
+				}
+			}
+			if t.ID == taskIDs[s.TopTask.IDRef] {
+				t.Title = titles[i]
+				t.Type = "group"
+				topTask.ChildTasks = append(topTask.ChildTasks, Reference{IDRef: t.ID})
+			}
+			merged.Tasks = append(merged.Tasks, t)
+		}
+	}
+
+	merged.Resources = append([]Resource{topResource}, resources...)
+	merged.Tasks = append([]Task{topTask}, merged.Tasks...)
+	return merged
+}
+
+// remapRefs maps references through ids, dropping unknown ones
+func remapRefs(refs []Reference, ids map[string]string) []Reference {
+	var out []Reference
+	for _, ref := range refs {
+		if id, ok := ids[ref.IDRef]; ok {
+			out = append(out, Reference{IDRef: id})
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package omniplan
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// NoteTemplate renders a note for each ticket task from a text/template,
+// executed with the jira.Ticket. Fetched extra fields are in .Fields, keyed
+// by the field as requested, e.g.
+//
+//	{{index .Fields "Acceptance Criteria"}}
+//	Sprint: {{index .Fields "Sprint"}}, due {{date .DueDate}}
+//	{{.BrowseURL}}
+//
+// Besides the built-in functions, join joins a list with ", " and date
+// formats a time as YYYY-MM-DD ("" when it is zero).
+type NoteTemplate struct {
+	tmpl *template.Template
+}
+
+var noteFuncs = template.FuncMap{
+	"join": func(values []string) string { return strings.Join(values, ", ") },
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	},
+}
+
+// ParseNoteTemplate parses a note template. Fields that do not exist render
+// as empty text rather than "<no value>".
+func ParseNoteTemplate(text string) (*NoteTemplate, error) {
+	tmpl, err := template.New("note").Funcs(noteFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &NoteTemplate{tmpl: tmpl}, nil
+}
+
+// Render returns the paragraphs of a ticket's note, one per line. Blank
+// lines at the start and end are dropped, so conditional sections can sit on
+// lines of their own.
+func (n *NoteTemplate) Render(ticket jira.Ticket) ([]NoteParagraph, error) {
+	var sb strings.Builder
+	if err := n.tmpl.Execute(&sb, ticket); err != nil {
+		return nil, err
+	}
+	text := strings.Trim(sb.String(), "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	var paragraphs []NoteParagraph
+	for _, line := range strings.Split(text, "\n") {
+		paragraphs = append(paragraphs, NoteParagraph{Runs: []NoteRun{{Literal: strings.TrimRight(line, " \t\r")}}})
+	}
+	return paragraphs, nil
+}
@@ -0,0 +1,28 @@
+package omniplan
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadScenario parses an OmniPlan scenario document from r
+func ReadScenario(r io.Reader) (*Scenario, error) {
+	var scenario Scenario
+	if err := xml.NewDecoder(r).Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("decoding scenario: %w", err)
+	}
+	return &scenario, nil
+}
+
+// ReadScenarioFile parses the OmniPlan scenario stored at path
+func ReadScenarioFile(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadScenario(f)
+}
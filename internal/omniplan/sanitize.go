@@ -0,0 +1,102 @@
+package omniplan
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeText makes s safe for an OmniPlan document. Control characters
+// other than tab and newline, which XML 1.0 cannot represent even as
+// character references, are dropped rather than left to show up as U+FFFD.
+// Noncharacters and invalid UTF-8 become U+FFFD, and CRLF or CR line breaks
+// become LF instead of the &#xD; references OmniPlan shows in titles and
+// notes. Everything else, including emoji and right-to-left text, is kept.
+func SanitizeText(s string) string {
+	if isCleanText(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '\r':
+			if i < len(s) && s[i] == '\n' {
+				i++
+			}
+			sb.WriteByte('\n')
+		case r == '\t' || r == '\n':
+			sb.WriteRune(r)
+		case r < 0x20:
+			// Dropped: XML 1.0 has no way to write these, not even as references
+		case r == utf8.RuneError && size == 1, r == 0xFFFE, r == 0xFFFF:
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isCleanText reports whether s needs no sanitizing, sparing the copy for
+// the common case
+func isCleanText(s string) bool {
+	for i, r := range s {
+		switch {
+		case r < 0x20 && r != '\t' && r != '\n':
+			return false
+		case r == utf8.RuneError:
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return false
+			}
+		case r == 0xFFFE, r == 0xFFFF:
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeScenario applies SanitizeText to every string of a scenario
+func sanitizeScenario(s *Scenario) {
+	for i := range s.Resources {
+		r := &s.Resources[i]
+		r.Name = SanitizeText(r.Name)
+		r.Type = SanitizeText(r.Type)
+		sanitizeUserData(r.UserData)
+		sanitizeNote(r.Note)
+	}
+	for i := range s.Tasks {
+		t := &s.Tasks[i]
+		t.Title = SanitizeText(t.Title)
+		sanitizeUserData(t.UserData)
+		sanitizeNote(t.Note)
+	}
+}
+
+func sanitizeUserData(u *UserData) {
+	if u == nil {
+		return
+	}
+	for i := range u.Items {
+		u.Items[i].Key = SanitizeText(u.Items[i].Key)
+		u.Items[i].Value = SanitizeText(u.Items[i].Value)
+	}
+}
+
+func sanitizeNote(n *Note) {
+	if n == nil {
+		return
+	}
+	for _, p := range n.Text.Paragraphs {
+		for i := range p.Runs {
+			run := &p.Runs[i]
+			run.Literal = SanitizeText(run.Literal)
+			if run.Style != nil {
+				for j := range run.Style.Values {
+					run.Style.Values[j].Value = SanitizeText(run.Style.Values[j].Value)
+				}
+			}
+		}
+	}
+}
@@ -4,15 +4,105 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/gunnarrb/jql-to-plan/internal/i18n"
 	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
 )
 
+// FlaggedNotePrefix starts the note of tasks whose Jira issue is flagged
+const FlaggedNotePrefix = "⚑ IMPEDED: "
+
+// RiskNotePrefix starts the note paragraph of tasks padded for risk
+const RiskNotePrefix = "⚠ "
+
 // Serializer converts Jira tickets to OmniPlan XML
 type Serializer struct {
 	ProjectName   string
 	GroupByEpic   bool
 	MilestoneDone bool
+
+	// NestSubtasks turns parents into groups of their sub-tasks
+	NestSubtasks bool
+
+	// EpicPhase, if set with GroupByEpic, returns the phase (e.g. sprint) of a
+	// ticket. Each epic group is split into one sub-group per phase; tickets
+	// without a phase stay directly in the epic group.
+	EpicPhase func(jira.Ticket) string
+
+	// GroupBy are the grouping levels, outermost first. Each returns the group
+	// a ticket belongs to at that level, or "" to skip the level. One group
+	// task is created per distinct value within its parent group. It is an
+	// alternative to GroupByEpic, which takes precedence.
+	GroupBy []func(jira.Ticket) string
+
+	// IssueTypePrefixes are prepended to task titles so issue types stand out,
+	// e.g. {"Bug": "🐞 "}. Issue type names are matched case-insensitively.
+	IssueTypePrefixes map[string]string
+
+	// MaxTitleLength, if positive, caps the characters of ticket and epic
+	// titles, including any prefix. Longer summaries are cut (see
+	// truncateTitle), and kept whole in the task's note and user data.
+	MaxTitleLength int
+
+	// Team, if set, returns the team of a ticket. Unassigned tickets with a
+	// team are assigned to a resource for the whole team, so capacity can be
+	// planned before individuals are chosen.
+	Team func(jira.Ticket) string
+
+	// WSJFScore, if set, returns a ticket's Weighted Shortest Job First score,
+	// recorded in the task's user data
+	WSJFScore func(jira.Ticket) (float64, bool)
+
+	// Risk, if set, returns a ticket's risk level and effort multiplier.
+	// The level is recorded in the task's user data, and padded tasks
+	// (multiplier above 1) say so in their note. The effort is expected to be
+	// padded already.
+	Risk func(jira.Ticket) (string, float64)
+
+	// NoteTemplate, if set, renders a note for each ticket task, added after
+	// the impediment and risk notes. A ticket it fails on gets a warning and
+	// no templated note.
+	NoteTemplate *NoteTemplate
+
+	// PrimaryUnits is the share of a task's work for its assignee when it has
+	// co-assignees (jira.Ticket.CoAssignees), who split the rest equally.
+	// Zero splits the work equally among everyone.
+	PrimaryUnits float64
+
+	// UserProfiles, keyed by assignee, add a note and user data with the
+	// assignee's Jira profile to their staff resource
+	UserProfiles map[string]jira.UserProfile
+
+	// Milestones are external commitments added as fixed-date milestones
+	Milestones []Milestone
+
+	// MaxTaskDays, if positive, splits tasks of more effort into a group of
+	// sequential parts of at most this many days (see splitTask)
+	MaxTaskDays float64
+
+	// BufferPercent, if positive, adds critical chain buffers of this
+	// percentage of the remaining effort they protect (see addBuffers)
+	BufferPercent int
+
+	// Palette, if set, styles the plan with its fonts and colors instead of
+	// OmniPlan's default gray
+	Palette *Palette
+
+	// FormatVersion selects the OmniPlan markup written: FormatVersion4
+	// (default when zero) or FormatVersion2 for older OmniPlan releases
+	FormatVersion int
+}
+
+// Milestone is a fixed-date commitment that does not live in Jira
+type Milestone struct {
+	Name      string
+	Date      time.Time // Day of the commitment, at midnight in the plan's time zone
+	DependsOn []string  // Keys of tickets or epics that must be done first
 }
 
 // NewSerializer creates a new OmniPlan serializer
@@ -25,6 +115,14 @@ func NewSerializer(projectName string) *Serializer {
 // Serialize writes Jira tickets as OmniPlan XML to the given writer
 func (s *Serializer) Serialize(w io.Writer, tickets []jira.Ticket, epics map[string]jira.Ticket) error {
 	scenario := s.buildScenario(tickets, epics)
+	downgrade(scenario, s.FormatVersion)
+	return WriteScenario(w, scenario)
+}
+
+// WriteScenario writes a scenario as an OmniPlan XML document. Its text is
+// sanitized in place first (see SanitizeText).
+func WriteScenario(w io.Writer, scenario *Scenario) error {
+	sanitizeScenario(scenario)
 
 	// Write XML header
 	if _, err := w.Write([]byte(xml.Header)); err != nil {
@@ -48,32 +146,55 @@ func (s *Serializer) Serialize(w io.Writer, tickets []jira.Ticket, epics map[str
 
 // buildScenario constructs the full OmniPlan scenario from tickets
 func (s *Serializer) buildScenario(tickets []jira.Ticket, epics map[string]jira.Ticket) *Scenario {
-	scenarioID := GenerateID()
+	ids := &idGenerator{}
+	scenarioID := ids.newID("gen")
 	topResourceID := "r-1"
 	topTaskID := "t-1"
 
-	// Collect unique assignees and create resource IDs for them
-	assigneeToResourceID := make(map[string]string)
+	// Collect unique assignees (and teams of unassigned tickets) and create
+	// resource IDs for them
+	resourceIDs := make(map[string]string)
 	var staffResources []Resource
 	var childResourceRefs []Reference
 
+	addResource := func(key string, resource func(id string) Resource) string {
+		if id, exists := resourceIDs[key]; exists {
+			return id
+		}
+		id := ids.newID("r")
+		resourceIDs[key] = id
+		staffResources = append(staffResources, resource(id))
+		childResourceRefs = append(childResourceRefs, Reference{IDRef: id})
+		return id
+	}
+
+	// Assignees known by ID come first, so tasks assigned by name (follow-ups,
+	// reviews and co-assignees) share their resource
 	for _, ticket := range tickets {
-		if ticket.Assignee != "" {
-			if _, exists := assigneeToResourceID[ticket.Assignee]; !exists {
-				resourceID := fmt.Sprintf("r%d", idCounter.Add(1))
-				assigneeToResourceID[ticket.Assignee] = resourceID
-				staffResources = append(staffResources, Resource{
-					ID:   resourceID,
-					Name: ticket.Assignee,
-					Type: "Staff",
-				})
-				childResourceRefs = append(childResourceRefs, Reference{IDRef: resourceID})
-			}
+		if ticket.AssigneeID == "" {
+			continue
+		}
+		id := addResource(s.resourceKey(ticket), func(id string) Resource { return s.staffResource(id, ticket.Assignee) })
+		if _, exists := resourceIDs[ticket.Assignee]; !exists {
+			resourceIDs[ticket.Assignee] = id
+		}
+	}
+	for _, ticket := range tickets {
+		if key := s.resourceKey(ticket); key != "" && ticket.AssigneeID == "" {
+			addResource(key, func(id string) Resource {
+				if ticket.Assignee != "" {
+					return s.staffResource(id, ticket.Assignee)
+				}
+				return Resource{ID: id, Name: s.Team(ticket), Type: "Staff"}
+			})
+		}
+		for _, coAssignee := range ticket.CoAssignees {
+			addResource(coAssignee, func(id string) Resource { return s.staffResource(id, coAssignee) })
 		}
 	}
 
-	// Build tasks from tickets, passing the assignee map
-	tasks, childTaskRefs := s.buildTasksFromTickets(tickets, assigneeToResourceID, epics)
+	// Build tasks from tickets, passing the resource map
+	tasks, childTaskRefs := s.buildTasksFromTickets(ids, tickets, resourceIDs, epics)
 
 	// Create the top-level group task that contains all child tasks
 	topTask := Task{
@@ -85,7 +206,9 @@ func (s *Serializer) buildScenario(tickets []jira.Ticket, epics map[string]jira.
 	}
 
 	// Prepend the top task to the task list
-	allTasks := append([]Task{topTask}, tasks...)
+	allTasks := make([]Task, 0, len(tasks)+1)
+	allTasks = append(allTasks, topTask)
+	allTasks = append(allTasks, tasks...)
 
 	// Create the project resource with staff as children
 	projectResource := Resource{
@@ -98,7 +221,7 @@ func (s *Serializer) buildScenario(tickets []jira.Ticket, epics map[string]jira.
 	// Combine project resource with staff resources
 	allResources := append([]Resource{projectResource}, staffResources...)
 
-	return &Scenario{
+	scenario := &Scenario{
 		XMLNS:       Namespace,
 		OPNS:        Namespace,
 		ID:          scenarioID,
@@ -121,26 +244,129 @@ func (s *Serializer) buildScenario(tickets []jira.Ticket, epics map[string]jira.
 			},
 		},
 	}
+	if s.Palette != nil {
+		s.Palette.applyStyles(scenario)
+	}
+	return scenario
+}
+
+// resourceKey identifies the resource a ticket is assigned to: its assignee,
+// by account ID or username when known and otherwise by name, or else its
+// team. IDs and teams get a prefix so they never merge with a person of the
+// same name. It returns "" for tickets with neither.
+func (s *Serializer) resourceKey(ticket jira.Ticket) string {
+	if ticket.AssigneeID != "" {
+		return "user:" + ticket.AssigneeID
+	}
+	if ticket.Assignee != "" {
+		return ticket.Assignee
+	}
+	if s.Team != nil {
+		if team := s.Team(ticket); team != "" {
+			return "team:" + team
+		}
+	}
+	return ""
+}
+
+// staffResource creates the resource for an assignee, described by their
+// Jira profile when there is one
+func (s *Serializer) staffResource(id, assignee string) Resource {
+	resource := Resource{ID: id, Name: assignee, Type: "Staff"}
+	profile, ok := s.UserProfiles[assignee]
+	if !ok {
+		return resource
+	}
+
+	var items []UserDataItem
+	note := &Note{}
+	if profile.TimeZone != "" {
+		items = append(items, UserDataItem{Key: UserDataJiraTimeZone, Value: profile.TimeZone})
+		note.Text.Paragraphs = append(note.Text.Paragraphs, NoteParagraph{Runs: []NoteRun{{Literal: i18n.T("Time zone: ") + profile.TimeZone}}})
+	}
+	if profile.Email != "" {
+		items = append(items, UserDataItem{Key: UserDataJiraEmail, Value: profile.Email})
+		note.AddLink(i18n.T("Email: "), profile.Email, "mailto:"+profile.Email)
+	}
+	if profile.AvatarURL != "" {
+		items = append(items, UserDataItem{Key: UserDataJiraAvatar, Value: profile.AvatarURL})
+		note.AddLink("", i18n.T("Avatar"), profile.AvatarURL)
+	}
+	if len(items) > 0 {
+		resource.UserData = &UserData{Items: items}
+		resource.Note = note
+	}
+	return resource
+}
+
+// assignments assigns a task to its assignee's or team's resource, splitting
+// the work with any co-assignees (see PrimaryUnits)
+func (s *Serializer) assignments(ticket jira.Ticket, resourceIDs map[string]string) []Assignment {
+	var assignments []Assignment
+	if resourceID, exists := resourceIDs[s.resourceKey(ticket)]; exists {
+		assignments = append(assignments, Assignment{IDRef: resourceID})
+	}
+	for _, coAssignee := range ticket.CoAssignees {
+		if resourceID, exists := resourceIDs[coAssignee]; exists {
+			assignments = append(assignments, Assignment{IDRef: resourceID})
+		}
+	}
+	if len(assignments) < 2 {
+		return assignments
+	}
+
+	share := 1 / float64(len(assignments))
+	if s.PrimaryUnits > 0 && ticket.Assignee != "" {
+		assignments[0].Units = s.PrimaryUnits
+		share = (1 - s.PrimaryUnits) / float64(len(assignments)-1)
+	} else {
+		assignments[0].Units = share
+	}
+	for i := 1; i < len(assignments); i++ {
+		assignments[i].Units = share
+	}
+	return assignments
 }
 
 // buildTasksFromTickets converts Jira tickets to OmniPlan tasks
-func (s *Serializer) buildTasksFromTickets(tickets []jira.Ticket, assigneeToResourceID map[string]string, epics map[string]jira.Ticket) ([]Task, []Reference) {
-	var tasks []Task
-	var refs []Reference
+func (s *Serializer) buildTasksFromTickets(ids *idGenerator, tickets []jira.Ticket, resourceIDs map[string]string, epics map[string]jira.Ticket) ([]Task, []Reference) {
+	refs := make([]Reference, 0, len(tickets))
 
 	// First pass: Create tasks and build a map of Jira Key -> Task ID
-	jiraKeyToTaskID := make(map[string]string)
-	var taskPtrs []*Task
+	jiraKeyToTaskID := make(map[string]string, len(tickets))
+	ticketTasks := make([]Task, len(tickets))
 
 	// If GroupByEpic is enabled, we need to organize tasks by Epic
 	// We'll use a map of EpicKey -> List of TaskIDs (child refs)
 	epicToChildRefs := make(map[string][]Reference)
-	// And a map to keep track of created Epic Group Tasks
-	epicTasks := make(map[string]*Task)
-	epicMilestones := make(map[string]*Task)
+	var epicOrder []string // Epic keys in first-seen order, so IDs are stable
+	// And a map to keep track of created Epic Milestone IDs
+	epicMilestones := make(map[string]string)
+	epicGroups := make(map[string]int) // Epic key -> index of its group task
+
+	// Phase groups take a placeholder slot in their epic's children
+	type phaseGroup struct {
+		title    string
+		epic     string
+		slot     int
+		children []Reference
+	}
+	var phases []phaseGroup
+	phaseIndex := make(map[[2]string]int) // Epic key and phase -> index in phases
+
+	// With NestSubtasks, sub-tasks go into their parent's task instead
+	ticketIndex := make(map[string]int)
+	if s.NestSubtasks {
+		for i, ticket := range tickets {
+			ticketIndex[ticket.Key] = i
+		}
+	}
+	subtaskRefs := make(map[int][]Reference) // Parent ticket index -> sub-task refs
 
-	for _, ticket := range tickets {
-		taskID := fmt.Sprintf("t%d", idCounter.Add(1))
+	var groups groupTree
+
+	for i, ticket := range tickets {
+		taskID := ids.newID("t")
 		jiraKeyToTaskID[ticket.Key] = taskID
 
 		// Calculate effort in seconds: days * 8 hours/day * 3600 seconds/hour
@@ -150,89 +376,268 @@ func (s *Serializer) buildTasksFromTickets(tickets []jira.Ticket, assigneeToReso
 			effort = int64(ticket.EffortDays * 8 * 3600)
 		}
 
-		task := &Task{
+		task := &ticketTasks[i]
+		*task = Task{
 			ID:          taskID,
-			Title:       ticket.Summary,
+			Title:       s.title(s.issueTypePrefix(ticket.IssueType), ticket.Summary),
 			Effort:      effort,
 			Recalculate: "duration",
 			StaticCost:  0,
 			UserData: &UserData{
 				Items: []UserDataItem{
-					{Key: "Jira Key", Value: ticket.Key},
-					{Key: "Jira Link", Value: ticket.Link},
-					{Key: "Jira Status", Value: ticket.Status},
+					{Key: UserDataJiraKey, Value: ticket.Key},
+					{Key: UserDataJiraLink, Value: ticket.Link},
+					{Key: UserDataJiraStatus, Value: ticket.Status},
 				},
 			},
 		}
 
-		// Assign the task to the resource if there's an assignee
-		if ticket.Assignee != "" {
-			if resourceID, exists := assigneeToResourceID[ticket.Assignee]; exists {
-				task.Assignments = []Reference{{IDRef: resourceID}}
+		if ticket.IssueType != "" {
+			task.UserData.Items = append(task.UserData.Items, UserDataItem{Key: UserDataJiraType, Value: ticket.IssueType})
+		}
+
+		if s.WSJFScore != nil {
+			if score, ok := s.WSJFScore(ticket); ok {
+				task.UserData.Items = append(task.UserData.Items, UserDataItem{Key: UserDataWSJF, Value: strconv.FormatFloat(score, 'f', 2, 64)})
+			}
+		}
+
+		// Make impediments stand out in the inspector, the note and the user data
+		if ticket.Flagged {
+			task.UserData.Items = append(task.UserData.Items, UserDataItem{Key: UserDataJiraFlagged, Value: "Impediment"})
+			task.Note = NewNote(i18n.T(FlaggedNotePrefix) + i18n.T("This issue is flagged as impeded in Jira."))
+		}
+		// Risky work is padded (see jira.RiskPadding) and says so
+		if s.Risk != nil {
+			if level, multiplier := s.Risk(ticket); level != "" {
+				task.UserData.Items = append(task.UserData.Items, UserDataItem{Key: UserDataJiraRisk, Value: level})
+				if multiplier > 1 {
+					text := i18n.Sprintf("%s%s RISK: effort padded ×%s", RiskNotePrefix, strings.ToUpper(level), strconv.FormatFloat(multiplier, 'f', -1, 64))
+					if task.Note == nil {
+						task.Note = NewNote(text)
+					} else {
+						task.Note.Text.Paragraphs = append(task.Note.Text.Paragraphs, NewNote(text).Text.Paragraphs...)
+					}
+				}
+			}
+		}
+		if s.NoteTemplate != nil {
+			paragraphs, err := s.NoteTemplate.Render(ticket)
+			if err != nil {
+				term.Warnf("Ticket %s: note template failed: %v", ticket.Key, err)
+			} else if len(paragraphs) > 0 {
+				if task.Note == nil {
+					task.Note = &Note{}
+				}
+				task.Note.Text.Paragraphs = append(task.Note.Text.Paragraphs, paragraphs...)
 			}
 		}
+		keepSummary(task, ticket.Summary)
+		addJiraLink(task, ticket.Key, ticket.BrowseURL())
+
+		// Resolved work shows as complete
+		if ticket.IsDone() {
+			task.EffortDone = effort
+		}
+
+		if !ticket.ActualStart.IsZero() {
+			task.ActualStart = ticket.ActualStart.UTC().Format(DateFormat)
+		}
+		// Target dates, as from Advanced Roadmaps, bound the whole of those days
+		if !ticket.TargetStart.IsZero() {
+			task.StartNoEarlierThan = ticket.TargetStart.UTC().Format(DateFormat)
+		}
+		if !ticket.TargetEnd.IsZero() {
+			task.EndNoLaterThan = ticket.TargetEnd.AddDate(0, 0, 1).UTC().Format(DateFormat)
+		}
 
-		taskPtrs = append(taskPtrs, task)
+		task.Assignments = s.assignments(ticket, resourceIDs)
 
-		// If GroupByEpic is on and ticket has an epic link, add to epic group
-		if s.GroupByEpic && ticket.EpicLink != "" {
-			epicToChildRefs[ticket.EpicLink] = append(epicToChildRefs[ticket.EpicLink], Reference{IDRef: taskID})
+		// Nested sub-tasks go under their parent; otherwise, if GroupByEpic is
+		// on and ticket has an epic link, add to epic group
+		if parent, ok := ticketIndex[ticket.Parent]; ok && ticket.Parent != "" {
+			subtaskRefs[parent] = append(subtaskRefs[parent], Reference{IDRef: taskID})
+		} else if s.GroupByEpic && ticket.EpicLink != "" {
+			if _, seen := epicToChildRefs[ticket.EpicLink]; !seen {
+				epicOrder = append(epicOrder, ticket.EpicLink)
+			}
+			phase := ""
+			if s.EpicPhase != nil {
+				phase = s.EpicPhase(ticket)
+			}
+			if phase == "" {
+				epicToChildRefs[ticket.EpicLink] = append(epicToChildRefs[ticket.EpicLink], Reference{IDRef: taskID})
+			} else {
+				key := [2]string{ticket.EpicLink, phase}
+				p, seen := phaseIndex[key]
+				if !seen {
+					p = len(phases)
+					phaseIndex[key] = p
+					phases = append(phases, phaseGroup{title: phase, epic: ticket.EpicLink, slot: len(epicToChildRefs[ticket.EpicLink])})
+					epicToChildRefs[ticket.EpicLink] = append(epicToChildRefs[ticket.EpicLink], Reference{})
+				}
+				phases[p].children = append(phases[p].children, Reference{IDRef: taskID})
+			}
+		} else if path := s.groupPath(ticket); len(path) > 0 {
+			node := &groups.nodes[groups.add(path)]
+			node.children = append(node.children, Reference{IDRef: taskID})
 		} else {
 			// Otherwise add to top level
 			refs = append(refs, Reference{IDRef: taskID})
 		}
 	}
 
+	// A parent with nested sub-tasks becomes a group; its work is theirs
+	for parent, children := range subtaskRefs {
+		group := &ticketTasks[parent]
+		group.Type = "group"
+		group.Effort = 0
+		group.EffortDone = 0
+		group.Assignments = nil
+		group.ChildTasks = children
+	}
+
+	// Oversized tasks become chains of parts, so they level and track
+	var parts []Task
+	if s.MaxTaskDays > 0 {
+		for i := range ticketTasks {
+			parts = append(parts, s.splitTask(ids, &ticketTasks[i])...)
+		}
+	}
+
+	// Room for the epic groups, phases and milestones, the other groups, the
+	// external and Done milestones and the tickets
+	tasks := make([]Task, 0, 2*len(epicOrder)+len(phases)+len(groups.nodes)+len(s.Milestones)+1+len(tickets))
+
+	// Create the epic phase groups, filling in their slots in the epics
+	for _, p := range phases {
+		phaseID := ids.newID("t")
+		epicToChildRefs[p.epic][p.slot] = Reference{IDRef: phaseID}
+		tasks = append(tasks, Task{
+			ID:          phaseID,
+			Title:       p.title,
+			Type:        "group",
+			Recalculate: "duration",
+			ChildTasks:  p.children,
+		})
+	}
+
 	// Create Epic Groups and Milestones if needed
 	if s.GroupByEpic {
-		for epicKey, children := range epicToChildRefs {
+		for _, epicKey := range epicOrder {
+			children := epicToChildRefs[epicKey]
 			// Retrieve epic details
+			epicTicket, found := epics[epicKey]
 			epicSummary := epicKey
-			epicLink := ""
-			epicStatus := ""
-			if epicTicket, ok := epics[epicKey]; ok {
+			if found {
 				epicSummary = epicTicket.Summary
-				epicLink = epicTicket.Link
-				epicStatus = epicTicket.Status
+			}
+			userData := []UserDataItem{
+				{Key: UserDataJiraKey, Value: epicKey},
+				{Key: UserDataJiraLink, Value: epicTicket.Link},
+				{Key: UserDataJiraStatus, Value: epicTicket.Status},
+			}
+			if epicTicket.EffortDays > 0 {
+				userData = append(userData, UserDataItem{Key: UserDataJiraEffort, Value: strconv.FormatFloat(epicTicket.EffortDays, 'f', -1, 64)})
+			}
+			if !epicTicket.DueDate.IsZero() {
+				userData = append(userData, UserDataItem{Key: UserDataJiraDueDate, Value: epicTicket.DueDate.Format("2006-01-02")})
 			}
 
 			// Create Group Task for Epic
-			groupID := fmt.Sprintf("t%d", idCounter.Add(1))
-			groupTask := &Task{
+			groupID := ids.newID("t")
+			tasks = append(tasks, Task{
 				ID:          groupID,
-				Title:       epicSummary,
+				Title:       s.title("", epicSummary),
 				Type:        "group",
 				Recalculate: "duration",
 				StaticCost:  0,
 				ChildTasks:  children,
-				UserData: &UserData{
-					Items: []UserDataItem{
-						{Key: "Jira Key", Value: epicKey},
-						{Key: "Jira Link", Value: epicLink},
-						{Key: "Jira Status", Value: epicStatus},
-					},
-				},
-			}
-			epicTasks[epicKey] = groupTask
-			tasks = append(tasks, *groupTask)
+				UserData:    &UserData{Items: userData},
+			})
+			keepSummary(&tasks[len(tasks)-1], epicSummary)
+			addJiraLink(&tasks[len(tasks)-1], epicKey, epicTicket.BrowseURL())
+			epicGroups[epicKey] = len(tasks) - 1
 			refs = append(refs, Reference{IDRef: groupID})
 
 			// Create Milestone for Epic
-			milestoneID := fmt.Sprintf("t%d", idCounter.Add(1))
-			milestoneTask := &Task{
+			milestoneID := ids.newID("t")
+			tasks = append(tasks, Task{
 				ID:          milestoneID,
-				Title:       fmt.Sprintf("%s Done", epicSummary),
+				Title:       i18n.Sprintf("%s Done", s.title("", epicSummary)),
 				Type:        "milestone",
 				Recalculate: "duration",
 				StaticCost:  0,
 				Prerequisites: []PrerequisiteTask{
 					{IDRef: groupID},
 				},
+			})
+			// The due date, or target end if any, is a deadline: the epic must
+			// be done by the end of that day
+			deadline := epicTicket.DueDate
+			if !epicTicket.TargetEnd.IsZero() {
+				deadline = epicTicket.TargetEnd
 			}
-			epicMilestones[epicKey] = milestoneTask
-			tasks = append(tasks, *milestoneTask)
+			if !deadline.IsZero() {
+				tasks[len(tasks)-1].EndNoLaterThan = deadline.AddDate(0, 0, 1).UTC().Format(DateFormat)
+			}
+			epicMilestones[epicKey] = milestoneID
 			refs = append(refs, Reference{IDRef: milestoneID})
 		}
+
+		// An epic depending on another epic starts once that epic's milestone is reached
+		for _, epicKey := range epicOrder {
+			for _, depKey := range epics[epicKey].DependencyKeys {
+				if milestoneID, ok := epicMilestones[depKey]; ok && depKey != epicKey {
+					group := &tasks[epicGroups[epicKey]]
+					group.Prerequisites = append(group.Prerequisites, PrerequisiteTask{IDRef: milestoneID})
+				}
+			}
+		}
+	}
+
+	// Create a group task per GroupBy value; only the outermost are top level
+	groups.assignIDs(ids)
+	for _, node := range groups.nodes {
+		tasks = append(tasks, Task{
+			ID:          node.id,
+			Title:       node.title,
+			Type:        "group",
+			Recalculate: "duration",
+			ChildTasks:  node.children,
+		})
+		if node.parent < 0 {
+			refs = append(refs, Reference{IDRef: node.id})
+		}
+	}
+
+	var targets bufferTargets
+	for _, epicKey := range epicOrder {
+		if id, ok := epicMilestones[epicKey]; ok {
+			targets.epicMilestones = append(targets.epicMilestones, id)
+		}
+	}
+
+	// External milestones are pinned to their day and wait for the work they depend on
+	for _, m := range s.Milestones {
+		milestone := Task{
+			ID:                 ids.newID("t"),
+			Title:              m.Name,
+			Type:               "milestone",
+			Recalculate:        "duration",
+			StartNoEarlierThan: m.Date.UTC().Format(DateFormat),
+			EndNoLaterThan:     m.Date.AddDate(0, 0, 1).UTC().Format(DateFormat),
+		}
+		for _, key := range m.DependsOn {
+			prereqs := s.milestonePrerequisites(key, tickets, jiraKeyToTaskID, epicMilestones)
+			if len(prereqs) == 0 {
+				term.Warnf("Milestone %q depends on %s, but %s was not found in the JQL result set.", m.Name, key, key)
+			}
+			milestone.Prerequisites = append(milestone.Prerequisites, prereqs...)
+		}
+		tasks = append(tasks, milestone)
+		refs = append(refs, Reference{IDRef: milestone.ID})
+		targets.milestones = append(targets.milestones, milestone.ID)
 	}
 
 	// Create "Done" Milestone if requested
@@ -242,9 +647,9 @@ func (s *Serializer) buildTasksFromTickets(tickets []jira.Ticket, assigneeToReso
 
 		// If GroupByEpic is active, depend on Epic milestones
 		if s.GroupByEpic {
-			for _, milestoneTask := range epicMilestones {
+			for _, epicKey := range epicOrder {
 				donePrereqs = append(donePrereqs, PrerequisiteTask{
-					IDRef: milestoneTask.ID,
+					IDRef: epicMilestones[epicKey],
 				})
 			}
 		} else {
@@ -262,17 +667,17 @@ func (s *Serializer) buildTasksFromTickets(tickets []jira.Ticket, assigneeToReso
 		}
 
 		if len(donePrereqs) > 0 {
-			milestoneID := fmt.Sprintf("t%d", idCounter.Add(1))
-			doneTask := &Task{
+			milestoneID := ids.newID("t")
+			tasks = append(tasks, Task{
 				ID:            milestoneID,
-				Title:         "Done",
+				Title:         i18n.T("Done"),
 				Type:          "milestone",
 				Recalculate:   "duration",
 				StaticCost:    0,
 				Prerequisites: donePrereqs,
-			}
-			tasks = append(tasks, *doneTask)
+			})
 			refs = append(refs, Reference{IDRef: milestoneID})
+			targets.done = milestoneID
 		}
 	}
 
@@ -284,23 +689,102 @@ func (s *Serializer) buildTasksFromTickets(tickets []jira.Ticket, assigneeToReso
 
 		for _, depKey := range ticket.DependencyKeys {
 			if depID, ok := jiraKeyToTaskID[depKey]; ok {
-				taskPtrs[i].Prerequisites = append(taskPtrs[i].Prerequisites, PrerequisiteTask{
+				ticketTasks[i].Prerequisites = append(ticketTasks[i].Prerequisites, PrerequisiteTask{
 					IDRef: depID,
 				})
 			} else {
-				fmt.Printf("Warning: Ticket %s depends on %s, but %s was not found in the JQL result set.\n", ticket.Key, depKey, depKey)
+				term.Warnf("Ticket %s depends on %s, but %s was not found in the JQL result set.", ticket.Key, depKey, depKey)
 			}
 		}
 	}
 
-	// Convert *Task back to Task struct values
-	for _, t := range taskPtrs {
-		tasks = append(tasks, *t)
+	tasks = append(tasks, ticketTasks...)
+	tasks = append(tasks, parts...)
+	if s.BufferPercent > 0 {
+		return s.addBuffers(ids, tasks, refs, targets)
 	}
-
 	return tasks, refs
 }
 
+// milestonePrerequisites resolves a milestone dependency: a ticket, an epic's
+// milestone when grouping by epic, or otherwise all tickets of the epic
+func (s *Serializer) milestonePrerequisites(key string, tickets []jira.Ticket, jiraKeyToTaskID, epicMilestones map[string]string) []PrerequisiteTask {
+	if id, ok := jiraKeyToTaskID[key]; ok {
+		return []PrerequisiteTask{{IDRef: id}}
+	}
+	if id, ok := epicMilestones[key]; ok {
+		return []PrerequisiteTask{{IDRef: id}}
+	}
+
+	var prereqs []PrerequisiteTask
+	for _, t := range tickets {
+		if t.EpicLink == key {
+			prereqs = append(prereqs, PrerequisiteTask{IDRef: jiraKeyToTaskID[t.Key]})
+		}
+	}
+	return prereqs
+}
+
+// title returns prefix and summary as a title of at most MaxTitleLength
+// characters. The prefix is kept, and the summary cut if need be.
+func (s *Serializer) title(prefix, summary string) string {
+	if s.MaxTitleLength <= 0 {
+		return prefix + summary
+	}
+	return prefix + truncateTitle(summary, s.MaxTitleLength-utf8.RuneCountInString(prefix))
+}
+
+// truncateTitle cuts s to at most max characters, ending in "…". It cuts at
+// the last space in the second half of the limit, so words stay whole
+// unless one is very long.
+func truncateTitle(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max < 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	cut := max - 1
+	for i := cut; i >= max/2; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[:cut]), " ,;:.-–—") + "…"
+}
+
+// keepSummary records the whole summary of a task whose title was cut, as
+// the first paragraph of its note and as user data
+func keepSummary(task *Task, summary string) {
+	if strings.HasSuffix(task.Title, summary) {
+		return
+	}
+	if task.UserData == nil {
+		task.UserData = &UserData{}
+	}
+	task.UserData.Items = append(task.UserData.Items, UserDataItem{Key: UserDataJiraSummary, Value: summary})
+	paragraph := NewNote(summary).Text.Paragraphs
+	if task.Note == nil {
+		task.Note = &Note{}
+	}
+	task.Note.Text.Paragraphs = append(paragraph, task.Note.Text.Paragraphs...)
+}
+
+// issueTypePrefix returns the title prefix configured for an issue type
+func (s *Serializer) issueTypePrefix(issueType string) string {
+	if issueType == "" {
+		return ""
+	}
+	for name, prefix := range s.IssueTypePrefixes {
+		if strings.EqualFold(name, issueType) {
+			return prefix
+		}
+	}
+	return ""
+}
+
 // SerializeToString is a convenience method that serializes to a string
 func (s *Serializer) SerializeToString(tickets []jira.Ticket) (string, error) {
 	var buf []byte
@@ -322,3 +806,14 @@ func (w *byteSliceWriter) Write(p []byte) (n int, err error) {
 	*w.buf = append(*w.buf, p...)
 	return len(p), nil
 }
+
+// addJiraLink appends a clickable link to the Jira issue to the task's note
+func addJiraLink(task *Task, key, url string) {
+	if url == "" {
+		return
+	}
+	if task.Note == nil {
+		task.Note = &Note{}
+	}
+	task.Note.AddLink(i18n.T("Jira: "), key, url)
+}
@@ -2,9 +2,19 @@ package omniplan
 
 import (
 	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gunnarrb/jql-to-plan/internal/i18n"
 	"github.com/gunnarrb/jql-to-plan/internal/jira"
 )
 
@@ -201,3 +211,1430 @@ func TestSerializer_Serialize_WithMilestoneDone(t *testing.T) {
 		t.Error("Output should contain 'Epic 1 Done' milestone")
 	}
 }
+
+func TestSerializer_Serialize_EpicDetails(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Backend Task", EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Frontend Task", EpicLink: "EPIC-2"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "Backend", EffortDays: 10, DueDate: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		"EPIC-2": {Key: "EPIC-2", Summary: "Frontend", DependencyKeys: []string{"EPIC-1", "EPIC-99"}},
+	}
+
+	serializer := NewSerializer("Epic Project")
+	serializer.GroupByEpic = true
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, epics); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+	}
+
+	if got := byTitle["Backend Done"].EndNoLaterThan; got != "2026-04-01T00:00:00.000Z" {
+		t.Errorf("Expected the Backend milestone deadline at the end of the due date, got %q", got)
+	}
+	if got := byTitle["Backend"].UserDataValue("Jira Effort"); got != "10" {
+		t.Errorf("Expected Jira Effort 10, got %q", got)
+	}
+	if got := byTitle["Backend"].UserDataValue("Jira Due Date"); got != "2026-03-31" {
+		t.Errorf("Expected Jira Due Date 2026-03-31, got %q", got)
+	}
+
+	// Frontend waits for the Backend milestone; the epic outside the plan is ignored
+	prereqs := byTitle["Frontend"].Prerequisites
+	if len(prereqs) != 1 || prereqs[0].IDRef != byTitle["Backend Done"].ID {
+		t.Errorf("Expected Frontend to depend on the Backend milestone, got %+v", prereqs)
+	}
+}
+
+func TestSerializer_Serialize_IssueTypes(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "BUG-1", Summary: "Crash on save", IssueType: "Bug"},
+		{Key: "STORY-1", Summary: "Export to CSV", IssueType: "Story"},
+	}
+
+	serializer := NewSerializer("Typed Project")
+	serializer.IssueTypePrefixes = map[string]string{"bug": "🐞 "}
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<title>🐞 Crash on save</title>") {
+		t.Error("Bug title should carry the configured prefix")
+	}
+	if !strings.Contains(output, "<title>Export to CSV</title>") {
+		t.Error("Story title should be unchanged")
+	}
+	if !strings.Contains(output, "<key>Jira Type</key>") || !strings.Contains(output, "<string>Story</string>") {
+		t.Error("Output should contain the Jira Type user data")
+	}
+}
+
+func TestDiffScenarios_RoundTrip(t *testing.T) {
+	before := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Task 1", EffortDays: 1, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Task 2", EffortDays: 2, EpicLink: "EPIC-1"},
+	}
+	after := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Task 1", EffortDays: 3, EpicLink: "EPIC-1"},
+		{Key: "TASK-3", Summary: "Task 3", EffortDays: 1, EpicLink: "EPIC-1"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "Epic 1"},
+	}
+
+	read := func(tickets []jira.Ticket) *Scenario {
+		serializer := NewSerializer("Diff Project")
+		serializer.GroupByEpic = true
+		var buf bytes.Buffer
+		if err := serializer.Serialize(&buf, tickets, epics); err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		scenario, err := ReadScenario(&buf)
+		if err != nil {
+			t.Fatalf("ReadScenario failed: %v", err)
+		}
+		return scenario
+	}
+
+	diff := DiffScenarios(read(before), read(after))
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "TASK-3" {
+		t.Errorf("Expected TASK-3 to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "TASK-2" {
+		t.Errorf("Expected TASK-2 to be removed, got %+v", diff.Removed)
+	}
+	if len(diff.EffortChanged) != 1 || diff.EffortChanged[0].Key != "TASK-1" {
+		t.Errorf("Expected TASK-1 effort change, got %+v", diff.EffortChanged)
+	}
+	// +2d on TASK-1, +1d TASK-3, -2d TASK-2
+	if diff.EffortDelta != 28800 {
+		t.Errorf("Expected effort delta of 28800 seconds, got %d", diff.EffortDelta)
+	}
+	if len(diff.SlippedMilestones) != 1 || diff.SlippedMilestones[0].Title != "Epic 1 Done" {
+		t.Errorf("Expected 'Epic 1 Done' to slip, got %+v", diff.SlippedMilestones)
+	}
+}
+
+func TestSerializer_Serialize_WithActualStart(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Started Task", ActualStart: time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC)},
+		{Key: "TASK-2", Summary: "Not Started Task"},
+	}
+
+	serializer := NewSerializer("Actual Start Project")
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<actual-start>2025-03-04T09:30:00.000Z</actual-start>") {
+		t.Error("Output should contain actual-start for the started task")
+	}
+	if strings.Count(output, "<actual-start>") != 1 {
+		t.Error("Only tickets with a known start should have actual-start")
+	}
+}
+
+func TestSerializer_Serialize_TargetDates(t *testing.T) {
+	oslo, _ := time.LoadLocation("Europe/Oslo")
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Targeted", TargetStart: time.Date(2026, 3, 2, 0, 0, 0, 0, oslo), TargetEnd: time.Date(2026, 3, 13, 0, 0, 0, 0, oslo)},
+		{Key: "TASK-2", Summary: "Untargeted", DueDate: time.Date(2026, 3, 13, 0, 0, 0, 0, oslo)},
+	}
+
+	serializer := NewSerializer("Roadmap Project")
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+	}
+
+	if got := byTitle["Targeted"]; got.StartNoEarlierThan != "2026-03-01T23:00:00.000Z" || got.EndNoLaterThan != "2026-03-13T23:00:00.000Z" {
+		t.Errorf("Expected constraints spanning the target days, got %s..%s", got.StartNoEarlierThan, got.EndNoLaterThan)
+	}
+	if got := byTitle["Untargeted"]; got.StartNoEarlierThan != "" || got.EndNoLaterThan != "" {
+		t.Errorf("Expected a due date alone to add no constraint, got %s..%s", got.StartNoEarlierThan, got.EndNoLaterThan)
+	}
+
+	read, _ := ScenarioTickets(scenario, oslo)
+	if !read[0].TargetStart.Equal(tickets[0].TargetStart) || !read[0].TargetEnd.Equal(tickets[0].TargetEnd) {
+		t.Errorf("Expected the target dates to read back, got %v..%v", read[0].TargetStart, read[0].TargetEnd)
+	}
+}
+
+func TestSerializer_Serialize_Flagged(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Blocked Task", Flagged: true},
+		{Key: "TASK-2", Summary: "Normal Task"},
+	}
+
+	serializer := NewSerializer("Flagged Project")
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<key>Jira Flagged</key>") {
+		t.Error("Output should contain Jira Flagged user data")
+	}
+	if !strings.Contains(output, FlaggedNotePrefix) {
+		t.Error("Output should contain the impeded note prefix")
+	}
+	if strings.Count(output, "<note>") != 1 {
+		t.Error("Only the flagged task should have a note")
+	}
+}
+
+func TestSerializer_Serialize_JiraLinkInNote(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Linked Task", Link: "https://jira.example.com/rest/api/2/issue/10001", EpicLink: "EPIC-1", Flagged: true},
+		{Key: "TASK-2", Summary: "No Link"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "Backend", Link: "https://jira.example.com/rest/api/2/issue/10000"},
+	}
+
+	serializer := NewSerializer("Linked Project")
+	serializer.GroupByEpic = true
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, epics); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+
+	links := make(map[string]string)
+	for _, task := range scenario.Tasks {
+		if task.Note == nil {
+			continue
+		}
+		for _, p := range task.Note.Text.Paragraphs {
+			for _, r := range p.Runs {
+				if r.Style != nil && len(r.Style.Values) == 1 && r.Style.Values[0].Key == "link" {
+					links[task.Title] = r.Literal + " " + r.Style.Values[0].Value
+				}
+			}
+		}
+	}
+
+	want := map[string]string{
+		"Linked Task": "TASK-1 https://jira.example.com/browse/TASK-1",
+		"Backend":     "EPIC-1 https://jira.example.com/browse/EPIC-1",
+	}
+	if fmt.Sprint(links) != fmt.Sprint(want) {
+		t.Errorf("note links = %v, want %v", links, want)
+	}
+}
+
+func TestSerializer_Serialize_ExternalMilestones(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "API", EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "UI", EpicLink: "EPIC-1"},
+		{Key: "TASK-3", Summary: "Docs"},
+	}
+	zone := time.FixedZone("UTC+2", 2*60*60)
+
+	serializer := NewSerializer("Launch")
+	serializer.Milestones = []Milestone{
+		{Name: "Marketing launch", Date: time.Date(2025, 10, 1, 0, 0, 0, 0, zone), DependsOn: []string{"EPIC-1", "TASK-3", "MISSING-1"}},
+	}
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+
+	ids := make(map[string]string)
+	var launch *Task
+	for i, task := range scenario.Tasks {
+		ids[task.Title] = task.ID
+		if task.Title == "Marketing launch" {
+			launch = &scenario.Tasks[i]
+		}
+	}
+	if launch == nil || launch.Type != "milestone" {
+		t.Fatalf("expected a Marketing launch milestone, got %+v", launch)
+	}
+	if launch.StartNoEarlierThan != "2025-09-30T22:00:00.000Z" || launch.EndNoLaterThan != "2025-10-01T22:00:00.000Z" {
+		t.Errorf("milestone constraints = %s..%s, want the whole of 2025-10-01 in UTC+2", launch.StartNoEarlierThan, launch.EndNoLaterThan)
+	}
+
+	var prereqs []string
+	for _, p := range launch.Prerequisites {
+		prereqs = append(prereqs, p.IDRef)
+	}
+	want := []string{ids["API"], ids["UI"], ids["Docs"]}
+	if fmt.Sprint(prereqs) != fmt.Sprint(want) {
+		t.Errorf("prerequisites = %v, want %v (the epic's tickets, then TASK-3)", prereqs, want)
+	}
+}
+
+func TestSerializer_Serialize_NestSubtasks(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "SUB-1", Summary: "Write migration", Parent: "STORY-1", EffortDays: 1, Assignee: "Alice"},
+		{Key: "STORY-1", Summary: "Store orders", EffortDays: 5, Assignee: "Alice", EpicLink: "EPIC-1"},
+		{Key: "SUB-2", Summary: "Backfill", Parent: "STORY-1", EffortDays: 2},
+	}
+
+	serializer := NewSerializer("Nested")
+	serializer.GroupByEpic = true
+	serializer.NestSubtasks = true
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+	}
+	story := byTitle["Store orders"]
+	if story.Type != "group" || story.Effort != 0 || len(story.Assignments) != 0 {
+		t.Errorf("parent should be an unassigned group without effort, got %+v", story)
+	}
+	children := []string{byTitle["Write migration"].ID, byTitle["Backfill"].ID}
+	var got []string
+	for _, ref := range story.ChildTasks {
+		got = append(got, ref.IDRef)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(children) {
+		t.Errorf("parent children = %v, want %v", got, children)
+	}
+
+	// The parent stays in its epic; sub-tasks appear only under the parent
+	epic := byTitle["EPIC-1"]
+	if len(epic.ChildTasks) != 1 || epic.ChildTasks[0].IDRef != story.ID {
+		t.Errorf("epic children = %v, want only the parent", epic.ChildTasks)
+	}
+	for _, ref := range scenario.Tasks[0].ChildTasks {
+		if ref.IDRef == children[0] || ref.IDRef == children[1] {
+			t.Errorf("sub-task %s should not be at the top level", ref.IDRef)
+		}
+	}
+}
+
+func TestSerializer_Serialize_GroupBy(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Checkout", Labels: []string{"stream-payments"}},
+		{Key: "TASK-2", Summary: "Search"},
+		{Key: "TASK-3", Summary: "Refunds", Labels: []string{"backend", "stream-payments"}},
+	}
+
+	serializer := NewSerializer("Streams")
+	serializer.GroupBy = []func(jira.Ticket) string{func(t jira.Ticket) string {
+		for _, label := range t.Labels {
+			if strings.HasPrefix(label, "stream-") {
+				return label
+			}
+		}
+		return ""
+	}}
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("ReadScenario failed: %v", err)
+	}
+
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+	}
+	group, ok := byTitle["stream-payments"]
+	if !ok || group.Type != "group" {
+		t.Fatalf("expected a stream-payments group, got %+v", group)
+	}
+	if len(group.ChildTasks) != 2 || group.ChildTasks[0].IDRef != byTitle["Checkout"].ID || group.ChildTasks[1].IDRef != byTitle["Refunds"].ID {
+		t.Errorf("group children = %v, want Checkout and Refunds", group.ChildTasks)
+	}
+
+	var top []string
+	for _, ref := range scenario.Tasks[0].ChildTasks {
+		top = append(top, ref.IDRef)
+	}
+	if want := []string{byTitle["Search"].ID, group.ID}; fmt.Sprint(top) != fmt.Sprint(want) {
+		t.Errorf("top-level tasks = %v, want the ungrouped ticket and the group %v", top, want)
+	}
+}
+
+func TestSerializer_Serialize_GroupByLevels(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Checkout", EpicLink: "EPIC-1", Fields: map[string]string{"components": "Web"}},
+		{Key: "TASK-2", Summary: "Refunds", EpicLink: "EPIC-1", Fields: map[string]string{"components": "API"}},
+		{Key: "TASK-3", Summary: "Receipts", EpicLink: "EPIC-1"},
+		{Key: "TASK-4", Summary: "Search", EpicLink: "EPIC-2", Fields: map[string]string{"components": "Web"}},
+	}
+
+	serializer := NewSerializer("Levels")
+	serializer.GroupBy = []func(jira.Ticket) string{
+		func(t jira.Ticket) string { return t.EpicLink },
+		func(t jira.Ticket) string { return t.Fields["components"] },
+	}
+	scenario := serializer.buildScenario(tickets, nil)
+
+	byTitle := make(map[string]Task)
+	var groups []Task
+	var titles []string
+	for _, task := range scenario.Tasks {
+		if task.Type == "group" && task.Title != "" {
+			groups = append(groups, task)
+			titles = append(titles, task.Title)
+		}
+		byTitle[task.Title] = task
+	}
+	children := func(title string) []string {
+		var ids []string
+		for _, ref := range byTitle[title].ChildTasks {
+			ids = append(ids, ref.IDRef)
+		}
+		return ids
+	}
+	id := func(titles ...string) []string {
+		var ids []string
+		for _, title := range titles {
+			ids = append(ids, byTitle[title].ID)
+		}
+		return ids
+	}
+
+	// Groups are keyed by their parent, so "Web" appears under both epics
+	if fmt.Sprint(titles) != "[EPIC-1 Web API EPIC-2 Web]" {
+		t.Errorf("group titles = %v, want [EPIC-1 Web API EPIC-2 Web]", titles)
+	}
+	if got, want := children("EPIC-1"), []string{groups[1].ID, groups[2].ID, byTitle["Receipts"].ID}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("EPIC-1 children = %v, want its component groups then the ticket without one %v", got, want)
+	}
+	if got, want := children("API"), id("Refunds"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("API children = %v, want %v", got, want)
+	}
+	if got, want := scenario.Tasks[0].ChildTasks, []Reference{{IDRef: byTitle["EPIC-1"].ID}, {IDRef: byTitle["EPIC-2"].ID}}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("top-level tasks = %v, want only the epic groups %v", got, want)
+	}
+}
+
+func TestSerializer_Serialize_EpicPhases(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Checkout", EpicLink: "EPIC-1", Fields: map[string]string{"Sprint": "Sprint 1"}},
+		{Key: "TASK-2", Summary: "Refunds", EpicLink: "EPIC-1", Fields: map[string]string{"Sprint": "Sprint 2"}},
+		{Key: "TASK-3", Summary: "Receipts", EpicLink: "EPIC-1"},
+		{Key: "TASK-4", Summary: "Wallet", EpicLink: "EPIC-1", Fields: map[string]string{"Sprint": "Sprint 1"}},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Payments"}}
+
+	serializer := NewSerializer("Phases")
+	serializer.GroupByEpic = true
+	serializer.EpicPhase = func(t jira.Ticket) string { return t.Fields["Sprint"] }
+	scenario := serializer.buildScenario(tickets, epics)
+
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+	}
+	refs := func(titles ...string) []Reference {
+		var refs []Reference
+		for _, title := range titles {
+			refs = append(refs, Reference{IDRef: byTitle[title].ID})
+		}
+		return refs
+	}
+
+	if got, want := byTitle["Payments"].ChildTasks, refs("Sprint 1", "Sprint 2", "Receipts"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("epic children = %v, want the sprint phases then the unplanned ticket %v", got, want)
+	}
+	if got, want := byTitle["Sprint 1"].ChildTasks, refs("Checkout", "Wallet"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Sprint 1 children = %v, want %v", got, want)
+	}
+	if byTitle["Sprint 2"].Type != "group" {
+		t.Errorf("Expected the Sprint 2 phase to be a group, got %q", byTitle["Sprint 2"].Type)
+	}
+}
+
+func TestSerializer_Serialize_DoneTickets(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Shipped", EffortDays: 2, StatusCategory: "done"},
+		{Key: "TASK-2", Summary: "Open", EffortDays: 1, StatusCategory: "indeterminate"},
+	}
+	scenario := NewSerializer("Done").buildScenario(tickets, nil)
+
+	if done := scenario.Tasks[1]; done.EffortDone != done.Effort || done.Effort != 2*28800 {
+		t.Errorf("Expected the done ticket to be complete, got effort %d done %d", done.Effort, done.EffortDone)
+	}
+	if open := scenario.Tasks[2]; open.EffortDone != 0 {
+		t.Errorf("Expected no effort done on the open ticket, got %d", open.EffortDone)
+	}
+}
+
+func TestSerializer_Serialize_UserProfiles(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Profiled", Assignee: "Ada"},
+		{Key: "TASK-2", Summary: "Unprofiled", Assignee: "Bob"},
+	}
+
+	serializer := NewSerializer("Profile Project")
+	serializer.UserProfiles = map[string]jira.UserProfile{
+		"Ada": {DisplayName: "Ada", Email: "ada@example.com", TimeZone: "Europe/Oslo", AvatarURL: "https://jira.example.com/avatar/ada"},
+	}
+	scenario := serializer.buildScenario(tickets, nil)
+
+	resources := make(map[string]Resource)
+	for _, r := range scenario.Resources {
+		resources[r.Name] = r
+	}
+	ada := resources["Ada"]
+	if ada.UserData == nil || len(ada.UserData.Items) != 3 || ada.UserData.Items[0] != (UserDataItem{Key: UserDataJiraTimeZone, Value: "Europe/Oslo"}) {
+		t.Errorf("Ada's resource should carry the profile as user data, got %+v", ada.UserData)
+	}
+	if ada.Note == nil || len(ada.Note.Text.Paragraphs) != 3 {
+		t.Fatalf("Ada's resource should have a three-paragraph note, got %+v", ada.Note)
+	}
+	if run := ada.Note.Text.Paragraphs[1].Runs[1]; run.Literal != "ada@example.com" || run.Style.Values[0].Value != "mailto:ada@example.com" {
+		t.Errorf("Email should link to mailto:, got %+v", run)
+	}
+	if bob := resources["Bob"]; bob.UserData != nil || bob.Note != nil {
+		t.Errorf("Bob has no profile and should have no note or user data, got %+v", bob)
+	}
+
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<key>Jira Time Zone</key>") {
+		t.Error("Output should contain the resource user data")
+	}
+}
+
+func TestSerializer_Serialize_TeamResources(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Assigned", Assignee: "Payments", Fields: map[string]string{"Team": "Search"}},
+		{Key: "TASK-2", Summary: "Team only", Fields: map[string]string{"Team": "Payments"}},
+		{Key: "TASK-3", Summary: "Same team", Fields: map[string]string{"Team": "Payments"}},
+		{Key: "TASK-4", Summary: "Nobody"},
+	}
+
+	serializer := NewSerializer("Team Project")
+	serializer.Team = func(t jira.Ticket) string { return t.Fields["Team"] }
+	scenario := serializer.buildScenario(tickets, nil)
+
+	// The person named like a team and the team get separate resources
+	if len(scenario.Resources) != 3 {
+		t.Fatalf("Expected the project, one person and one team resource, got %+v", scenario.Resources)
+	}
+	assigned := make(map[string]string)
+	for _, task := range scenario.Tasks {
+		if len(task.Assignments) == 1 {
+			assigned[task.UserDataValue(UserDataJiraKey)] = task.Assignments[0].IDRef
+		}
+	}
+	person, team := scenario.Resources[1].ID, scenario.Resources[2].ID
+	if assigned["TASK-1"] != person || assigned["TASK-2"] != team || assigned["TASK-3"] != team {
+		t.Errorf("Expected TASK-1 on %s and TASK-2 and TASK-3 on team %s, got %v", person, team, assigned)
+	}
+	if _, ok := assigned["TASK-4"]; ok {
+		t.Error("A ticket without assignee or team should stay unassigned")
+	}
+}
+
+func TestSerializer_Serialize_CoAssignees(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Pairing", Assignee: "Alice", CoAssignees: []string{"Bob"}},
+		{Key: "TASK-2", Summary: "Mob", Assignee: "Bob", CoAssignees: []string{"Alice", "Carol"}},
+		{Key: "TASK-3", Summary: "Solo", Assignee: "Carol"},
+	}
+
+	serializer := NewSerializer("Pairing Project")
+	scenario := serializer.buildScenario(tickets, nil)
+
+	// Co-assignees get staff resources like assignees
+	names := make(map[string]string)
+	for _, r := range scenario.Resources[1:] {
+		names[r.ID] = r.Name
+	}
+	if len(names) != 3 {
+		t.Fatalf("Expected one resource per person, got %+v", scenario.Resources)
+	}
+	assignments := func(scenario *Scenario) map[string]string {
+		got := make(map[string]string)
+		for _, task := range scenario.Tasks {
+			var parts []string
+			for _, a := range task.Assignments {
+				parts = append(parts, fmt.Sprintf("%s:%.2f", names[a.IDRef], a.Units))
+			}
+			got[task.UserDataValue(UserDataJiraKey)] = strings.Join(parts, " ")
+		}
+		return got
+	}
+
+	got := assignments(scenario)
+	if got["TASK-1"] != "Alice:0.50 Bob:0.50" || got["TASK-2"] != "Bob:0.33 Alice:0.33 Carol:0.33" || got["TASK-3"] != "Carol:0.00" {
+		t.Errorf("Expected equal splits and a full-time solo assignment, got %v", got)
+	}
+
+	serializer.PrimaryUnits = 0.6
+	got = assignments(serializer.buildScenario(tickets, nil))
+	if got["TASK-1"] != "Alice:0.60 Bob:0.40" || got["TASK-2"] != "Bob:0.60 Alice:0.20 Carol:0.20" {
+		t.Errorf("Expected the assignee to get 60%%, got %v", got)
+	}
+}
+
+func TestSerializer_Serialize_ResourcesByAssigneeID(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Review notes", Assignee: "Sam Lee", CoAssignees: []string{"Ann Ray"}},
+		{Key: "TASK-2", Summary: "Before rename", Assignee: "Ann Ray", AssigneeID: "u1"},
+		{Key: "TASK-3", Summary: "After rename", Assignee: "Ann Ray-Berg", AssigneeID: "u1"},
+		{Key: "TASK-4", Summary: "Namesake", Assignee: "Sam Lee", AssigneeID: "u2"},
+	}
+
+	scenario := NewSerializer("People").buildScenario(tickets, nil)
+	names := make(map[string]string)
+	for _, r := range scenario.Resources[1:] {
+		names[r.ID] = r.Name
+	}
+	if len(names) != 2 {
+		t.Fatalf("Expected one resource per user, got %+v", scenario.Resources)
+	}
+	resources := make(map[string][]string)
+	for _, task := range scenario.Tasks {
+		for _, a := range task.Assignments {
+			key := task.UserDataValue(UserDataJiraKey)
+			resources[key] = append(resources[key], a.IDRef)
+		}
+	}
+	if resources["TASK-2"][0] != resources["TASK-3"][0] || names[resources["TASK-3"][0]] != "Ann Ray" {
+		t.Errorf("Expected the renamed user on one resource labelled with the first name, got %v of %v", resources, names)
+	}
+	if resources["TASK-1"][0] != resources["TASK-4"][0] || resources["TASK-1"][1] != resources["TASK-2"][0] {
+		t.Errorf("Expected tasks assigned by name to share the resource of the user with that name, got %v", resources)
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"Short title", 20, "Short title"},
+		{"Exactly twenty chars", 20, "Exactly twenty chars"},
+		{"Migrate the billing service to the new cluster", 20, "Migrate the billing…"},
+		{"Migrate the billing, then the cluster", 21, "Migrate the billing…"},
+		{"Supercalifragilisticexpialidocious words", 12, "Supercalifr…"},
+		{"Déploiement du système 🚀 de paiement", 25, "Déploiement du système 🚀…"},
+		{"Déploiement du système 🚀 de paiement", 24, "Déploiement du système…"},
+		{"Anything", 0, "…"},
+	}
+	for _, tt := range tests {
+		got := truncateTitle(tt.in, tt.max)
+		if got != tt.want {
+			t.Errorf("truncateTitle(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+		if n := len([]rune(got)); n > max(tt.max, 1) {
+			t.Errorf("truncateTitle(%q, %d) has %d characters", tt.in, tt.max, n)
+		}
+	}
+}
+
+func TestSerializer_Serialize_MaxTitleLength(t *testing.T) {
+	long := "Rework the checkout flow so that saved payment methods are offered first"
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: long, IssueType: "Bug", EpicLink: "EPIC-1", Flagged: true},
+		{Key: "TASK-2", Summary: "Short one", EpicLink: "EPIC-1"},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Payments overhaul for the European market launch"}}
+
+	serializer := NewSerializer("Titles")
+	serializer.GroupByEpic = true
+	serializer.MaxTitleLength = 30
+	serializer.IssueTypePrefixes = map[string]string{"Bug": "🐞 "}
+	scenario := serializer.buildScenario(tickets, epics)
+
+	byTitle := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		if len([]rune(task.Title)) > 30 && task.Type != "milestone" {
+			t.Errorf("Title %q is longer than 30 characters", task.Title)
+		}
+		byTitle[task.Title] = task
+	}
+	bug, ok := byTitle["🐞 Rework the checkout flow so…"]
+	if !ok {
+		t.Fatalf("Expected the prefixed, cut title, got %v", slices.Collect(maps.Keys(byTitle)))
+	}
+	if bug.UserDataValue(UserDataJiraSummary) != long || bug.Note.Text.Paragraphs[0].Runs[0].Literal != long ||
+		!strings.HasPrefix(bug.Note.Text.Paragraphs[1].Runs[0].Literal, FlaggedNotePrefix) {
+		t.Errorf("Expected the whole summary first in the note and in user data, got %+v", bug.Note)
+	}
+	if short := byTitle["Short one"]; short.UserDataValue(UserDataJiraSummary) != "" {
+		t.Errorf("Expected no summary data on an uncut title")
+	}
+	if _, ok := byTitle["Payments overhaul for the…"]; !ok {
+		t.Errorf("Expected the epic group's title cut, got %v", slices.Collect(maps.Keys(byTitle)))
+	}
+	if _, ok := byTitle["Payments overhaul for the… Done"]; !ok {
+		t.Errorf("Expected the epic milestone named after the cut title, got %v", slices.Collect(maps.Keys(byTitle)))
+	}
+
+	// Reading the plan back gives the whole summaries
+	read, _ := ScenarioTickets(scenario, time.UTC)
+	for _, ticket := range read {
+		if ticket.Key == "TASK-1" && ticket.Summary != long {
+			t.Errorf("Expected the whole summary read back, got %q", ticket.Summary)
+		}
+	}
+}
+
+func TestSerializer_Serialize_NoteTemplate(t *testing.T) {
+	tmpl, err := ParseNoteTemplate(`
+{{index .Fields "Acceptance Criteria"}}
+Sprint: {{index .Fields "Sprint"}}{{with date .DueDate}}, due {{.}}{{end}}
+{{with .Labels}}Labels: {{join .}}{{end}}
+`)
+	if err != nil {
+		t.Fatalf("ParseNoteTemplate failed: %v", err)
+	}
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Noted", Link: "https://jira.example.com/rest/api/2/issue/10001", Labels: []string{"api", "mobile"},
+			DueDate: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), Fields: map[string]string{"Acceptance Criteria": "Works offline", "Sprint": "Sprint 12"}},
+		{Key: "TASK-2", Summary: "Bare"},
+	}
+
+	serializer := NewSerializer("Notes")
+	serializer.NoteTemplate = tmpl
+	scenario := serializer.buildScenario(tickets, nil)
+
+	notes := make(map[string][]string)
+	for _, task := range scenario.Tasks {
+		if task.Note == nil {
+			continue
+		}
+		for _, p := range task.Note.Text.Paragraphs {
+			var runs []string
+			for _, run := range p.Runs {
+				runs = append(runs, run.Literal)
+			}
+			key := task.UserDataValue(UserDataJiraKey)
+			notes[key] = append(notes[key], strings.Join(runs, ""))
+		}
+	}
+	want := []string{"Works offline", "Sprint: Sprint 12, due 2026-05-04", "Labels: api, mobile", "Jira: TASK-1"}
+	if !slices.Equal(notes["TASK-1"], want) {
+		t.Errorf("Expected the templated note before the Jira link, got %q", notes["TASK-1"])
+	}
+	if want := []string{"Sprint:"}; !slices.Equal(notes["TASK-2"], want) {
+		t.Errorf("Expected empty values for missing fields and blank lines dropped, got %q", notes["TASK-2"])
+	}
+
+	if _, err := ParseNoteTemplate("{{.Summary"); err == nil {
+		t.Error("Expected a parse error for an unclosed action")
+	}
+	failing, _ := ParseNoteTemplate("{{.Summary.Missing}}")
+	serializer.NoteTemplate = failing
+	for _, task := range serializer.buildScenario(tickets[1:], nil).Tasks {
+		if task.Note != nil {
+			t.Errorf("Expected no note when the template fails, got %+v", task.Note)
+		}
+	}
+}
+
+func TestSerializer_Serialize_Language(t *testing.T) {
+	if err := i18n.SetLanguage("de"); err != nil {
+		t.Fatalf("SetLanguage failed: %v", err)
+	}
+	defer i18n.SetLanguage("")
+
+	tickets := []jira.Ticket{{Key: "TASK-1", Summary: "Big", EffortDays: 4, EpicLink: "EPIC-1", Flagged: true}}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Launch"}}
+	serializer := NewSerializer("Sprache")
+	serializer.GroupByEpic = true
+	serializer.MilestoneDone = true
+	serializer.MaxTaskDays = 2
+
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, epics); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"<title>Launch erledigt</title>", "<title>Erledigt</title>", "<title>Teil 2</title>", "⚑ BLOCKIERT: "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the German plan", want)
+		}
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	c, err := HexColor("#1f6FEB")
+	if err != nil {
+		t.Fatalf("HexColor failed: %v", err)
+	}
+	if *c != (Color{Space: "srgb", R: 0.121569, G: 0.435294, B: 0.921569}) {
+		t.Errorf("Unexpected color %+v", *c)
+	}
+	if c, err := HexColor("f00"); err != nil || *c != (Color{Space: "srgb", R: 1}) {
+		t.Errorf("Expected short f00 to be red, got %+v, %v", c, err)
+	}
+	for _, bad := range []string{"", "#12345", "#ggg", "#-12345", "red"} {
+		if _, err := HexColor(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestSerializer_Serialize_Palette(t *testing.T) {
+	tickets := []jira.Ticket{{Key: "TASK-1", Summary: "Styled", EffortDays: 1}}
+	serializer := NewSerializer("Styled Project")
+
+	var plain bytes.Buffer
+	if err := serializer.Serialize(&plain, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if strings.Contains(plain.String(), "-style>") {
+		t.Error("Expected no style definitions without a palette")
+	}
+
+	green, _ := HexColor("#00ff00")
+	serializer.Palette = &Palette{Font: "Helvetica Neue", FontSize: 12.5, MilestoneColor: green, CompletedColor: green}
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		`<value key="font-name">Helvetica Neue</value>`,
+		`<value key="font-size">12.5</value>`,
+		`<milestone-task-style>`,
+		`<color space="srgb" g="1"></color>`,
+		`<value key="strikethrough-style">single</value>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the styled plan", want)
+		}
+	}
+	if strings.Contains(output, "<group-task-style>") {
+		t.Error("Expected no group style without a group color")
+	}
+
+	// Styled plans still read back
+	if _, err := ReadScenario(strings.NewReader(output)); err != nil {
+		t.Errorf("ReadScenario failed on a styled plan: %v", err)
+	}
+}
+
+func TestSerializer_Serialize_WSJF(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Scored", EffortDays: 3, Fields: map[string]string{"Value": "10"}},
+		{Key: "TASK-2", Summary: "Unscored", EffortDays: 1},
+	}
+
+	serializer := NewSerializer("WSJF Project")
+	serializer.WSJFScore = jira.WSJF{CostOfDelayFields: []string{"Value"}}.Score
+	scenario := serializer.buildScenario(tickets, nil)
+
+	scores := make(map[string]string)
+	for _, task := range scenario.Tasks {
+		scores[task.UserDataValue(UserDataJiraKey)] = task.UserDataValue(UserDataWSJF)
+	}
+	if scores["TASK-1"] != "3.33" || scores["TASK-2"] != "" {
+		t.Errorf("Expected a WSJF of 3.33 on TASK-1 only, got %v", scores)
+	}
+}
+
+func TestSerializer_Serialize_MaxTaskDays(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Monolith", Assignee: "Alice", EffortDays: 20, Status: "Done", StatusCategory: "done"},
+		{Key: "TASK-2", Summary: "Big", Assignee: "Alice", EffortDays: 10, DependencyKeys: []string{"TASK-1"}},
+		{Key: "TASK-3", Summary: "Small", EffortDays: 8},
+	}
+
+	serializer := NewSerializer("Split Project")
+	serializer.MaxTaskDays = 8
+	scenario := serializer.buildScenario(tickets, nil)
+
+	byID := make(map[string]Task)
+	byKey := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byID[task.ID] = task
+		if key := task.UserDataValue(UserDataJiraKey); key != "" {
+			byKey[key] = task
+		}
+	}
+
+	const day = 8 * 3600
+	big := byKey["TASK-2"]
+	if big.Type != "group" || big.Effort != 0 || len(big.Assignments) != 0 || len(big.ChildTasks) != 2 {
+		t.Fatalf("Expected TASK-2 to become a group of 2 parts, got %+v", big)
+	}
+	if len(big.Prerequisites) != 1 || big.Prerequisites[0].IDRef != byKey["TASK-1"].ID {
+		t.Errorf("The group should keep the ticket's dependencies, got %+v", big.Prerequisites)
+	}
+	part1, part2 := byID[big.ChildTasks[0].IDRef], byID[big.ChildTasks[1].IDRef]
+	if part1.Title != "Part 1" || part2.Title != "Part 2" || part1.Effort != 5*day || part2.Effort != 5*day {
+		t.Errorf("Expected two equal parts, got %+v and %+v", part1, part2)
+	}
+	if len(part2.Prerequisites) != 1 || part2.Prerequisites[0].IDRef != part1.ID {
+		t.Errorf("Part 2 should follow part 1, got %+v", part2.Prerequisites)
+	}
+	if len(part1.Assignments) != 1 || part1.Assignments[0] != part2.Assignments[0] {
+		t.Errorf("Parts should keep the assignee, got %+v and %+v", part1.Assignments, part2.Assignments)
+	}
+
+	monolith := byKey["TASK-1"]
+	if len(monolith.ChildTasks) != 3 {
+		t.Fatalf("Expected 20 days in 3 parts, got %+v", monolith.ChildTasks)
+	}
+	var effort int64
+	for _, ref := range monolith.ChildTasks {
+		part := byID[ref.IDRef]
+		if part.EffortDone != part.Effort {
+			t.Errorf("Parts of done work should be done, got %+v", part)
+		}
+		effort += part.Effort
+	}
+	if effort != 20*day {
+		t.Errorf("Parts add up to %v, want 20 days", effort)
+	}
+
+	if small := byKey["TASK-3"]; small.Type != "" || small.Effort != 8*day {
+		t.Errorf("A task at the limit should stay whole, got %+v", small)
+	}
+}
+
+func TestSerializer_Serialize_Buffers(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Big", EffortDays: 2, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Small", EffortDays: 1, EpicLink: "EPIC-1"},
+		{Key: "TASK-3", Summary: "Side", EffortDays: 1, EpicLink: "EPIC-2"},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Main"}, "EPIC-2": {Key: "EPIC-2", Summary: "Feeder"}}
+
+	serializer := NewSerializer("Buffer Project")
+	serializer.GroupByEpic = true
+	serializer.MilestoneDone = true
+	serializer.BufferPercent = 50
+	serializer.Milestones = []Milestone{{Name: "Launch", Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), DependsOn: []string{"TASK-3"}}}
+	scenario := serializer.buildScenario(tickets, epics)
+
+	byTitle := make(map[string]Task)
+	byID := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		byTitle[task.Title] = task
+		byID[task.ID] = task
+	}
+	keyTask := func(key string) string {
+		for _, task := range scenario.Tasks {
+			if task.UserDataValue(UserDataJiraKey) == key && task.Type == "" {
+				return task.ID
+			}
+		}
+		return ""
+	}
+
+	// The larger epic is the critical chain: no feeding buffer of its own
+	if children := byTitle["Main"].ChildTasks; len(children) != 2 {
+		t.Errorf("The critical epic should not get a feeding buffer, got children %v", children)
+	}
+	feeder := byTitle["Feeder"].ChildTasks
+	if len(feeder) != 2 {
+		t.Fatalf("The feeding epic should end with a buffer, got children %v", feeder)
+	}
+	buffer := byID[feeder[1].IDRef]
+	if buffer.Title != FeedingBufferTitle || buffer.Effort != 4*3600 || len(buffer.Prerequisites) != 1 || buffer.Prerequisites[0].IDRef != keyTask("TASK-3") {
+		t.Errorf("Expected a 4h feeding buffer after TASK-3, got %+v", buffer)
+	}
+
+	launchBuffer := byTitle[FeedingBufferTitle+": Launch"]
+	if launchBuffer.Effort != 4*3600 || len(byTitle["Launch"].Prerequisites) != 1 || byTitle["Launch"].Prerequisites[0].IDRef != launchBuffer.ID {
+		t.Errorf("Launch should wait on a 4h buffer, got buffer %+v and milestone %+v", launchBuffer, byTitle["Launch"])
+	}
+
+	project := byTitle[ProjectBufferTitle]
+	if project.Effort != 12*3600 || len(project.Prerequisites) != 2 {
+		t.Errorf("Expected a 12h project buffer after both epic milestones, got %+v", project)
+	}
+	if done := byTitle["Done"]; len(done.Prerequisites) != 1 || done.Prerequisites[0].IDRef != project.ID {
+		t.Errorf("Done should wait on the project buffer only, got %+v", done.Prerequisites)
+	}
+	if issues := lintScenario(scenario); len(issues) != 0 {
+		t.Errorf("Buffered scenario has structural problems: %v", issues)
+	}
+}
+
+func TestSerializer_Serialize_Risk(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Risky", EffortDays: 3, Flagged: true, Fields: map[string]string{"Risk": "High"}},
+		{Key: "TASK-2", Summary: "Safe", EffortDays: 1, Fields: map[string]string{"Risk": "Low"}},
+	}
+
+	serializer := NewSerializer("Risk Project")
+	serializer.Risk = jira.RiskPadding{Field: "Risk", Multipliers: map[string]float64{"high": 1.5}}.Level
+	scenario := serializer.buildScenario(tickets, nil)
+
+	tasks := make(map[string]Task)
+	for _, task := range scenario.Tasks {
+		tasks[task.UserDataValue(UserDataJiraKey)] = task
+	}
+	risky, safe := tasks["TASK-1"], tasks["TASK-2"]
+	if risky.UserDataValue(UserDataJiraRisk) != "High" || safe.UserDataValue(UserDataJiraRisk) != "Low" {
+		t.Errorf("Risk levels should be recorded as user data, got %q and %q", risky.UserDataValue(UserDataJiraRisk), safe.UserDataValue(UserDataJiraRisk))
+	}
+
+	// The risk follows the impediment in the note
+	if paragraphs := risky.Note.Text.Paragraphs; len(paragraphs) != 2 || paragraphs[1].Runs[0].Literal != RiskNotePrefix+"HIGH RISK: effort padded ×1.5" {
+		t.Errorf("Expected the risk marker as the second note paragraph, got %+v", paragraphs)
+	}
+	if safe.Note != nil {
+		t.Errorf("An unpadded task should not be marked as risky, got %+v", safe.Note)
+	}
+}
+
+func TestSerializer_Serialize_FormatVersion2(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Shipped", Link: "https://jira.example.com/rest/api/2/issue/1", StatusCategory: "done", Flagged: true},
+	}
+
+	serializer := NewSerializer("Legacy")
+	serializer.FormatVersion = FormatVersion2
+	var buf bytes.Buffer
+	if err := serializer.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, unwanted := range []string{"xmlns:opns", "<style>", "<effort-done>"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Version 2 output should not contain %s, got:\n%s", unwanted, out)
+		}
+	}
+	if want := "<lit>Jira: TASK-1 &lt;https://jira.example.com/browse/TASK-1&gt;</lit>"; !strings.Contains(out, want) {
+		t.Errorf("Expected the link written out as text %s, got:\n%s", want, out)
+	}
+	if !strings.Contains(out, "<lit>"+FlaggedNotePrefix) {
+		t.Errorf("Expected the flagged paragraph to be kept, got:\n%s", out)
+	}
+}
+
+func TestSerializer_Serialize_Deterministic(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "First Task", Assignee: "Alice", EffortDays: 2, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Second Task", Assignee: "Bob", EffortDays: 1, EpicLink: "EPIC-2", DependencyKeys: []string{"TASK-1"}},
+		{Key: "TASK-3", Summary: "Third Task", Assignee: "Alice", EffortDays: 3, EpicLink: "EPIC-3"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "First Epic"},
+		"EPIC-2": {Key: "EPIC-2", Summary: "Second Epic"},
+		"EPIC-3": {Key: "EPIC-3", Summary: "Third Epic"},
+	}
+
+	serializer := NewSerializer("Deterministic Project")
+	serializer.GroupByEpic = true
+	serializer.MilestoneDone = true
+
+	serialize := func() string {
+		var buf bytes.Buffer
+		if err := serializer.Serialize(&buf, tickets, epics); err != nil {
+			t.Errorf("Serialize failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	want := serialize()
+	if got := serialize(); got != want {
+		t.Error("Repeated Serialize calls should produce identical output")
+	}
+
+	// Parallel runs must not interleave IDs
+	results := make([]string, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = serialize()
+		}(i)
+	}
+	wg.Wait()
+	for i, got := range results {
+		if got != want {
+			t.Errorf("Concurrent Serialize call %d produced different output", i)
+		}
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Fix login", "Fix login"},
+		{"emoji", "Ship it 🚀👩‍💻", "Ship it 🚀👩‍💻"},
+		{"right-to-left", "תיקון באג — إصلاح", "תיקון באג — إصلاح"},
+		{"control characters", "Bell\a and\x00 escape\x1b[0m", "Bell and escape[0m"},
+		{"tabs and newlines", "a\tb\nc", "a\tb\nc"},
+		{"CRLF and CR", "one\r\ntwo\rthree\r", "one\ntwo\nthree\n"},
+		{"invalid UTF-8", "caf\xe9", "caf\uFFFD"},
+		{"noncharacter", "x\uFFFEy", "x\uFFFDy"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeText(tt.in); got != tt.want {
+			t.Errorf("%s: SanitizeText(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSerializer_Serialize_Sanitized(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Deploy\x0b 🚀 \u05e9\u05dc\u05d5\u05dd", Assignee: "Zo\x07e", Flagged: true, Labels: []string{"line\r\nbreak"}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewSerializer("Sanitized\x01 Project").Serialize(&buf, tickets, nil); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	output := buf.String()
+	if strings.Contains(output, "\uFFFD") || strings.Contains(output, "&#xD;") {
+		t.Errorf("Expected no replacement characters or carriage returns, got:\n%s", output)
+	}
+
+	var scenario Scenario
+	if err := xml.Unmarshal(buf.Bytes(), &scenario); err != nil {
+		t.Fatalf("Output is not well-formed XML: %v", err)
+	}
+	titles := make([]string, len(scenario.Tasks))
+	for i, task := range scenario.Tasks {
+		titles[i] = task.Title
+	}
+	if !slices.Contains(titles, "Deploy 🚀 שלום") {
+		t.Errorf("Expected the title with emoji and Hebrew kept, got %q", titles)
+	}
+	if !strings.Contains(output, "<name>Zoe</name>") {
+		t.Error("Expected the resource name without the control character")
+	}
+}
+
+func BenchmarkSerializer_Serialize(b *testing.B) {
+	tickets := make([]jira.Ticket, 10000)
+	epics := make(map[string]jira.Ticket)
+	for i := range tickets {
+		epicKey := fmt.Sprintf("EPIC-%d", i%100)
+		epics[epicKey] = jira.Ticket{Key: epicKey, Summary: "Epic " + epicKey}
+		tickets[i] = jira.Ticket{
+			Key:        fmt.Sprintf("TASK-%d", i),
+			Summary:    fmt.Sprintf("Task %d", i),
+			Link:       fmt.Sprintf("https://jira.example.com/browse/TASK-%d", i),
+			Assignee:   fmt.Sprintf("User %d", i%25),
+			Status:     "To Do",
+			EffortDays: 2,
+			EpicLink:   epicKey,
+		}
+		if i > 0 {
+			tickets[i].DependencyKeys = []string{fmt.Sprintf("TASK-%d", i-1)}
+		}
+	}
+
+	serializer := NewSerializer("Benchmark Project")
+	serializer.GroupByEpic = true
+	serializer.MilestoneDone = true
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := serializer.Serialize(io.Discard, tickets, epics); err != nil {
+			b.Fatalf("Serialize failed: %v", err)
+		}
+	}
+}
+
+func TestManifest_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	want := &Manifest{
+		Project:     "Q1Planning",
+		JQL:         "project = PROJ",
+		Flags:       map[string]string{"epic-group": "true"},
+		ConfigFile:  "/home/user/.jql-to-plan.yaml",
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := WriteManifest(dir, want); err != nil {
+		t.Fatalf("WriteManifest failed: %v", err)
+	}
+
+	got, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	if got.Project != want.Project || got.JQL != want.JQL || got.Flags["epic-group"] != "true" ||
+		got.ConfigFile != want.ConfigFile || !got.GeneratedAt.Equal(want.GeneratedAt) {
+		t.Errorf("ReadManifest() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAppendChangelog(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := AppendChangelog(dir, first, "Ada", "Imported 3 tasks from Jira"); err != nil {
+		t.Fatalf("AppendChangelog failed: %v", err)
+	}
+	if err := AppendChangelog(dir, first.Add(time.Hour), "Ada", "Imported 4 tasks from Jira"); err != nil {
+		t.Fatalf("AppendChangelog failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ChangelogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changelog Changelog
+	if err := xml.Unmarshal(data, &changelog); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if changelog.Version != ChangelogVersion || len(changelog.Entries) != 2 {
+		t.Fatalf("Expected version %s with 2 entries, got %+v", ChangelogVersion, changelog)
+	}
+	want := ChangelogEntry{Date: "2026-01-02T04:04:05.000Z", Author: "Ada", Comment: "Imported 4 tasks from Jira"}
+	if changelog.Entries[1] != want {
+		t.Errorf("Latest entry = %+v, want %+v", changelog.Entries[1], want)
+	}
+	if !strings.Contains(string(data), `<changelog xmlns="`+Namespace+`">`) {
+		t.Errorf("Expected the OmniPlan namespace, got:\n%s", data)
+	}
+}
+
+func TestPackageScenarios(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Actual.xml":   `<?xml version="1.0"?><scenario xmlns="` + Namespace + `" id="gen1"><granularity>days</granularity></scenario>`,
+		"Baseline.xml": `<scenario id="fBase1"></scenario>`,
+		"__TOC.xml":    `<omniplan></omniplan>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios, err := PackageScenarios(dir)
+	if err != nil {
+		t.Fatalf("PackageScenarios failed: %v", err)
+	}
+	want := []TOCScenario{{ID: "gen1", Name: "Actual", Filename: "Actual.xml"}, {ID: "fBase1", Name: "Baseline", Filename: "Baseline.xml"}}
+	if fmt.Sprint(scenarios) != fmt.Sprint(want) {
+		t.Errorf("PackageScenarios = %v, want %v", scenarios, want)
+	}
+
+	if _, err := PackageScenarios(t.TempDir()); err == nil {
+		t.Error("Expected an error for a package without scenarios")
+	}
+}
+
+func TestWriteTOC(t *testing.T) {
+	tmpl := `<editing-scenario>{{.EditingScenario}}</editing-scenario><outline>{{range .Columns}}<column name="{{.}}"/>{{end}}</outline>` +
+		`<user-data>{{range .Keys}}<key>{{.}}</key><null/>{{end}}</user-data>` +
+		`{{range .Scenarios}}<scenario id="{{.ID}}" name="{{.Name}}" filename="{{.Filename}}"/>{{end}}`
+	scenarios := []TOCScenario{{ID: "gen1", Name: "Actual", Filename: "Actual.xml"}, {ID: "b7", Name: "Q1 & Q2", Filename: "Q1 & Q2.xml"}}
+
+	var buf bytes.Buffer
+	if err := WriteTOC(&buf, tmpl, scenarios); err != nil {
+		t.Fatalf("WriteTOC failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<column name="Jira Key"/><column name="Jira Status"/>`,
+		`<key>Jira Due Date</key><null/>`,
+		`<editing-scenario>gen1</editing-scenario>`,
+		`<scenario id="gen1" name="Actual" filename="Actual.xml"/><scenario id="b7" name="Q1 &amp; Q2" filename="Q1 &amp; Q2.xml"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("TOC should contain %s, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `<column name="Jira Link"/>`) {
+		t.Error("Jira Link should be declared as user data but not shown as a column")
+	}
+}
+
+func TestLintPackage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Actual.xml": `<scenario xmlns="` + Namespace + `" id="gen1">` +
+			`<top-resource idref="r-1"/><resource id="r-1"><child-resource idref="r1"/></resource><resource id="r1"/><resource id="r1"/>` +
+			`<top-task idref="t-1"/><task id="t-1"><child-task idref="t1"/><child-task idref="t9"/></task>` +
+			`<task id="t1"><title>Kept</title><prerequisite-task idref="t8"/><assignment idref="r7"/></task>` +
+			`<task id="t2"><title>Lost</title></task></scenario>`,
+		"Baseline.xml": `<scenario id="fBase1"></scenario>`,
+		"__TOC.xml": `<omniplan><project><scenario id="gen2" name="Actual" filename="Actual.xml"/>` +
+			`<scenario id="a1" name="Alternate" filename="Alternate.xml"/></project></omniplan>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues, err := LintPackage(dir)
+	if err != nil {
+		t.Fatalf("LintPackage failed: %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	for _, want := range []string{
+		`__TOC.xml: scenario "Actual" has id gen2, but Actual.xml has id gen1`,
+		`__TOC.xml: scenario "Alternate" refers to missing file Alternate.xml`,
+		`__TOC.xml: scenario file Baseline.xml is not listed`,
+		`Actual.xml: duplicate resource id r1`,
+		`Actual.xml: task t-1 has unknown child task t9`,
+		`Actual.xml: task t1 has unknown prerequisite t8`,
+		`Actual.xml: task t1 is assigned to unknown resource r7`,
+		`Actual.xml: task t2 ("Lost") is not in the task outline`,
+		`Baseline.xml: missing top-task`,
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Expected issue %q, got:\n%s", want, strings.Join(got, "\n"))
+		}
+	}
+	for _, issue := range got {
+		if strings.Contains(issue, "Kept") {
+			t.Errorf("Task in the outline reported as an issue: %s", issue)
+		}
+	}
+
+	// A generated package is clean
+	clean := t.TempDir()
+	f, err := os.Create(filepath.Join(clean, ActualScenarioFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSerializer("Clean").Serialize(f, []jira.Ticket{{Key: "A-1", Summary: "One", EffortDays: 1}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	scenarios, err := PackageScenarios(clean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toc := `<omniplan><project><scenario id="` + scenarios[0].ID + `" name="Actual" filename="Actual.xml"/></project></omniplan>`
+	if err := os.WriteFile(filepath.Join(clean, TOCFile), []byte(toc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if issues, err := LintPackage(clean); err != nil || len(issues) != 0 {
+		t.Errorf("Expected a generated package to be clean, got %v (err %v)", issues, err)
+	}
+}
+
+func TestMergeScenarios(t *testing.T) {
+	payments := NewSerializer("Payments").buildScenario([]jira.Ticket{
+		{Key: "PAY-1", Summary: "Refunds", Assignee: "Alice"},
+		{Key: "PAY-2", Summary: "Payouts", Assignee: "Bob", DependencyKeys: []string{"PAY-1"}},
+	}, nil)
+	search := NewSerializer("Search").buildScenario([]jira.Ticket{
+		{Key: "SRCH-1", Summary: "Ranking", Assignee: "Alice"},
+	}, nil)
+
+	merged := MergeScenarios("Programme", []*Scenario{payments, search}, []string{"Payments", "Search"})
+
+	if issues := lintScenario(merged); len(issues) != 0 {
+		t.Fatalf("Merged scenario has problems: %v", issues)
+	}
+
+	// Alice is one resource across both plans
+	var names []string
+	for _, r := range merged.Resources {
+		names = append(names, r.Name)
+	}
+	if got := fmt.Sprint(names); got != "[Programme Alice Bob]" {
+		t.Errorf("Resources = %s, want the programme, Alice and Bob", got)
+	}
+
+	byID := make(map[string]Task)
+	byKey := make(map[string]Task)
+	for _, task := range merged.Tasks {
+		byID[task.ID] = task
+		byKey[task.UserDataValue(UserDataJiraKey)] = task
+	}
+	top := byID[merged.TopTask.IDRef]
+	if len(top.ChildTasks) != 2 || byID[top.ChildTasks[0].IDRef].Title != "Payments" || byID[top.ChildTasks[1].IDRef].Title != "Search" {
+		t.Errorf("Expected one group per plan under the top task, got %+v", top.ChildTasks)
+	}
+	if byKey["PAY-1"].Assignments[0] != byKey["SRCH-1"].Assignments[0] {
+		t.Error("Alice's tasks should share her merged resource")
+	}
+	if prereqs := byKey["PAY-2"].Prerequisites; len(prereqs) != 1 || prereqs[0].IDRef != byKey["PAY-1"].ID {
+		t.Errorf("Dependencies should follow the new IDs, got %+v", prereqs)
+	}
+}
+
+func TestScenarioTickets(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	started := time.Date(2025, 3, 3, 9, 0, 0, 0, loc)
+	tickets := []jira.Ticket{
+		{Key: "PROJ-1", Summary: "Parent", Status: "In Progress", StatusCategory: "indeterminate", EffortDays: 2},
+		{Key: "PROJ-2", Summary: "Child", Parent: "PROJ-1", Status: "Done", StatusCategory: "done", EffortDays: 1.5, Assignee: "Alice", CoAssignees: []string{"Bob"}},
+		{Key: "PROJ-3", Summary: "Next", IssueType: "Story", StatusCategory: "indeterminate", EffortDays: 3, ActualStart: started, DependencyKeys: []string{"PROJ-2"}},
+	}
+	s := NewSerializer("Round Trip")
+	s.NestSubtasks = true
+	s.Milestones = []Milestone{{Name: "Beta", Date: time.Date(2025, 4, 1, 0, 0, 0, 0, loc), DependsOn: []string{"PROJ-3"}}}
+	s.MilestoneDone = true
+
+	var buf bytes.Buffer
+	if err := s.Serialize(&buf, tickets, nil); err != nil {
+		t.Fatal(err)
+	}
+	scenario, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, milestones := ScenarioTickets(scenario, loc)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 tickets, got %d: %+v", len(got), got)
+	}
+	byKey := make(map[string]jira.Ticket)
+	for _, ticket := range got {
+		byKey[ticket.Key] = ticket
+	}
+
+	if child := byKey["PROJ-2"]; child.Parent != "PROJ-1" || child.EffortDays != 1.5 || !child.IsDone() || child.Assignee != "Alice" || strings.Join(child.CoAssignees, ",") != "Bob" {
+		t.Errorf("unexpected child %+v", child)
+	}
+	next := byKey["PROJ-3"]
+	if next.IssueType != "Story" || next.StatusCategory != "indeterminate" || strings.Join(next.DependencyKeys, ",") != "PROJ-2" {
+		t.Errorf("unexpected ticket %+v", next)
+	}
+	if !next.ActualStart.Equal(started) {
+		t.Errorf("expected actual start %v, got %v", started, next.ActualStart)
+	}
+
+	// The Done milestone has no date, so only the commitment comes back
+	if len(milestones) != 1 || milestones[0].Name != "Beta" || !milestones[0].Date.Equal(s.Milestones[0].Date) || strings.Join(milestones[0].DependsOn, ",") != "PROJ-3" {
+		t.Errorf("unexpected milestones %+v", milestones)
+	}
+}
@@ -0,0 +1,50 @@
+package omniplan
+
+import "github.com/gunnarrb/jql-to-plan/internal/i18n"
+
+// splitTask turns a task of more than MaxTaskDays into a group of equal parts
+// titled "Part 1" to "Part k", done one after the other, and returns the
+// parts. Completed work fills the parts in order. Groups are left alone.
+func (s *Serializer) splitTask(ids *idGenerator, task *Task) []Task {
+	const day = 8 * 3600
+	limit := int64(s.MaxTaskDays * day)
+	if task.Type != "" || limit <= 0 || task.Effort <= limit {
+		return nil
+	}
+
+	k := (task.Effort + limit - 1) / limit
+	parts := make([]Task, k)
+	refs := make([]Reference, k)
+	done := task.EffortDone
+	for p := range parts {
+		effort := task.Effort / k
+		if p == len(parts)-1 {
+			effort = task.Effort - effort*(k-1)
+		}
+		partDone := min(done, effort)
+		done -= partDone
+
+		parts[p] = Task{
+			ID:          ids.newID("t"),
+			Title:       i18n.Sprintf("Part %d", p+1),
+			Effort:      effort,
+			EffortDone:  partDone,
+			Recalculate: "duration",
+			Assignments: task.Assignments,
+		}
+		if p == 0 {
+			parts[p].ActualStart = task.ActualStart
+		} else {
+			parts[p].Prerequisites = []PrerequisiteTask{{IDRef: parts[p-1].ID}}
+		}
+		refs[p] = Reference{IDRef: parts[p].ID}
+	}
+
+	task.Type = "group"
+	task.Effort = 0
+	task.EffortDone = 0
+	task.ActualStart = ""
+	task.Assignments = nil
+	task.ChildTasks = refs
+	return parts
+}
@@ -0,0 +1,76 @@
+package omniplan
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Palette is the look of a generated plan, written as style definitions in
+// its scenario so it is presentable on import. Zero fields keep OmniPlan's
+// default style.
+type Palette struct {
+	Font     string  // Font name, e.g. "Helvetica Neue"
+	FontSize float64 // In points
+
+	GroupColor     *Color // Gantt bar color of groups, such as epics
+	MilestoneColor *Color // Gantt color of milestones
+	CompletedColor *Color // Text color of completed tasks, which are also struck through
+}
+
+// StyleSet wraps the style of a kind of task, e.g. <group-task-style>
+type StyleSet struct {
+	Style NoteStyle `xml:"style"`
+}
+
+// HexColor parses an sRGB color written as "#RRGGBB" or "#RGB", the "#"
+// being optional
+func HexColor(s string) (*Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return nil, fmt.Errorf("invalid color %q (expected #RRGGBB)", s)
+	}
+	channel := func(shift uint) float64 {
+		// Six decimals, as OmniPlan writes them
+		return math.Round(float64(v>>shift&0xff)/255*1e6) / 1e6
+	}
+	return &Color{Space: "srgb", R: channel(16), G: channel(8), B: channel(0)}, nil
+}
+
+// applyStyles adds the palette's style definitions to a scenario
+func (p *Palette) applyStyles(scenario *Scenario) {
+	var base []StyleValue
+	if p.Font != "" {
+		base = append(base, StyleValue{Key: "font-name", Value: p.Font})
+	}
+	if p.FontSize > 0 {
+		base = append(base, StyleValue{Key: "font-size", Value: strconv.FormatFloat(p.FontSize, 'f', -1, 64)})
+	}
+	scenario.BaseStyle = styleSet(base...)
+
+	if p.GroupColor != nil {
+		scenario.GroupStyle = styleSet(StyleValue{Key: "gantt-fill-color", Color: p.GroupColor})
+	}
+	if p.MilestoneColor != nil {
+		scenario.MilestoneStyle = styleSet(StyleValue{Key: "gantt-fill-color", Color: p.MilestoneColor})
+	}
+	if p.CompletedColor != nil {
+		scenario.CompletedStyle = styleSet(
+			StyleValue{Key: "font-fill", Color: p.CompletedColor},
+			StyleValue{Key: "strikethrough-style", Value: "single"},
+		)
+	}
+}
+
+// styleSet returns a style of values, or nil without any
+func styleSet(values ...StyleValue) *StyleSet {
+	if len(values) == 0 {
+		return nil
+	}
+	return &StyleSet{Style: NoteStyle{Values: values}}
+}
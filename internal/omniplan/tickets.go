@@ -0,0 +1,123 @@
+package omniplan
+
+import (
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// ScenarioTickets converts the tasks of a scenario back into tickets, so a
+// plan made or edited in OmniPlan can be written in other formats. Dates are
+// returned in loc.
+//
+// Tasks are keyed by their Jira Key user data, or else their task ID. The
+// tasks in a group are the group's sub-tasks (its Parent), and a task's first
+// assignment is its assignee, the others its co-assignees. Milestones with a
+// fixed date become Milestones and other milestones are dropped; dependencies
+// on milestones pass through to their prerequisites.
+func ScenarioTickets(scenario *Scenario, loc *time.Location) ([]jira.Ticket, []Milestone) {
+	tasks := make(map[string]*Task, len(scenario.Tasks))
+	keys := make(map[string]string, len(scenario.Tasks)) // Task ID -> key
+	for i := range scenario.Tasks {
+		t := &scenario.Tasks[i]
+		tasks[t.ID] = t
+		keys[t.ID] = t.UserDataValue(UserDataJiraKey)
+		if keys[t.ID] == "" {
+			keys[t.ID] = t.ID
+		}
+	}
+	resourceNames := make(map[string]string, len(scenario.Resources))
+	for _, r := range scenario.Resources {
+		resourceNames[r.ID] = r.Name
+	}
+
+	var tickets []jira.Ticket
+	var milestones []Milestone
+
+	// Walk the outline from the top task, so tickets keep the plan's order
+	var walk func(id, parent string)
+	walk = func(id, parent string) {
+		t, ok := tasks[id]
+		if !ok {
+			return
+		}
+		deps := scenarioDependencies(t, tasks, keys, map[string]bool{})
+		switch {
+		case t.Type == "milestone":
+			if date, err := time.Parse(DateFormat, t.StartNoEarlierThan); err == nil {
+				milestones = append(milestones, Milestone{Name: t.Title, Date: date.In(loc), DependsOn: deps})
+			}
+		case id != scenario.TopTask.IDRef:
+			ticket := jira.Ticket{
+				Key:            keys[id],
+				Summary:        t.Title,
+				Link:           t.UserDataValue(UserDataJiraLink),
+				IssueType:      t.UserDataValue(UserDataJiraType),
+				Status:         t.UserDataValue(UserDataJiraStatus),
+				StatusCategory: "new",
+				EffortDays:     float64(t.Effort) / (8 * 3600),
+				Parent:         parent,
+				DependencyKeys: deps,
+			}
+			// Titles cut to MaxTitleLength keep the whole summary in user data
+			if summary := t.UserDataValue(UserDataJiraSummary); summary != "" {
+				ticket.Summary = summary
+			}
+			switch {
+			case t.Effort > 0 && t.EffortDone >= t.Effort:
+				ticket.StatusCategory = "done"
+			case t.EffortDone > 0 || t.ActualStart != "":
+				ticket.StatusCategory = "indeterminate"
+			}
+			if start, err := time.Parse(DateFormat, t.ActualStart); err == nil {
+				ticket.ActualStart = start.In(loc)
+			}
+			if start, err := time.Parse(DateFormat, t.StartNoEarlierThan); err == nil {
+				start = start.In(loc)
+				ticket.TargetStart = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+			}
+			// The end constraint is the midnight after the due date, kept as a
+			// constraint when written again
+			if end, err := time.Parse(DateFormat, t.EndNoLaterThan); err == nil {
+				due := end.Add(-time.Nanosecond).In(loc)
+				ticket.DueDate = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, loc)
+				ticket.TargetEnd = ticket.DueDate
+			}
+			for i, a := range t.Assignments {
+				if name := resourceNames[a.IDRef]; i == 0 {
+					ticket.Assignee = name
+				} else if name != "" {
+					ticket.CoAssignees = append(ticket.CoAssignees, name)
+				}
+			}
+			tickets = append(tickets, ticket)
+			parent = ticket.Key
+		default:
+			parent = ""
+		}
+		for _, child := range t.ChildTasks {
+			walk(child.IDRef, parent)
+		}
+	}
+	walk(scenario.TopTask.IDRef, "")
+	return tickets, milestones
+}
+
+// scenarioDependencies returns the keys of a task's prerequisites, replacing
+// milestones by their own prerequisites
+func scenarioDependencies(t *Task, tasks map[string]*Task, keys map[string]string, seen map[string]bool) []string {
+	var deps []string
+	for _, p := range t.Prerequisites {
+		prereq, ok := tasks[p.IDRef]
+		if !ok || seen[p.IDRef] {
+			continue
+		}
+		seen[p.IDRef] = true
+		if prereq.Type == "milestone" {
+			deps = append(deps, scenarioDependencies(prereq, tasks, keys, seen)...)
+		} else {
+			deps = append(deps, keys[p.IDRef])
+		}
+	}
+	return deps
+}
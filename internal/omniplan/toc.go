@@ -0,0 +1,189 @@
+package omniplan
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ActualScenarioFile is the scenario the serializer writes; others in a
+// package are baselines or alternates added in OmniPlan
+const ActualScenarioFile = "Actual.xml"
+
+// TOCFile is the name of the package's table of contents
+const TOCFile = "__TOC.xml"
+
+// TemplateFS holds the package templates, such as templates/__TOC.xml
+//
+//go:embed templates/__TOC.xml
+var TemplateFS embed.FS
+
+// User data keys written on tasks
+const (
+	UserDataJiraKey     = "Jira Key"
+	UserDataJiraLink    = "Jira Link"
+	UserDataJiraStatus  = "Jira Status"
+	UserDataJiraType    = "Jira Type"
+	UserDataJiraFlagged = "Jira Flagged"
+	UserDataJiraEffort  = "Jira Effort"
+	UserDataJiraDueDate = "Jira Due Date"
+	UserDataWSJF        = "WSJF"
+	UserDataJiraRisk    = "Jira Risk"
+	UserDataJiraSummary = "Jira Summary"
+)
+
+// User data keys written on staff resources with a Jira user profile
+const (
+	UserDataJiraEmail    = "Jira Email"
+	UserDataJiraTimeZone = "Jira Time Zone"
+	UserDataJiraAvatar   = "Jira Avatar"
+)
+
+// TaskUserDataKeys are all user data keys the serializer writes, declared as
+// custom data in __TOC.xml so OmniPlan offers them as columns
+var TaskUserDataKeys = []string{
+	UserDataJiraKey,
+	UserDataJiraStatus,
+	UserDataJiraType,
+	UserDataJiraLink,
+	UserDataJiraFlagged,
+	UserDataJiraEffort,
+	UserDataJiraDueDate,
+	UserDataWSJF,
+	UserDataJiraRisk,
+	UserDataJiraSummary,
+}
+
+// TaskColumns are the user data keys shown as task outline columns on import
+var TaskColumns = []string{
+	UserDataJiraKey,
+	UserDataJiraStatus,
+	UserDataJiraType,
+}
+
+// TOCScenario is a scenario file listed in __TOC.xml
+type TOCScenario struct {
+	ID       string // The id attribute of the file's scenario element
+	Name     string
+	Filename string
+}
+
+// PackageScenarios lists the scenario files in a package directory, the
+// Actual scenario first and the others by name. Files whose root element is
+// not a scenario, such as __TOC.xml, are skipped.
+func PackageScenarios(dir string) ([]TOCScenario, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var scenarios []TOCScenario
+	for _, path := range paths {
+		id, ok, err := scenarioID(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+		}
+		if !ok {
+			continue
+		}
+		filename := filepath.Base(path)
+		scenario := TOCScenario{ID: id, Name: strings.TrimSuffix(filename, ".xml"), Filename: filename}
+		if filename == ActualScenarioFile {
+			scenarios = append([]TOCScenario{scenario}, scenarios...)
+		} else {
+			scenarios = append(scenarios, scenario)
+		}
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenario files in %s", dir)
+	}
+	return scenarios, nil
+}
+
+// scenarioID returns the id of the root scenario element of an XML file, and
+// false if the root is some other element
+func scenarioID(path string) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(f)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "", false, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "scenario" {
+				return "", false, nil
+			}
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "id" {
+					return attr.Value, true, nil
+				}
+			}
+			return "", false, fmt.Errorf("scenario has no id")
+		}
+	}
+}
+
+// WriteTOC renders the __TOC.xml template. The template ranges over .Columns
+// for the outline columns, .Keys for the task user data key declarations and
+// .Scenarios for the scenario files; .EditingScenario is the first one's ID.
+func WriteTOC(w io.Writer, tmpl string, scenarios []TOCScenario) error {
+	if len(scenarios) == 0 {
+		return fmt.Errorf("rendering TOC: no scenarios")
+	}
+	t, err := template.New("__TOC.xml").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("parsing TOC template: %w", err)
+	}
+
+	// Scenario names come from file names, so escape them for attributes
+	escaped := make([]TOCScenario, len(scenarios))
+	for i, s := range scenarios {
+		escaped[i] = TOCScenario{ID: escapeXML(s.ID), Name: escapeXML(s.Name), Filename: escapeXML(s.Filename)}
+	}
+	return t.Execute(w, struct {
+		Columns         []string
+		Keys            []string
+		Scenarios       []TOCScenario
+		EditingScenario string
+	}{TaskColumns, TaskUserDataKeys, escaped, escaped[0].ID})
+}
+
+// escapeXML escapes s for use in XML text and attribute values
+func escapeXML(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// WritePackageTOC renders the embedded __TOC.xml template into the package
+// directory, listing the scenario files it contains
+func WritePackageTOC(dir string) error {
+	tmpl, err := TemplateFS.ReadFile("templates/" + TOCFile)
+	if err != nil {
+		return fmt.Errorf("failed to read embedded TOC template: %w", err)
+	}
+	scenarios, err := PackageScenarios(dir)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTOC(&buf, string(tmpl), scenarios); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, TOCFile), buf.Bytes(), 0644)
+}
@@ -5,17 +5,19 @@ package omniplan
 import (
 	"encoding/xml"
 	"fmt"
-	"sync/atomic"
 )
 
 // Namespace is the OmniPlan v2 XML namespace
 const Namespace = "http://www.omnigroup.com/namespace/OmniPlan/v2"
 
+// DateFormat is the timestamp format used for dates in OmniPlan XML
+const DateFormat = "2006-01-02T15:04:05.000Z"
+
 // Scenario is the root element of an OmniPlan document
 type Scenario struct {
 	XMLName       xml.Name       `xml:"scenario"`
 	XMLNS         string         `xml:"xmlns,attr"`
-	OPNS          string         `xml:"xmlns:opns,attr"`
+	OPNS          string         `xml:"xmlns:opns,attr,omitempty"`
 	ID            string         `xml:"id,attr"`
 	Granularity   string         `xml:"granularity"`
 	TopResource   Reference      `xml:"top-resource"`
@@ -23,6 +25,12 @@ type Scenario struct {
 	TopTask       Reference      `xml:"top-task"`
 	Tasks         []Task         `xml:"task"`
 	CriticalPaths []CriticalPath `xml:"critical-path"`
+
+	// Style definitions from a Palette, nil to keep OmniPlan's defaults
+	BaseStyle      *StyleSet `xml:"base-style,omitempty"`
+	GroupStyle     *StyleSet `xml:"group-task-style,omitempty"`
+	MilestoneStyle *StyleSet `xml:"milestone-task-style,omitempty"`
+	CompletedStyle *StyleSet `xml:"completed-task-style,omitempty"`
 }
 
 // Reference holds an idref attribute for linking elements
@@ -35,23 +43,29 @@ type Resource struct {
 	ID             string      `xml:"id,attr"`
 	Name           string      `xml:"name,omitempty"`
 	Type           string      `xml:"type,omitempty"`
+	UserData       *UserData   `xml:"user-data,omitempty"`
+	Note           *Note       `xml:"note,omitempty"`
 	ChildResources []Reference `xml:"child-resource,omitempty"`
 }
 
 // Task represents a project task
 type Task struct {
-	ID            string             `xml:"id,attr"`
-	Title         string             `xml:"title,omitempty"`
-	Type          string             `xml:"type,omitempty"`
-	LeveledStart  string             `xml:"leveled-start,omitempty"`
-	Effort        int64              `xml:"effort,omitempty"`
-	Recalculate   string             `xml:"recalculate,omitempty"`
-	StaticCost    int                `xml:"static-cost"`
-	ChildTasks    []Reference        `xml:"child-task,omitempty"`
-	UserData      *UserData          `xml:"user-data,omitempty"`
-	Prerequisites []PrerequisiteTask `xml:"prerequisite-task,omitempty"`
-	Assignments   []Reference        `xml:"assignment,omitempty"`
-	Note          *Note              `xml:"note,omitempty"`
+	ID                 string             `xml:"id,attr"`
+	Title              string             `xml:"title,omitempty"`
+	Type               string             `xml:"type,omitempty"`
+	LeveledStart       string             `xml:"leveled-start,omitempty"`
+	ActualStart        string             `xml:"actual-start,omitempty"`
+	StartNoEarlierThan string             `xml:"start-no-earlier-than,omitempty"`
+	EndNoLaterThan     string             `xml:"end-no-later-than,omitempty"`
+	Effort             int64              `xml:"effort,omitempty"`
+	EffortDone         int64              `xml:"effort-done,omitempty"`
+	Recalculate        string             `xml:"recalculate,omitempty"`
+	StaticCost         int                `xml:"static-cost"`
+	ChildTasks         []Reference        `xml:"child-task,omitempty"`
+	UserData           *UserData          `xml:"user-data,omitempty"`
+	Prerequisites      []PrerequisiteTask `xml:"prerequisite-task,omitempty"`
+	Assignments        []Assignment       `xml:"assignment,omitempty"`
+	Note               *Note              `xml:"note,omitempty"`
 }
 
 // PrerequisiteTask represents a task dependency
@@ -60,7 +74,14 @@ type PrerequisiteTask struct {
 	Kind  string `xml:"kind,attr,omitempty"`
 }
 
-// UserData holds custom key-value data for a task
+// Assignment assigns a resource to a task. Units is the share of the
+// resource's time spent on the task; zero means full time.
+type Assignment struct {
+	IDRef string  `xml:"idref,attr"`
+	Units float64 `xml:"units,attr,omitempty"`
+}
+
+// UserData holds custom key-value data for a task or resource
 type UserData struct {
 	Items []UserDataItem
 }
@@ -80,16 +101,28 @@ func (u UserData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return err
 	}
 	for _, item := range u.Items {
-		if err := e.EncodeElement(item.Key, xml.StartElement{Name: xml.Name{Local: "key"}}); err != nil {
+		if err := encodeText(e, "key", item.Key); err != nil {
 			return err
 		}
-		if err := e.EncodeElement(item.Value, xml.StartElement{Name: xml.Name{Local: "string"}}); err != nil {
+		if err := encodeText(e, "string", item.Value); err != nil {
 			return err
 		}
 	}
 	return e.EncodeToken(start.End())
 }
 
+// encodeText writes <name>text</name> as tokens, skipping EncodeElement's reflection
+func encodeText(e *xml.Encoder, name, text string) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeToken(xml.CharData(text)); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
 // Note represents a rich text note on a task
 type Note struct {
 	Text NoteText `xml:"text"`
@@ -102,12 +135,25 @@ type NoteText struct {
 
 // NoteParagraph represents a paragraph in a note
 type NoteParagraph struct {
-	Run NoteRun `xml:"run"`
+	Runs []NoteRun `xml:"run"`
 }
 
-// NoteRun contains the literal text
+// NoteRun contains the literal text, optionally styled
 type NoteRun struct {
-	Literal string `xml:"lit"`
+	Style   *NoteStyle `xml:"style,omitempty"`
+	Literal string     `xml:"lit"`
+}
+
+// NoteStyle holds the style attributes of a run, such as a link target
+type NoteStyle struct {
+	Values []StyleValue `xml:"value"`
+}
+
+// StyleValue is a single style attribute, holding either text or a color
+type StyleValue struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+	Color *Color `xml:"color,omitempty"`
 }
 
 // CriticalPath represents a critical path configuration
@@ -134,18 +180,74 @@ func NewNote(text string) *Note {
 	return &Note{
 		Text: NoteText{
 			Paragraphs: []NoteParagraph{
-				{Run: NoteRun{Literal: text}},
+				{Runs: []NoteRun{{Literal: text}}},
 			},
 		},
 	}
 }
 
-// Package level counter for generating unique IDs
-var idCounter atomic.Int64
+// AddLink appends a paragraph of label followed by text linked to url
+func (n *Note) AddLink(label, text, url string) {
+	var runs []NoteRun
+	if label != "" {
+		runs = append(runs, NoteRun{Literal: label})
+	}
+	runs = append(runs, NoteRun{
+		Style:   &NoteStyle{Values: []StyleValue{{Key: "link", Value: url}}},
+		Literal: text,
+	})
+	n.Text.Paragraphs = append(n.Text.Paragraphs, NoteParagraph{Runs: runs})
+}
+
+// idGenerator hands out element IDs for a single document. Each Serialize
+// call uses its own generator, so output does not depend on earlier runs.
+type idGenerator struct {
+	next int
+}
 
-// GenerateID creates a unique ID for OmniPlan elements
-func GenerateID() string {
+// newID creates a unique ID with the given prefix
+func (g *idGenerator) newID(prefix string) string {
 	// OmniPlan uses alphanumeric IDs like "fimc6xeFjk1" or "dFRdv9nCq3E"
 	// For simplicity, we'll use a counter-based approach with a prefix
-	return fmt.Sprintf("gen%d", idCounter.Add(1))
+	g.next++
+	return fmt.Sprintf("%s%d", prefix, g.next)
+}
+
+// UnmarshalXML implements custom unmarshaling for the OmniPlan key/string pair format
+func (u *UserData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var key string
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			if t.Name.Local == "key" {
+				key = value
+			} else {
+				u.Items = append(u.Items, UserDataItem{Key: key, Value: value})
+				key = ""
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// UserDataValue returns the user-data value stored under key, or "" if absent
+func (t Task) UserDataValue(key string) string {
+	if t.UserData == nil {
+		return ""
+	}
+	for _, item := range t.UserData.Items {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return ""
 }
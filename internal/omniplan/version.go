@@ -0,0 +1,61 @@
+package omniplan
+
+import "fmt"
+
+// Format versions for Serializer.FormatVersion
+const (
+	// FormatVersion2 suits OmniPlan 2 and 3, which reject some newer markup:
+	// the scenario only declares the default namespace, notes are plain text
+	// (links are written out as URLs) and completed effort is left out.
+	FormatVersion2 = 2
+	// FormatVersion4 is the full OmniPlan 4 output (the default)
+	FormatVersion4 = 4
+)
+
+// ValidFormatVersion reports whether v is a supported format version
+func ValidFormatVersion(v int) bool {
+	return v == FormatVersion2 || v == FormatVersion4
+}
+
+// downgrade strips the markup that the format version does not support
+func downgrade(scenario *Scenario, version int) {
+	if version != FormatVersion2 {
+		return
+	}
+
+	scenario.OPNS = ""
+	for i := range scenario.Tasks {
+		task := &scenario.Tasks[i]
+		task.EffortDone = 0
+		if task.Note != nil {
+			task.Note = plainNote(task.Note)
+		}
+	}
+	for i := range scenario.Resources {
+		if r := &scenario.Resources[i]; r.Note != nil {
+			r.Note = plainNote(r.Note)
+		}
+	}
+}
+
+// plainNote returns the note with its runs' styles dropped, each linked run
+// followed by its URL so the link survives as text
+func plainNote(note *Note) *Note {
+	plain := &Note{}
+	for _, p := range note.Text.Paragraphs {
+		var text string
+		for _, run := range p.Runs {
+			text += run.Literal
+			if run.Style == nil {
+				continue
+			}
+			for _, v := range run.Style.Values {
+				if v.Key == "link" {
+					text += fmt.Sprintf(" <%s>", v.Value)
+				}
+			}
+		}
+		plain.Text.Paragraphs = append(plain.Text.Paragraphs, NoteParagraph{Runs: []NoteRun{{Literal: text}}})
+	}
+	return plain
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// BurnPoint is the projected state of the scope at the end of a workday, in days of effort
+type BurnPoint struct {
+	Date      time.Time
+	Remaining float64
+	Completed float64
+	Scope     float64
+}
+
+// Burndown is a day-by-day projection of remaining and completed effort
+type Burndown struct {
+	Points       []BurnPoint
+	ProjectedEnd time.Time
+
+	DateLayout string // Go layout for dates in the HTML chart (DefaultDateLayout if empty); CSV always uses ISO dates
+}
+
+// NewBurndown schedules the open tickets from origin and projects how the
+// remaining effort burns down (and completed effort burns up) per workday
+func NewBurndown(tickets []jira.Ticket, origin time.Time, cal schedule.Calendar) (*Burndown, error) {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return nil, err
+	}
+
+	var scope, done float64
+	for _, t := range tickets {
+		hours := schedule.EffortHours(t, cal.HoursPerDay)
+		scope += hours
+		if t.IsDone() {
+			done += hours
+		}
+	}
+
+	workdays := int(math.Ceil(result.End / cal.HoursPerDay))
+	if workdays < 1 {
+		workdays = 1
+	}
+	burndown := &Burndown{ProjectedEnd: result.EndDate()}
+	day := cal.NextWorkday(origin)
+
+	for k := 1; k <= workdays; k++ {
+		// Effort scheduled to be worked by the end of the k-th workday (partial tasks count pro rata)
+		cutoff := float64(k) * cal.HoursPerDay
+		completed := done
+		for _, slot := range result.Slots {
+			completed += math.Min(math.Max(cutoff-slot.Start, 0), slot.Finish-slot.Start)
+		}
+
+		burndown.Points = append(burndown.Points, BurnPoint{
+			Date:      day,
+			Remaining: (scope - completed) / cal.HoursPerDay,
+			Completed: completed / cal.HoursPerDay,
+			Scope:     scope / cal.HoursPerDay,
+		})
+		day = cal.AddWorkdays(day, 1)
+	}
+
+	return burndown, nil
+}
+
+// WriteCSV writes one row per workday
+func (b *Burndown) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "remaining_days", "completed_days", "scope_days"}); err != nil {
+		return err
+	}
+	for _, p := range b.Points {
+		row := []string{
+			p.Date.Format("2006-01-02"),
+			fmt.Sprintf("%.2f", p.Remaining),
+			fmt.Sprintf("%.2f", p.Completed),
+			fmt.Sprintf("%.2f", p.Scope),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Chart dimensions for the HTML export
+const (
+	chartWidth   = 800
+	chartHeight  = 400
+	chartPadding = 50
+)
+
+// WriteHTML writes a standalone HTML page with an SVG burndown/burnup chart
+func (b *Burndown) WriteHTML(w io.Writer, title string) error {
+	var maxValue float64
+	for _, p := range b.Points {
+		maxValue = math.Max(maxValue, math.Max(p.Scope, p.Remaining))
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+
+	x := func(i int) float64 {
+		if len(b.Points) < 2 {
+			return chartPadding
+		}
+		return chartPadding + float64(i)*float64(chartWidth-2*chartPadding)/float64(len(b.Points)-1)
+	}
+	y := func(v float64) float64 {
+		return chartHeight - chartPadding - v*float64(chartHeight-2*chartPadding)/maxValue
+	}
+	line := func(value func(BurnPoint) float64) string {
+		points := make([]string, len(b.Points))
+		for i, p := range b.Points {
+			points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(value(p)))
+		}
+		return strings.Join(points, " ")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body style=\"font-family: sans-serif\">\n", html.EscapeString(title))
+	fmt.Fprintf(&sb, "<h1>%s</h1>\n", html.EscapeString(title))
+	if len(b.Points) > 0 {
+		fmt.Fprintf(&sb, "<p>Projected completion: %s</p>\n", b.ProjectedEnd.Format(dateLayout(b.DateLayout)))
+	}
+	fmt.Fprintf(&sb, "<svg width=\"%d\" height=\"%d\" xmlns=\"http://www.w3.org/2000/svg\">\n", chartWidth, chartHeight)
+	fmt.Fprintf(&sb, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#999\"/>\n", chartPadding, chartHeight-chartPadding, chartWidth-chartPadding, chartHeight-chartPadding)
+	fmt.Fprintf(&sb, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#999\"/>\n", chartPadding, chartPadding, chartPadding, chartHeight-chartPadding)
+	fmt.Fprintf(&sb, "<text x=\"5\" y=\"%d\" font-size=\"12\">%.0fd</text>\n", chartPadding+4, maxValue)
+	if len(b.Points) > 0 {
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"12\">%s</text>\n", chartPadding, chartHeight-chartPadding+20, b.Points[0].Date.Format(dateLayout(b.DateLayout)))
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" font-size=\"12\" text-anchor=\"end\">%s</text>\n", chartWidth-chartPadding, chartHeight-chartPadding+20, b.Points[len(b.Points)-1].Date.Format(dateLayout(b.DateLayout)))
+	}
+	fmt.Fprintf(&sb, "<polyline fill=\"none\" stroke=\"#999\" stroke-dasharray=\"4\" points=\"%s\"/>\n", line(func(p BurnPoint) float64 { return p.Scope }))
+	fmt.Fprintf(&sb, "<polyline fill=\"none\" stroke=\"#d9534f\" stroke-width=\"2\" points=\"%s\"/>\n", line(func(p BurnPoint) float64 { return p.Remaining }))
+	fmt.Fprintf(&sb, "<polyline fill=\"none\" stroke=\"#5cb85c\" stroke-width=\"2\" points=\"%s\"/>\n", line(func(p BurnPoint) float64 { return p.Completed }))
+	sb.WriteString("</svg>\n")
+	sb.WriteString("<p><span style=\"color:#d9534f\">&#9632;</span> Remaining (burndown) &nbsp; <span style=\"color:#5cb85c\">&#9632;</span> Completed (burnup) &nbsp; <span style=\"color:#999\">&#9632;</span> Scope</p>\n")
+	sb.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
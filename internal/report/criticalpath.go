@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// CriticalStep is a ticket on the critical path
+type CriticalStep struct {
+	Ticket jira.Ticket
+	Start  time.Time
+	Finish time.Time
+	Effort float64 // Remaining effort in days
+	Slack  float64 // Total float in days
+	After  string  // Key of the ticket it waits for, "" for the first
+	Reason string  // Why it waits: "dependency" or "assignee"
+}
+
+// CriticalPath is the chain of tickets that determines the projected finish
+type CriticalPath struct {
+	Origin time.Time
+	Finish time.Time
+	Steps  []CriticalStep
+
+	DateLayout string // Go layout for printed dates (DefaultDateLayout if empty)
+}
+
+// NewCriticalPath schedules the open tickets from origin, like the forecast,
+// and traces the chain of tickets that ends last back to the start
+func NewCriticalPath(tickets []jira.Ticket, origin time.Time, cal schedule.Calendar) (*CriticalPath, error) {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]jira.Ticket, len(tickets))
+	for _, t := range tickets {
+		byKey[t.Key] = t
+	}
+	floats := result.Floats()
+
+	cp := &CriticalPath{Origin: cal.NextWorkday(origin), Finish: result.EndDate()}
+	var previous string
+	for _, key := range result.CriticalPath() {
+		t := byKey[key]
+		slot := result.Slots[key]
+		step := CriticalStep{
+			Ticket: t,
+			Start:  result.StartDate(key),
+			Finish: result.FinishDate(key),
+			Effort: (slot.Finish - slot.Start) / cal.HoursPerDay,
+			Slack:  floats[key].Total / cal.HoursPerDay,
+			After:  previous,
+		}
+		if previous != "" {
+			step.Reason = "assignee"
+			if slices.Contains(t.DependencyKeys, previous) {
+				step.Reason = "dependency"
+			}
+		}
+		cp.Steps = append(cp.Steps, step)
+		previous = key
+	}
+	return cp, nil
+}
+
+// WriteText prints the critical path in order, one ticket per row
+func (c *CriticalPath) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	layout := dateLayout(c.DateLayout)
+
+	fmt.Fprintf(tw, "Critical path from %s\n", c.Origin.Format(layout))
+	if len(c.Steps) == 0 {
+		fmt.Fprintln(tw, "No open work")
+		return tw.Flush()
+	}
+	fmt.Fprintf(tw, "Projected finish:\t%s\n", c.Finish.Format(layout))
+	fmt.Fprintf(tw, "Tickets:\t%d\n", len(c.Steps))
+
+	fmt.Fprintf(tw, "\n%sKey\tSummary\tAssignee\tStart\tFinish\tEffort\tSlack\tWaits for%s\n", term.Bold.Start(), term.End())
+	for _, s := range c.Steps {
+		assignee, waits := s.Ticket.Assignee, "-"
+		if assignee == "" {
+			assignee = "-"
+		}
+		switch s.Reason {
+		case "dependency":
+			waits = s.After
+		case "assignee":
+			waits = s.After + " (same assignee)"
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\t%.1fd\t%.1fd\t%s%s\n", term.Default.Start(), s.Ticket.Key, s.Ticket.Summary, assignee,
+			s.Start.Format(layout), s.Finish.Format(layout), s.Effort, s.Slack, waits, term.End())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// CycleTimeGroup holds the lead and cycle time distributions for one group
+type CycleTimeGroup struct {
+	Name      string
+	LeadTime  Distribution
+	CycleTime Distribution
+}
+
+// CycleTimeReport groups lead/cycle time distributions overall, per issue type and per assignee
+type CycleTimeReport struct {
+	Overall    CycleTimeGroup
+	ByType     []CycleTimeGroup
+	ByAssignee []CycleTimeGroup
+	Unresolved int // Issues without a resolution, excluded from all distributions
+}
+
+// NewCycleTimeReport computes the report from flow records
+func NewCycleTimeReport(records []jira.FlowRecord) *CycleTimeReport {
+	report := &CycleTimeReport{}
+
+	var resolved []jira.FlowRecord
+	for _, r := range records {
+		if r.Resolved.IsZero() {
+			report.Unresolved++
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+
+	report.Overall = cycleTimeGroup("All issues", resolved)
+	report.ByType = groupRecords(resolved, func(r jira.FlowRecord) string { return r.IssueType })
+	report.ByAssignee = groupRecords(resolved, func(r jira.FlowRecord) string { return r.Assignee })
+
+	return report
+}
+
+// groupRecords splits records by key and computes a group per distinct key
+func groupRecords(records []jira.FlowRecord, key func(jira.FlowRecord) string) []CycleTimeGroup {
+	buckets := make(map[string][]jira.FlowRecord)
+	for _, r := range records {
+		name := key(r)
+		if name == "" {
+			name = "(none)"
+		}
+		buckets[name] = append(buckets[name], r)
+	}
+
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make([]CycleTimeGroup, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, cycleTimeGroup(name, buckets[name]))
+	}
+	return groups
+}
+
+func cycleTimeGroup(name string, records []jira.FlowRecord) CycleTimeGroup {
+	var lead, cycle []time.Duration
+	for _, r := range records {
+		if d, ok := r.LeadTime(); ok {
+			lead = append(lead, d)
+		}
+		if d, ok := r.CycleTime(); ok {
+			cycle = append(cycle, d)
+		}
+	}
+	return CycleTimeGroup{
+		Name:      name,
+		LeadTime:  NewDistribution(lead),
+		CycleTime: NewDistribution(cycle),
+	}
+}
+
+// WriteText renders the report as aligned text tables, in calendar days
+func (r *CycleTimeReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	writeSection := func(title string, groups []CycleTimeGroup) {
+		fmt.Fprintf(tw, "%s\n", title)
+		fmt.Fprintf(tw, "%s\tMetric\tCount\tMin\tP50\tP85\tP95\tMax\tMean%s\n", term.Bold.Start(), term.End())
+		for _, g := range groups {
+			writeDistribution(tw, g.Name, "lead", g.LeadTime)
+			writeDistribution(tw, "", "cycle", g.CycleTime)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	writeSection("Overall (days)", []CycleTimeGroup{r.Overall})
+	writeSection("By issue type (days)", r.ByType)
+	writeSection("By assignee (days)", r.ByAssignee)
+
+	if r.Unresolved > 0 {
+		fmt.Fprintf(tw, "%d unresolved issue(s) excluded\n", r.Unresolved)
+	}
+
+	return tw.Flush()
+}
+
+func writeDistribution(w io.Writer, name, metric string, d Distribution) {
+	// Rows start with a color to stay aligned with the bold header
+	if d.Count == 0 {
+		fmt.Fprintf(w, "%s%s\t%s\t0\t-\t-\t-\t-\t-\t-\n", term.Default.Start(), name, metric)
+		return
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\n", term.Default.Start(), name, metric, d.Count,
+		Days(d.Min), Days(d.P50), Days(d.P85), Days(d.P95), Days(d.Max), Days(d.Mean))
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// DependencyEdge is a dependency of one ticket on another
+type DependencyEdge struct {
+	Key       string
+	DependsOn string
+	External  bool // DependsOn is not among the reported tickets
+	CrossEpic bool // The tickets are in different epics (or one is in none)
+	CrossTeam bool // The tickets belong to different teams
+}
+
+// DependencyReport is the dependency graph between tickets, for dependency
+// review meetings
+type DependencyReport struct {
+	Keys    []string // Tickets with dependencies either way, in query order
+	Tickets map[string]jira.Ticket
+	Teams   map[string]string // Team per ticket key, if teams are known
+	Edges   []DependencyEdge  // In the order of Keys
+}
+
+// NewDependencyReport collects the dependencies of the tickets. team, if not
+// nil, returns a ticket's team; edges between tickets with different known
+// teams are cross-team.
+func NewDependencyReport(tickets []jira.Ticket, team func(jira.Ticket) string) *DependencyReport {
+	r := &DependencyReport{Tickets: make(map[string]jira.Ticket, len(tickets))}
+	for _, t := range tickets {
+		r.Tickets[t.Key] = t
+	}
+	if team != nil {
+		r.Teams = make(map[string]string, len(tickets))
+		for _, t := range tickets {
+			r.Teams[t.Key] = team(t)
+		}
+	}
+
+	involved := make(map[string]bool)
+	for _, t := range tickets {
+		for _, dep := range t.DependencyKeys {
+			edge := DependencyEdge{Key: t.Key, DependsOn: dep}
+			if other, ok := r.Tickets[dep]; ok {
+				edge.CrossEpic = other.EpicLink != t.EpicLink
+				edge.CrossTeam = r.Teams[t.Key] != "" && r.Teams[dep] != "" && r.Teams[t.Key] != r.Teams[dep]
+				involved[dep] = true
+			} else {
+				edge.External = true
+			}
+			r.Edges = append(r.Edges, edge)
+			involved[t.Key] = true
+		}
+	}
+	for _, t := range tickets {
+		if involved[t.Key] {
+			r.Keys = append(r.Keys, t.Key)
+		}
+	}
+	return r
+}
+
+// WriteMatrixCSV writes the dependency matrix: one row and one column per
+// ticket, with "X" where the row's ticket depends on the column's
+func (r *DependencyReport) WriteMatrixCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	index := make(map[string]int, len(r.Keys))
+	for i, key := range r.Keys {
+		index[key] = i
+	}
+
+	header := append([]string{"key / depends_on"}, r.Keys...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	rows := make(map[string][]string, len(r.Keys))
+	for _, key := range r.Keys {
+		rows[key] = make([]string, len(r.Keys)+1)
+		rows[key][0] = key
+	}
+	for _, e := range r.Edges {
+		if !e.External {
+			rows[e.Key][index[e.DependsOn]+1] = "X"
+		}
+	}
+	for _, key := range r.Keys {
+		if err := cw.Write(rows[key]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEdgesCSV writes one row per dependency, flagging cross-epic and
+// cross-team edges
+func (r *DependencyReport) WriteEdgesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"key", "summary", "epic", "team", "depends_on", "depends_on_summary", "depends_on_epic", "depends_on_team", "cross_epic", "cross_team"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range r.Edges {
+		from, to := r.Tickets[e.Key], r.Tickets[e.DependsOn]
+		row := []string{
+			e.Key, from.Summary, from.EpicLink, r.Teams[e.Key],
+			e.DependsOn, to.Summary, to.EpicLink, r.Teams[e.DependsOn],
+			strconv.FormatBool(e.CrossEpic), strconv.FormatBool(e.CrossTeam),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteText lists the dependencies per ticket, then the cross-epic and
+// cross-team ones
+func (r *DependencyReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%d dependencies between %d tickets\n", len(r.Edges), len(r.Keys))
+	writeEdges := func(title string, keep func(DependencyEdge) bool) {
+		fmt.Fprintf(tw, "\n%s\n%sTicket\tSummary\tDepends on\tSummary%s\n", title, term.Bold.Start(), term.End())
+		for _, e := range r.Edges {
+			if !keep(e) {
+				continue
+			}
+			summary, color := r.Tickets[e.DependsOn].Summary, term.Default
+			if e.External {
+				summary, color = "(not in query)", term.Yellow
+			}
+			fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s%s\n", color.Start(), e.Key, r.Tickets[e.Key].Summary, e.DependsOn, summary, term.End())
+		}
+	}
+
+	writeEdges("All dependencies", func(DependencyEdge) bool { return true })
+	writeEdges("Cross-epic dependencies", func(e DependencyEdge) bool { return e.CrossEpic })
+	if r.Teams != nil {
+		writeEdges("Cross-team dependencies", func(e DependencyEdge) bool { return e.CrossTeam })
+	}
+
+	return tw.Flush()
+}
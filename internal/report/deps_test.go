@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// depsReport is a dependency report of five tickets over two epics and two
+// teams, one depending on a ticket outside the query
+func depsReport() *DependencyReport {
+	tickets := []jira.Ticket{
+		{Key: "WEB-1", Summary: "Login form", EpicLink: "WEB-10", Fields: map[string]string{"Team": "Web"}},
+		{Key: "WEB-2", Summary: "Session handling", EpicLink: "WEB-10", DependencyKeys: []string{"WEB-1", "API-1"}, Fields: map[string]string{"Team": "Web"}},
+		{Key: "API-1", Summary: "Auth endpoint", EpicLink: "API-10", Fields: map[string]string{"Team": "API"}},
+		{Key: "API-2", Summary: "Rate limits", EpicLink: "API-10", DependencyKeys: []string{"OPS-7"}, Fields: map[string]string{"Team": "API"}},
+		{Key: "DOC-1", Summary: "Unrelated", Fields: map[string]string{"Team": "Docs"}},
+	}
+	return NewDependencyReport(tickets, func(t jira.Ticket) string { return t.Fields["Team"] })
+}
+
+func TestDependencyReport_WriteText(t *testing.T) {
+	const golden = `3 dependencies between 4 tickets
+
+All dependencies
+Ticket  Summary           Depends on  Summary
+WEB-2   Session handling  WEB-1       Login form
+WEB-2   Session handling  API-1       Auth endpoint
+API-2   Rate limits       OPS-7       (not in query)
+
+Cross-epic dependencies
+Ticket  Summary           Depends on  Summary
+WEB-2   Session handling  API-1       Auth endpoint
+
+Cross-team dependencies
+Ticket  Summary           Depends on  Summary
+WEB-2   Session handling  API-1       Auth endpoint
+`
+	var buf bytes.Buffer
+	if err := depsReport().WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteText =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
+
+func TestDependencyReport_WriteText_WithoutTeams(t *testing.T) {
+	r := NewDependencyReport([]jira.Ticket{
+		{Key: "A-1", Summary: "First"},
+		{Key: "A-2", Summary: "Second", DependencyKeys: []string{"A-1"}},
+	}, nil)
+	const golden = `1 dependencies between 2 tickets
+
+All dependencies
+Ticket  Summary  Depends on  Summary
+A-2     Second   A-1         First
+
+Cross-epic dependencies
+Ticket  Summary  Depends on  Summary
+`
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteText =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
+
+func TestDependencyReport_WriteMatrixCSV(t *testing.T) {
+	const golden = `key / depends_on,WEB-1,WEB-2,API-1,API-2
+WEB-1,,,,
+WEB-2,X,,X,
+API-1,,,,
+API-2,,,,
+`
+	var buf bytes.Buffer
+	if err := depsReport().WriteMatrixCSV(&buf); err != nil {
+		t.Fatalf("WriteMatrixCSV failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteMatrixCSV =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
+
+func TestDependencyReport_WriteEdgesCSV(t *testing.T) {
+	const golden = `key,summary,epic,team,depends_on,depends_on_summary,depends_on_epic,depends_on_team,cross_epic,cross_team
+WEB-2,Session handling,WEB-10,Web,WEB-1,Login form,WEB-10,Web,false,false
+WEB-2,Session handling,WEB-10,Web,API-1,Auth endpoint,API-10,API,true,true
+API-2,Rate limits,API-10,API,OPS-7,,,,false,false
+`
+	var buf bytes.Buffer
+	if err := depsReport().WriteEdgesCSV(&buf); err != nil {
+		t.Fatalf("WriteEdgesCSV failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteEdgesCSV =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// Overcommitment is a due date an assignee cannot meet: the open effort due
+// by then exceeds their working days until the end of that day
+type Overcommitment struct {
+	Assignee  string
+	DueDate   time.Time
+	Effort    float64  // Open effort in days due by DueDate
+	Available int      // Workdays from origin through DueDate
+	Keys      []string // Open tickets due by DueDate, earliest first
+}
+
+// Overcommitments checks, per assignee and due date, whether the open tickets
+// due by that date fit into the working days left from origin. Unassigned
+// tickets and tickets without a due date are ignored.
+func Overcommitments(tickets []jira.Ticket, origin time.Time, cal schedule.Calendar) []Overcommitment {
+	byAssignee := make(map[string][]jira.Ticket)
+	for _, t := range tickets {
+		if t.Assignee != "" && !t.DueDate.IsZero() && !t.IsDone() {
+			byAssignee[t.Assignee] = append(byAssignee[t.Assignee], t)
+		}
+	}
+
+	var result []Overcommitment
+	for assignee, due := range byAssignee {
+		sort.SliceStable(due, func(i, j int) bool { return due[i].DueDate.Before(due[j].DueDate) })
+
+		var effort float64
+		var keys []string
+		for i, t := range due {
+			effort += schedule.EffortHours(t, cal.HoursPerDay) / cal.HoursPerDay
+			keys = append(keys, t.Key)
+			// Check each deadline once, with all the work due by then
+			if i+1 < len(due) && due[i+1].DueDate.Equal(t.DueDate) {
+				continue
+			}
+			available := cal.WorkdaysBetween(origin, t.DueDate.AddDate(0, 0, 1))
+			if effort > float64(available) {
+				result = append(result, Overcommitment{
+					Assignee:  assignee,
+					DueDate:   t.DueDate,
+					Effort:    effort,
+					Available: available,
+					Keys:      append([]string(nil), keys...),
+				})
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].DueDate.Equal(result[j].DueDate) {
+			return result[i].DueDate.Before(result[j].DueDate)
+		}
+		return result[i].Assignee < result[j].Assignee
+	})
+	return result
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// day returns a date of March 2026; the 2nd is a Monday
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOvercommitments(t *testing.T) {
+	origin := day(2)
+	tests := []struct {
+		name    string
+		tickets []jira.Ticket
+		want    []Overcommitment
+	}{
+		{
+			name: "over-committed assignee",
+			tickets: []jira.Ticket{
+				{Key: "A-1", Assignee: "Ada", EffortDays: 2, DueDate: day(4)},
+				{Key: "A-2", Assignee: "Ada", EffortDays: 2, DueDate: day(4)},
+			},
+			want: []Overcommitment{{Assignee: "Ada", DueDate: day(4), Effort: 4, Available: 3, Keys: []string{"A-1", "A-2"}}},
+		},
+		{
+			name: "feasible assignee",
+			tickets: []jira.Ticket{
+				{Key: "A-1", Assignee: "Ada", EffortDays: 2, DueDate: day(4)},
+				{Key: "A-2", Assignee: "Ada", EffortDays: 3, DueDate: day(6)},
+			},
+		},
+		{
+			name: "work due earlier counts towards later dates",
+			tickets: []jira.Ticket{
+				{Key: "A-2", Assignee: "Ada", EffortDays: 2, DueDate: day(3)},
+				{Key: "A-1", Assignee: "Ada", EffortDays: 0.5, DueDate: day(2)},
+			},
+			want: []Overcommitment{{Assignee: "Ada", DueDate: day(3), Effort: 2.5, Available: 2, Keys: []string{"A-1", "A-2"}}},
+		},
+		{
+			name: "ticket without effort uses the default",
+			tickets: []jira.Ticket{
+				{Key: "B-1", Assignee: "Bo", DueDate: day(2)},
+				{Key: "B-2", Assignee: "Bo", DueDate: day(2)},
+			},
+			want: []Overcommitment{{Assignee: "Bo", DueDate: day(2), Effort: 2, Available: 1, Keys: []string{"B-1", "B-2"}}},
+		},
+		{
+			name: "past due date",
+			tickets: []jira.Ticket{
+				{Key: "C-1", Assignee: "Cy", EffortDays: 0.5, DueDate: time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)},
+			},
+			want: []Overcommitment{{Assignee: "Cy", DueDate: time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC), Effort: 0.5, Available: 0, Keys: []string{"C-1"}}},
+		},
+		{
+			name: "done, unassigned and undated tickets are ignored",
+			tickets: []jira.Ticket{
+				{Key: "D-1", Assignee: "Di", EffortDays: 5, DueDate: day(2), StatusCategory: "done"},
+				{Key: "D-2", EffortDays: 5, DueDate: day(2)},
+				{Key: "D-3", Assignee: "Di", EffortDays: 5},
+			},
+		},
+		{
+			name: "ordered by due date, then assignee",
+			tickets: []jira.Ticket{
+				{Key: "E-1", Assignee: "Zed", EffortDays: 2, DueDate: day(2)},
+				{Key: "E-2", Assignee: "Eve", EffortDays: 4, DueDate: day(3)},
+				{Key: "E-3", Assignee: "Ann", EffortDays: 2, DueDate: day(2)},
+			},
+			want: []Overcommitment{
+				{Assignee: "Ann", DueDate: day(2), Effort: 2, Available: 1, Keys: []string{"E-3"}},
+				{Assignee: "Zed", DueDate: day(2), Effort: 2, Available: 1, Keys: []string{"E-1"}},
+				{Assignee: "Eve", DueDate: day(3), Effort: 4, Available: 2, Keys: []string{"E-2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Overcommitments(tt.tickets, origin, schedule.DefaultCalendar())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Overcommitments =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecast_InfeasibleDueDates(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "A-1", Assignee: "Ada", EffortDays: 2, DueDate: day(2)},
+		{Key: "A-2", Assignee: "Ada", EffortDays: 1, DueDate: day(6)},
+	}
+	forecast, err := NewForecast(tickets, nil, day(2), schedule.DefaultCalendar())
+	if err != nil {
+		t.Fatalf("NewForecast failed: %v", err)
+	}
+
+	var text bytes.Buffer
+	if err := forecast.WriteText(&text, false); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if !strings.Contains(text.String(), "Infeasible due dates") || !strings.Contains(text.String(), "Ada       2026-03-02  2.0d    1d         A-1") {
+		t.Errorf("Expected the infeasible due date of A-1 in:\n%s", text.String())
+	}
+	if strings.Contains(text.String(), "A-2") {
+		t.Errorf("Expected the feasible A-2 not to be listed:\n%s", text.String())
+	}
+
+	var html bytes.Buffer
+	if err := forecast.WriteHTML(&html, "project = A"); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if !strings.Contains(html.String(), "<tr><td>Ada</td><td>2026-03-02</td><td>2.0d</td><td>1d</td><td>A-1</td></tr>") {
+		t.Errorf("Expected the infeasible due date row in:\n%s", html.String())
+	}
+
+	// Without overcommitments the section is left out
+	forecast.Overcommitments = nil
+	text.Reset()
+	forecast.WriteText(&text, false)
+	if strings.Contains(text.String(), "Infeasible") {
+		t.Errorf("Expected no infeasible section:\n%s", text.String())
+	}
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// TicketFloat is the projected float of an open ticket, in days
+type TicketFloat struct {
+	Ticket jira.Ticket
+	Start  time.Time
+	Finish time.Time
+	Total  float64 // Slip that delays the projected finish
+	Free   float64 // Slip that delays another ticket
+}
+
+// Critical reports whether any slip of the ticket delays the projected finish
+func (f TicketFloat) Critical() bool {
+	return f.Total < floatEpsilon
+}
+
+// floatEpsilon absorbs rounding in floats computed from fractional hours
+const floatEpsilon = 1e-9
+
+// FloatReport lists the float of every open ticket, least first, so leads
+// see where schedule risk concentrates
+type FloatReport struct {
+	Origin    time.Time
+	Threshold float64 // Tickets with less total float are near-critical
+	Tickets   []TicketFloat
+
+	DateLayout string // Go layout for printed dates (DefaultDateLayout if empty)
+}
+
+// NewFloatReport schedules the open tickets from origin, like the forecast,
+// and computes their total and free float
+func NewFloatReport(tickets []jira.Ticket, origin time.Time, cal schedule.Calendar, threshold float64) (*FloatReport, error) {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return nil, err
+	}
+	floats := result.Floats()
+
+	r := &FloatReport{Origin: cal.NextWorkday(origin), Threshold: threshold}
+	for _, t := range tickets {
+		if t.IsDone() {
+			continue
+		}
+		f := floats[t.Key]
+		r.Tickets = append(r.Tickets, TicketFloat{
+			Ticket: t,
+			Start:  result.StartDate(t.Key),
+			Finish: result.FinishDate(t.Key),
+			Total:  f.Total / cal.HoursPerDay,
+			Free:   f.Free / cal.HoursPerDay,
+		})
+	}
+	sort.SliceStable(r.Tickets, func(i, j int) bool {
+		a, b := r.Tickets[i], r.Tickets[j]
+		if a.Total != b.Total {
+			return a.Total < b.Total
+		}
+		return a.Start.Before(b.Start)
+	})
+	return r, nil
+}
+
+// NearCritical reports whether a ticket has float but less than the threshold
+func (r *FloatReport) NearCritical(f TicketFloat) bool {
+	return !f.Critical() && f.Total < r.Threshold
+}
+
+// WriteText prints one row per open ticket, critical tickets in red and
+// near-critical ones in yellow
+func (r *FloatReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	layout := dateLayout(r.DateLayout)
+
+	var critical, near int
+	for _, f := range r.Tickets {
+		if f.Critical() {
+			critical++
+		} else if r.NearCritical(f) {
+			near++
+		}
+	}
+	fmt.Fprintf(tw, "Float from %s\n", r.Origin.Format(layout))
+	fmt.Fprintf(tw, "Open tickets:\t%d\n", len(r.Tickets))
+	fmt.Fprintf(tw, "Critical:\t%d\n", critical)
+	fmt.Fprintf(tw, "Near-critical (< %.1fd):\t%d\n", r.Threshold, near)
+
+	fmt.Fprintf(tw, "\n%sKey\tSummary\tAssignee\tStart\tFinish\tTotal float\tFree float%s\n", term.Bold.Start(), term.End())
+	for _, f := range r.Tickets {
+		color := term.Default
+		if f.Critical() {
+			color = term.Red
+		} else if r.NearCritical(f) {
+			color = term.Yellow
+		}
+		assignee := f.Ticket.Assignee
+		if assignee == "" {
+			assignee = "-"
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\t%.1fd\t%.1fd%s\n", color.Start(), f.Ticket.Key, f.Ticket.Summary, assignee,
+			f.Start.Format(layout), f.Finish.Format(layout), f.Total, f.Free, term.End())
+	}
+	return tw.Flush()
+}
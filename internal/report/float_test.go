@@ -0,0 +1,124 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// floatTickets run one week from Monday the 2nd: Ada's chain is critical,
+// Di's ticket has half a day of float and Bo's and Cy's three days
+var floatTickets = []jira.Ticket{
+	{Key: "B-1", Summary: "Backend", Assignee: "Bo", EffortDays: 1},
+	{Key: "B-2", Summary: "Wiring", Assignee: "Cy", EffortDays: 1, DependencyKeys: []string{"B-1"}},
+	{Key: "A-1", Summary: "Design", Assignee: "Ada", EffortDays: 3},
+	{Key: "A-2", Summary: "Build", Assignee: "Ada", EffortDays: 2, DependencyKeys: []string{"A-1"}},
+	{Key: "C-1", Summary: "Docs", Assignee: "Di", EffortDays: 4.5},
+	{Key: "D-1", Summary: "Done", EffortDays: 2, StatusCategory: "done"},
+}
+
+func TestNewFloatReport(t *testing.T) {
+	r, err := NewFloatReport(floatTickets, day(2), schedule.DefaultCalendar(), 1)
+	if err != nil {
+		t.Fatalf("NewFloatReport failed: %v", err)
+	}
+
+	// Least total float first, then by start
+	want := []struct {
+		key         string
+		total, free float64
+	}{
+		{"A-1", 0, 0},
+		{"A-2", 0, 0},
+		{"C-1", 0.5, 0.5},
+		{"B-1", 3, 0}, // B-2 follows it directly
+		{"B-2", 3, 3},
+	}
+	if len(r.Tickets) != len(want) {
+		t.Fatalf("Expected %d open tickets, got %d", len(want), len(r.Tickets))
+	}
+	for i, w := range want {
+		got := r.Tickets[i]
+		if got.Ticket.Key != w.key || got.Total != w.total || got.Free != w.free {
+			t.Errorf("Ticket %d = %s with %v/%v days, want %s with %v/%v", i, got.Ticket.Key, got.Total, got.Free, w.key, w.total, w.free)
+		}
+	}
+	if !r.Tickets[1].Start.Equal(day(5)) || !r.Tickets[1].Finish.Equal(day(6)) {
+		t.Errorf("Expected A-2 from the 5th to the 6th, got %s to %s", r.Tickets[1].Start, r.Tickets[1].Finish)
+	}
+}
+
+func TestFloatReport_Threshold(t *testing.T) {
+	tests := []struct {
+		threshold float64
+		near      []string
+	}{
+		{0, nil},
+		{0.5, nil}, // Less than the threshold, not equal
+		{1, []string{"C-1"}},
+		{3.5, []string{"C-1", "B-1", "B-2"}},
+	}
+	for _, tt := range tests {
+		r, err := NewFloatReport(floatTickets, day(2), schedule.DefaultCalendar(), tt.threshold)
+		if err != nil {
+			t.Fatalf("NewFloatReport failed: %v", err)
+		}
+		var near []string
+		for _, f := range r.Tickets {
+			if r.NearCritical(f) {
+				near = append(near, f.Ticket.Key)
+			}
+		}
+		if strings.Join(near, ",") != strings.Join(tt.near, ",") {
+			t.Errorf("Threshold %v: near-critical %v, want %v", tt.threshold, near, tt.near)
+		}
+
+		var buf bytes.Buffer
+		if err := r.WriteText(&buf); err != nil {
+			t.Fatalf("WriteText failed: %v", err)
+		}
+		text := strings.Join(strings.Fields(buf.String()), " ")
+		if want := fmt.Sprintf("Critical: 2 Near-critical (< %.1fd): %d", tt.threshold, len(tt.near)); !strings.Contains(text, want) {
+			t.Errorf("Threshold %v: expected %q in:\n%s", tt.threshold, want, buf.String())
+		}
+	}
+}
+
+func TestNewCriticalPath(t *testing.T) {
+	cp, err := NewCriticalPath(floatTickets, day(2), schedule.DefaultCalendar())
+	if err != nil {
+		t.Fatalf("NewCriticalPath failed: %v", err)
+	}
+	if !cp.Finish.Equal(day(6)) || len(cp.Steps) != 2 {
+		t.Fatalf("Expected two steps to the 6th, got %s and %+v", cp.Finish, cp.Steps)
+	}
+	first, second := cp.Steps[0], cp.Steps[1]
+	if first.Ticket.Key != "A-1" || first.Effort != 3 || first.Slack != 0 || first.After != "" || first.Reason != "" {
+		t.Errorf("Unexpected first step %+v", first)
+	}
+	if second.Ticket.Key != "A-2" || second.After != "A-1" || second.Reason != "dependency" || !second.Start.Equal(day(5)) {
+		t.Errorf("Unexpected second step %+v", second)
+	}
+
+	// Without a link, the same assignee is what makes the tickets wait
+	tickets := []jira.Ticket{
+		{Key: "A-1", Assignee: "Ada", EffortDays: 1},
+		{Key: "A-2", Assignee: "Ada", EffortDays: 1},
+	}
+	cp, err = NewCriticalPath(tickets, day(2), schedule.DefaultCalendar())
+	if err != nil {
+		t.Fatalf("NewCriticalPath failed: %v", err)
+	}
+	if len(cp.Steps) != 2 || cp.Steps[1].Reason != "assignee" {
+		t.Errorf("Expected A-2 to wait for its assignee, got %+v", cp.Steps)
+	}
+	var buf bytes.Buffer
+	cp.WriteText(&buf)
+	if !strings.Contains(buf.String(), "A-1 (same assignee)") {
+		t.Errorf("Expected the assignee wait in:\n%s", buf.String())
+	}
+}
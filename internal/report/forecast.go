@@ -0,0 +1,214 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+	"github.com/gunnarrb/jql-to-plan/internal/term"
+)
+
+// EpicForecast is the projected completion of one epic's open work
+type EpicForecast struct {
+	Key        string // Empty for tickets without an epic
+	Summary    string
+	Open       int       // Open tickets
+	Remaining  float64   // Open effort in days
+	Unassigned float64   // Open effort in days nobody is assigned to
+	Assignees  int       // People with open work in the epic
+	Finish     time.Time // Projected finish (zero when nothing is open)
+	DueDate    time.Time // Epic due date from Jira (zero if unset)
+}
+
+// Late reports whether the epic is projected to finish after its due date
+func (e EpicForecast) Late() bool {
+	return !e.DueDate.IsZero() && !e.Finish.IsZero() && e.Finish.After(e.DueDate)
+}
+
+// Forecast projects when the open work finishes, overall and per epic
+type Forecast struct {
+	Origin    time.Time
+	Finish    time.Time
+	Open      int
+	Remaining float64 // Open effort in days
+	Assignees int
+	Epics     []EpicForecast // Ordered by projected finish, finished epics last
+
+	// Overcommitments are ticket due dates that their assignees cannot meet
+	Overcommitments []Overcommitment
+
+	DateLayout string // Go layout for printed dates (DefaultDateLayout if empty)
+}
+
+// NewForecast schedules the open tickets from origin and groups the projected
+// finish dates by epic
+func NewForecast(tickets []jira.Ticket, epics map[string]jira.Ticket, origin time.Time, cal schedule.Calendar) (*Forecast, error) {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &Forecast{
+		Origin:          cal.NextWorkday(origin),
+		Finish:          result.EndDate(),
+		Overcommitments: Overcommitments(tickets, origin, cal),
+	}
+	byEpic := make(map[string]*EpicForecast)
+	people := make(map[string]bool)
+	epicPeople := make(map[string]map[string]bool)
+
+	for _, t := range tickets {
+		ef, ok := byEpic[t.EpicLink]
+		if !ok {
+			epic := epics[t.EpicLink]
+			ef = &EpicForecast{Key: t.EpicLink, Summary: epic.Summary, DueDate: epic.DueDate}
+			byEpic[t.EpicLink] = ef
+			epicPeople[t.EpicLink] = make(map[string]bool)
+		}
+		if t.IsDone() {
+			continue
+		}
+
+		days := schedule.EffortHours(t, cal.HoursPerDay) / cal.HoursPerDay
+		ef.Open++
+		ef.Remaining += days
+		forecast.Open++
+		forecast.Remaining += days
+		if t.Assignee == "" {
+			ef.Unassigned += days
+		} else {
+			people[t.Assignee] = true
+			epicPeople[t.EpicLink][t.Assignee] = true
+		}
+
+		if finish := result.FinishDate(t.Key); finish.After(ef.Finish) {
+			ef.Finish = finish
+		}
+	}
+	forecast.Assignees = len(people)
+
+	for key, ef := range byEpic {
+		ef.Assignees = len(epicPeople[key])
+		forecast.Epics = append(forecast.Epics, *ef)
+	}
+	sort.Slice(forecast.Epics, func(i, j int) bool {
+		a, b := forecast.Epics[i], forecast.Epics[j]
+		if (a.Open == 0) != (b.Open == 0) {
+			return b.Open == 0 // Finished epics go last
+		}
+		if !a.Finish.Equal(b.Finish) {
+			return a.Finish.Before(b.Finish)
+		}
+		return a.Key < b.Key
+	})
+
+	return forecast, nil
+}
+
+// WriteText renders the overall forecast and, if byEpic is set, one row per epic
+func (f *Forecast) WriteText(w io.Writer, byEpic bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Forecast from %s\n", f.formatDate(f.Origin))
+	fmt.Fprintf(tw, "Open tickets:\t%d\n", f.Open)
+	fmt.Fprintf(tw, "Remaining effort:\t%.1fd\n", f.Remaining)
+	fmt.Fprintf(tw, "Assignees:\t%d\n", f.Assignees)
+	fmt.Fprintf(tw, "Projected finish:\t%s\n", f.formatFinish(f.Finish, f.Open))
+
+	if byEpic {
+		fmt.Fprintf(tw, "\n%sEpic\tSummary\tOpen\tRemaining\tAssignees\tUnassigned\tFinish\tDue%s\n", term.Bold.Start(), term.End())
+		for _, e := range f.Epics {
+			key, summary := e.Key, e.Summary
+			if key == "" {
+				key, summary = "-", "(no epic)"
+			}
+			due, color := "-", term.Default
+			if e.Open == 0 {
+				color = term.Green
+			}
+			if !e.DueDate.IsZero() {
+				due = f.formatDate(e.DueDate)
+				if e.Late() {
+					due += " LATE"
+					color = term.Red
+				}
+			}
+			fmt.Fprintf(tw, "%s%s\t%s\t%d\t%.1fd\t%d\t%.1fd\t%s\t%s%s\n",
+				color.Start(), key, summary, e.Open, e.Remaining, e.Assignees, e.Unassigned, f.formatFinish(e.Finish, e.Open), due, term.End())
+		}
+	}
+
+	if len(f.Overcommitments) > 0 {
+		fmt.Fprintf(tw, "\nInfeasible due dates\n%sAssignee\tDue\tEffort\tAvailable\tTickets%s\n", term.Bold.Start(), term.End())
+		for _, o := range f.Overcommitments {
+			fmt.Fprintf(tw, "%s%s\t%s\t%.1fd\t%dd\t%s%s\n", term.Red.Start(), o.Assignee, f.formatDate(o.DueDate), o.Effort, o.Available, strings.Join(o.Keys, ", "), term.End())
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteHTML renders the forecast and the per-epic table as an XHTML fragment,
+// usable as Confluence storage format
+func (f *Forecast) WriteHTML(w io.Writer, jql string) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "<p>Generated %s from <code>%s</code></p>\n", time.Now().In(f.Origin.Location()).Format(dateLayout(f.DateLayout)+" 15:04"), html.EscapeString(jql))
+	fmt.Fprintf(&sb, "<table><tbody>\n")
+	fmt.Fprintf(&sb, "<tr><th>Open tickets</th><td>%d</td></tr>\n", f.Open)
+	fmt.Fprintf(&sb, "<tr><th>Remaining effort</th><td>%.1fd</td></tr>\n", f.Remaining)
+	fmt.Fprintf(&sb, "<tr><th>Assignees</th><td>%d</td></tr>\n", f.Assignees)
+	fmt.Fprintf(&sb, "<tr><th>Projected finish</th><td>%s</td></tr>\n", f.formatFinish(f.Finish, f.Open))
+	fmt.Fprintf(&sb, "</tbody></table>\n")
+
+	fmt.Fprintf(&sb, "<h2>Epics</h2>\n<table><tbody>\n")
+	fmt.Fprintf(&sb, "<tr><th>Epic</th><th>Summary</th><th>Open</th><th>Remaining</th><th>Assignees</th><th>Unassigned</th><th>Finish</th><th>Due</th></tr>\n")
+	for _, e := range f.Epics {
+		key, summary := e.Key, e.Summary
+		if key == "" {
+			key, summary = "-", "(no epic)"
+		}
+		due := "-"
+		if !e.DueDate.IsZero() {
+			due = f.formatDate(e.DueDate)
+			if e.Late() {
+				due += " <strong>LATE</strong>"
+			}
+		}
+		fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%d</td><td>%.1fd</td><td>%d</td><td>%.1fd</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(key), html.EscapeString(summary), e.Open, e.Remaining, e.Assignees, e.Unassigned, f.formatFinish(e.Finish, e.Open), due)
+	}
+	fmt.Fprintf(&sb, "</tbody></table>\n")
+
+	if len(f.Overcommitments) > 0 {
+		fmt.Fprintf(&sb, "<h2>Infeasible due dates</h2>\n<table><tbody>\n")
+		fmt.Fprintf(&sb, "<tr><th>Assignee</th><th>Due</th><th>Effort</th><th>Available</th><th>Tickets</th></tr>\n")
+		for _, o := range f.Overcommitments {
+			fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%.1fd</td><td>%dd</td><td>%s</td></tr>\n",
+				html.EscapeString(o.Assignee), f.formatDate(o.DueDate), o.Effort, o.Available, html.EscapeString(strings.Join(o.Keys, ", ")))
+		}
+		fmt.Fprintf(&sb, "</tbody></table>\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatDate prints a date with the forecast's layout
+func (f *Forecast) formatDate(t time.Time) string {
+	return t.Format(dateLayout(f.DateLayout))
+}
+
+// formatFinish prints a projected finish date, or "done" when nothing is open
+func (f *Forecast) formatFinish(finish time.Time, open int) string {
+	if open == 0 {
+		return "done"
+	}
+	return f.formatDate(finish)
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+func TestNewForecast_Epics(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "A-1", Assignee: "Ada", EffortDays: 2, EpicLink: "EPIC-1"},
+		{Key: "A-2", Assignee: "Ada", EffortDays: 1, EpicLink: "EPIC-1", DependencyKeys: []string{"A-1"}},
+		{Key: "B-1", Assignee: "Bo", EffortDays: 4, EpicLink: "EPIC-2"},
+		{Key: "B-2", Assignee: "Bo", EffortDays: 2, EpicLink: "EPIC-2", DependencyKeys: []string{"B-1"}}, // Over the weekend
+		{Key: "C-1", EffortDays: 0.5},
+		{Key: "D-1", Assignee: "Ada", EffortDays: 3, EpicLink: "EPIC-3", StatusCategory: "done"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "Checkout", DueDate: day(6)},
+		"EPIC-2": {Key: "EPIC-2", Summary: "Payments", DueDate: day(6)},
+		"EPIC-3": {Key: "EPIC-3", Summary: "Shipped"},
+	}
+
+	// Saturday, so the forecast starts on Monday the 2nd
+	forecast, err := NewForecast(tickets, epics, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), schedule.DefaultCalendar())
+	if err != nil {
+		t.Fatalf("NewForecast failed: %v", err)
+	}
+
+	if !forecast.Origin.Equal(day(2)) || !forecast.Finish.Equal(day(9)) {
+		t.Errorf("Expected the forecast from the 2nd to the 9th, got %s to %s", forecast.Origin, forecast.Finish)
+	}
+	if forecast.Open != 5 || forecast.Remaining != 9.5 || forecast.Assignees != 2 {
+		t.Errorf("Unexpected totals: %d open, %vd, %d assignees", forecast.Open, forecast.Remaining, forecast.Assignees)
+	}
+
+	want := []EpicForecast{
+		{Key: "", Open: 1, Remaining: 0.5, Unassigned: 0.5, Finish: day(2)},
+		{Key: "EPIC-1", Summary: "Checkout", Open: 2, Remaining: 3, Assignees: 1, Finish: day(4), DueDate: day(6)},
+		{Key: "EPIC-2", Summary: "Payments", Open: 2, Remaining: 6, Assignees: 1, Finish: day(9), DueDate: day(6)},
+		{Key: "EPIC-3", Summary: "Shipped"}, // Finished epics go last
+	}
+	if len(forecast.Epics) != len(want) {
+		t.Fatalf("Expected %d epics, got %+v", len(want), forecast.Epics)
+	}
+	for i, w := range want {
+		if got := forecast.Epics[i]; got != w {
+			t.Errorf("Epic %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if forecast.Epics[1].Late() || !forecast.Epics[2].Late() || forecast.Epics[3].Late() {
+		t.Error("Expected only EPIC-2 to be late")
+	}
+
+	var buf bytes.Buffer
+	if err := forecast.WriteText(&buf, true); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, line := range []string{
+		"Projected finish:  2026-03-09",
+		"-       (no epic)  1     0.5d       0          0.5d        2026-03-02  -",
+		"EPIC-2  Payments   2     6.0d       1          0.0d        2026-03-09  2026-03-06 LATE",
+		"EPIC-3  Shipped    0     0.0d       0          0.0d        done        -",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected %q in:\n%s", line, buf.String())
+		}
+	}
+}
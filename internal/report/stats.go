@@ -0,0 +1,69 @@
+// Package report computes and renders analytical reports from Jira data.
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Distribution summarizes a set of durations
+type Distribution struct {
+	Count int
+	Min   time.Duration
+	P50   time.Duration
+	P85   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+}
+
+// NewDistribution computes summary statistics for the given durations
+func NewDistribution(values []time.Duration) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, v := range sorted {
+		total += v
+	}
+
+	return Distribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		P50:   percentile(sorted, 0.50),
+		P85:   percentile(sorted, 0.85),
+		P95:   percentile(sorted, 0.95),
+		Max:   sorted[len(sorted)-1],
+		Mean:  total / time.Duration(len(sorted)),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// Days converts a duration into fractional calendar days
+func Days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
+
+// DefaultDateLayout is the Go layout used for dates unless a report sets its own
+const DefaultDateLayout = "2006-01-02"
+
+// dateLayout returns layout, or DefaultDateLayout if it is empty
+func dateLayout(layout string) string {
+	if layout == "" {
+		return DefaultDateLayout
+	}
+	return layout
+}
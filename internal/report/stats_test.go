@@ -0,0 +1,112 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// hours returns durations of the given numbers of hours
+func hours(values ...int) []time.Duration {
+	durations := make([]time.Duration, len(values))
+	for i, v := range values {
+		durations[i] = time.Duration(v) * time.Hour
+	}
+	return durations
+}
+
+func TestNewDistribution(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []time.Duration
+		want   Distribution
+	}{
+		{name: "empty", want: Distribution{}},
+		{
+			name:   "single sample",
+			values: hours(5),
+			want:   Distribution{Count: 1, Min: 5 * time.Hour, P50: 5 * time.Hour, P85: 5 * time.Hour, P95: 5 * time.Hour, Max: 5 * time.Hour, Mean: 5 * time.Hour},
+		},
+		{
+			// Nearest rank: P50 is rank ceil(0.5*4) = 2, P85 rank ceil(3.4) = 4
+			name:   "unsorted",
+			values: hours(40, 10, 30, 20),
+			want:   Distribution{Count: 4, Min: 10 * time.Hour, P50: 20 * time.Hour, P85: 40 * time.Hour, P95: 40 * time.Hour, Max: 40 * time.Hour, Mean: 25 * time.Hour},
+		},
+		{
+			name:   "twenty samples",
+			values: hours(20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1),
+			want:   Distribution{Count: 20, Min: 1 * time.Hour, P50: 10 * time.Hour, P85: 17 * time.Hour, P95: 19 * time.Hour, Max: 20 * time.Hour, Mean: 10*time.Hour + 30*time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDistribution(tt.values); got != tt.want {
+				t.Errorf("NewDistribution = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDistribution_KeepsInput(t *testing.T) {
+	values := hours(3, 1, 2)
+	NewDistribution(values)
+	if values[0] != 3*time.Hour || values[1] != time.Hour || values[2] != 2*time.Hour {
+		t.Errorf("Expected the input order to be kept, got %v", values)
+	}
+}
+
+func TestPercentile_BetweenRanks(t *testing.T) {
+	sorted := hours(10, 20, 30, 40, 50, 60, 70, 80, 90, 100)
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 10 * time.Hour},     // Clamped to the first rank
+		{0.10, 10 * time.Hour},  // Exactly rank 1
+		{0.11, 20 * time.Hour},  // Between ranks 1 and 2: the next rank up
+		{0.50, 50 * time.Hour},  // Exactly rank 5
+		{0.55, 60 * time.Hour},  // Halfway between ranks 5 and 6
+		{0.85, 90 * time.Hour},  // Rank 8.5 rounds up to 9
+		{0.95, 100 * time.Hour}, // Rank 9.5 rounds up to 10
+		{1, 100 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestDays(t *testing.T) {
+	if got := Days(36 * time.Hour); got != 1.5 {
+		t.Errorf("Days(36h) = %v, want 1.5", got)
+	}
+}
+
+func TestNewCycleTimeReport(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	records := []jira.FlowRecord{
+		{Key: "A-1", IssueType: "Bug", Assignee: "Ada", Created: created, Started: created.Add(24 * time.Hour), Resolved: created.Add(72 * time.Hour)},
+		{Key: "A-2", IssueType: "Story", Assignee: "Ada", Created: created, Resolved: created.Add(48 * time.Hour)}, // Never started
+		{Key: "A-3", IssueType: "Story", Created: created, Started: created.Add(time.Hour)},                        // Open
+	}
+	r := NewCycleTimeReport(records)
+
+	if r.Unresolved != 1 {
+		t.Errorf("Expected 1 unresolved issue, got %d", r.Unresolved)
+	}
+	if r.Overall.LeadTime.Count != 2 || r.Overall.LeadTime.Max != 72*time.Hour || r.Overall.LeadTime.Mean != 60*time.Hour {
+		t.Errorf("Unexpected overall lead time %+v", r.Overall.LeadTime)
+	}
+	if r.Overall.CycleTime.Count != 1 || r.Overall.CycleTime.P50 != 48*time.Hour {
+		t.Errorf("Expected only A-1 to have a cycle time of 2 days, got %+v", r.Overall.CycleTime)
+	}
+	if len(r.ByType) != 2 || r.ByType[0].Name != "Bug" || r.ByType[1].Name != "Story" || r.ByType[1].LeadTime.Count != 1 {
+		t.Errorf("Unexpected groups by type %+v", r.ByType)
+	}
+	if len(r.ByAssignee) != 1 || r.ByAssignee[0].Name != "Ada" {
+		t.Errorf("Unexpected groups by assignee %+v", r.ByAssignee)
+	}
+}
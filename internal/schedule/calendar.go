@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"math"
+	"time"
+)
+
+// Calendar describes working time
+type Calendar struct {
+	HoursPerDay float64
+	Workdays    [7]bool // Indexed by time.Weekday
+}
+
+// DefaultCalendar is a Monday to Friday, 8 hours per day calendar
+func DefaultCalendar() Calendar {
+	cal := Calendar{HoursPerDay: 8}
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday} {
+		cal.Workdays[day] = true
+	}
+	return cal
+}
+
+// IsWorkday reports whether work happens on the given date
+func (c Calendar) IsWorkday(t time.Time) bool {
+	return c.Workdays[t.Weekday()]
+}
+
+// NextWorkday returns t if it is a workday, otherwise the following workday
+func (c Calendar) NextWorkday(t time.Time) time.Time {
+	for i := 0; i < 7 && !c.IsWorkday(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// AddWorkdays moves t forward by n workdays, starting from the next workday
+func (c Calendar) AddWorkdays(t time.Time, n int) time.Time {
+	t = c.NextWorkday(t)
+	for i := 0; i < n; i++ {
+		t = c.NextWorkday(t.AddDate(0, 0, 1))
+	}
+	return t
+}
+
+// DateAt converts an offset in working hours from origin into a date. For
+// finishes, an offset landing exactly on a day boundary belongs to the day
+// that just ended rather than the next one.
+func (c Calendar) DateAt(origin time.Time, hours float64, finish bool) time.Time {
+	origin = truncateDay(origin)
+	if hours <= 0 || c.HoursPerDay <= 0 {
+		return c.NextWorkday(origin)
+	}
+
+	days := hours / c.HoursPerDay
+	var n int
+	if finish {
+		n = int(math.Ceil(days)) - 1
+	} else {
+		n = int(math.Floor(days))
+	}
+	return c.AddWorkdays(origin, n)
+}
+
+// WorkdaysBetween counts workdays in [from, to)
+func (c Calendar) WorkdaysBetween(from, to time.Time) int {
+	from, to = truncateDay(from), truncateDay(to)
+	count := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkday(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// truncateDay drops the time of day, keeping the location
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
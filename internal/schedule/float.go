@@ -0,0 +1,69 @@
+package schedule
+
+import "math"
+
+// Float is how far an item can slip, in working hours
+type Float struct {
+	Total float64 // Without delaying the end of the schedule
+	Free  float64 // Without delaying any of its successors
+}
+
+// Floats computes the float of each item with a backward pass over the
+// schedule. Successors include the next item of the same resource, since
+// slipping an item also delays the resource's following work.
+func (r *Result) Floats() map[string]Float {
+	successors := make(map[string][]string, len(r.Order))
+	for _, id := range r.Order {
+		for _, pred := range r.Predecessors[id] {
+			successors[pred] = append(successors[pred], id)
+		}
+	}
+
+	lateStart := make(map[string]float64, len(r.Order))
+	floats := make(map[string]Float, len(r.Order))
+	for i := len(r.Order) - 1; i >= 0; i-- {
+		id := r.Order[i]
+		slot := r.Slots[id]
+		lateFinish, earliestNext := r.End, r.End
+		for _, succ := range successors[id] {
+			lateFinish = math.Min(lateFinish, lateStart[succ])
+			earliestNext = math.Min(earliestNext, r.Slots[succ].Start)
+		}
+		lateStart[id] = lateFinish - (slot.Finish - slot.Start)
+		floats[id] = Float{
+			Total: lateStart[id] - slot.Start,
+			Free:  earliestNext - slot.Finish,
+		}
+	}
+	return floats
+}
+
+// CriticalPath returns the chain of items that determines the end of the
+// schedule, first to last. Each item starts when the previous one, a
+// prerequisite or the previous work of the same resource, finishes. Items
+// without effort are left out.
+func (r *Result) CriticalPath() []string {
+	var last string
+	for _, id := range r.Order {
+		if slot := r.Slots[id]; slot.Finish == r.End && slot.Finish > slot.Start {
+			last = id
+		}
+	}
+
+	var path []string
+	for id := last; id != ""; {
+		path = append(path, id)
+		start := r.Slots[id].Start
+		id = ""
+		for _, pred := range r.Predecessors[path[len(path)-1]] {
+			if slot := r.Slots[pred]; slot.Finish == start && slot.Finish > slot.Start {
+				id = pred
+				break
+			}
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,148 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+var horizonPattern = regexp.MustCompile(`^(\d+)([dwmq])$`)
+
+// HorizonDate returns the end of a planning horizon such as "90d", "12w",
+// "6m" or "2q" (days, weeks, months or quarters) from origin
+func HorizonDate(spec string, origin time.Time) (time.Time, error) {
+	m := horizonPattern.FindStringSubmatch(spec)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid horizon %q (expected days, weeks, months or quarters, e.g. 2q)", spec)
+	}
+	n, _ := strconv.Atoi(m[1])
+	switch m[2] {
+	case "w":
+		return origin.AddDate(0, 0, 7*n), nil
+	case "m":
+		return origin.AddDate(0, n, 0), nil
+	case "q":
+		return origin.AddDate(0, 3*n, 0), nil
+	default:
+		return origin.AddDate(0, 0, n), nil
+	}
+}
+
+// RollingWave replaces the tickets of each epic whose open work is projected
+// to start after horizon with a single summary ticket for the epic, keeping
+// near-term work in detail. The summary carries the epic's key and link, the
+// remaining effort and the dependencies of its tickets on other work;
+// dependencies on its tickets move to it. It returns the tickets and the keys
+// of the collapsed epics.
+func RollingWave(tickets []jira.Ticket, epics map[string]jira.Ticket, origin, horizon time.Time, cal Calendar) ([]jira.Ticket, []string, error) {
+	result, err := Schedule(ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// An epic is far off when none of its open work starts before the horizon
+	far := make(map[string]bool)
+	near := make(map[string]bool)
+	for _, t := range tickets {
+		if t.EpicLink == "" || t.IsDone() {
+			continue
+		}
+		if result.StartDate(t.Key).After(horizon) {
+			far[t.EpicLink] = true
+		} else {
+			near[t.EpicLink] = true
+		}
+	}
+	var collapsed []string
+	epicOf := make(map[string]string) // Collapsed ticket key -> epic key
+	summaries := make(map[string]*jira.Ticket)
+	for _, t := range tickets {
+		epicKey := t.EpicLink
+		if epicKey == "" && far[t.Key] {
+			epicKey = t.Key // The epic issue itself
+		}
+		if !far[epicKey] || near[epicKey] {
+			continue
+		}
+		epicOf[t.Key] = epicKey
+
+		summary, ok := summaries[epicKey]
+		if !ok {
+			epic := epics[epicKey]
+			summary = &jira.Ticket{
+				Key:            epicKey,
+				Summary:        epic.Summary,
+				Link:           epic.Link,
+				Assignee:       t.Assignee,
+				IssueType:      epic.IssueType,
+				Status:         epic.Status,
+				StatusCategory: epic.StatusCategory,
+				EpicLink:       epicKey,
+				Labels:         t.Labels,
+				DueDate:        epic.DueDate,
+				Fields:         t.Fields,
+			}
+			if summary.Summary == "" {
+				summary.Summary = epicKey
+			}
+			if summary.IsDone() {
+				summary.StatusCategory = "indeterminate" // It still has open work
+			}
+			summaries[epicKey] = summary
+			collapsed = append(collapsed, epicKey)
+		}
+		if !t.IsDone() {
+			summary.EffortDays += EffortHours(t, cal.HoursPerDay) / cal.HoursPerDay
+		}
+		if summary.Assignee != t.Assignee {
+			summary.Assignee = "" // Shared work is left for later planning
+		}
+	}
+	if len(collapsed) == 0 {
+		return tickets, nil, nil
+	}
+
+	// Point dependencies at the summaries, dropping those within an epic
+	dependencies := func(key string, deps []string) []string {
+		var keys []string
+		for _, dep := range deps {
+			if epicKey, ok := epicOf[dep]; ok {
+				dep = epicKey
+			}
+			if dep != epicOf[key] && dep != key && !slices.Contains(keys, dep) {
+				keys = append(keys, dep)
+			}
+		}
+		return keys
+	}
+
+	// Each summary takes the place of its epic's first ticket
+	kept := make([]jira.Ticket, 0, len(tickets))
+	slots := make(map[string]int)
+	for _, t := range tickets {
+		epicKey, ok := epicOf[t.Key]
+		if !ok {
+			t.DependencyKeys = dependencies(t.Key, t.DependencyKeys)
+			kept = append(kept, t)
+			continue
+		}
+		summary := summaries[epicKey]
+		for _, dep := range dependencies(t.Key, t.DependencyKeys) {
+			if !slices.Contains(summary.DependencyKeys, dep) {
+				summary.DependencyKeys = append(summary.DependencyKeys, dep)
+			}
+		}
+		if _, ok := slots[epicKey]; !ok {
+			slots[epicKey] = len(kept)
+			kept = append(kept, jira.Ticket{})
+		}
+	}
+	for epicKey, i := range slots {
+		kept[i] = *summaries[epicKey]
+	}
+	return kept, collapsed, nil
+}
@@ -0,0 +1,185 @@
+// Package schedule projects start and finish dates for a set of tasks using
+// a simple resource-levelled forward pass over their dependencies.
+package schedule
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Item is a unit of work to schedule
+type Item struct {
+	ID       string
+	Effort   float64  // Remaining effort in working hours
+	Resource string   // Tasks sharing a resource run one after another; empty means unconstrained
+	Prereqs  []string // IDs of items that must finish first
+}
+
+// Slot is the scheduled position of an item, in working hours from the start
+type Slot struct {
+	Start  float64
+	Finish float64
+}
+
+// Result holds the projected schedule
+type Result struct {
+	Calendar Calendar
+	Origin   time.Time
+	Slots    map[string]Slot
+	Order    []string // Item IDs in the order they were scheduled
+	End      float64  // Working hours until the last item finishes
+
+	// Predecessors are the scheduled prerequisites of each item, followed by
+	// the previous item of its resource, if any
+	Predecessors map[string][]string
+}
+
+// StartDate returns the calendar date on which the item starts
+func (r *Result) StartDate(id string) time.Time {
+	return r.Calendar.DateAt(r.Origin, r.Slots[id].Start, false)
+}
+
+// FinishDate returns the calendar date on which the item finishes
+func (r *Result) FinishDate(id string) time.Time {
+	return r.Calendar.DateAt(r.Origin, r.Slots[id].Finish, true)
+}
+
+// EndDate returns the date on which the last item finishes
+func (r *Result) EndDate() time.Time {
+	return r.Calendar.DateAt(r.Origin, r.End, true)
+}
+
+// Schedule places items in dependency order starting at origin. Each item
+// starts once its prerequisites have finished and its resource is free.
+// Prerequisites that are not part of items are ignored.
+func Schedule(items []Item, origin time.Time, cal Calendar) (*Result, error) {
+	byID := make(map[string]Item, len(items))
+	for _, item := range items {
+		if _, dup := byID[item.ID]; dup {
+			return nil, fmt.Errorf("duplicate item %s", item.ID)
+		}
+		byID[item.ID] = item
+	}
+
+	order, err := topoSort(items, byID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Calendar: cal,
+		Origin:   origin,
+		Slots:    make(map[string]Slot, len(items)),
+		Order:    order,
+
+		Predecessors: make(map[string][]string, len(items)),
+	}
+	resourceFree := make(map[string]float64)
+	resourceLast := make(map[string]string)
+
+	for _, id := range order {
+		item := byID[id]
+
+		var start float64
+		var preds []string
+		for _, prereq := range item.Prereqs {
+			if slot, ok := result.Slots[prereq]; ok {
+				start = math.Max(start, slot.Finish)
+				preds = append(preds, prereq)
+			}
+		}
+		if item.Resource != "" && resourceFree[item.Resource] > start {
+			start = resourceFree[item.Resource]
+		}
+		if last, ok := resourceLast[item.Resource]; ok && item.Resource != "" {
+			preds = append(preds, last)
+		}
+
+		finish := start + item.Effort
+		if item.Resource != "" {
+			resourceFree[item.Resource] = finish
+			resourceLast[item.Resource] = id
+		}
+		result.Predecessors[id] = preds
+
+		result.Slots[id] = Slot{Start: start, Finish: finish}
+		if finish > result.End {
+			result.End = finish
+		}
+	}
+
+	return result, nil
+}
+
+// topoSort orders items so prerequisites come first, keeping the input order
+// among items that are ready at the same time
+func topoSort(items []Item, byID map[string]Item) ([]string, error) {
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[item.ID] = i
+	}
+
+	inDegree := make(map[string]int, len(items))
+	dependents := make(map[string][]string)
+	for _, item := range items {
+		for _, prereq := range item.Prereqs {
+			if _, ok := byID[prereq]; !ok {
+				continue
+			}
+			inDegree[item.ID]++
+			dependents[prereq] = append(dependents[prereq], item.ID)
+		}
+	}
+
+	ready := &readyQueue{}
+	for i, item := range items {
+		if inDegree[item.ID] == 0 {
+			*ready = append(*ready, i)
+		}
+	}
+	heap.Init(ready)
+
+	order := make([]string, 0, len(items))
+	for ready.Len() > 0 {
+		id := items[heap.Pop(ready).(int)].ID
+		order = append(order, id)
+
+		for _, dependent := range dependents[id] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				heap.Push(ready, index[dependent])
+			}
+		}
+	}
+
+	if len(order) < len(items) {
+		var cyclic []string
+		for _, item := range items {
+			if inDegree[item.ID] > 0 {
+				cyclic = append(cyclic, item.ID)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle between %s", strings.Join(cyclic, ", "))
+	}
+
+	return order, nil
+}
+
+// readyQueue is a min-heap of the input indexes of items whose prerequisites
+// are all ordered
+type readyQueue []int
+
+func (q readyQueue) Len() int           { return len(q) }
+func (q readyQueue) Less(i, j int) bool { return q[i] < q[j] }
+func (q readyQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *readyQueue) Push(x any)        { *q = append(*q, x.(int)) }
+
+func (q *readyQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
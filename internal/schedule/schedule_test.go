@@ -0,0 +1,216 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+func TestSchedule_DependenciesAndResources(t *testing.T) {
+	items := []Item{
+		{ID: "A", Effort: 16, Resource: "alice"},
+		{ID: "B", Effort: 8, Resource: "bob", Prereqs: []string{"A"}},
+		{ID: "C", Effort: 8, Resource: "alice"},
+		{ID: "D", Effort: 4, Prereqs: []string{"MISSING"}},
+	}
+
+	// Monday
+	origin := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	result, err := Schedule(items, origin, DefaultCalendar())
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	expected := map[string]Slot{
+		"A": {Start: 0, Finish: 16},
+		"B": {Start: 16, Finish: 24}, // Waits for A
+		"C": {Start: 16, Finish: 24}, // Waits for alice
+		"D": {Start: 0, Finish: 4},   // Unknown prerequisites are ignored
+	}
+	for id, want := range expected {
+		if got := result.Slots[id]; got != want {
+			t.Errorf("Slot for %s = %+v, want %+v", id, got, want)
+		}
+	}
+
+	if got := result.FinishDate("A"); !got.Equal(time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("A should finish on Tuesday, got %s", got)
+	}
+	if got := result.EndDate(); !got.Equal(time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Plan should end on Wednesday, got %s", got)
+	}
+}
+
+func TestSchedule_SkipsWeekends(t *testing.T) {
+	// Friday
+	origin := time.Date(2025, 6, 6, 0, 0, 0, 0, time.UTC)
+	result, err := Schedule([]Item{{ID: "A", Effort: 16}}, origin, DefaultCalendar())
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	if got := result.EndDate(); !got.Equal(time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Two days of work starting Friday should end Monday, got %s", got)
+	}
+}
+
+func TestSchedule_Cycle(t *testing.T) {
+	items := []Item{
+		{ID: "A", Effort: 8, Prereqs: []string{"B"}},
+		{ID: "B", Effort: 8, Prereqs: []string{"A"}},
+	}
+	if _, err := Schedule(items, time.Now(), DefaultCalendar()); err == nil {
+		t.Error("Expected an error for a dependency cycle")
+	}
+}
+
+func TestTopoSort_KeepsInputOrder(t *testing.T) {
+	items := []Item{
+		{ID: "E", Prereqs: []string{"D"}},
+		{ID: "A"},
+		{ID: "D", Prereqs: []string{"B"}},
+		{ID: "C", Prereqs: []string{"A"}},
+		{ID: "B"},
+		{ID: "F", Prereqs: []string{"A", "B"}},
+	}
+	byID := make(map[string]Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	order, err := topoSort(items, byID)
+	if err != nil {
+		t.Fatalf("topoSort failed: %v", err)
+	}
+	// The earliest ready item goes next: C, freed by A, before B, and E,
+	// freed by D, before F
+	want := "A C B D E F"
+	if got := fmt.Sprint(order); got != "["+want+"]" {
+		t.Errorf("topoSort = %s, want [%s]", got, want)
+	}
+}
+
+func TestTopoSort_LongChain(t *testing.T) {
+	// Each item depends on the next, so only one is ever ready
+	const n = 10000
+	items := make([]Item, n)
+	byID := make(map[string]Item, n)
+	for i := range items {
+		items[i] = Item{ID: fmt.Sprint(i)}
+		if i+1 < n {
+			items[i].Prereqs = []string{fmt.Sprint(i + 1)}
+		}
+		byID[items[i].ID] = items[i]
+	}
+	order, err := topoSort(items, byID)
+	if err != nil {
+		t.Fatalf("topoSort failed: %v", err)
+	}
+	for i, id := range order {
+		if id != fmt.Sprint(n-1-i) {
+			t.Fatalf("order[%d] = %s, want %d", i, id, n-1-i)
+		}
+	}
+}
+
+func TestHorizonDate(t *testing.T) {
+	origin := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"10d": time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC),
+		"2w":  time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC),
+		"6m":  time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC),
+		"2q":  time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC),
+	}
+	for spec, want := range tests {
+		if got, err := HorizonDate(spec, origin); err != nil || !got.Equal(want) {
+			t.Errorf("HorizonDate(%q) = %s, %v, want %s", spec, got, err, want)
+		}
+	}
+	if _, err := HorizonDate("2y", origin); err == nil {
+		t.Error("HorizonDate should reject unknown units")
+	}
+}
+
+func TestRollingWave(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "NOW-1", EpicLink: "EPIC-1", Assignee: "alice", EffortDays: 10},
+		{Key: "LATER-1", EpicLink: "EPIC-2", Assignee: "alice", EffortDays: 2, DependencyKeys: []string{"NOW-1"}},
+		{Key: "LATER-2", EpicLink: "EPIC-2", Assignee: "bob", EffortDays: 3, DependencyKeys: []string{"LATER-1", "NOW-1"}},
+		{Key: "LATER-3", EpicLink: "EPIC-2", StatusCategory: "done", EffortDays: 5},
+		{Key: "AFTER-1", Assignee: "carol", DependencyKeys: []string{"LATER-2"}},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-2": {Key: "EPIC-2", Summary: "Far future", Link: "https://jira/rest/api/2/issue/2"},
+	}
+
+	// Monday; NOW-1 takes two weeks, so EPIC-2 starts after a one week horizon
+	origin := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	got, collapsed, err := RollingWave(tickets, epics, origin, origin.AddDate(0, 0, 7), DefaultCalendar())
+	if err != nil {
+		t.Fatalf("RollingWave failed: %v", err)
+	}
+	if len(collapsed) != 1 || collapsed[0] != "EPIC-2" {
+		t.Fatalf("Collapsed %v, want EPIC-2", collapsed)
+	}
+
+	var keys []string
+	for _, t := range got {
+		keys = append(keys, t.Key)
+	}
+	if want := "[NOW-1 EPIC-2 AFTER-1]"; fmt.Sprint(keys) != want {
+		t.Fatalf("Keys = %v, want %s", keys, want)
+	}
+	summary := got[1]
+	if summary.Summary != "Far future" || summary.EffortDays != 5 || summary.EpicLink != "EPIC-2" || summary.Link == "" {
+		t.Errorf("Summary = %+v, want the epic with its 5 open days", summary)
+	}
+	if summary.Assignee != "" {
+		t.Errorf("Work shared by several people should be unassigned, got %q", summary.Assignee)
+	}
+	if fmt.Sprint(summary.DependencyKeys) != "[NOW-1]" {
+		t.Errorf("Summary depends on %v, want only the outside work", summary.DependencyKeys)
+	}
+	if fmt.Sprint(got[2].DependencyKeys) != "[EPIC-2]" {
+		t.Errorf("AFTER-1 depends on %v, want the summary", got[2].DependencyKeys)
+	}
+
+	// With a horizon past all work nothing changes
+	got, collapsed, err = RollingWave(tickets, epics, origin, origin.AddDate(1, 0, 0), DefaultCalendar())
+	if err != nil || len(collapsed) != 0 || len(got) != len(tickets) {
+		t.Errorf("Expected no collapsing within the horizon, got %v, %v", collapsed, err)
+	}
+}
+
+func TestResult_FloatsAndCriticalPath(t *testing.T) {
+	items := []Item{
+		{ID: "A", Effort: 16, Resource: "alice"},
+		{ID: "B", Effort: 8, Resource: "bob", Prereqs: []string{"A"}},
+		{ID: "C", Effort: 8, Resource: "alice"},
+		{ID: "D", Effort: 4, Resource: "carol"},
+		{ID: "E", Effort: 8, Resource: "dave", Prereqs: []string{"D"}},
+	}
+	result, err := Schedule(items, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), DefaultCalendar())
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	// A and then B or C (alice's next task) run until hour 24
+	expected := map[string]Float{
+		"A": {Total: 0, Free: 0},
+		"B": {Total: 0, Free: 0},
+		"C": {Total: 0, Free: 0},
+		"D": {Total: 12, Free: 0},  // E follows it directly
+		"E": {Total: 12, Free: 12}, // Nothing follows
+	}
+	floats := result.Floats()
+	for id, want := range expected {
+		if got := floats[id]; got != want {
+			t.Errorf("Float of %s = %+v, want %+v", id, got, want)
+		}
+	}
+
+	if got := fmt.Sprint(result.CriticalPath()); got != "[A C]" {
+		t.Errorf("CriticalPath = %s, want [A C]", got)
+	}
+}
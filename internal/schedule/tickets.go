@@ -0,0 +1,33 @@
+package schedule
+
+import "github.com/gunnarrb/jql-to-plan/internal/jira"
+
+// DefaultEffortHours is used for tickets without an estimate, matching the serializer
+const DefaultEffortHours = 8
+
+// EffortHours returns the planned effort of a ticket in working hours
+func EffortHours(t jira.Ticket, hoursPerDay float64) float64 {
+	if t.EffortDays > 0 {
+		return t.EffortDays * hoursPerDay
+	}
+	return DefaultEffortHours
+}
+
+// ItemsFromTickets converts tickets into schedule items keyed by Jira key.
+// Completed tickets keep their place in the dependency graph with no remaining effort.
+func ItemsFromTickets(tickets []jira.Ticket, cal Calendar) []Item {
+	items := make([]Item, 0, len(tickets))
+	for _, t := range tickets {
+		effort := EffortHours(t, cal.HoursPerDay)
+		if t.IsDone() {
+			effort = 0
+		}
+		items = append(items, Item{
+			ID:       t.Key,
+			Effort:   effort,
+			Resource: t.Assignee,
+			Prereqs:  t.DependencyKeys,
+		})
+	}
+	return items
+}
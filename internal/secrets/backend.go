@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/awssig"
+)
+
+// References to secrets kept outside the configuration file
+const (
+	vaultPrefix = "vault://"  // vault://<path>#<key>, the API path of a KV secret
+	awsSMPrefix = "aws-sm://" // aws-sm://<name or ARN>[#<key>]
+)
+
+// fetchTimeout bounds fetching a secret from a backend
+const fetchTimeout = 30 * time.Second
+
+// IsReference reports whether value refers to a secret in Vault or AWS
+// Secrets Manager rather than holding it
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, vaultPrefix) || strings.HasPrefix(value, awsSMPrefix)
+}
+
+// Resolve returns the secret value stands for: references are fetched from
+// HashiCorp Vault or AWS Secrets Manager, values produced by Encrypt are
+// decrypted and all others are returned unchanged
+func Resolve(ctx context.Context, value string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	switch {
+	case strings.HasPrefix(value, vaultPrefix):
+		path, key, _ := strings.Cut(strings.TrimPrefix(value, vaultPrefix), "#")
+		if path == "" || key == "" {
+			return "", fmt.Errorf("%q must have the form vault://<path>#<key>", value)
+		}
+		return fetchVault(ctx, path, key)
+	case strings.HasPrefix(value, awsSMPrefix):
+		name, key, _ := strings.Cut(strings.TrimPrefix(value, awsSMPrefix), "#")
+		if name == "" {
+			return "", fmt.Errorf("%q must have the form aws-sm://<name>[#<key>]", value)
+		}
+		return fetchAWSSecret(ctx, name, key)
+	}
+	return Decrypt(value)
+}
+
+// fetchVault reads key from the secret at path, using VAULT_ADDR and
+// VAULT_TOKEN (or the token file written by 'vault login'). path is the API
+// path, which for the KV version 2 engine includes "data", e.g.
+// secret/data/jira.
+func fetchVault(ctx context.Context, path, key string) (string, error) {
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		addr = "https://127.0.0.1:8200"
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if token == "" {
+		return "", errors.New("vault: VAULT_TOKEN is not set and there is no ~/.vault-token")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := doJSON(req, &secret); err != nil {
+		return "", fmt.Errorf("vault: reading %s: %w", path, err)
+	}
+
+	// KV version 2 nests the values under data.data, next to metadata
+	fields := secret.Data
+	if nested, ok := fields["data"]; ok {
+		if _, versioned := fields["metadata"]; versioned {
+			fields = nil
+			if err := json.Unmarshal(nested, &fields); err != nil {
+				return "", fmt.Errorf("vault: reading %s: %w", path, err)
+			}
+		}
+	}
+	return stringField(fields, key, "vault: "+path)
+}
+
+// fetchAWSSecret reads the secret name, an ID or ARN, from AWS Secrets
+// Manager using the standard AWS environment variables. With key, the secret
+// must be a JSON object and the key's value is returned.
+func fetchAWSSecret(ctx context.Context, name, key string) (string, error) {
+	creds, err := awssig.CredentialsFromEnv()
+	if err != nil {
+		return "", fmt.Errorf("aws-sm: %w", err)
+	}
+	region := awssig.RegionFromEnv()
+	// arn:aws:secretsmanager:<region>:<account>:secret:<name>
+	if parts := strings.Split(name, ":"); len(parts) > 3 && parts[0] == "arn" {
+		region = parts[3]
+	}
+	if region == "" {
+		return "", errors.New("aws-sm: set AWS_REGION or use the secret's ARN")
+	}
+
+	endpoint := strings.TrimRight(os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER"), "/")
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("aws-sm: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	awssig.Sign(req, body, region, "secretsmanager", creds, time.Now())
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := doJSON(req, &secret); err != nil {
+		return "", fmt.Errorf("aws-sm: reading %s: %w", name, err)
+	}
+	if secret.SecretString == nil {
+		return "", fmt.Errorf("aws-sm: %s is a binary secret; store the PAT as a string", name)
+	}
+	if key == "" {
+		return *secret.SecretString, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*secret.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("aws-sm: %s is not a JSON object, so #%s cannot be read from it", name, key)
+	}
+	return stringField(fields, key, "aws-sm: "+name)
+}
+
+// doJSON performs req and decodes a successful JSON response into v
+func doJSON(req *http.Request, v any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// stringField returns the string value of key in fields
+func stringField(fields map[string]json.RawMessage, key, source string) (string, error) {
+	raw, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("%s has no key %q", source, key)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s: %q is not a string", source, key)
+	}
+	return value, nil
+}
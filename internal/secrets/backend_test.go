@@ -0,0 +1,235 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// vaultServer serves the secret at /v1/secret/... as body, checking the token
+func vaultServer(t *testing.T, status int, body string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			t.Errorf("X-Vault-Token = %q, want vault-token", r.Header.Get("X-Vault-Token"))
+		}
+		if r.Header.Get("X-Vault-Namespace") != "team" {
+			t.Errorf("X-Vault-Namespace = %q, want team", r.Header.Get("X-Vault-Namespace"))
+		}
+		if !strings.HasPrefix(r.URL.Path, "/v1/secret/") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("VAULT_ADDR", server.URL+"/")
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	t.Setenv("VAULT_NAMESPACE", "team")
+}
+
+func TestResolve_Vault(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		status int
+		body   string
+		want   string
+		err    string
+	}{
+		{
+			name:   "KV version 1",
+			ref:    "vault://secret/jira#pat",
+			status: http.StatusOK,
+			body:   `{"data": {"pat": "v1-pat", "user": "planner"}}`,
+			want:   "v1-pat",
+		},
+		{
+			name:   "KV version 2",
+			ref:    "vault://secret/data/jira#pat",
+			status: http.StatusOK,
+			body:   `{"data": {"data": {"pat": "v2-pat"}, "metadata": {"version": 3}}}`,
+			want:   "v2-pat",
+		},
+		{
+			name:   "KV version 1 secret with a data key",
+			ref:    "vault://secret/jira#data",
+			status: http.StatusOK,
+			body:   `{"data": {"data": "not nested"}}`,
+			want:   "not nested",
+		},
+		{
+			name:   "missing key",
+			ref:    "vault://secret/data/jira#token",
+			status: http.StatusOK,
+			body:   `{"data": {"data": {"pat": "v2-pat"}, "metadata": {}}}`,
+			err:    `vault: secret/data/jira has no key "token"`,
+		},
+		{
+			name:   "not a string",
+			ref:    "vault://secret/jira#pat",
+			status: http.StatusOK,
+			body:   `{"data": {"pat": 42}}`,
+			err:    `"pat" is not a string`,
+		},
+		{
+			name:   "permission denied",
+			ref:    "vault://secret/jira#pat",
+			status: http.StatusForbidden,
+			body:   `{"errors": ["permission denied"]}`,
+			err:    `vault: reading secret/jira: HTTP 403: {"errors": ["permission denied"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vaultServer(t, tt.status, tt.body)
+			got, err := Resolve(context.Background(), tt.ref)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Resolve = %q, %v, want an error with %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_VaultWithoutToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := Resolve(context.Background(), "vault://secret/jira#pat"); err == nil || !strings.Contains(err.Error(), "VAULT_TOKEN is not set") {
+		t.Errorf("expected a missing token error, got %v", err)
+	}
+}
+
+func TestResolve_InvalidReferences(t *testing.T) {
+	for _, ref := range []string{"vault://secret/jira", "vault://#pat", "aws-sm://"} {
+		if _, err := Resolve(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "must have the form") {
+			t.Errorf("Resolve(%q): expected a form error, got %v", ref, err)
+		}
+	}
+}
+
+// awsServer is a fake Secrets Manager endpoint returning secretString for
+// every GetSecretValue request, which it checks is signed
+func awsServer(t *testing.T, status int, secretString string) *[]string {
+	t.Helper()
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			t.Errorf("unexpected request %s with target %q", r.Method, r.Header.Get("X-Amz-Target"))
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") {
+			t.Errorf("unexpected Authorization %q", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session-token" {
+			t.Errorf("X-Amz-Security-Token = %q, want session-token", r.Header.Get("X-Amz-Security-Token"))
+		}
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		ids = append(ids, req.SecretId)
+
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			io.WriteString(w, `{"__type": "ResourceNotFoundException"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Name": req.SecretId, "SecretString": secretString})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	t.Setenv("AWS_SESSION_TOKEN", "session-token")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	return &ids
+}
+
+func TestResolve_AWSSecretsManager(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		status int
+		secret string
+		want   string
+		err    string
+	}{
+		{
+			name:   "plain secret",
+			ref:    "aws-sm://jira-pat",
+			status: http.StatusOK,
+			secret: "sm-pat",
+			want:   "sm-pat",
+		},
+		{
+			name:   "key of a JSON secret",
+			ref:    "aws-sm://jira#pat",
+			status: http.StatusOK,
+			secret: `{"pat": "json-pat", "user": "planner"}`,
+			want:   "json-pat",
+		},
+		{
+			name:   "secret ARN",
+			ref:    "aws-sm://arn:aws:secretsmanager:eu-west-1:123456789012:secret:jira-AbCdEf",
+			status: http.StatusOK,
+			secret: "arn-pat",
+			want:   "arn-pat",
+		},
+		{
+			name:   "missing key",
+			ref:    "aws-sm://jira#token",
+			status: http.StatusOK,
+			secret: `{"pat": "json-pat"}`,
+			err:    `aws-sm: jira has no key "token"`,
+		},
+		{
+			name:   "key of a plain secret",
+			ref:    "aws-sm://jira-pat#pat",
+			status: http.StatusOK,
+			secret: "sm-pat",
+			err:    "jira-pat is not a JSON object",
+		},
+		{
+			name:   "not found",
+			ref:    "aws-sm://missing",
+			status: http.StatusBadRequest,
+			err:    `aws-sm: reading missing: HTTP 400: {"__type": "ResourceNotFoundException"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := awsServer(t, tt.status, tt.secret)
+			got, err := Resolve(context.Background(), tt.ref)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Resolve = %q, %v, want an error with %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve = %q, %v, want %q", got, err, tt.want)
+			}
+			name, _, _ := strings.Cut(strings.TrimPrefix(tt.ref, awsSMPrefix), "#")
+			if len(*ids) != 1 || (*ids)[0] != name {
+				t.Errorf("requested %v, want [%s]", *ids, name)
+			}
+		})
+	}
+}
+
+func TestResolve_AWSWithoutRegion(t *testing.T) {
+	awsServer(t, http.StatusOK, "sm-pat")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	if _, err := Resolve(context.Background(), "aws-sm://jira-pat"); err == nil || !strings.Contains(err.Error(), "set AWS_REGION") {
+		t.Errorf("expected a missing region error, got %v", err)
+	}
+}
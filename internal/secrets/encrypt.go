@@ -0,0 +1,169 @@
+// Package secrets resolves sensitive configuration values such as the Jira PAT.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Encrypted values are stored as "enc:<source>:<base64(salt|nonce|ciphertext)>"
+// where source is "pass" (passphrase from the environment) or "key" (machine key file).
+const (
+	encryptedPrefix  = "enc:"
+	sourcePassphrase = "pass"
+	sourceMachineKey = "key"
+
+	// PassphraseEnv names the environment variable holding the encryption passphrase
+	PassphraseEnv = "JQL_TO_PLAN_PASSPHRASE"
+
+	saltSize       = 16
+	keySize        = 32
+	pbkdf2Rounds   = 600000
+	machineKeyFile = ".jql-to-plan.key"
+)
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt seals plaintext with AES-256-GCM. The key is derived from the
+// passphrase in JQL_TO_PLAN_PASSPHRASE if set, otherwise from a per-machine
+// key file in the user's home directory, which is created on first use.
+func Encrypt(plaintext string) (string, error) {
+	source := sourceMachineKey
+	if os.Getenv(PassphraseEnv) != "" {
+		source = sourcePassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := deriveKey(source, salt, true)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+	payload := append(append(salt, nonce...), sealed...)
+
+	return encryptedPrefix + source + ":" + base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// Decrypt reverses Encrypt. Values that are not encrypted are returned unchanged.
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	source, encoded, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok || (source != sourcePassphrase && source != sourceMachineKey) {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if len(payload) < saltSize {
+		return "", errors.New("malformed encrypted value: too short")
+	}
+
+	salt := payload[:saltSize]
+	key, err := deriveKey(source, salt, false)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	rest := payload[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value: too short")
+	}
+	plaintext, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		if source == sourcePassphrase {
+			return "", fmt.Errorf("decryption failed: wrong %s?", PassphraseEnv)
+		}
+		return "", fmt.Errorf("decryption failed: was the value encrypted on another machine?")
+	}
+
+	return string(plaintext), nil
+}
+
+// deriveKey stretches the passphrase or machine key into an AES key
+func deriveKey(source string, salt []byte, create bool) ([]byte, error) {
+	var secret string
+	switch source {
+	case sourcePassphrase:
+		secret = os.Getenv(PassphraseEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("value is passphrase-encrypted but %s is not set", PassphraseEnv)
+		}
+	default:
+		machineKey, err := loadMachineKey(create)
+		if err != nil {
+			return nil, err
+		}
+		secret = string(machineKey)
+	}
+
+	return pbkdf2.Key(sha256.New, secret, salt, pbkdf2Rounds, keySize)
+}
+
+// loadMachineKey reads the per-machine key file, creating it if requested
+func loadMachineKey(create bool) ([]byte, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not find user home directory: %w", err)
+	}
+	path := filepath.Join(home, machineKeyFile)
+
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("reading machine key %s: %w", path, err)
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("writing machine key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolate points the home directory, and so the machine key, at a new
+// directory and clears the passphrase
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(PassphraseEnv, "")
+	return home
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		source     string
+	}{
+		{"passphrase", "correct horse battery staple", sourcePassphrase},
+		{"machine key", "", sourceMachineKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolate(t)
+			t.Setenv(PassphraseEnv, tt.passphrase)
+
+			encrypted, err := Encrypt("my-pat")
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if !IsEncrypted(encrypted) || !strings.HasPrefix(encrypted, encryptedPrefix+tt.source+":") {
+				t.Errorf("Encrypt = %q, want an %s%s: value", encrypted, encryptedPrefix, tt.source)
+			}
+			if strings.Contains(encrypted, "my-pat") {
+				t.Errorf("Encrypt leaks the plaintext: %q", encrypted)
+			}
+
+			// A new salt and nonce every time
+			again, err := Encrypt("my-pat")
+			if err != nil || again == encrypted {
+				t.Errorf("expected a different value for the same plaintext, got %q, %v", again, err)
+			}
+
+			for _, value := range []string{encrypted, again} {
+				if got, err := Decrypt(value); err != nil || got != "my-pat" {
+					t.Errorf("Decrypt = %q, %v, want my-pat", got, err)
+				}
+			}
+
+			_, err = os.Stat(filepath.Join(home, machineKeyFile))
+			if created := err == nil; created != (tt.source == sourceMachineKey) {
+				t.Errorf("machine key file created = %v, want %v", created, tt.source == sourceMachineKey)
+			}
+		})
+	}
+}
+
+func TestDecrypt_Plaintext(t *testing.T) {
+	isolate(t)
+	if got, err := Decrypt("plain-pat"); err != nil || got != "plain-pat" {
+		t.Errorf("Decrypt = %q, %v, want the value unchanged", got, err)
+	}
+}
+
+func TestDecrypt_WrongPassphrase(t *testing.T) {
+	isolate(t)
+	t.Setenv(PassphraseEnv, "right")
+	encrypted, err := Encrypt("my-pat")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	t.Setenv(PassphraseEnv, "wrong")
+	if _, err := Decrypt(encrypted); err == nil || !strings.Contains(err.Error(), "wrong "+PassphraseEnv) {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+	t.Setenv(PassphraseEnv, "")
+	if _, err := Decrypt(encrypted); err == nil || !strings.Contains(err.Error(), PassphraseEnv+" is not set") {
+		t.Errorf("expected a missing passphrase error, got %v", err)
+	}
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	isolate(t)
+	t.Setenv(PassphraseEnv, "passphrase")
+	encrypted, err := Encrypt("my-pat")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	prefix := encryptedPrefix + sourcePassphrase + ":"
+	payload, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flipping a bit anywhere fails authentication: the salt changes the key,
+	// the nonce and ciphertext fail the GCM tag
+	for _, i := range []int{0, saltSize, len(payload) - 1} {
+		tampered := append([]byte(nil), payload...)
+		tampered[i] ^= 1
+		if _, err := Decrypt(prefix + base64.StdEncoding.EncodeToString(tampered)); err == nil || !strings.Contains(err.Error(), "decryption failed") {
+			t.Errorf("byte %d tampered: expected decryption to fail, got %v", i, err)
+		}
+	}
+
+	for _, value := range []string{
+		encryptedPrefix + "other:" + base64.StdEncoding.EncodeToString(payload),
+		prefix + "not base64!",
+		prefix + base64.StdEncoding.EncodeToString(payload[:saltSize+4]),
+	} {
+		if _, err := Decrypt(value); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("Decrypt(%q): expected a malformed value error, got %v", value, err)
+		}
+	}
+}
+
+func TestDecrypt_MissingMachineKey(t *testing.T) {
+	home := isolate(t)
+	encrypted, err := Encrypt("my-pat")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	// Decrypting must not create a new key, which could never decrypt it
+	keyFile := filepath.Join(home, machineKeyFile)
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(encrypted); err == nil || !strings.Contains(err.Error(), "reading machine key") {
+		t.Errorf("expected a missing machine key error, got %v", err)
+	}
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Errorf("expected no machine key to be created, got %v", err)
+	}
+
+	// A different machine key fails like another machine would
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("k", keySize)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(encrypted); err == nil || !strings.Contains(err.Error(), "another machine") {
+		t.Errorf("expected a different machine key to fail, got %v", err)
+	}
+}
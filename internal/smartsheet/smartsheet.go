@@ -0,0 +1,292 @@
+// Package smartsheet exports tickets as an Excel workbook in the layout
+// Smartsheet imports as a project sheet: one row per task, indented under its
+// epic and parent, with durations, dates and predecessors by row number.
+package smartsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// Header is the first row of the sheet. Smartsheet recognizes Duration,
+// Start, Finish, Predecessors, Assigned To and % Complete as the project
+// columns once dependencies are enabled in the project settings.
+var Header = []string{"Task Name", "Duration", "Start", "Finish", "Predecessors", "Assigned To", "% Complete", "Status", "Jira Key", "Link"}
+
+// Cell styles, see styles
+const (
+	styleDate    = 1
+	stylePercent = 2
+	styleIndent  = 3 // Indent level 1; deeper levels follow
+)
+
+// row is a sheet row before it is written
+type row struct {
+	level   int // Indentation, 0 for top-level rows
+	ticket  jira.Ticket
+	parent  bool     // Rolls up its children, so Smartsheet computes its dates
+	prereqs []string // Keys of the rows that must finish first
+}
+
+// Write renders one row per epic with its tickets indented below, sub-tasks
+// indented below their parent, and tickets without an epic last. Open work
+// is given the scheduled start and finish; Smartsheet recomputes them from
+// the durations and predecessors once dependencies are enabled.
+func Write(w io.Writer, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket, origin time.Time, cal schedule.Calendar) error {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return err
+	}
+	rows := outline(tickets, epics)
+
+	// Smartsheet predecessors are row numbers, counting from the first row after the header
+	rowNumbers := make(map[string]int, len(rows))
+	for i, r := range rows {
+		rowNumbers[r.ticket.Key] = i + 1
+	}
+
+	var sheet bytes.Buffer
+	maxLevel := 0
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeRow(&sheet, 1, 0, stringCells(Header))
+	for i, r := range rows {
+		maxLevel = max(maxLevel, r.level)
+		t := r.ticket
+		nameStyle := 0
+		if r.level > 0 {
+			nameStyle = styleIndent + r.level - 1
+		}
+		cells := []cell{{value: t.Summary, style: nameStyle}}
+		if r.parent {
+			cells = append(cells, cell{}, cell{}, cell{})
+		} else {
+			days := schedule.EffortHours(t, cal.HoursPerDay) / cal.HoursPerDay
+			cells = append(cells, cell{value: strconv.FormatFloat(days, 'f', -1, 64) + "d"})
+			switch {
+			case !t.IsDone():
+				cells = append(cells, dateCell(result.StartDate(t.Key)), dateCell(result.FinishDate(t.Key)))
+			case !t.ActualStart.IsZero():
+				cells = append(cells, dateCell(t.ActualStart), cell{})
+			default:
+				cells = append(cells, cell{}, cell{})
+			}
+		}
+
+		var predecessors []string
+		for _, key := range r.prereqs {
+			if n, ok := rowNumbers[key]; ok && key != t.Key {
+				predecessors = append(predecessors, strconv.Itoa(n))
+			}
+		}
+		complete := 0.0
+		if t.IsDone() {
+			complete = 1
+		}
+		cells = append(cells,
+			cell{value: strings.Join(predecessors, ", ")},
+			cell{value: t.Assignee},
+			cell{number: true, value: strconv.FormatFloat(complete, 'f', -1, 64), style: stylePercent},
+			cell{value: t.Status},
+			cell{value: t.Key},
+			cell{value: t.Link},
+		)
+		writeRow(&sheet, i+2, r.level, cells)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	zw := zip.NewWriter(w)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, escape(sheetName(projectName)))},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles(maxLevel)},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// outline orders the tickets into rows: each epic followed by its tickets,
+// then the tickets without an epic, with sub-tasks below their parent
+func outline(tickets []jira.Ticket, epics map[string]jira.Ticket) []row {
+	keys := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		keys[t.Key] = true
+	}
+	children := make(map[string][]jira.Ticket)
+	var epicOrder []string
+	byEpic := make(map[string][]jira.Ticket)
+	for _, t := range tickets {
+		if t.Parent != "" && keys[t.Parent] {
+			children[t.Parent] = append(children[t.Parent], t)
+			continue
+		}
+		if _, seen := byEpic[t.EpicLink]; !seen && t.EpicLink != "" {
+			epicOrder = append(epicOrder, t.EpicLink)
+		}
+		byEpic[t.EpicLink] = append(byEpic[t.EpicLink], t)
+	}
+
+	var rows []row
+	var add func(t jira.Ticket, level int)
+	add = func(t jira.Ticket, level int) {
+		rows = append(rows, row{level: level, ticket: t, parent: len(children[t.Key]) > 0, prereqs: t.DependencyKeys})
+		for _, child := range children[t.Key] {
+			add(child, level+1)
+		}
+	}
+	for _, epicKey := range epicOrder {
+		epic, ok := epics[epicKey]
+		if !ok {
+			epic = jira.Ticket{Key: epicKey}
+		}
+		if epic.Summary == "" {
+			epic.Summary = epicKey
+		}
+		// An epic waits for the epics it depends on
+		rows = append(rows, row{ticket: epic, parent: true, prereqs: epic.DependencyKeys})
+		for _, t := range byEpic[epicKey] {
+			add(t, 1)
+		}
+	}
+	for _, t := range byEpic[""] {
+		add(t, 0)
+	}
+	return rows
+}
+
+// cell is a sheet cell: inline text, or a number when number is set
+type cell struct {
+	value  string
+	number bool
+	style  int
+}
+
+func stringCells(values []string) []cell {
+	cells := make([]cell, len(values))
+	for i, v := range values {
+		cells[i] = cell{value: v}
+	}
+	return cells
+}
+
+// dateCell returns a date cell, as Excel counts days since 1899-12-30
+func dateCell(d time.Time) cell {
+	day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	serial := int(day.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	return cell{number: true, value: strconv.Itoa(serial), style: styleDate}
+}
+
+// writeRow writes a row of cells; level groups it in the Excel outline
+func writeRow(b *bytes.Buffer, n, level int, cells []cell) {
+	if level > 0 {
+		fmt.Fprintf(b, `<row r="%d" outlineLevel="%d">`, n, level)
+	} else {
+		fmt.Fprintf(b, `<row r="%d">`, n)
+	}
+	for i, c := range cells {
+		if c.value == "" {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(n)
+		style := ""
+		if c.style != 0 {
+			style = fmt.Sprintf(` s="%d"`, c.style)
+		}
+		if c.number {
+			fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, style, c.value)
+		} else {
+			fmt.Fprintf(b, `<c r="%s"%s t="inlineStr"><is><t>%s</t></is></c>`, ref, style, escape(c.value))
+		}
+	}
+	b.WriteString(`</row>`)
+}
+
+// columnName returns the letter of a column, counting from 0; the sheet has
+// fewer than 26 columns
+func columnName(i int) string {
+	return string(rune('A' + i))
+}
+
+// sheetName makes a project name a valid worksheet name: at most 31
+// characters, none of them []:*?/\
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Plan"
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// styles returns the stylesheet: the default, a date, a percentage and one
+// indented style per outline level, which Smartsheet imports as hierarchy
+func styles(maxLevel int) string {
+	var xfs strings.Builder
+	xfs.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0"/>`)
+	xfs.WriteString(`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" applyNumberFormat="1"/>`)
+	xfs.WriteString(`<xf numFmtId="9" fontId="0" fillId="0" borderId="0" applyNumberFormat="1"/>`)
+	for level := 1; level <= maxLevel; level++ {
+		fmt.Fprintf(&xfs, `<xf numFmtId="0" fontId="0" fillId="0" borderId="0" applyAlignment="1"><alignment indent="%d"/></xf>`, level)
+	}
+	return xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		fmt.Sprintf(`<cellXfs count="%d">%s</cellXfs>`, styleIndent+maxLevel, xfs.String()) +
+		`</styleSheet>`
+}
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// workbook takes the sheet name
+const workbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
@@ -0,0 +1,62 @@
+package smartsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+func TestWrite(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Build API", Assignee: "Alice", EffortDays: 2, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Docs & examples", Assignee: "Alice", EffortDays: 0.5, EpicLink: "EPIC-1", DependencyKeys: []string{"TASK-1"}},
+		{Key: "TASK-3", Summary: "Review docs", EffortDays: 1, Parent: "TASK-2"},
+		{Key: "TASK-4", Summary: "Old work", StatusCategory: "done", Status: "Done"},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Backend"}}
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Q1: Payments", tickets, epics, monday, schedule.DefaultCalendar()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	files := make(map[string]string)
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Not a zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Q1- Payments"`) {
+		t.Errorf("Expected the sheet named after the project, got %s", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		// The epic row rolls up its tickets
+		`<row r="2"><c r="A2" t="inlineStr"><is><t>Backend</t></is></c><c r="G2" s="2"><v>0</v></c><c r="I2" t="inlineStr"><is><t>EPIC-1</t></is></c></row>`,
+		// 2026-01-05 is Excel day 46027
+		`<row r="3" outlineLevel="1"><c r="A3" s="3" t="inlineStr"><is><t>Build API</t></is></c><c r="B3" t="inlineStr"><is><t>2d</t></is></c><c r="C3" s="1"><v>46027</v></c><c r="D3" s="1"><v>46028</v></c>`,
+		`<t>Docs &amp; examples</t>`,
+		// Predecessors count rows from the first after the header
+		`<c r="E4" t="inlineStr"><is><t>2</t></is></c>`,
+		// Sub-tasks are indented below their parent
+		`<row r="5" outlineLevel="2"><c r="A5" s="4" t="inlineStr"><is><t>Review docs</t></is></c>`,
+		`<row r="6"><c r="A6" t="inlineStr"><is><t>Old work</t></is></c><c r="B6" t="inlineStr"><is><t>1d</t></is></c><c r="G6" s="2"><v>1</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("Sheet lacks %s:\n%s", want, sheet)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// EffortDrift is a ticket whose estimate changed between snapshots
+type EffortDrift struct {
+	Key       string
+	Summary   string
+	OldEffort float64
+	NewEffort float64
+}
+
+// Dependency is a "From depends on To" edge
+type Dependency struct {
+	From string
+	To   string
+}
+
+// Comparison describes how a plan evolved between two snapshots
+type Comparison struct {
+	Old, New *Snapshot
+
+	Added   []jira.Ticket
+	Removed []jira.Ticket
+	Drift   []EffortDrift
+
+	NewDependencies     []Dependency
+	RemovedDependencies []Dependency
+
+	OldEffort float64 // Total effort in days
+	NewEffort float64
+}
+
+// Compare diffs two snapshots by Jira key
+func Compare(older, newer *Snapshot) *Comparison {
+	c := &Comparison{Old: older, New: newer}
+
+	oldByKey := indexTickets(older.Tickets)
+	newByKey := indexTickets(newer.Tickets)
+
+	for _, t := range newer.Tickets {
+		c.NewEffort += t.EffortDays
+		prev, ok := oldByKey[t.Key]
+		if !ok {
+			c.Added = append(c.Added, t)
+			continue
+		}
+		if prev.EffortDays != t.EffortDays {
+			c.Drift = append(c.Drift, EffortDrift{Key: t.Key, Summary: t.Summary, OldEffort: prev.EffortDays, NewEffort: t.EffortDays})
+		}
+	}
+	for _, t := range older.Tickets {
+		c.OldEffort += t.EffortDays
+		if _, ok := newByKey[t.Key]; !ok {
+			c.Removed = append(c.Removed, t)
+		}
+	}
+
+	oldDeps := dependencySet(older.Tickets)
+	newDeps := dependencySet(newer.Tickets)
+	for dep := range newDeps {
+		if !oldDeps[dep] {
+			c.NewDependencies = append(c.NewDependencies, dep)
+		}
+	}
+	for dep := range oldDeps {
+		if !newDeps[dep] {
+			c.RemovedDependencies = append(c.RemovedDependencies, dep)
+		}
+	}
+	sortDependencies(c.NewDependencies)
+	sortDependencies(c.RemovedDependencies)
+
+	return c
+}
+
+// WriteText renders the comparison as a human readable summary
+func (c *Comparison) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("Comparing %s (%s) with %s (%s)\n\n", c.Old.Project, c.Old.TakenAt.Format("2006-01-02 15:04"), c.New.Project, c.New.TakenAt.Format("2006-01-02 15:04"))
+
+	ew.printf("Scope: %d → %d tickets (+%d, -%d)\n", len(c.Old.Tickets), len(c.New.Tickets), len(c.Added), len(c.Removed))
+	ew.printf("Effort: %.1fd → %.1fd (%+.1fd)\n", c.OldEffort, c.NewEffort, c.NewEffort-c.OldEffort)
+
+	if len(c.Added) > 0 {
+		ew.printf("\nAdded tickets:\n")
+		for _, t := range c.Added {
+			ew.printf("  + %s %s (%.1fd)\n", t.Key, t.Summary, t.EffortDays)
+		}
+	}
+	if len(c.Removed) > 0 {
+		ew.printf("\nRemoved tickets:\n")
+		for _, t := range c.Removed {
+			ew.printf("  - %s %s (%.1fd)\n", t.Key, t.Summary, t.EffortDays)
+		}
+	}
+	if len(c.Drift) > 0 {
+		ew.printf("\nEffort drift:\n")
+		for _, d := range c.Drift {
+			ew.printf("  ~ %s %s: %.1fd → %.1fd (%+.1fd)\n", d.Key, d.Summary, d.OldEffort, d.NewEffort, d.NewEffort-d.OldEffort)
+		}
+	}
+	if len(c.NewDependencies) > 0 {
+		ew.printf("\nNew dependencies:\n")
+		for _, d := range c.NewDependencies {
+			ew.printf("  + %s depends on %s\n", d.From, d.To)
+		}
+	}
+	if len(c.RemovedDependencies) > 0 {
+		ew.printf("\nRemoved dependencies:\n")
+		for _, d := range c.RemovedDependencies {
+			ew.printf("  - %s depends on %s\n", d.From, d.To)
+		}
+	}
+
+	return ew.err
+}
+
+func indexTickets(tickets []jira.Ticket) map[string]jira.Ticket {
+	index := make(map[string]jira.Ticket, len(tickets))
+	for _, t := range tickets {
+		index[t.Key] = t
+	}
+	return index
+}
+
+func dependencySet(tickets []jira.Ticket) map[Dependency]bool {
+	set := make(map[Dependency]bool)
+	for _, t := range tickets {
+		for _, dep := range t.DependencyKeys {
+			set[Dependency{From: t.Key, To: dep}] = true
+		}
+	}
+	return set
+}
+
+func sortDependencies(deps []Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].From != deps[j].From {
+			return deps[i].From < deps[j].From
+		}
+		return deps[i].To < deps[j].To
+	})
+}
+
+// errWriter remembers the first write error so output code stays linear
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
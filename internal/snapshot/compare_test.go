@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+func TestCompare(t *testing.T) {
+	older := &Snapshot{
+		Project: "PROJ",
+		TakenAt: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		Tickets: []jira.Ticket{
+			{Key: "PROJ-1", Summary: "Design", EffortDays: 2},
+			{Key: "PROJ-2", Summary: "Build", EffortDays: 3, DependencyKeys: []string{"PROJ-1"}},
+			{Key: "PROJ-3", Summary: "Dropped", EffortDays: 1, DependencyKeys: []string{"PROJ-1"}},
+		},
+	}
+	newer := &Snapshot{
+		Project: "PROJ",
+		TakenAt: time.Date(2026, 3, 9, 9, 30, 0, 0, time.UTC),
+		Tickets: []jira.Ticket{
+			{Key: "PROJ-5", Summary: "Docs", EffortDays: 0.5, DependencyKeys: []string{"PROJ-2"}},
+			{Key: "PROJ-1", Summary: "Design", EffortDays: 2},
+			{Key: "PROJ-2", Summary: "Build", EffortDays: 5, DependencyKeys: []string{"PROJ-4", "PROJ-1"}},
+			{Key: "PROJ-4", Summary: "Review", EffortDays: 1},
+		},
+	}
+
+	const golden = `Comparing PROJ (2026-03-02 09:30) with PROJ (2026-03-09 09:30)
+
+Scope: 3 → 4 tickets (+2, -1)
+Effort: 6.0d → 8.5d (+2.5d)
+
+Added tickets:
+  + PROJ-5 Docs (0.5d)
+  + PROJ-4 Review (1.0d)
+
+Removed tickets:
+  - PROJ-3 Dropped (1.0d)
+
+Effort drift:
+  ~ PROJ-2 Build: 3.0d → 5.0d (+2.0d)
+
+New dependencies:
+  + PROJ-2 depends on PROJ-4
+  + PROJ-5 depends on PROJ-2
+
+Removed dependencies:
+  - PROJ-3 depends on PROJ-1
+`
+	var buf bytes.Buffer
+	if err := Compare(older, newer).WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteText =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
+
+func TestCompare_Unchanged(t *testing.T) {
+	snap := &Snapshot{
+		Project: "PROJ",
+		TakenAt: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		Tickets: []jira.Ticket{{Key: "PROJ-1", EffortDays: 2, DependencyKeys: []string{"PROJ-0"}}},
+	}
+	const golden = `Comparing PROJ (2026-03-02 09:30) with PROJ (2026-03-02 09:30)
+
+Scope: 1 → 1 tickets (+0, -0)
+Effort: 2.0d → 2.0d (+0.0d)
+`
+	var buf bytes.Buffer
+	if err := Compare(snap, snap).WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if buf.String() != golden {
+		t.Errorf("WriteText =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
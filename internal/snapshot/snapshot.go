@@ -0,0 +1,76 @@
+// Package snapshot stores fetched plan state over time and compares snapshots.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+// fileTimeFormat names snapshot files so they sort chronologically
+const fileTimeFormat = "20060102T150405Z"
+
+// Snapshot is the fetched state of a plan at a point in time
+type Snapshot struct {
+	Project string                 `json:"project"`
+	JQL     string                 `json:"jql"`
+	TakenAt time.Time              `json:"taken_at"`
+	Tickets []jira.Ticket          `json:"tickets"`
+	Epics   map[string]jira.Ticket `json:"epics,omitempty"`
+}
+
+// Save writes the snapshot to <dir>/<project>/<timestamp>.json and returns the path
+func Save(dir string, snap *Snapshot) (string, error) {
+	projectDir := filepath.Join(dir, snap.Project)
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return "", fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	path := filepath.Join(projectDir, snap.TakenAt.UTC().Format(fileTimeFormat)+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("writing snapshot: %w", err)
+	}
+	return path, nil
+}
+
+// Load reads a snapshot file
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("decoding snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// List returns the snapshot files of a project, oldest first
+func List(dir, project string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, project))
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			paths = append(paths, filepath.Join(dir, project, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
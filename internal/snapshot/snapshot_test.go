@@ -0,0 +1,86 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+)
+
+func TestSaveListLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	// Saved out of order, and in another time zone, to check List sorts by time
+	taken := []time.Time{
+		base.Add(48 * time.Hour),
+		base,
+		base.Add(24 * time.Hour).In(time.FixedZone("CET", 3600)),
+	}
+	for i, at := range taken {
+		path, err := Save(dir, &Snapshot{
+			Project: "PROJ",
+			JQL:     "project = PROJ",
+			TakenAt: at,
+			Tickets: []jira.Ticket{{Key: "PROJ-1", Summary: "Design", EffortDays: float64(i + 1), DependencyKeys: []string{"PROJ-2"}}},
+			Epics:   map[string]jira.Ticket{"PROJ-10": {Key: "PROJ-10", Summary: "Launch"}},
+		})
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if want := filepath.Join(dir, "PROJ", at.UTC().Format(fileTimeFormat)+".json"); path != want {
+			t.Errorf("Save = %s, want %s", path, want)
+		}
+	}
+	// Other projects and files are left out
+	if _, err := Save(dir, &Snapshot{Project: "OTHER", TakenAt: base}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "PROJ", "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := List(dir, "PROJ")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "PROJ", "20260302T093000Z.json"),
+		filepath.Join(dir, "PROJ", "20260303T093000Z.json"),
+		filepath.Join(dir, "PROJ", "20260304T093000Z.json"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("List = %v, want %v", paths, want)
+	}
+
+	snap, err := Load(paths[1])
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snap.Project != "PROJ" || snap.JQL != "project = PROJ" || !snap.TakenAt.Equal(taken[2]) {
+		t.Errorf("Load = %+v", snap)
+	}
+	wantTickets := []jira.Ticket{{Key: "PROJ-1", Summary: "Design", EffortDays: 3, DependencyKeys: []string{"PROJ-2"}}}
+	if !reflect.DeepEqual(snap.Tickets, wantTickets) || snap.Epics["PROJ-10"].Summary != "Launch" {
+		t.Errorf("Load = %+v and %+v, want the saved tickets and epics", snap.Tickets, snap.Epics)
+	}
+}
+
+func TestList_UnknownProject(t *testing.T) {
+	if _, err := List(t.TempDir(), "NONE"); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an invalid snapshot")
+	}
+}
@@ -0,0 +1,108 @@
+// Package taskpaper exports tickets as a TaskPaper outline that can be pasted
+// into OmniFocus.
+package taskpaper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+const dateFormat = "2006-01-02"
+
+// Write renders one project per epic, plus one named after projectName for
+// tickets without an epic. Each task carries its estimate and, for open work,
+// the scheduled start and finish as @defer and @due dates.
+func Write(w io.Writer, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket, origin time.Time, cal schedule.Calendar) error {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return err
+	}
+
+	// Projects in first-seen order, with tickets without an epic last
+	var order []string
+	byEpic := make(map[string][]jira.Ticket)
+	for _, t := range tickets {
+		if _, seen := byEpic[t.EpicLink]; !seen && t.EpicLink != "" {
+			order = append(order, t.EpicLink)
+		}
+		byEpic[t.EpicLink] = append(byEpic[t.EpicLink], t)
+	}
+	if len(byEpic[""]) > 0 {
+		order = append(order, "")
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, epicKey := range order {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+
+		title := projectName
+		var tags []string
+		if epicKey != "" {
+			epic := epics[epicKey]
+			title = epicKey
+			if epic.Summary != "" {
+				title = epic.Summary
+			}
+			if !epic.DueDate.IsZero() {
+				tags = append(tags, fmt.Sprintf("@due(%s)", epic.DueDate.Format(dateFormat)))
+			}
+		}
+		fmt.Fprintf(bw, "%s:%s\n", clean(title), joinTags(tags))
+
+		for _, t := range byEpic[epicKey] {
+			tags := []string{fmt.Sprintf("@estimate(%s)", formatHours(schedule.EffortHours(t, cal.HoursPerDay)))}
+			if t.IsDone() {
+				tags = append(tags, "@done")
+			} else {
+				tags = append(tags,
+					fmt.Sprintf("@defer(%s)", result.StartDate(t.Key).Format(dateFormat)),
+					fmt.Sprintf("@due(%s)", result.FinishDate(t.Key).Format(dateFormat)))
+			}
+			if t.Flagged {
+				tags = append(tags, "@flagged")
+			}
+
+			fmt.Fprintf(bw, "\t- %s %s%s\n", t.Key, clean(t.Summary), joinTags(tags))
+			if t.Link != "" {
+				fmt.Fprintf(bw, "\t\t%s\n", t.Link)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// clean keeps text on one line and stops it from being parsed as tags or a project
+func clean(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.ReplaceAll(s, "@", "(at)")
+	return strings.TrimRight(s, ":")
+}
+
+func joinTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " " + strings.Join(tags, " ")
+}
+
+// formatHours renders an estimate the way OmniFocus reads it, e.g. 16h or 1h30m
+func formatHours(hours float64) string {
+	minutes := int(hours*60 + 0.5)
+	if minutes%60 == 0 {
+		return strconv.Itoa(minutes/60) + "h"
+	}
+	if minutes < 60 {
+		return strconv.Itoa(minutes) + "m"
+	}
+	return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+}
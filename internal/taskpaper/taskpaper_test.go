@@ -0,0 +1,43 @@
+package taskpaper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+func TestWrite(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Build @api", Assignee: "Alice", EffortDays: 2, EpicLink: "EPIC-1", Link: "https://jira.example.com/browse/TASK-1"},
+		{Key: "TASK-2", Summary: "Write docs", Assignee: "Alice", EffortDays: 0.5, EpicLink: "EPIC-1", DependencyKeys: []string{"TASK-1"}},
+		{Key: "TASK-3", Summary: "Old work", StatusCategory: "done"},
+	}
+	epics := map[string]jira.Ticket{
+		"EPIC-1": {Key: "EPIC-1", Summary: "Backend", DueDate: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)},
+	}
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Q1", tickets, epics, monday, schedule.DefaultCalendar()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	want := `Backend: @due(2026-01-09)
+	- TASK-1 Build (at)api @estimate(16h) @defer(2026-01-05) @due(2026-01-06)
+		https://jira.example.com/browse/TASK-1
+	- TASK-2 Write docs @estimate(4h) @defer(2026-01-07) @due(2026-01-07)
+
+Q1:
+	- TASK-3 Old work @estimate(8h) @done
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected outline:\n%s\nwant:\n%s", got, want)
+	}
+	if strings.Contains(buf.String(), "@api") {
+		t.Error("Summaries must not produce tags")
+	}
+}
@@ -0,0 +1,146 @@
+// Package term colors terminal output. Color is on when stdout is a
+// terminal, unless NO_COLOR is set, TERM is "dumb" or Configure turns it off.
+package term
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gunnarrb/jql-to-plan/internal/i18n"
+)
+
+// Color is an ANSI SGR code. All codes have two digits, so rows that start
+// with a color stay aligned in a tabwriter.
+type Color string
+
+const (
+	Default Color = "39"
+	Bold    Color = "01"
+	Red     Color = "31"
+	Green   Color = "32"
+	Yellow  Color = "33"
+)
+
+const reset = "\x1b[0m"
+
+var enabled = detect()
+
+// detect reports whether stdout supports color
+func detect() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Configure turns color off when noColor is set (the --no-color flag), and
+// otherwise leaves the automatic detection in place
+func Configure(noColor bool) {
+	if noColor {
+		enabled = false
+	}
+}
+
+// Enabled reports whether output is colored
+func Enabled() bool {
+	return enabled
+}
+
+// Start returns the escape sequence that switches to c, or "" without color.
+// Paired with End it colors a whole table row; every row of the table must
+// start with a color (Default for plain rows) to keep the columns aligned.
+func (c Color) Start() string {
+	if !enabled {
+		return ""
+	}
+	return "\x1b[" + string(c) + "m"
+}
+
+// End returns the escape sequence that resets the color, or "" without color
+func End() string {
+	if !enabled {
+		return ""
+	}
+	return reset
+}
+
+// Paint returns s in color c
+func (c Color) Paint(s string) string {
+	return c.Start() + s + End()
+}
+
+// warnings counts the warnings printed by Warnf
+var warnings atomic.Int64
+
+// annotations is the CI annotation format set by ConfigureAnnotations, or ""
+// for plain output
+var annotations string
+
+// annotationFile is the file annotations point at, or "" for none
+var annotationFile string
+
+// ConfigureAnnotations makes warnings and errors print as CI annotations
+// (the --annotations flag). The only format is "github", which prints GitHub
+// Actions workflow commands; "" keeps plain output.
+func ConfigureAnnotations(format string) error {
+	if format != "" && format != "github" {
+		return fmt.Errorf("unsupported annotation format %q (expected github)", format)
+	}
+	annotations = format
+	return nil
+}
+
+// Annotating reports whether warnings and errors print as CI annotations
+func Annotating() bool {
+	return annotations != ""
+}
+
+// AnnotateFile sets the file that the following annotations point at, such as
+// the plan being written. "" leaves annotations without a file.
+func AnnotateFile(path string) {
+	annotationFile = path
+}
+
+// Warnf prints a warning line to stdout, with the "Warning:" prefix in red.
+// The format is translated (see i18n.T).
+func Warnf(format string, args ...interface{}) {
+	warnings.Add(1)
+	msg := i18n.Sprintf(format, args...)
+	if annotations != "" {
+		fmt.Println(workflowCommand("warning", annotationFile, msg))
+		return
+	}
+	fmt.Printf("%s %s\n", Red.Paint(i18n.T("Warning:")), msg)
+}
+
+// AnnotateError prints msg as an error annotation for file, or for the file
+// set by AnnotateFile if file is "". Without annotations it prints nothing, as
+// the caller reports the error itself.
+func AnnotateError(file, msg string) {
+	if annotations == "" {
+		return
+	}
+	if file == "" {
+		file = annotationFile
+	}
+	fmt.Println(workflowCommand("error", file, msg))
+}
+
+// workflowCommand formats a GitHub Actions annotation, escaping the message
+// and file so newlines, colons and commas survive
+func workflowCommand(level, file, msg string) string {
+	escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	if file == "" {
+		return "::" + level + "::" + escape.Replace(msg)
+	}
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	return "::" + level + " file=" + property.Replace(file) + "::" + escape.Replace(msg)
+}
+
+// Warnings returns the number of warnings printed so far
+func Warnings() int {
+	return int(warnings.Load())
+}
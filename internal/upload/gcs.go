@@ -0,0 +1,66 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+type gcsUploader struct {
+	bucket string
+	prefix string
+	token  string
+	client *http.Client
+}
+
+func newGCSUploader(bucket, prefix string, opts Options, client *http.Client) (*gcsUploader, error) {
+	if bucket == "" {
+		return nil, errors.New("gs destination is missing a bucket name")
+	}
+
+	// Access tokens can be minted with `gcloud auth print-access-token`
+	token := opts.GCSAccessToken
+	if token == "" {
+		token = os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	}
+	if token == "" {
+		return nil, errors.New("gcs upload: gcs_access_token or GOOGLE_OAUTH_ACCESS_TOKEN must be set")
+	}
+
+	return &gcsUploader{
+		bucket: bucket,
+		prefix: prefix,
+		token:  token,
+		client: client,
+	}, nil
+}
+
+func (g *gcsUploader) Destination() string {
+	return "gs://" + joinKey(g.bucket, g.prefix)
+}
+
+func (g *gcsUploader) Upload(ctx context.Context, name string, data []byte) error {
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", joinKey(g.prefix, name))
+	target := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?%s", url.PathEscape(g.bucket), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Content-Type", contentType(name))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
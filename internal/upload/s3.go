@@ -0,0 +1,96 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/awssig"
+)
+
+type s3Uploader struct {
+	bucket   string
+	prefix   string
+	region   string
+	endpoint string
+	creds    awssig.Credentials
+	client   *http.Client
+}
+
+func newS3Uploader(bucket, prefix string, opts Options, client *http.Client) (*s3Uploader, error) {
+	if bucket == "" {
+		return nil, errors.New("s3 destination is missing a bucket name")
+	}
+
+	creds, err := awssig.CredentialsFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("s3 upload: %w", err)
+	}
+
+	region := opts.S3Region
+	if region == "" {
+		region = awssig.RegionFromEnv()
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &s3Uploader{
+		bucket:   bucket,
+		prefix:   prefix,
+		region:   region,
+		endpoint: strings.TrimRight(opts.S3Endpoint, "/"),
+		creds:    creds,
+		client:   client,
+	}, nil
+}
+
+func (s *s3Uploader) Destination() string {
+	return "s3://" + joinKey(s.bucket, s.prefix)
+}
+
+func (s *s3Uploader) Upload(ctx context.Context, name string, data []byte) error {
+	key := joinKey(s.prefix, name)
+
+	// Custom endpoints (MinIO etc.) use path-style addressing
+	var target string
+	if s.endpoint != "" {
+		target = fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, (&url.URL{Path: key}).EscapedPath())
+	} else {
+		target = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, (&url.URL{Path: key}).EscapedPath())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	awssig.Sign(req, data, s.region, "s3", s.creds, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+// contentType guesses a MIME type from the file extension
+func contentType(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".xml"):
+		return "application/xml"
+	case strings.HasSuffix(name, ".html"):
+		return "text/html; charset=utf-8"
+	case strings.HasSuffix(name, ".zip"):
+		return "application/zip"
+	default:
+		return "application/octet-stream"
+	}
+}
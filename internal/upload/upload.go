@@ -0,0 +1,102 @@
+// Package upload publishes generated plan files to remote destinations
+// (Amazon S3, Google Cloud Storage and WebDAV servers).
+package upload
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Uploader stores a single file under the given slash-separated name
+type Uploader interface {
+	Upload(ctx context.Context, name string, data []byte) error
+	// Destination returns a printable description of where files end up
+	Destination() string
+}
+
+// Options holds the credentials and endpoints used by the uploaders
+type Options struct {
+	S3Region       string
+	S3Endpoint     string // Optional, for S3-compatible stores such as MinIO
+	GCSAccessToken string
+	WebDAVUsername string
+	WebDAVPassword string
+	HTTPClient     *http.Client
+}
+
+// New returns an Uploader for a destination URL:
+// s3://bucket/prefix, gs://bucket/prefix or an http(s) WebDAV collection URL.
+func New(destination string, opts Options) (Uploader, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload destination %q: %w", destination, err)
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "s3":
+		return newS3Uploader(u.Host, prefix, opts, client)
+	case "gs":
+		return newGCSUploader(u.Host, prefix, opts, client)
+	case "http", "https":
+		return newWebDAVUploader(u, opts, client), nil
+	default:
+		return nil, fmt.Errorf("unsupported upload destination %q (expected s3://, gs:// or http(s)://)", destination)
+	}
+}
+
+// UploadDir uploads every file below dir, keeping the directory name as the
+// top-level path component (e.g. Plan.oplx/Actual.xml)
+func UploadDir(ctx context.Context, u Uploader, dir string) error {
+	base := filepath.Base(dir)
+	return filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		name := path.Join(base, filepath.ToSlash(rel))
+		if err := u.Upload(ctx, name, data); err != nil {
+			return fmt.Errorf("uploading %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// checkResponse turns non-2xx responses into errors
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// joinKey joins a prefix and object name into an object key
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
@@ -0,0 +1,289 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// request is a request received by a recordingServer
+type request struct {
+	Method string
+	Path   string // Escaped path with the query, if any
+	Header http.Header
+	Body   string
+}
+
+// recordingServer records requests and answers them with 200 OK, or with
+// the status statusFor returns when it is set and non-zero
+type recordingServer struct {
+	*httptest.Server
+	statusFor func(r *http.Request) int
+
+	mu       sync.Mutex
+	requests []request
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	t.Helper()
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		target := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, request{Method: r.Method, Path: target, Header: r.Header.Clone(), Body: string(body)})
+		s.mu.Unlock()
+		status := http.StatusOK
+		if s.statusFor != nil {
+			if code := s.statusFor(r); code != 0 {
+				status = code
+			}
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) recorded() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+// redirectTransport sends every request to the test server, whatever its host
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestS3Upload(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	srv := newRecordingServer(t)
+
+	u, err := New("s3://plans/team a", Options{S3Region: "eu-north-1", S3Endpoint: srv.URL + "/"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := u.Destination(); got != "s3://plans/team a" {
+		t.Errorf("Destination = %q", got)
+	}
+	if err := u.Upload(context.Background(), "Plan.oplx/Actual.xml", []byte("<scenario/>")); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	reqs := srv.recorded()
+	if len(reqs) != 1 {
+		t.Fatalf("Expected one request, got %+v", reqs)
+	}
+	r := reqs[0]
+	if r.Method != http.MethodPut || r.Path != "/plans/team%20a/Plan.oplx/Actual.xml" {
+		t.Errorf("Expected a path-style PUT of the key, got %s %s", r.Method, r.Path)
+	}
+	if r.Body != "<scenario/>" {
+		t.Errorf("Unexpected body %q", r.Body)
+	}
+	if got := r.Header.Get("Content-Type"); got != "application/xml" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-north-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date,") {
+		t.Errorf("Unexpected Authorization %q", auth)
+	}
+	if r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		t.Errorf("Expected the SigV4 date and payload hash headers, got %v", r.Header)
+	}
+}
+
+func TestS3Upload_Errors(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := New("s3://plans", Options{}); err == nil {
+		t.Error("Expected an error without AWS credentials")
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	if _, err := New("s3:///prefix", Options{}); err == nil {
+		t.Error("Expected an error without a bucket")
+	}
+
+	srv := newRecordingServer(t)
+	srv.statusFor = func(*http.Request) int { return http.StatusForbidden }
+	u, err := New("s3://plans", Options{S3Endpoint: srv.URL})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := u.Upload(context.Background(), "plan.html", nil); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the 403 as an error, got %v", err)
+	}
+}
+
+func TestGCSUpload(t *testing.T) {
+	srv := newRecordingServer(t)
+	target, _ := url.Parse(srv.URL)
+	client := &http.Client{Transport: redirectTransport{target: target}}
+
+	u, err := New("gs://plans/weekly", Options{GCSAccessToken: "ya29.token", HTTPClient: client})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := u.Destination(); got != "gs://plans/weekly" {
+		t.Errorf("Destination = %q", got)
+	}
+	if err := u.Upload(context.Background(), "Plan.oplx/Actual.xml", []byte("<scenario/>")); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	reqs := srv.recorded()
+	if len(reqs) != 1 {
+		t.Fatalf("Expected one request, got %+v", reqs)
+	}
+	r := reqs[0]
+	want := "/upload/storage/v1/b/plans/o?name=weekly%2FPlan.oplx%2FActual.xml&uploadType=media"
+	if r.Method != http.MethodPost || r.Path != want {
+		t.Errorf("Expected POST %s, got %s %s", want, r.Method, r.Path)
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer ya29.token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := r.Header.Get("Content-Type"); got != "application/xml" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if r.Body != "<scenario/>" {
+		t.Errorf("Unexpected body %q", r.Body)
+	}
+}
+
+func TestGCSUpload_TokenFromEnv(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	if _, err := New("gs://plans", Options{}); err == nil {
+		t.Error("Expected an error without an access token")
+	}
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "env-token")
+	u, err := New("gs://plans", Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := u.(*gcsUploader).token; got != "env-token" {
+		t.Errorf("Expected the token from the environment, got %q", got)
+	}
+}
+
+func TestWebDAVUpload(t *testing.T) {
+	srv := newRecordingServer(t)
+	// The plans collection exists already
+	srv.statusFor = func(r *http.Request) int {
+		if r.Method == "MKCOL" && r.URL.Path == "/dav/plans/" {
+			return http.StatusMethodNotAllowed
+		}
+		return http.StatusCreated
+	}
+	base, _ := url.Parse(srv.URL)
+	base.User = url.UserPassword("ada", "s3cret")
+	base.Path = "/dav/"
+
+	u, err := New(base.String(), Options{WebDAVUsername: "ignored", WebDAVPassword: "ignored"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := u.Destination(); got != srv.URL+"/dav" {
+		t.Errorf("Destination = %q, want it without credentials", got)
+	}
+	ctx := context.Background()
+	if err := u.Upload(ctx, "plans/Plan.oplx/Actual.xml", []byte("<scenario/>")); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if err := u.Upload(ctx, "plans/Plan.oplx/__TOC.xml", []byte("<omniplan/>")); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range srv.recorded() {
+		got = append(got, r.Method+" "+r.Path)
+		if user, pass, ok := (&http.Request{Header: r.Header}).BasicAuth(); !ok || user != "ada" || pass != "s3cret" {
+			t.Errorf("%s %s: expected the URL's basic auth, got %q %q", r.Method, r.Path, user, pass)
+		}
+	}
+	// Collections are created once, parents first
+	want := []string{
+		"MKCOL /dav/plans/",
+		"MKCOL /dav/plans/Plan.oplx/",
+		"PUT /dav/plans/Plan.oplx/Actual.xml",
+		"PUT /dav/plans/Plan.oplx/__TOC.xml",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if reqs := srv.recorded(); reqs[2].Body != "<scenario/>" || reqs[2].Header.Get("Content-Type") != "application/xml" {
+		t.Errorf("Unexpected PUT %+v", reqs[2])
+	}
+}
+
+func TestWebDAVUpload_MkcolFails(t *testing.T) {
+	srv := newRecordingServer(t)
+	srv.statusFor = func(r *http.Request) int { return http.StatusConflict }
+	u, err := New(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := u.Upload(context.Background(), "Plan.oplx/Actual.xml", nil); err == nil {
+		t.Error("Expected an error when the collection cannot be created")
+	}
+	if reqs := srv.recorded(); len(reqs) != 1 || reqs[0].Method != "MKCOL" {
+		t.Errorf("Expected nothing to be uploaded after the failed MKCOL, got %+v", reqs)
+	}
+}
+
+// memoryUploader keeps uploaded files by name
+type memoryUploader map[string]string
+
+func (m memoryUploader) Upload(_ context.Context, name string, data []byte) error {
+	m[name] = string(data)
+	return nil
+}
+
+func (m memoryUploader) Destination() string { return "memory" }
+
+func TestUploadDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Plan.oplx")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "Actual.xml"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "notes.txt"), []byte("b"), 0644)
+
+	m := memoryUploader{}
+	if err := UploadDir(context.Background(), m, dir); err != nil {
+		t.Fatalf("UploadDir failed: %v", err)
+	}
+	if len(m) != 2 || m["Plan.oplx/Actual.xml"] != "a" || m["Plan.oplx/sub/notes.txt"] != "b" {
+		t.Errorf("Unexpected uploads %v", m)
+	}
+}
+
+func TestNew_UnsupportedDestination(t *testing.T) {
+	for _, destination := range []string{"ftp://example.com/plans", "plans", "://bad"} {
+		if _, err := New(destination, Options{}); err == nil {
+			t.Errorf("Expected an error for %q", destination)
+		}
+	}
+}
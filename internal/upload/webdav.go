@@ -0,0 +1,112 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type webdavUploader struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+
+	// created remembers collections already created during this run
+	created map[string]bool
+}
+
+func newWebDAVUploader(base *url.URL, opts Options, client *http.Client) *webdavUploader {
+	username, password := opts.WebDAVUsername, opts.WebDAVPassword
+	// Credentials embedded in the URL take precedence
+	if base.User != nil {
+		username = base.User.Username()
+		password, _ = base.User.Password()
+	}
+
+	clean := *base
+	clean.User = nil
+	clean.Path = strings.TrimRight(clean.Path, "/")
+
+	return &webdavUploader{
+		base:     &clean,
+		username: username,
+		password: password,
+		client:   client,
+		created:  make(map[string]bool),
+	}
+}
+
+func (w *webdavUploader) Destination() string {
+	return w.base.String()
+}
+
+func (w *webdavUploader) Upload(ctx context.Context, name string, data []byte) error {
+	// Make sure every parent collection exists (MKCOL is not recursive)
+	dir := path.Dir(name)
+	if dir != "." {
+		var current string
+		for _, part := range strings.Split(dir, "/") {
+			current = path.Join(current, part)
+			if err := w.mkcol(ctx, current); err != nil {
+				return err
+			}
+		}
+	}
+
+	req, err := w.newRequest(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+func (w *webdavUploader) mkcol(ctx context.Context, dir string) error {
+	if w.created[dir] {
+		return nil
+	}
+
+	req, err := w.newRequest(ctx, "MKCOL", dir+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 405 Method Not Allowed means the collection already exists
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		if err := checkResponse(resp); err != nil {
+			return err
+		}
+	}
+	w.created[dir] = true
+	return nil
+}
+
+func (w *webdavUploader) newRequest(ctx context.Context, method, name string, data []byte) (*http.Request, error) {
+	target := *w.base
+	target.Path = w.base.Path + "/" + name
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return req, nil
+}
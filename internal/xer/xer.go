@@ -0,0 +1,249 @@
+// Package xer exports tickets as a Primavera P6 XER file: a project with one
+// WBS node per epic, activities with durations and dates, finish-to-start
+// relationships, resources and their assignments, and a calendar.
+package xer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+// dateFormat is the XER date and time format
+const dateFormat = "2006-01-02 15:04"
+
+// dayStart is the hour work starts on the calendar
+const dayStart = 8
+
+// IDs of the objects there is one of
+const (
+	projectID  = 1
+	calendarID = 1
+	rootWBSID  = 1
+)
+
+// table is an XER table: its fields and one value per field in each record
+type table struct {
+	name    string
+	fields  []string
+	records [][]string
+}
+
+func (t *table) add(values ...string) {
+	t.records = append(t.records, values)
+}
+
+// Write renders the tickets as one P6 project named projectName. Open work
+// is given its remaining duration and the scheduled dates as early dates, so
+// P6 reproduces the projection when scheduled with origin as data date; done
+// work is complete with its actual start, if known.
+func Write(w io.Writer, projectName string, tickets []jira.Ticket, epics map[string]jira.Ticket, origin time.Time, cal schedule.Calendar) error {
+	result, err := schedule.Schedule(schedule.ItemsFromTickets(tickets, cal), origin, cal)
+	if err != nil {
+		return err
+	}
+	shortName := shortName(projectName)
+	proj := strconv.Itoa(projectID)
+	clndr := strconv.Itoa(calendarID)
+	dataDate := at(origin, 0)
+
+	currencies := &table{name: "CURRTYPE", fields: []string{"curr_id", "decimal_digit_cnt", "curr_symbol", "decimal_symbol", "digit_group_symbol", "pos_curr_fmt_type", "neg_curr_fmt_type", "curr_type", "curr_short_name", "group_digit_cnt", "base_exch_rate"}}
+	currencies.add("1", "2", "$", ".", ",", "#1.1", "(#1.1)", "US Dollar", "USD", "3", "1")
+
+	calendars := &table{name: "CALENDAR", fields: []string{"clndr_id", "default_flag", "clndr_name", "proj_id", "base_clndr_id", "last_chng_date", "clndr_type", "day_hr_cnt", "week_hr_cnt", "month_hr_cnt", "year_hr_cnt", "rsrc_private", "clndr_data"}}
+	workdays := 0
+	for _, ok := range cal.Workdays {
+		if ok {
+			workdays++
+		}
+	}
+	calendars.add(clndr, "Y", "Standard", "", "", dataDate, "CA_Base",
+		number(cal.HoursPerDay), number(cal.HoursPerDay*float64(workdays)),
+		number(cal.HoursPerDay*float64(workdays)*52/12), number(cal.HoursPerDay*float64(workdays)*52),
+		"N", calendarData(cal))
+
+	projects := &table{name: "PROJECT", fields: []string{"proj_id", "proj_short_name", "clndr_id", "plan_start_date", "last_recalc_date", "def_complete_pct_type", "def_duration_type", "def_task_type", "task_code_base", "task_code_step", "add_by_name"}}
+	projects.add(proj, shortName, clndr, dataDate, dataDate, "CP_Phys", "DT_FixedDUR2", "TT_Task", "1000", "10", "jql-to-plan")
+
+	// One WBS node per epic below the project node, in first-seen order
+	wbs := &table{name: "PROJWBS", fields: []string{"wbs_id", "proj_id", "parent_wbs_id", "seq_num", "proj_node_flag", "status_code", "wbs_short_name", "wbs_name"}}
+	wbs.add(strconv.Itoa(rootWBSID), proj, "", "0", "Y", "WS_Open", shortName, clean(projectName))
+	wbsIDs := make(map[string]int)
+	for _, t := range tickets {
+		if _, seen := wbsIDs[t.EpicLink]; seen || t.EpicLink == "" {
+			continue
+		}
+		id := rootWBSID + len(wbsIDs) + 1
+		wbsIDs[t.EpicLink] = id
+		name := t.EpicLink
+		if epic, ok := epics[t.EpicLink]; ok && epic.Summary != "" {
+			name = epic.Summary
+		}
+		wbs.add(strconv.Itoa(id), proj, strconv.Itoa(rootWBSID), strconv.Itoa(len(wbsIDs)), "N", "WS_Open", t.EpicLink, clean(name))
+	}
+
+	// Resources in name order, for everyone assigned to a ticket
+	rsrcIDs := make(map[string]int)
+	var people []string
+	for _, t := range tickets {
+		for _, name := range append([]string{t.Assignee}, t.CoAssignees...) {
+			if _, seen := rsrcIDs[name]; !seen && name != "" {
+				rsrcIDs[name] = 0
+				people = append(people, name)
+			}
+		}
+	}
+	sort.Strings(people)
+	resources := &table{name: "RSRC", fields: []string{"rsrc_id", "clndr_id", "rsrc_name", "rsrc_short_name", "rsrc_type", "active_flag", "def_qty_per_hr"}}
+	for i, name := range people {
+		rsrcIDs[name] = i + 1
+		resources.add(strconv.Itoa(i+1), clndr, clean(name), resourceShortName(name, i+1), "RT_Labor", "Y", "1")
+	}
+
+	taskIDs := make(map[string]int, len(tickets))
+	for i, t := range tickets {
+		taskIDs[t.Key] = i + 1
+	}
+	tasks := &table{name: "TASK", fields: []string{"task_id", "proj_id", "wbs_id", "clndr_id", "task_code", "task_name", "task_type", "duration_type", "complete_pct_type", "status_code", "phys_complete_pct",
+		"target_drtn_hr_cnt", "remain_drtn_hr_cnt", "target_start_date", "target_end_date", "early_start_date", "early_end_date", "act_start_date", "cstr_type", "cstr_date", "cstr_type2", "cstr_date2"}}
+	preds := &table{name: "TASKPRED", fields: []string{"task_pred_id", "task_id", "pred_task_id", "proj_id", "pred_proj_id", "pred_type", "lag_hr_cnt"}}
+	assignments := &table{name: "TASKRSRC", fields: []string{"taskrsrc_id", "task_id", "proj_id", "rsrc_id", "rsrc_type", "remain_qty", "target_qty", "remain_qty_per_hr", "target_qty_per_hr"}}
+	for _, t := range tickets {
+		id := strconv.Itoa(taskIDs[t.Key])
+		wbsID := rootWBSID
+		if n, ok := wbsIDs[t.EpicLink]; ok {
+			wbsID = n
+		}
+		hours := schedule.EffortHours(t, cal.HoursPerDay)
+		remaining := hours
+
+		status, complete := "TK_NotStart", "0"
+		var start, finish, actualStart string
+		switch {
+		case t.IsDone():
+			status, complete, remaining = "TK_Complete", "100", 0
+			if !t.ActualStart.IsZero() {
+				actualStart = at(t.ActualStart, 0)
+				start = actualStart
+			}
+		default:
+			slot := result.Slots[t.Key]
+			start = at(result.StartDate(t.Key), math.Mod(slot.Start, cal.HoursPerDay))
+			end := math.Mod(slot.Finish, cal.HoursPerDay)
+			if end == 0 && slot.Finish > 0 {
+				end = cal.HoursPerDay
+			}
+			finish = at(result.FinishDate(t.Key), end)
+			if !t.ActualStart.IsZero() {
+				status = "TK_Active"
+				actualStart = at(t.ActualStart, 0)
+			}
+		}
+
+		// Target dates are a start-on-or-after constraint; the due date,
+		// or the target end, a finish-on-or-before one
+		var constraints []string
+		if !t.TargetStart.IsZero() {
+			constraints = append(constraints, "CS_MSOA", at(t.TargetStart, 0))
+		}
+		if deadline := t.DueDate; !deadline.IsZero() || !t.TargetEnd.IsZero() {
+			if deadline.IsZero() {
+				deadline = t.TargetEnd
+			}
+			constraints = append(constraints, "CS_MEOB", at(deadline, cal.HoursPerDay))
+		}
+		// Type and date of the primary and secondary constraint, blank if unset
+		constraints = append(constraints, make([]string, 4-len(constraints))...)
+
+		tasks.add(append([]string{id, proj, strconv.Itoa(wbsID), clndr, t.Key, clean(t.Summary), "TT_Task", "DT_FixedDUR2", "CP_Phys", status, complete,
+			number(hours), number(remaining), start, finish, start, finish, actualStart}, constraints...)...)
+
+		for _, key := range t.DependencyKeys {
+			if pred, ok := taskIDs[key]; ok && key != t.Key {
+				preds.add(strconv.Itoa(len(preds.records)+1), id, strconv.Itoa(pred), proj, proj, "PR_FS", "0")
+			}
+		}
+		for _, name := range append([]string{t.Assignee}, t.CoAssignees...) {
+			if name == "" {
+				continue
+			}
+			assignments.add(strconv.Itoa(len(assignments.records)+1), id, proj, strconv.Itoa(rsrcIDs[name]), "RT_Labor", number(remaining), number(hours), "1", "1")
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ERMHDR\t8.0\t%s\tProject\tadmin\tjql-to-plan\tdbxDatabaseNoName\tProject Management\tUSD\r\n", origin.Format("2006-01-02"))
+	for _, t := range []*table{currencies, calendars, projects, wbs, resources, tasks, preds, assignments} {
+		fmt.Fprintf(bw, "%%T\t%s\r\n%%F\t%s\r\n", t.name, strings.Join(t.fields, "\t"))
+		for _, r := range t.records {
+			fmt.Fprintf(bw, "%%R\t%s\r\n", strings.Join(r, "\t"))
+		}
+	}
+	bw.WriteString("%E\r\n")
+	return bw.Flush()
+}
+
+// at returns the date of d at hours working hours into the working day
+func at(d time.Time, hours float64) string {
+	day := time.Date(d.Year(), d.Month(), d.Day(), dayStart, 0, 0, 0, time.UTC)
+	return day.Add(time.Duration(hours * float64(time.Hour))).Format(dateFormat)
+}
+
+// calendarData describes the working week in P6's calendar notation: one
+// work period per workday, starting at dayStart. Days are numbered from
+// Sunday as 1.
+func calendarData(cal schedule.Calendar) string {
+	minutes := min(int(math.Round((dayStart+cal.HoursPerDay)*60)), 24*60)
+	end := fmt.Sprintf("%02d:%02d", minutes/60%24, minutes%60)
+	var b strings.Builder
+	b.WriteString("(0||CalendarData()((0||DaysOfWeek()(")
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if cal.Workdays[day] {
+			fmt.Fprintf(&b, "(0||%d()((0||0(s|%02d:00|f|%s)())))", day+1, dayStart, end)
+		} else {
+			fmt.Fprintf(&b, "(0||%d()())", day+1)
+		}
+	}
+	b.WriteString("))(0||Exceptions()()))")
+	return b.String()
+}
+
+// number formats a quantity without trailing zeros
+func number(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// clean drops the tabs and line breaks that would break a record
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// shortName makes the project ID from its name: P6 allows 40 characters
+func shortName(name string) string {
+	name = clean(name)
+	if runes := []rune(name); len(runes) > 40 {
+		name = string(runes[:40])
+	}
+	if name == "" {
+		name = "Plan"
+	}
+	return name
+}
+
+// resourceShortName makes a unique resource ID from the name: at most 20
+// characters of it, followed by the resource number
+func resourceShortName(name string, n int) string {
+	id := strings.ToUpper(strings.Join(strings.Fields(name), ""))
+	if runes := []rune(id); len(runes) > 20 {
+		id = string(runes[:20])
+	}
+	return id + strconv.Itoa(n)
+}
@@ -0,0 +1,52 @@
+package xer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/schedule"
+)
+
+func TestWrite(t *testing.T) {
+	tickets := []jira.Ticket{
+		{Key: "TASK-1", Summary: "Build API", Assignee: "Alice", EffortDays: 1.5, EpicLink: "EPIC-1"},
+		{Key: "TASK-2", Summary: "Docs\tand examples", Assignee: "Alice", CoAssignees: []string{"Bob"}, EffortDays: 1, EpicLink: "EPIC-1",
+			DependencyKeys: []string{"TASK-1", "OTHER-1"}, DueDate: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{Key: "TASK-3", Summary: "Old work", StatusCategory: "done", ActualStart: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	epics := map[string]jira.Ticket{"EPIC-1": {Key: "EPIC-1", Summary: "Backend"}}
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Payments", tickets, epics, monday, schedule.DefaultCalendar()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "ERMHDR\t8.0\t2026-01-05\t") || !strings.HasSuffix(out, "%E\r\n") {
+		t.Errorf("Expected the XER header and end marker, got:\n%s", out)
+	}
+	for _, want := range []string{
+		// Monday to Friday, 08:00 to 16:00
+		"(0||1()())(0||2()((0||0(s|08:00|f|16:00)())))",
+		"%R\t1\tPayments\t1\t2026-01-05 08:00\t2026-01-05 08:00\t",
+		"%R\t2\t1\t1\t1\tN\tWS_Open\tEPIC-1\tBackend\r\n",
+		"%R\t1\t1\tAlice\tALICE1\tRT_Labor\t",
+		"%R\t2\t1\tBob\tBOB2\tRT_Labor\t",
+		// Half days continue on the same day
+		"%R\t1\t1\t2\t1\tTASK-1\tBuild API\tTT_Task\tDT_FixedDUR2\tCP_Phys\tTK_NotStart\t0\t12\t12\t2026-01-05 08:00\t2026-01-06 12:00\t",
+		"%R\t2\t1\t2\t1\tTASK-2\tDocs and examples\tTT_Task\tDT_FixedDUR2\tCP_Phys\tTK_NotStart\t0\t8\t8\t2026-01-06 12:00\t2026-01-07 12:00\t2026-01-06 12:00\t2026-01-07 12:00\t\tCS_MEOB\t2026-01-09 16:00\t\t\r\n",
+		// Tickets without an epic are in the project node
+		"%R\t3\t1\t1\t1\tTASK-3\tOld work\tTT_Task\tDT_FixedDUR2\tCP_Phys\tTK_Complete\t100\t8\t0\t2025-12-01 08:00\t\t2025-12-01 08:00\t\t2025-12-01 08:00\t",
+		// Dependencies outside the export are dropped
+		"%F\ttask_pred_id\ttask_id\tpred_task_id\tproj_id\tpred_proj_id\tpred_type\tlag_hr_cnt\r\n%R\t1\t2\t1\t1\t1\tPR_FS\t0\r\n%T",
+		"%R\t3\t2\t1\t2\tRT_Labor\t8\t8\t1\t1\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output lacks %q:\n%s", want, out)
+		}
+	}
+}
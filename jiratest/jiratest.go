@@ -0,0 +1,342 @@
+// Package jiratest provides a fake Jira server for tests, serving the REST
+// endpoints jql-to-plan uses from seeded issues, so the whole pipeline from
+// query to plan runs without a Jira instance.
+//
+//	srv := jiratest.NewServer()
+//	defer srv.Close()
+//	srv.AddField("customfield_10016", "Story Points")
+//	srv.AddIssues(jiratest.Issue{Key: "WEB-1", Summary: "Login", Fields: map[string]interface{}{"customfield_10016": 3}})
+//	// Point the client at srv.URL
+//
+// Searches support "key in (...)" and "project = X" or "project in (...)"
+// conditions; other JQL matches every issue unless its results were set with
+// SetQuery.
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPageSize is the most issues a search page holds unless PageSize is set
+const DefaultPageSize = 50
+
+// Issue is a seeded issue. Empty fields are left out of responses, except
+// the issue type and status, which default to "Story" and "To Do".
+type Issue struct {
+	Key            string
+	Summary        string
+	IssueType      string
+	Subtask        bool
+	Status         string
+	StatusCategory string // "new" (default), "indeterminate" or "done"
+	Assignee       string // Display name, also used as user name
+	Parent         string
+	Labels         []string
+	DueDate        string   // YYYY-MM-DD
+	DependsOn      []string // Keys of the issues linked as "Dependent" outward
+
+	// Fields holds custom field values by field ID, e.g. an estimate under
+	// "customfield_10016" or an epic key under the epic link field
+	Fields map[string]interface{}
+}
+
+// Server is a fake Jira server, started by NewServer. Its methods are safe
+// for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Token, if set, is the PAT requests must carry as a Bearer token;
+	// others are refused with 401
+	Token string
+
+	// PageSize caps the issues per search page, as Jira caps maxResults
+	// (DefaultPageSize if zero)
+	PageSize int
+
+	mu         sync.Mutex
+	issues     []Issue
+	fields     []field
+	queries    map[string][]string
+	throttled  int
+	retryAfter time.Duration
+	requests   []string
+}
+
+// field is a field of the server, listed by /rest/api/2/field
+type field struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+}
+
+// standardFields are the system fields every server has
+var standardFields = []field{
+	{ID: "summary", Name: "Summary"},
+	{ID: "issuetype", Name: "Issue Type"},
+	{ID: "status", Name: "Status"},
+	{ID: "assignee", Name: "Assignee"},
+	{ID: "parent", Name: "Parent"},
+	{ID: "labels", Name: "Labels"},
+	{ID: "duedate", Name: "Due date"},
+	{ID: "issuelinks", Name: "Linked Issues"},
+}
+
+// NewServer starts a fake Jira server without issues. Close it when done.
+func NewServer() *Server {
+	s := &Server{fields: append([]field(nil), standardFields...), queries: make(map[string][]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// AddIssues seeds issues, returned by searches in the order they were added
+func (s *Server) AddIssues(issues ...Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issues = append(s.issues, issues...)
+}
+
+// AddField declares a custom field, so that searches report its name and
+// configurations can refer to it by name
+func (s *Server) AddField(id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fields = append(s.fields, field{ID: id, Name: name, Custom: true})
+}
+
+// SetQuery makes searches for jql return the issues with the keys, in that order
+func (s *Server) SetQuery(jql string, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[strings.TrimSpace(jql)] = keys
+}
+
+// Throttle answers the next n requests with HTTP 429 and a Retry-After
+// header of retryAfter, as Jira does when rate limiting
+func (s *Server) Throttle(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled, s.retryAfter = n, retryAfter
+}
+
+// Requests returns the requests served so far as method and path, e.g.
+// "GET /rest/api/2/search"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+		return
+	}
+	if s.throttled > 0 {
+		s.throttled--
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.retryAfter.Seconds())))
+		writeError(w, http.StatusTooManyRequests, "Rate limit exceeded.")
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+		issues := s.match(query.Get("jql"))
+		start, _ := strconv.Atoi(query.Get("startAt"))
+		page := s.page(issues, start, query.Get("maxResults"))
+		result := map[string]interface{}{"startAt": start, "maxResults": len(page), "total": len(issues), "issues": s.render(page)}
+		s.addNames(result, query.Get("expand"))
+		writeJSON(w, result)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/3/search/jql":
+		issues := s.match(query.Get("jql"))
+		start, _ := strconv.Atoi(query.Get("nextPageToken"))
+		page := s.page(issues, start, query.Get("maxResults"))
+		result := map[string]interface{}{"issues": s.render(page), "isLast": start+len(page) >= len(issues)}
+		if start+len(page) < len(issues) {
+			result["nextPageToken"] = strconv.Itoa(start + len(page))
+		}
+		s.addNames(result, query.Get("expand"))
+		writeJSON(w, result)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/3/issue/bulkfetch":
+		var body struct {
+			Keys []string `json:"issueIdsOrKeys"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, map[string]interface{}{"issues": s.render(s.byKeys(body.Keys))})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/field":
+		writeJSON(w, s.fields)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/serverInfo":
+		writeJSON(w, map[string]interface{}{"version": "9.12.0", "serverTitle": "jiratest", "deploymentType": "Server", "baseUrl": s.URL})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/myself":
+		writeJSON(w, map[string]interface{}{"name": "jiratest", "displayName": "jiratest"})
+
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No resource %s %s in jiratest.", r.Method, r.URL.Path))
+	}
+}
+
+var (
+	keyIn     = regexp.MustCompile(`(?i)\bkey\s+in\s*\(([^)]*)\)`)
+	projectIs = regexp.MustCompile(`(?i)\bproject\s*(?:=\s*"?([\w-]+)"?|in\s*\(([^)]*)\))`)
+)
+
+// match returns the issues a query selects
+func (s *Server) match(jql string) []Issue {
+	if keys, ok := s.queries[strings.TrimSpace(jql)]; ok {
+		return s.byKeys(keys)
+	}
+	if m := keyIn.FindStringSubmatch(jql); m != nil {
+		return s.byKeys(list(m[1]))
+	}
+	if m := projectIs.FindStringSubmatch(jql); m != nil {
+		projects := list(m[2])
+		if m[1] != "" {
+			projects = []string{m[1]}
+		}
+		var issues []Issue
+		for _, i := range s.issues {
+			for _, p := range projects {
+				if strings.HasPrefix(i.Key, strings.ToUpper(p)+"-") {
+					issues = append(issues, i)
+					break
+				}
+			}
+		}
+		return issues
+	}
+	return s.issues
+}
+
+// list splits a JQL list such as `A-1, "A-2"`
+func list(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.Trim(strings.TrimSpace(v), `"'`); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// byKeys returns the issues with the keys that exist, in the order of keys
+func (s *Server) byKeys(keys []string) []Issue {
+	var issues []Issue
+	for _, key := range keys {
+		for _, i := range s.issues {
+			if strings.EqualFold(i.Key, key) {
+				issues = append(issues, i)
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// page returns the issues of a search page starting at start
+func (s *Server) page(issues []Issue, start int, maxResults string) []Issue {
+	size := s.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if n, err := strconv.Atoi(maxResults); err == nil && n > 0 && n < size {
+		size = n
+	}
+	if start >= len(issues) {
+		return nil
+	}
+	return issues[start:min(start+size, len(issues))]
+}
+
+// addNames adds the field ID to name map when a search expands names
+func (s *Server) addNames(result map[string]interface{}, expand string) {
+	if !strings.Contains(expand, "names") {
+		return
+	}
+	names := make(map[string]string, len(s.fields))
+	for _, f := range s.fields {
+		names[f.ID] = f.Name
+	}
+	result["names"] = names
+}
+
+// render converts issues to their REST representation
+func (s *Server) render(issues []Issue) []interface{} {
+	rendered := make([]interface{}, len(issues))
+	for n, i := range issues {
+		issueType, status, category := i.IssueType, i.Status, i.StatusCategory
+		if issueType == "" {
+			issueType = "Story"
+		}
+		if status == "" {
+			status = "To Do"
+		}
+		if category == "" {
+			category = "new"
+		}
+		fields := map[string]interface{}{
+			"summary":   i.Summary,
+			"issuetype": map[string]interface{}{"name": issueType, "subtask": i.Subtask},
+			"status":    map[string]interface{}{"name": status, "statusCategory": map[string]interface{}{"key": category}},
+			"labels":    i.Labels,
+		}
+		if i.Assignee != "" {
+			fields["assignee"] = map[string]interface{}{"name": i.Assignee, "key": i.Assignee, "displayName": i.Assignee}
+		}
+		if i.Parent != "" {
+			fields["parent"] = map[string]interface{}{"key": i.Parent}
+		}
+		if i.DueDate != "" {
+			fields["duedate"] = i.DueDate
+		}
+		links := make([]interface{}, 0, len(i.DependsOn))
+		for _, key := range i.DependsOn {
+			links = append(links, map[string]interface{}{
+				"type":         map[string]interface{}{"name": "Dependent", "inward": "is depended on by", "outward": "depends on"},
+				"outwardIssue": map[string]interface{}{"key": key},
+			})
+		}
+		fields["issuelinks"] = links
+		for id, value := range i.Fields {
+			fields[id] = value
+		}
+		rendered[n] = map[string]interface{}{
+			"id":     strconv.Itoa(10000 + n),
+			"key":    i.Key,
+			"self":   s.URL + "/rest/api/2/issue/" + i.Key,
+			"fields": fields,
+		}
+	}
+	return rendered
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with a Jira error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errorMessages": []string{message}, "errors": map[string]string{}})
+}
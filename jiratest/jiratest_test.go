@@ -0,0 +1,138 @@
+package jiratest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// get fetches path from the server and decodes the JSON response
+func get(t *testing.T, s *Server, path string, v interface{}) *http.Response {
+	t.Helper()
+	resp, err := http.Get(s.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("Decoding %s failed: %v", path, err)
+		}
+	}
+	return resp
+}
+
+func keysOf(issues []struct{ Key string }) string {
+	keys := make([]string, len(issues))
+	for i, issue := range issues {
+		keys[i] = issue.Key
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestServer_Search(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	s.AddIssues(Issue{Key: "WEB-1"}, Issue{Key: "API-1"}, Issue{Key: "WEB-2"}, Issue{Key: "WEB-3"})
+	s.SetQuery("filter = 10001", "WEB-3", "API-1")
+
+	tests := []struct {
+		jql       string
+		startAt   string
+		want      string
+		wantTotal int
+	}{
+		{"project = WEB ORDER BY rank", "0", "WEB-1,WEB-2", 3},
+		{"project = WEB ORDER BY rank", "2", "WEB-3", 3},
+		{`project in (api, "WEB")`, "0", "WEB-1,API-1", 4},
+		{"key in (WEB-3, WEB-1, WEB-9)", "0", "WEB-3,WEB-1", 2},
+		{"filter = 10001", "0", "WEB-3,API-1", 2},
+		{"assignee = currentUser()", "3", "WEB-3", 4},
+	}
+	for _, tt := range tests {
+		var result struct {
+			Total  int
+			Issues []struct{ Key string }
+		}
+		get(t, s, "/rest/api/2/search?"+url.Values{"jql": {tt.jql}, "startAt": {tt.startAt}, "maxResults": {"100"}}.Encode(), &result)
+		if got := keysOf(result.Issues); got != tt.want || result.Total != tt.wantTotal {
+			t.Errorf("Search %q from %s = %s of %d, want %s of %d", tt.jql, tt.startAt, got, result.Total, tt.want, tt.wantTotal)
+		}
+	}
+}
+
+func TestServer_SearchJQL(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddField("customfield_10016", "Story Points")
+	s.AddIssues(Issue{Key: "WEB-1", Fields: map[string]interface{}{"customfield_10016": 3}}, Issue{Key: "WEB-2"}, Issue{Key: "WEB-3"})
+
+	var keys []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatalf("Expected the last page by now, got %v", keys)
+		}
+		var result struct {
+			Issues []struct {
+				Key    string
+				Fields map[string]interface{}
+			}
+			Names         map[string]string
+			NextPageToken string
+			IsLast        bool
+		}
+		get(t, s, "/rest/api/3/search/jql?"+url.Values{"jql": {"project = WEB"}, "maxResults": {"2"}, "nextPageToken": {token}, "expand": {"names"}}.Encode(), &result)
+		if result.Names["customfield_10016"] != "Story Points" {
+			t.Errorf("Expected the custom field's name, got %v", result.Names)
+		}
+		for _, i := range result.Issues {
+			keys = append(keys, i.Key)
+			if i.Key == "WEB-1" && i.Fields["customfield_10016"] != 3.0 {
+				t.Errorf("Expected WEB-1's custom field, got %v", i.Fields)
+			}
+		}
+		if result.IsLast {
+			break
+		}
+		token = result.NextPageToken
+	}
+	if strings.Join(keys, ",") != "WEB-1,WEB-2,WEB-3" {
+		t.Errorf("Expected all issues over two pages, got %v", keys)
+	}
+}
+
+func TestServer_Throttle(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Token = "pat"
+	s.Throttle(1, 30*time.Second)
+
+	resp := get(t, s, "/rest/api/2/myself", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL+"/rest/api/2/myself", nil)
+	req.Header.Set("Authorization", "Bearer pat")
+	for _, want := range []int{http.StatusTooManyRequests, http.StatusOK} {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Expected %d, got %d", want, resp.StatusCode)
+		}
+		if want == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "30" {
+			t.Errorf("Expected Retry-After 30, got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+	if got := len(s.Requests()); got != 3 {
+		t.Errorf("Expected 3 requests logged, got %d", got)
+	}
+}
@@ -0,0 +1,164 @@
+// Package plan builds OmniPlan plans from tickets of any source, so Go
+// programs can plan work kept in in-house systems next to, or instead of, Jira.
+//
+//	b := plan.NewBuilder("Platform").
+//		AddProvider(plan.ProviderFunc(fetchFromTracker)).
+//		AddMilestone(plan.Milestone{Name: "Beta", Date: beta, DependsOn: []string{"TRK-12"}})
+//	if err := b.WritePackage(ctx, "Platform.oplx"); err != nil {
+//		...
+//	}
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gunnarrb/jql-to-plan/internal/jira"
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+)
+
+// Task is a unit of planned work: its Key, Summary, Assignee, EffortDays and
+// DependencyKeys are what most plans need. It is the ticket model the
+// serializers use, so the Jira-specific fields may stay empty.
+type Task = jira.Ticket
+
+// Milestone is a fixed-date commitment depending on tasks
+type Milestone = omniplan.Milestone
+
+// TicketProvider supplies the tasks of a plan from some source
+type TicketProvider interface {
+	FetchTickets(ctx context.Context) ([]Task, error)
+}
+
+// ProviderFunc adapts a function to a TicketProvider
+type ProviderFunc func(ctx context.Context) ([]Task, error)
+
+// FetchTickets calls f
+func (f ProviderFunc) FetchTickets(ctx context.Context) ([]Task, error) {
+	return f(ctx)
+}
+
+// Tasks returns a provider of a fixed list of tasks
+func Tasks(tasks ...Task) TicketProvider {
+	return ProviderFunc(func(context.Context) ([]Task, error) {
+		return tasks, nil
+	})
+}
+
+// Builder assembles a plan from the tasks of its providers, in the order they
+// were added. Builders are themselves providers, so plans can be composed.
+type Builder struct {
+	serializer *omniplan.Serializer
+	providers  []TicketProvider
+	epics      map[string]Task
+}
+
+// NewBuilder creates a builder for a plan of the given project name
+func NewBuilder(projectName string) *Builder {
+	return &Builder{serializer: omniplan.NewSerializer(projectName)}
+}
+
+// AddProvider adds a source of tasks
+func (b *Builder) AddProvider(p TicketProvider) *Builder {
+	b.providers = append(b.providers, p)
+	return b
+}
+
+// AddMilestone adds a fixed-date milestone
+func (b *Builder) AddMilestone(m Milestone) *Builder {
+	b.serializer.Milestones = append(b.serializer.Milestones, m)
+	return b
+}
+
+// AddEpic adds an epic. Tasks whose EpicLink is its Key are grouped under it,
+// with a milestone for its completion.
+func (b *Builder) AddEpic(epic Task) *Builder {
+	if b.epics == nil {
+		b.epics = make(map[string]Task)
+	}
+	b.epics[epic.Key] = epic
+	b.serializer.GroupByEpic = true
+	return b
+}
+
+// NestSubtasks turns tasks into groups of the tasks whose Parent they are
+func (b *Builder) NestSubtasks() *Builder {
+	b.serializer.NestSubtasks = true
+	return b
+}
+
+// MaxTaskDays splits tasks of more effort into sequential parts of at most
+// this many days
+func (b *Builder) MaxTaskDays(days float64) *Builder {
+	b.serializer.MaxTaskDays = days
+	return b
+}
+
+// MaxTitleLength cuts longer task and epic titles, in characters, with an
+// ellipsis; the whole summary stays in the task note
+func (b *Builder) MaxTitleLength(chars int) *Builder {
+	b.serializer.MaxTitleLength = chars
+	return b
+}
+
+// FetchTickets fetches the tasks of all providers. Every task needs a key,
+// unique across providers, for dependencies to refer to.
+func (b *Builder) FetchTickets(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	seen := make(map[string]bool)
+	for i, p := range b.providers {
+		fetched, err := p.FetchTickets(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("provider %d: %w", i+1, err)
+		}
+		for _, t := range fetched {
+			if t.Key == "" {
+				return nil, fmt.Errorf("provider %d: task %q has no key", i+1, t.Summary)
+			}
+			if seen[t.Key] {
+				return nil, fmt.Errorf("provider %d: duplicate task key %q", i+1, t.Key)
+			}
+			seen[t.Key] = true
+		}
+		tasks = append(tasks, fetched...)
+	}
+	return tasks, nil
+}
+
+// Write fetches the tasks and writes the plan as an OmniPlan scenario
+// document, the Actual.xml of a package
+func (b *Builder) Write(ctx context.Context, w io.Writer) error {
+	tasks, err := b.FetchTickets(ctx)
+	if err != nil {
+		return err
+	}
+	return b.serializer.Serialize(w, tasks, b.epics)
+}
+
+// WritePackage fetches the tasks and writes the plan as an OmniPlan package
+// directory, creating it if needed
+func (b *Builder) WritePackage(ctx context.Context, dir string) error {
+	tasks, err := b.FetchTickets(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, omniplan.ActualScenarioFile))
+	if err != nil {
+		return err
+	}
+	if err := b.serializer.Serialize(f, tasks, b.epics); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return omniplan.WritePackageTOC(dir)
+}
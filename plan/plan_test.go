@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunnarrb/jql-to-plan/internal/omniplan"
+)
+
+func TestBuilder_WritePackage(t *testing.T) {
+	tracker := ProviderFunc(func(ctx context.Context) ([]Task, error) {
+		return []Task{
+			{Key: "TRK-1", Summary: "Design", Assignee: "Alice", EffortDays: 2},
+			{Key: "TRK-2", Summary: "Build", Assignee: "Bob", EffortDays: 3, DependencyKeys: []string{"TRK-1"}},
+		}, nil
+	})
+	b := NewBuilder("Platform").
+		AddProvider(tracker).
+		AddProvider(Tasks(Task{Key: "OPS-1", Summary: "Provision", DependencyKeys: []string{"TRK-2"}})).
+		AddMilestone(Milestone{Name: "Beta", Date: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), DependsOn: []string{"OPS-1"}})
+
+	dir := filepath.Join(t.TempDir(), "Platform.oplx")
+	if err := b.WritePackage(context.Background(), dir); err != nil {
+		t.Fatalf("WritePackage failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, omniplan.TOCFile)); err != nil {
+		t.Errorf("expected a table of contents: %v", err)
+	}
+	scenario, err := omniplan.ReadScenarioFile(filepath.Join(dir, omniplan.ActualScenarioFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byKey := make(map[string]omniplan.Task)
+	var milestone *omniplan.Task
+	for _, task := range scenario.Tasks {
+		if key := task.UserDataValue(omniplan.UserDataJiraKey); key != "" {
+			byKey[key] = task
+		}
+		if task.Title == "Beta" {
+			milestone = &task
+		}
+	}
+	if len(byKey) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(byKey))
+	}
+	if prereqs := byKey["OPS-1"].Prerequisites; len(prereqs) != 1 || prereqs[0].IDRef != byKey["TRK-2"].ID {
+		t.Errorf("expected OPS-1 to depend on TRK-2 across providers, got %+v", prereqs)
+	}
+	if milestone == nil || len(milestone.Prerequisites) != 1 || milestone.Prerequisites[0].IDRef != byKey["OPS-1"].ID {
+		t.Errorf("expected the Beta milestone after OPS-1, got %+v", milestone)
+	}
+}
+
+func TestBuilder_Compose(t *testing.T) {
+	team := NewBuilder("Team").AddProvider(Tasks(Task{Key: "A-1", Summary: "One"}))
+	var buf bytes.Buffer
+	if err := NewBuilder("Programme").AddProvider(team).Write(context.Background(), &buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<title>One</title>") {
+		t.Errorf("expected the nested builder's task in the plan:\n%s", buf.String())
+	}
+}
+
+func TestBuilder_FetchTickets_Errors(t *testing.T) {
+	failure := errors.New("tracker unavailable")
+	tests := map[string]struct {
+		providers []TicketProvider
+		want      string
+	}{
+		"provider error": {
+			providers: []TicketProvider{ProviderFunc(func(context.Context) ([]Task, error) { return nil, failure })},
+			want:      "tracker unavailable",
+		},
+		"missing key": {
+			providers: []TicketProvider{Tasks(Task{Summary: "Keyless"})},
+			want:      "has no key",
+		},
+		"duplicate key": {
+			providers: []TicketProvider{Tasks(Task{Key: "A-1"}), Tasks(Task{Key: "A-1"})},
+			want:      `provider 2: duplicate task key "A-1"`,
+		},
+	}
+	for name, tt := range tests {
+		b := NewBuilder("Plan")
+		for _, p := range tt.providers {
+			b.AddProvider(p)
+		}
+		_, err := b.FetchTickets(context.Background())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tt.want, err)
+		}
+	}
+	_, err := NewBuilder("Plan").AddProvider(ProviderFunc(func(context.Context) ([]Task, error) { return nil, failure })).FetchTickets(context.Background())
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the provider error to be wrapped, got %v", err)
+	}
+}